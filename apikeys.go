@@ -0,0 +1,72 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+)
+
+// APIKeyParams holds data used to create a new API key.
+// More at: https://docs.btcpayserver.org/API/Greenfield/v1/#tag/API-Keys
+type APIKeyParams struct {
+	Label       string      `json:"label,omitempty"`
+	Permissions Permissions `json:"permissions"`
+}
+
+// APIKey holds data of an API key.
+type APIKey struct {
+	APIKey      string      `json:"apiKey"`
+	Label       string      `json:"label"`
+	Permissions Permissions `json:"permissions"`
+}
+
+// CreateAPIKey creates a new API key for the user that owns the current
+// API key. The new key's permissions cannot exceed the current key's.
+func (c *Client) CreateAPIKey(ctx context.Context, p APIKeyParams, opts ...RequestOption) (APIKey, error) {
+	resp, err := c.sendAPI(ctx, http.MethodPost, newRoute("/api/v1/api-keys"), nil, p, opts...)
+	if err != nil {
+		return APIKey{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var k APIKey
+
+	if err = c.decode(resp.Body, &k); err != nil {
+		return APIKey{}, err
+	}
+
+	return k, nil
+}
+
+// CurrentAPIKey retrieves data of the API key used by the client.
+func (c *Client) CurrentAPIKey(ctx context.Context, opts ...RequestOption) (APIKey, error) {
+	resp, err := c.sendAPI(ctx, http.MethodGet, newRoute("/api/v1/api-keys/current"), nil, nil, opts...)
+	if err != nil {
+		return APIKey{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var k APIKey
+
+	if err = c.decode(resp.Body, &k); err != nil {
+		return APIKey{}, err
+	}
+
+	return k, nil
+}
+
+// RevokeAPIKey revokes the specified API key. If the key is empty,
+// the API key used by the client is revoked.
+func (c *Client) RevokeAPIKey(ctx context.Context, key string, opts ...RequestOption) error {
+	if key == "" {
+		key = "current"
+	}
+
+	resp, err := c.sendAPI(ctx, http.MethodDelete, newRoute("/api/v1/api-keys/:id", key), nil, nil, opts...)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
@@ -0,0 +1,174 @@
+package btcpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_CreateAPIKey(t *testing.T) {
+	check := func(r *http.Request) error {
+		var p map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			return err
+		}
+
+		if p["label"] != "l1" || len(p["permissions"].([]interface{})) != 1 {
+			return errors.New("invalid payload")
+		}
+
+		return nil
+	}
+
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result APIKey
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, "{"), nil
+			},
+			Err: true,
+		},
+		"Successful execution": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"apiKey":"k1","label":"l1","permissions":["btcpay.store.canviewinvoices"]}`), nil
+			},
+			Result: APIKey{APIKey: "k1", Label: "l1", Permissions: Permissions{"btcpay.store.canviewinvoices"}},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/api-keys", c.Resp)
+
+			res, err := client.CreateAPIKey(context.Background(), APIKeyParams{Label: "l1", Permissions: Permissions{"btcpay.store.canviewinvoices"}})
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodPost+" http://test.com/api/v1/api-keys"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_CurrentAPIKey(t *testing.T) {
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result APIKey
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, "{"),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `{"apiKey":"k1","label":"l1"}`),
+			Result: APIKey{APIKey: "k1", Label: "l1"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/api-keys/current", c.Resp)
+
+			res, err := client.CurrentAPIKey(context.Background())
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodGet+" http://test.com/api/v1/api-keys/current"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_RevokeAPIKey(t *testing.T) {
+	cc := map[string]struct {
+		Resp httpmock.Responder
+		Err  bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, ""),
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodDelete, "http://test.com/api/v1/api-keys/k1", c.Resp)
+
+			err = client.RevokeAPIKey(context.Background(), "k1")
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodDelete+" http://test.com/api/v1/api-keys/k1"])
+
+			if c.Err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
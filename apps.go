@@ -0,0 +1,308 @@
+package btcpay
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/shopspring/decimal"
+)
+
+// App holds generic data of a store app.
+// More at: https://docs.btcpayserver.org/API/Greenfield/v1/#tag/Apps
+type App struct {
+	ID        string `json:"id"`
+	AppName   string `json:"appName"`
+	StoreID   string `json:"storeId"`
+	Created   int64  `json:"created"`
+	AppType   string `json:"appType"`
+	Archived  bool   `json:"archived"`
+	Reference string `json:"reference"`
+}
+
+// AppItem holds data of a single item of a Point of Sale app or a perk
+// of a crowdfund app.
+type AppItem struct {
+	ID            string           `json:"id"`
+	Title         string           `json:"title"`
+	Description   string           `json:"description,omitempty"`
+	Image         string           `json:"image,omitempty"`
+	Price         *decimal.Decimal `json:"price,omitempty"`
+	PriceType     string           `json:"priceType,omitempty"`
+	BuyButtonText string           `json:"buyButtonText,omitempty"`
+	Inventory     *int64           `json:"inventory,omitempty"`
+	Disabled      bool             `json:"disabled,omitempty"`
+	Categories    []string         `json:"categories,omitempty"`
+}
+
+// encodeAppItems encodes items as an app template. Nil is returned if
+// there are no items, so that the template is left intact.
+func encodeAppItems(ii []AppItem) (*string, error) {
+	if ii == nil {
+		return nil, nil
+	}
+
+	d, err := json.Marshal(ii)
+	if err != nil {
+		return nil, err
+	}
+
+	s := string(d)
+
+	return &s, nil
+}
+
+// decodeAppItems decodes items from an app template.
+func decodeAppItems(tmpl string) ([]AppItem, error) {
+	if tmpl == "" {
+		return nil, nil
+	}
+
+	var ii []AppItem
+	if err := json.Unmarshal([]byte(tmpl), &ii); err != nil {
+		return nil, err
+	}
+
+	return ii, nil
+}
+
+// PointOfSaleAppParams holds data used to create or update a Point of
+// Sale app.
+type PointOfSaleAppParams struct {
+	AppName               string  `json:"appName"`
+	Title                 string  `json:"title,omitempty"`
+	Description           string  `json:"description,omitempty"`
+	DefaultView           string  `json:"defaultView,omitempty"`
+	Currency              string  `json:"currency,omitempty"`
+	ShowCustomAmount      bool    `json:"showCustomAmount"`
+	ShowDiscount          bool    `json:"showDiscount"`
+	EnableTips            bool    `json:"enableTips"`
+	CustomTipPercentages  []int64 `json:"customTipPercentages,omitempty"`
+	NotificationURL       string  `json:"notificationUrl,omitempty"`
+	RedirectURL           string  `json:"redirectUrl,omitempty"`
+	RedirectAutomatically *bool   `json:"redirectAutomatically,omitempty"`
+	Archived              bool    `json:"archived"`
+
+	// Items is the item catalog of the app. It replaces the whole
+	// catalog if set.
+	Items []AppItem `json:"-"`
+}
+
+// MarshalJSON encodes the params along with the item catalog.
+func (p PointOfSaleAppParams) MarshalJSON() ([]byte, error) {
+	tmpl, err := encodeAppItems(p.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	type params PointOfSaleAppParams
+
+	return json.Marshal(struct {
+		params
+		Template *string `json:"template,omitempty"`
+	}{params(p), tmpl})
+}
+
+// PointOfSaleApp holds data of a Point of Sale app.
+type PointOfSaleApp struct {
+	App
+	Title                string  `json:"title"`
+	Description          string  `json:"description"`
+	DefaultView          string  `json:"defaultView"`
+	Currency             string  `json:"currency"`
+	ShowCustomAmount     bool    `json:"showCustomAmount"`
+	ShowDiscount         bool    `json:"showDiscount"`
+	EnableTips           bool    `json:"enableTips"`
+	CustomTipPercentages []int64 `json:"customTipPercentages"`
+	NotificationURL      string  `json:"notificationUrl"`
+	RedirectURL          string  `json:"redirectUrl"`
+	Template             string  `json:"template"`
+}
+
+// Items decodes the item catalog of the app.
+func (a PointOfSaleApp) Items() ([]AppItem, error) {
+	return decodeAppItems(a.Template)
+}
+
+// CrowdfundAppParams holds data used to create or update a crowdfund
+// app.
+type CrowdfundAppParams struct {
+	AppName               string           `json:"appName"`
+	Title                 string           `json:"title,omitempty"`
+	Description           string           `json:"description,omitempty"`
+	Tagline               string           `json:"tagline,omitempty"`
+	Enabled               bool             `json:"enabled"`
+	EnforceTargetAmount   bool             `json:"enforceTargetAmount"`
+	StartDate             int64            `json:"startDate,omitempty"`
+	EndDate               int64            `json:"endDate,omitempty"`
+	TargetCurrency        string           `json:"targetCurrency,omitempty"`
+	TargetAmount          *decimal.Decimal `json:"targetAmount,omitempty"`
+	MainImageURL          string           `json:"mainImageUrl,omitempty"`
+	NotificationURL       string           `json:"notificationUrl,omitempty"`
+	DisplayPerksValue     bool             `json:"displayPerksValue"`
+	DisplayPerksRanking   bool             `json:"displayPerksRanking"`
+	SortPerksByPopularity bool             `json:"sortPerksByPopularity"`
+	ResetEvery            string           `json:"resetEvery,omitempty"`
+	ResetEveryAmount      int64            `json:"resetEveryAmount,omitempty"`
+
+	// Perks are the perks of the app. They replace all existing perks
+	// if set.
+	Perks []AppItem `json:"-"`
+}
+
+// MarshalJSON encodes the params along with the perks.
+func (p CrowdfundAppParams) MarshalJSON() ([]byte, error) {
+	tmpl, err := encodeAppItems(p.Perks)
+	if err != nil {
+		return nil, err
+	}
+
+	type params CrowdfundAppParams
+
+	return json.Marshal(struct {
+		params
+		PerksTemplate *string `json:"perksTemplate,omitempty"`
+	}{params(p), tmpl})
+}
+
+// CrowdfundApp holds data of a crowdfund app.
+type CrowdfundApp struct {
+	App
+	Title                 string          `json:"title"`
+	Description           string          `json:"description"`
+	Tagline               string          `json:"tagline"`
+	Enabled               bool            `json:"enabled"`
+	EnforceTargetAmount   bool            `json:"enforceTargetAmount"`
+	StartDate             int64           `json:"startDate"`
+	EndDate               int64           `json:"endDate"`
+	TargetCurrency        string          `json:"targetCurrency"`
+	TargetAmount          decimal.Decimal `json:"targetAmount"`
+	MainImageURL          string          `json:"mainImageUrl"`
+	NotificationURL       string          `json:"notificationUrl"`
+	DisplayPerksValue     bool            `json:"displayPerksValue"`
+	DisplayPerksRanking   bool            `json:"displayPerksRanking"`
+	SortPerksByPopularity bool            `json:"sortPerksByPopularity"`
+	ResetEvery            string          `json:"resetEvery"`
+	ResetEveryAmount      int64           `json:"resetEveryAmount"`
+	PerksTemplate         string          `json:"perksTemplate"`
+}
+
+// Perks decodes the perks of the app.
+func (a CrowdfundApp) Perks() ([]AppItem, error) {
+	return decodeAppItems(a.PerksTemplate)
+}
+
+// Apps retrieves all apps of the specified store.
+func (c *Client) Apps(ctx context.Context, storeID string, opts ...RequestOption) ([]App, error) {
+	resp, err := c.sendAPI(ctx, http.MethodGet, newRoute("/api/v1/stores/:id/apps", storeID), nil, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var aa []App
+
+	if err = c.decode(resp.Body, &aa); err != nil {
+		return nil, err
+	}
+
+	return aa, nil
+}
+
+// App retrieves an app by the provided ID.
+func (c *Client) App(ctx context.Context, id string, opts ...RequestOption) (App, error) {
+	resp, err := c.sendAPI(ctx, http.MethodGet, newRoute("/api/v1/apps/:id", id), nil, nil, opts...)
+	if err != nil {
+		return App{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var a App
+
+	if err = c.decode(resp.Body, &a); err != nil {
+		return App{}, err
+	}
+
+	return a, nil
+}
+
+// RemoveApp removes the specified app.
+func (c *Client) RemoveApp(ctx context.Context, id string, opts ...RequestOption) error {
+	resp, err := c.sendAPI(ctx, http.MethodDelete, newRoute("/api/v1/apps/:id", id), nil, nil, opts...)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// CreatePointOfSaleApp creates a new Point of Sale app in the specified
+// store.
+func (c *Client) CreatePointOfSaleApp(ctx context.Context, storeID string, p PointOfSaleAppParams, opts ...RequestOption) (PointOfSaleApp, error) {
+	return c.sendPointOfSaleApp(ctx, http.MethodPost, newRoute("/api/v1/stores/:id/apps/pos", storeID), p, opts)
+}
+
+// PointOfSaleApp retrieves a Point of Sale app by the provided ID.
+func (c *Client) PointOfSaleApp(ctx context.Context, id string, opts ...RequestOption) (PointOfSaleApp, error) {
+	return c.sendPointOfSaleApp(ctx, http.MethodGet, newRoute("/api/v1/apps/pos/:id", id), nil, opts)
+}
+
+// UpdatePointOfSaleApp updates the specified Point of Sale app.
+func (c *Client) UpdatePointOfSaleApp(ctx context.Context, id string, p PointOfSaleAppParams, opts ...RequestOption) (PointOfSaleApp, error) {
+	return c.sendPointOfSaleApp(ctx, http.MethodPut, newRoute("/api/v1/apps/pos/:id", id), p, opts)
+}
+
+// sendPointOfSaleApp sends a Point of Sale app request and decodes the
+// returned app.
+func (c *Client) sendPointOfSaleApp(ctx context.Context, method string, endpoint route, payload interface{}, opts []RequestOption) (PointOfSaleApp, error) {
+	resp, err := c.sendAPI(ctx, method, endpoint, nil, payload, opts...)
+	if err != nil {
+		return PointOfSaleApp{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var a PointOfSaleApp
+
+	if err = c.decode(resp.Body, &a); err != nil {
+		return PointOfSaleApp{}, err
+	}
+
+	return a, nil
+}
+
+// CreateCrowdfundApp creates a new crowdfund app in the specified store.
+func (c *Client) CreateCrowdfundApp(ctx context.Context, storeID string, p CrowdfundAppParams, opts ...RequestOption) (CrowdfundApp, error) {
+	return c.sendCrowdfundApp(ctx, http.MethodPost, newRoute("/api/v1/stores/:id/apps/crowdfund", storeID), p, opts)
+}
+
+// CrowdfundApp retrieves a crowdfund app by the provided ID.
+func (c *Client) CrowdfundApp(ctx context.Context, id string, opts ...RequestOption) (CrowdfundApp, error) {
+	return c.sendCrowdfundApp(ctx, http.MethodGet, newRoute("/api/v1/apps/crowdfund/:id", id), nil, opts)
+}
+
+// UpdateCrowdfundApp updates the specified crowdfund app.
+func (c *Client) UpdateCrowdfundApp(ctx context.Context, id string, p CrowdfundAppParams, opts ...RequestOption) (CrowdfundApp, error) {
+	return c.sendCrowdfundApp(ctx, http.MethodPut, newRoute("/api/v1/apps/crowdfund/:id", id), p, opts)
+}
+
+// sendCrowdfundApp sends a crowdfund app request and decodes the
+// returned app.
+func (c *Client) sendCrowdfundApp(ctx context.Context, method string, endpoint route, payload interface{}, opts []RequestOption) (CrowdfundApp, error) {
+	resp, err := c.sendAPI(ctx, method, endpoint, nil, payload, opts...)
+	if err != nil {
+		return CrowdfundApp{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var a CrowdfundApp
+
+	if err = c.decode(resp.Body, &a); err != nil {
+		return CrowdfundApp{}, err
+	}
+
+	return a, nil
+}
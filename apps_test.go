@@ -0,0 +1,576 @@
+package btcpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_Apps(t *testing.T) {
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result []App
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, "["),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `[{"id":"a1","appType":"PointOfSale"}]`),
+			Result: []App{{ID: "a1", AppType: "PointOfSale"}},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/stores/s1/apps", c.Resp)
+
+			res, err := client.Apps(context.Background(), "s1")
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodGet+" http://test.com/api/v1/stores/s1/apps"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Nil(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_App(t *testing.T) {
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result App
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, "{"),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `{"id":"a1","appType":"PointOfSale"}`),
+			Result: App{ID: "a1", AppType: "PointOfSale"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/apps/a1", c.Resp)
+
+			res, err := client.App(context.Background(), "a1")
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodGet+" http://test.com/api/v1/apps/a1"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_RemoveApp(t *testing.T) {
+	cc := map[string]struct {
+		Resp httpmock.Responder
+		Err  bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, ""),
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodDelete, "http://test.com/api/v1/apps/a1", c.Resp)
+
+			err = client.RemoveApp(context.Background(), "a1")
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodDelete+" http://test.com/api/v1/apps/a1"])
+
+			if c.Err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_Client_CreatePointOfSaleApp(t *testing.T) {
+	check := func(r *http.Request) error {
+		var p map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			return err
+		}
+
+		if p["appName"] != "a1" || p["template"] != `[{"id":"i1","title":"t1"}]` {
+			return errors.New("invalid payload")
+		}
+
+		return nil
+	}
+
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result PointOfSaleApp
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, "{"), nil
+			},
+			Err: true,
+		},
+		"Successful execution": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"id":"a1","appName":"a1","template":"[]"}`), nil
+			},
+			Result: PointOfSaleApp{App: App{ID: "a1", AppName: "a1"}, Template: "[]"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/stores/s1/apps/pos", c.Resp)
+
+			res, err := client.CreatePointOfSaleApp(context.Background(), "s1", PointOfSaleAppParams{AppName: "a1", Items: []AppItem{{ID: "i1", Title: "t1"}}})
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodPost+" http://test.com/api/v1/stores/s1/apps/pos"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_PointOfSaleApp(t *testing.T) {
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result PointOfSaleApp
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, "{"),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `{"id":"a1","title":"t1"}`),
+			Result: PointOfSaleApp{App: App{ID: "a1"}, Title: "t1"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/apps/pos/a1", c.Resp)
+
+			res, err := client.PointOfSaleApp(context.Background(), "a1")
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodGet+" http://test.com/api/v1/apps/pos/a1"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_UpdatePointOfSaleApp(t *testing.T) {
+	check := func(r *http.Request) error {
+		var p map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			return err
+		}
+
+		if p["appName"] != "a1" || p["template"] != `[{"id":"i1","title":"t1"}]` {
+			return errors.New("invalid payload")
+		}
+
+		return nil
+	}
+
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result PointOfSaleApp
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, "{"), nil
+			},
+			Err: true,
+		},
+		"Successful execution": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"id":"a1","appName":"a1"}`), nil
+			},
+			Result: PointOfSaleApp{App: App{ID: "a1", AppName: "a1"}},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodPut, "http://test.com/api/v1/apps/pos/a1", c.Resp)
+
+			res, err := client.UpdatePointOfSaleApp(context.Background(), "a1", PointOfSaleAppParams{AppName: "a1", Items: []AppItem{{ID: "i1", Title: "t1"}}})
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodPut+" http://test.com/api/v1/apps/pos/a1"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_CreateCrowdfundApp(t *testing.T) {
+	check := func(r *http.Request) error {
+		var p map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			return err
+		}
+
+		if p["appName"] != "a1" || p["perksTemplate"] != `[{"id":"p1","title":"t1"}]` {
+			return errors.New("invalid payload")
+		}
+
+		return nil
+	}
+
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result CrowdfundApp
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, "{"), nil
+			},
+			Err: true,
+		},
+		"Successful execution": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"id":"a1","appName":"a1","targetAmount":"10"}`), nil
+			},
+			Result: CrowdfundApp{App: App{ID: "a1", AppName: "a1"}, TargetAmount: decimal.NewFromInt(10)},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/stores/s1/apps/crowdfund", c.Resp)
+
+			res, err := client.CreateCrowdfundApp(context.Background(), "s1", CrowdfundAppParams{AppName: "a1", Perks: []AppItem{{ID: "p1", Title: "t1"}}})
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodPost+" http://test.com/api/v1/stores/s1/apps/crowdfund"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_CrowdfundApp(t *testing.T) {
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result CrowdfundApp
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, "{"),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `{"id":"a1","title":"t1"}`),
+			Result: CrowdfundApp{App: App{ID: "a1"}, Title: "t1"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/apps/crowdfund/a1", c.Resp)
+
+			res, err := client.CrowdfundApp(context.Background(), "a1")
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodGet+" http://test.com/api/v1/apps/crowdfund/a1"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_UpdateCrowdfundApp(t *testing.T) {
+	check := func(r *http.Request) error {
+		var p map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			return err
+		}
+
+		if p["appName"] != "a1" || p["perksTemplate"] != `[{"id":"p1","title":"t1"}]` {
+			return errors.New("invalid payload")
+		}
+
+		return nil
+	}
+
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result CrowdfundApp
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, "{"), nil
+			},
+			Err: true,
+		},
+		"Successful execution": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"id":"a1","appName":"a1"}`), nil
+			},
+			Result: CrowdfundApp{App: App{ID: "a1", AppName: "a1"}},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodPut, "http://test.com/api/v1/apps/crowdfund/a1", c.Resp)
+
+			res, err := client.UpdateCrowdfundApp(context.Background(), "a1", CrowdfundAppParams{AppName: "a1", Perks: []AppItem{{ID: "p1", Title: "t1"}}})
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodPut+" http://test.com/api/v1/apps/crowdfund/a1"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_PointOfSaleApp_Items(t *testing.T) {
+	price := decimal.NewFromInt(5)
+
+	cc := map[string]struct {
+		Template string
+		Result   []AppItem
+		Err      bool
+	}{
+		"Empty template": {},
+		"Invalid template": {
+			Template: "[",
+			Err:      true,
+		},
+		"Successful decoding": {
+			Template: `[{"id":"i1","title":"t1","price":"5","priceType":"Fixed","categories":["c1"]}]`,
+			Result:   []AppItem{{ID: "i1", Title: "t1", Price: &price, PriceType: "Fixed", Categories: []string{"c1"}}},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			res, err := PointOfSaleApp{Template: c.Template}.Items()
+			if c.Err {
+				assert.Error(t, err)
+				assert.Nil(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
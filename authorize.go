@@ -0,0 +1,109 @@
+package btcpay
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// AuthorizeParams holds data used to build an API key authorization
+// URL.
+// More at: https://docs.btcpayserver.org/API/Greenfield/v1/#section/Authentication/APIKey
+type AuthorizeParams struct {
+	// Permissions are the permissions requested for the API key.
+	Permissions Permissions
+
+	// ApplicationName is the name of the application shown to the user.
+	ApplicationName string
+
+	// ApplicationIdentifier is used to detect an existing key of the
+	// application, so that a new one is not created every time.
+	// Redirect must be set for it to take effect.
+	ApplicationIdentifier string
+
+	// Redirect is the URL that the approved API key is POSTed to.
+	Redirect string
+
+	// Strict prevents the user from altering the requested permissions.
+	Strict bool
+
+	// SelectiveStores allows the user to choose the stores that the
+	// store permissions apply to.
+	SelectiveStores bool
+}
+
+// AuthorizeURL builds the URL that the user should be sent to in order
+// to approve the creation of an API key for the application.
+func (c *Client) AuthorizeURL(p AuthorizeParams) (string, error) {
+	u, err := url.Parse(c.baseURL() + "/api-keys/authorize")
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+
+	for _, perm := range p.Permissions {
+		params.Add("permissions", string(perm))
+	}
+
+	if p.ApplicationName != "" {
+		params.Set("applicationName", p.ApplicationName)
+	}
+
+	if p.Redirect != "" {
+		r, err := url.Parse(p.Redirect)
+		if err != nil {
+			return "", err
+		}
+
+		if !r.IsAbs() {
+			return "", errors.New("redirect URL must be absolute")
+		}
+
+		params.Set("redirect", p.Redirect)
+
+		if p.ApplicationIdentifier != "" {
+			params.Set("applicationIdentifier", p.ApplicationIdentifier)
+		}
+	}
+
+	params.Set("strict", strconv.FormatBool(p.Strict))
+	params.Set("selectiveStores", strconv.FormatBool(p.SelectiveStores))
+
+	u.RawQuery = params.Encode()
+
+	return u.String(), nil
+}
+
+// AuthorizeCallback holds data of an API key approved by the user.
+type AuthorizeCallback struct {
+	APIKey      string
+	UserID      string
+	Permissions Permissions
+}
+
+// ParseAuthorizeCallback extracts the approved API key from the request
+// sent to the redirect URL.
+func ParseAuthorizeCallback(r *http.Request) (AuthorizeCallback, error) {
+	if err := r.ParseForm(); err != nil {
+		return AuthorizeCallback{}, err
+	}
+
+	cb := AuthorizeCallback{
+		APIKey: r.Form.Get("apiKey"),
+		UserID: r.Form.Get("userId"),
+	}
+
+	if cb.APIKey == "" {
+		return AuthorizeCallback{}, errors.New("API key is missing")
+	}
+
+	for _, k := range []string{"permissions[]", "permissions"} {
+		for _, perm := range r.Form[k] {
+			cb.Permissions = append(cb.Permissions, Permission(perm))
+		}
+	}
+
+	return cb, nil
+}
@@ -0,0 +1,130 @@
+package btcpay
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_AuthorizeURL(t *testing.T) {
+	cc := map[string]struct {
+		Params AuthorizeParams
+		Result url.Values
+		Err    bool
+	}{
+		"Invalid redirect URL": {
+			Params: AuthorizeParams{Redirect: "://test"},
+			Err:    true,
+		},
+		"Relative redirect URL": {
+			Params: AuthorizeParams{Redirect: "/callback"},
+			Err:    true,
+		},
+		"Application identifier without redirect URL": {
+			Params: AuthorizeParams{
+				Permissions:           Permissions{"btcpay.store.canviewinvoices"},
+				ApplicationIdentifier: "app1",
+			},
+			Result: url.Values{
+				"permissions":     {"btcpay.store.canviewinvoices"},
+				"strict":          {"false"},
+				"selectiveStores": {"false"},
+			},
+		},
+		"Successful build": {
+			Params: AuthorizeParams{
+				Permissions:           Permissions{"btcpay.store.canviewinvoices", "btcpay.store.cancreateinvoice"},
+				ApplicationName:       "App 1",
+				ApplicationIdentifier: "app1",
+				Redirect:              "https://app.com/callback",
+				Strict:                true,
+				SelectiveStores:       true,
+			},
+			Result: url.Values{
+				"permissions":           {"btcpay.store.canviewinvoices", "btcpay.store.cancreateinvoice"},
+				"applicationName":       {"App 1"},
+				"applicationIdentifier": {"app1"},
+				"redirect":              {"https://app.com/callback"},
+				"strict":                {"true"},
+				"selectiveStores":       {"true"},
+			},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			client, err := NewClient("http://test.com", "")
+			require.NoError(t, err)
+
+			res, err := client.AuthorizeURL(c.Params)
+			if c.Err {
+				assert.Error(t, err)
+				assert.Empty(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+
+			u, err := url.Parse(res)
+			require.NoError(t, err)
+
+			assert.Equal(t, "http://test.com/api-keys/authorize", u.Scheme+"://"+u.Host+u.Path)
+			assert.Equal(t, c.Result, u.Query())
+		})
+	}
+}
+
+func Test_ParseAuthorizeCallback(t *testing.T) {
+	cc := map[string]struct {
+		Body   string
+		Result AuthorizeCallback
+		Err    bool
+	}{
+		"Invalid form": {
+			Body: "%",
+			Err:  true,
+		},
+		"Missing API key": {
+			Body: "userId=u1",
+			Err:  true,
+		},
+		"Successful parsing": {
+			Body: "apiKey=k1&userId=u1&permissions%5B%5D=btcpay.store.canviewinvoices&permissions%5B%5D=btcpay.store.cancreateinvoice",
+			Result: AuthorizeCallback{
+				APIKey:      "k1",
+				UserID:      "u1",
+				Permissions: Permissions{"btcpay.store.canviewinvoices", "btcpay.store.cancreateinvoice"},
+			},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/callback", strings.NewReader(c.Body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			res, err := ParseAuthorizeCallback(req)
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
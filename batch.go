@@ -0,0 +1,86 @@
+package btcpay
+
+import (
+	"context"
+	"sync"
+)
+
+// defaultBatchConcurrency is the default number of concurrent requests
+// sent during batch operations.
+const defaultBatchConcurrency = 4
+
+// BatchOptions holds settings of batch operations.
+type BatchOptions struct {
+	// Concurrency is the maximum number of concurrent requests.
+	// Defaults to 4.
+	Concurrency int
+
+	// FailFast stops the batch on the first error. Items that were not
+	// processed have their errors set to context.Canceled.
+	FailFast bool
+}
+
+// InvoiceResult holds the result of a single invoice creation.
+type InvoiceResult struct {
+	Invoice Invoice
+	Err     error
+}
+
+// CreateInvoices creates multiple invoices concurrently. Results are
+// returned in the order of the provided params. An error is returned
+// only in the fail-fast mode; otherwise, errors are reported per item.
+func (c *Client) CreateInvoices(ctx context.Context, pp []CreateInvoiceParams, bo BatchOptions, opts ...RequestOption) ([]InvoiceResult, error) {
+	if bo.Concurrency <= 0 {
+		bo.Concurrency = defaultBatchConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		res  = make([]InvoiceResult, len(pp))
+		sem  = make(chan struct{}, bo.Concurrency)
+		wg   sync.WaitGroup
+		once sync.Once
+		ferr error
+	)
+
+	for i := range pp {
+		select {
+		case <-ctx.Done():
+			res[i].Err = ctx.Err()
+			continue
+		case sem <- struct{}{}:
+		}
+
+		// the context may be cancelled while waiting for a free slot
+		if err := ctx.Err(); err != nil {
+			res[i].Err = err
+			<-sem
+
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			res[i].Invoice, res[i].Err = c.CreateInvoice(ctx, pp[i], opts...)
+
+			if res[i].Err != nil && bo.FailFast {
+				once.Do(func() {
+					ferr = res[i].Err
+					cancel()
+				})
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	return res, ferr
+}
@@ -0,0 +1,129 @@
+package btcpay
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_CreateInvoices(t *testing.T) {
+	params := func(ids ...string) []CreateInvoiceParams {
+		pp := make([]CreateInvoiceParams, len(ids))
+		for i, id := range ids {
+			pp[i] = CreateInvoiceParams{Currency: "USD", Price: decimal.NewFromInt(1), OrderID: id}
+		}
+
+		return pp
+	}
+
+	cc := map[string]struct {
+		Params  []CreateInvoiceParams
+		Options BatchOptions
+		Calls   int
+		Result  []string
+		Errs    []bool
+		Err     bool
+	}{
+		"No invoices": {
+			Result: []string{},
+			Errs:   []bool{},
+		},
+		"Continue on error": {
+			Params: params("1", "fail", "3"),
+			Calls:  3,
+			Result: []string{"1", "", "3"},
+			Errs:   []bool{false, true, false},
+		},
+		"Fail fast": {
+			Params:  params("fail", "2", "3"),
+			Options: BatchOptions{Concurrency: 1, FailFast: true},
+			Calls:   1,
+			Result:  []string{"", "", ""},
+			Errs:    []bool{true, true, true},
+			Err:     true,
+		},
+		"Successful execution": {
+			Params:  params("1", "2", "3", "4", "5"),
+			Options: BatchOptions{Concurrency: 2, FailFast: true},
+			Calls:   5,
+			Result:  []string{"1", "2", "3", "4", "5"},
+			Errs:    []bool{false, false, false, false, false},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			var inflight, maxInflight int32
+
+			mt.RegisterResponder(http.MethodPost, "http://test.com/invoices", func(r *http.Request) (*http.Response, error) {
+				n := atomic.AddInt32(&inflight, 1)
+				defer atomic.AddInt32(&inflight, -1)
+
+				for {
+					m := atomic.LoadInt32(&maxInflight)
+					if n <= m || atomic.CompareAndSwapInt32(&maxInflight, m, n) {
+						break
+					}
+				}
+
+				time.Sleep(time.Millisecond * 10)
+
+				var p CreateInvoiceParams
+				if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+					return nil, err
+				}
+
+				if p.OrderID == "fail" {
+					return httpmock.NewStringResponse(http.StatusBadRequest, `{"error":"test"}`), nil
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"data":{"orderId":"`+p.OrderID+`"}}`), nil
+			})
+
+			res, err := client.CreateInvoices(context.Background(), c.Params, c.Options)
+
+			assert.Equal(t, c.Calls, mt.GetTotalCallCount())
+
+			concurrency := c.Options.Concurrency
+			if concurrency == 0 {
+				concurrency = defaultBatchConcurrency
+			}
+
+			assert.LessOrEqual(t, int(maxInflight), concurrency)
+
+			if c.Err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			require.Len(t, res, len(c.Result))
+
+			for i, r := range res {
+				assert.Equal(t, c.Result[i], r.Invoice.OrderID)
+
+				if c.Errs[i] {
+					assert.Error(t, r.Err)
+				} else {
+					assert.NoError(t, r.Err)
+				}
+			}
+		})
+	}
+}
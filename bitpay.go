@@ -0,0 +1,56 @@
+package btcpay
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+)
+
+// WithBitPayCompatibility makes the BTCPay client's legacy API requests
+// compatible with BitPay servers. A facade token is required by all
+// signed requests, the idempotency key of a new invoice is sent as its
+// guid and error envelopes returned with successful HTTP statuses are
+// treated as errors.
+func WithBitPayCompatibility() setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.bitPay = true
+	}
+}
+
+// checkBitPayEnvelope checks whether the response body contains an error
+// envelope and returns it as an *APIError. The body is buffered, so that
+// it can be decoded again.
+func checkBitPayEnvelope(resp *http.Response) (*http.Response, error) {
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var env struct {
+		Status  string `json:"status"`
+		Code    string `json:"code"`
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+
+	// bodies that aren't JSON objects can't be envelopes
+	if json.Unmarshal(b, &env) == nil && env.Status == "error" {
+		msg := env.Message
+		if msg == "" {
+			msg = env.Error
+		}
+
+		if msg == "" {
+			msg = "unknown error"
+		}
+
+		return nil, &APIError{StatusCode: resp.StatusCode, Code: env.Code, Message: msg}
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	return resp, nil
+}
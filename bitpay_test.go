@@ -0,0 +1,102 @@
+package btcpay
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithBitPayCompatibility(t *testing.T) {
+	c := &Client{}
+	WithBitPayCompatibility()(c)
+	assert.True(t, c.bitPay)
+}
+
+func Test_checkBitPayEnvelope(t *testing.T) {
+	cc := map[string]struct {
+		Body string
+		Err  *APIError
+	}{
+		"Error envelope with message": {
+			Body: `{"status":"error","code":"unauthorized","message":"invalid token"}`,
+			Err:  &APIError{StatusCode: http.StatusOK, Code: "unauthorized", Message: "invalid token"},
+		},
+		"Error envelope with error": {
+			Body: `{"status":"error","error":"invalid token"}`,
+			Err:  &APIError{StatusCode: http.StatusOK, Message: "invalid token"},
+		},
+		"Error envelope without message": {
+			Body: `{"status":"error"}`,
+			Err:  &APIError{StatusCode: http.StatusOK, Message: "unknown error"},
+		},
+		"Successful envelope": {
+			Body: `{"status":"success","data":{"id":"i1"}}`,
+		},
+		"Data envelope": {
+			Body: `{"data":{"id":"i1"}}`,
+		},
+		"Not an object": {
+			Body: `[{"token":"t1"}]`,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			resp, err := checkBitPayEnvelope(httpmock.NewStringResponse(http.StatusOK, c.Body))
+			if c.Err != nil {
+				assert.Equal(t, c.Err, err)
+				assert.Nil(t, resp)
+				return
+			}
+
+			assert.NoError(t, err)
+			require.NotNil(t, resp)
+
+			b, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, c.Body, string(b))
+		})
+	}
+}
+
+func Test_Client_send_BitPay(t *testing.T) {
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder(http.MethodPost, "http://test.com/invoices", func(r *http.Request) (*http.Response, error) {
+		if r.Header.Get("Idempotency-Key") != "" {
+			return nil, errors.New("unexpected idempotency header")
+		}
+
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		if !strings.HasSuffix(string(b), `,"guid":"key123","token":"t1"}`) {
+			return nil, errors.New("invalid body")
+		}
+
+		return httpmock.NewStringResponse(http.StatusOK, `{"status":"error","message":"invalid price"}`), nil
+	})
+
+	client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}), WithBitPayCompatibility())
+	require.NoError(t, err)
+
+	_, err = client.CreateInvoice(context.Background(), CreateInvoiceParams{Currency: "USD", IdempotencyKey: "key123"})
+	assert.Error(t, err)
+	assert.Zero(t, mt.GetTotalCallCount())
+
+	_, err = client.CreateInvoice(context.Background(), CreateInvoiceParams{Currency: "USD", IdempotencyKey: "key123"}, WithToken("t1"))
+	assert.EqualError(t, err, "[200] invalid price")
+	assert.Equal(t, 1, mt.GetTotalCallCount())
+}
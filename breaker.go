@@ -0,0 +1,153 @@
+package btcpay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Default circuit breaker settings.
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCoolDown  = time.Second * 30
+)
+
+// ErrCircuitOpen is returned when a request is not sent because the
+// circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreakerConfig holds circuit breaker settings.
+type CircuitBreakerConfig struct {
+	// Threshold is the number of consecutive failures that opens the
+	// breaker. Defaults to 5.
+	Threshold int
+
+	// CoolDown is the duration for which requests fail fast once the
+	// breaker opens. After it passes, a single trial request is let
+	// through: the breaker closes if it succeeds and opens again
+	// otherwise. Defaults to 30 seconds.
+	CoolDown time.Duration
+
+	// IsFailure decides whether the outcome of a request counts as a
+	// failure. By default, transport errors and 5xx responses are
+	// failures. Cancelled requests are always ignored.
+	IsFailure func(resp *http.Response, err error) bool
+}
+
+// WithCircuitBreaker enables a circuit breaker on the BTCPay client, so
+// that requests fail fast with ErrCircuitOpen while the server keeps
+// failing.
+func WithCircuitBreaker(cfg CircuitBreakerConfig) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.breaker = newBreaker(cfg)
+	}
+}
+
+// breaker is a consecutive failures circuit breaker.
+type breaker struct {
+	cfg CircuitBreakerConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	open     bool
+	trial    bool
+}
+
+// newBreaker creates a new closed circuit breaker.
+func newBreaker(cfg CircuitBreakerConfig) *breaker {
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = defaultBreakerThreshold
+	}
+
+	if cfg.CoolDown <= 0 {
+		cfg.CoolDown = defaultBreakerCoolDown
+	}
+
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = isServerFailure
+	}
+
+	return &breaker{
+		cfg: cfg,
+		now: time.Now,
+	}
+}
+
+// allow checks whether a request may be sent. Once the cool-down
+// period passes, only one trial request is allowed at a time; true is
+// returned for it, so that its outcome can be told apart from those of
+// requests sent before the breaker opened.
+func (b *breaker) allow() (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.open {
+		return false, nil
+	}
+
+	if b.trial || b.now().Sub(b.openedAt) < b.cfg.CoolDown {
+		return false, ErrCircuitOpen
+	}
+
+	b.trial = true
+
+	return true, nil
+}
+
+// record updates the breaker's state with the outcome of a request.
+// Only the outcome of the trial request decides whether an open breaker
+// closes.
+func (b *breaker) record(trial bool, resp *http.Response, err error) {
+	canceled := errors.Is(err, context.Canceled)
+	failed := !canceled && b.cfg.IsFailure(resp, err)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if trial {
+		b.trial = false
+
+		switch {
+		case canceled:
+			// the outcome says nothing about the server, so
+			// another trial request is allowed
+		case failed:
+			b.openedAt = b.now()
+		default:
+			b.open = false
+			b.failures = 0
+		}
+
+		return
+	}
+
+	if canceled || b.open {
+		return
+	}
+
+	if !failed {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+
+	if b.failures >= b.cfg.Threshold {
+		b.open = true
+		b.openedAt = b.now()
+	}
+}
+
+// isServerFailure checks whether the request failed because of the
+// server or the network.
+func isServerFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	return resp.StatusCode >= 500
+}
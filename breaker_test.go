@@ -0,0 +1,107 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithCircuitBreaker(t *testing.T) {
+	c := &Client{}
+	WithCircuitBreaker(CircuitBreakerConfig{})(c)
+	require.NotNil(t, c.breaker)
+	assert.Equal(t, defaultBreakerThreshold, c.breaker.cfg.Threshold)
+	assert.Equal(t, defaultBreakerCoolDown, c.breaker.cfg.CoolDown)
+	assert.NotNil(t, c.breaker.cfg.IsFailure)
+}
+
+func Test_breaker(t *testing.T) {
+	now := time.Now()
+
+	b := newBreaker(CircuitBreakerConfig{Threshold: 2, CoolDown: time.Minute})
+	b.now = func() time.Time { return now }
+
+	fail := &http.Response{StatusCode: http.StatusBadGateway}
+	ok := &http.Response{StatusCode: http.StatusOK}
+
+	allow := func(exp bool) {
+		t.Helper()
+
+		trial, err := b.allow()
+		require.NoError(t, err)
+		assert.Equal(t, exp, trial)
+	}
+
+	// failures that are not consecutive keep the breaker closed
+	allow(false)
+	b.record(false, fail, nil)
+	allow(false)
+	b.record(false, ok, nil)
+	allow(false)
+	b.record(false, nil, assert.AnError)
+	allow(false)
+	b.record(false, nil, context.Canceled)
+	allow(false)
+
+	// consecutive failures open the breaker
+	b.record(false, nil, assert.AnError)
+	_, err := b.allow()
+	assert.Equal(t, ErrCircuitOpen, err)
+
+	now = now.Add(time.Minute)
+
+	// only a single trial request is allowed after the cool-down
+	allow(true)
+	_, err = b.allow()
+	assert.Equal(t, ErrCircuitOpen, err)
+
+	// outcomes of older requests don't affect the trial
+	b.record(false, ok, nil)
+	b.record(false, nil, context.Canceled)
+	_, err = b.allow()
+	assert.Equal(t, ErrCircuitOpen, err)
+
+	// a cancelled trial request allows another one
+	b.record(true, nil, context.Canceled)
+	allow(true)
+
+	// a failed trial request opens the breaker again
+	b.record(true, fail, nil)
+	_, err = b.allow()
+	assert.Equal(t, ErrCircuitOpen, err)
+
+	now = now.Add(time.Minute)
+
+	// a successful trial request closes the breaker
+	allow(true)
+	b.record(true, ok, nil)
+	allow(false)
+	allow(false)
+}
+
+func Test_Client_do_CircuitBreaker(t *testing.T) {
+	mt := httpmock.NewMockTransport()
+	client, err := NewClient("http://test.com", "",
+		WithHTTPClient(&http.Client{Transport: mt}),
+		WithCircuitBreaker(CircuitBreakerConfig{Threshold: 2}),
+	)
+	require.NoError(t, err)
+
+	mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/stores",
+		httpmock.NewStringResponder(http.StatusServiceUnavailable, `{"message":"unavailable"}`))
+
+	for i := 0; i < 2; i++ {
+		_, err = client.Stores(context.Background())
+		assert.Error(t, err)
+		assert.NotEqual(t, ErrCircuitOpen, err)
+	}
+
+	_, err = client.Stores(context.Background())
+	assert.Equal(t, ErrCircuitOpen, err)
+	assert.Equal(t, 2, mt.GetTotalCallCount())
+}
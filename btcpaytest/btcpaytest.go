@@ -0,0 +1,593 @@
+// Package btcpaytest provides an in-process BTCPay server that emulates
+// the legacy API, so that integration tests of applications that use
+// the btcpay package can run offline. Interactions with a real server
+// can be recorded and replayed with a Recorder.
+package btcpaytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/swithek/btcpay-go"
+)
+
+// defaultTickInterval is the default interval at which the server
+// applies status transitions.
+const defaultTickInterval = time.Millisecond * 50
+
+// Invoice statuses used by the legacy API.
+const (
+	StatusNew       = "new"
+	StatusPaid      = "paid"
+	StatusConfirmed = "confirmed"
+	StatusComplete  = "complete"
+	StatusExpired   = "expired"
+	StatusInvalid   = "invalid"
+)
+
+// Transition changes the status of an invoice once the specified
+// duration elapses since the invoice's creation.
+type Transition struct {
+	After  time.Duration
+	Status string
+}
+
+// Option configures the server.
+type Option func(s *Server)
+
+// WithClock sets the clock used to timestamp invoices and to schedule
+// status transitions.
+func WithClock(clk btcpay.Clock) Option {
+	return func(s *Server) {
+		s.clock = clk
+	}
+}
+
+// WithTimeline sets the status transitions applied to every new invoice.
+// Transitions must be sorted by their durations.
+func WithTimeline(tt ...Transition) Option {
+	return func(s *Server) {
+		s.timeline = tt
+	}
+}
+
+// WithTickInterval sets how often the server checks whether status
+// transitions are due. Transitions are applied on every request as
+// well.
+func WithTickInterval(d time.Duration) Option {
+	return func(s *Server) {
+		s.tick = d
+	}
+}
+
+// WithIPNHandler makes the server deliver invoice notifications to the
+// handler directly instead of the notification URL of the invoice.
+func WithIPNHandler(h http.Handler) Option {
+	return func(s *Server) {
+		s.ipnHandler = h
+	}
+}
+
+// Server is a BTCPay server emulator built on top of httptest.Server.
+// Pairing codes are always accepted, request signatures are not
+// verified, and tokens must have been issued through pairing or added
+// with AddToken.
+type Server struct {
+	*httptest.Server
+
+	clock      btcpay.Clock
+	timeline   []Transition
+	tick       time.Duration
+	ipnHandler http.Handler
+	hc         *http.Client
+	stop       chan struct{}
+	stopOnce   sync.Once
+
+	mu       sync.Mutex
+	seq      int
+	tokens   map[string]bool
+	invoices map[string]*invoice
+	faults   []*fault
+}
+
+// invoice holds the state of a single emulated invoice.
+type invoice struct {
+	data    btcpay.Invoice
+	notify  string
+	created time.Time
+	step    int
+}
+
+// fault is an injected error response.
+type fault struct {
+	method string
+	path   string
+	left   int
+	status int
+	body   string
+}
+
+// NewServer starts a new BTCPay server emulator. It must be closed once
+// it is no longer needed.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		clock:    systemClock{},
+		tick:     defaultTickInterval,
+		hc:       &http.Client{Timeout: time.Second * 5},
+		stop:     make(chan struct{}),
+		tokens:   make(map[string]bool),
+		invoices: make(map[string]*invoice),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tokens", s.handleTokens)
+	mux.HandleFunc("/invoices", s.handleInvoices)
+	mux.HandleFunc("/invoices/", s.handleInvoice)
+
+	s.Server = httptest.NewServer(s.intercept(mux))
+
+	go s.run()
+
+	return s
+}
+
+// Close stops the server.
+func (s *Server) Close() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+
+	s.Server.Close()
+}
+
+// AddToken makes the server accept the facade token.
+func (s *Server) AddToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[token] = true
+}
+
+// InjectError makes the next n requests with the specified method and
+// path fail with the status code and a legacy API error message. An
+// empty method or path matches any request.
+func (s *Server) InjectError(method, path string, n, status int, msg string) {
+	b, _ := json.Marshal(map[string]string{"error": msg}) //nolint:errcheck // strings are always encodable
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &fault{
+		method: method,
+		path:   path,
+		left:   n,
+		status: status,
+		body:   string(b),
+	})
+}
+
+// Invoice returns the current state of the invoice. False is returned
+// if the invoice does not exist.
+func (s *Server) Invoice(id string) (btcpay.Invoice, bool) {
+	s.Advance()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv, ok := s.invoices[id]
+	if !ok {
+		return btcpay.Invoice{}, false
+	}
+
+	return inv.data, true
+}
+
+// SetStatus changes the status of the invoice immediately and delivers
+// the related notifications. Scheduled transitions of the invoice are
+// cancelled.
+func (s *Server) SetStatus(id, status string) error {
+	s.mu.Lock()
+
+	inv, ok := s.invoices[id]
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("invoice %q not found", id)
+	}
+
+	inv.step = len(s.timeline)
+	ipns := s.transition(inv, status)
+
+	s.mu.Unlock()
+
+	s.deliver(ipns)
+
+	return nil
+}
+
+// Advance applies all status transitions that are due and delivers the
+// related notifications.
+func (s *Server) Advance() {
+	now := s.clock.Now()
+
+	var ipns []ipn
+
+	s.mu.Lock()
+
+	ids := make([]string, 0, len(s.invoices))
+	for id := range s.invoices {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		inv := s.invoices[id]
+
+		for inv.step < len(s.timeline) && !now.Before(inv.created.Add(s.timeline[inv.step].After)) {
+			ipns = append(ipns, s.transition(inv, s.timeline[inv.step].Status)...)
+			inv.step++
+		}
+	}
+
+	s.mu.Unlock()
+
+	s.deliver(ipns)
+}
+
+// run applies status transitions periodically until the server is
+// closed.
+func (s *Server) run() {
+	t := time.NewTicker(s.tick)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-t.C:
+			s.Advance()
+		}
+	}
+}
+
+// intercept applies due status transitions and injected errors before
+// the request is handled.
+func (s *Server) intercept(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Advance()
+
+		if f := s.takeFault(r); f != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(f.status)
+			w.Write([]byte(f.body)) //nolint:errcheck // the client may be gone
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// takeFault returns the first injected error that matches the request.
+func (s *Server) takeFault(r *http.Request) *fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, f := range s.faults {
+		if (f.method != "" && f.method != r.Method) || (f.path != "" && f.path != r.URL.Path) {
+			continue
+		}
+
+		f.left--
+		if f.left <= 0 {
+			s.faults = append(s.faults[:i], s.faults[i+1:]...)
+		}
+
+		return f
+	}
+
+	return nil
+}
+
+// handleTokens pairs a client.
+func (s *Server) handleTokens(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var p struct {
+		ID          string `json:"id"`
+		PairingCode string `json:"pairingCode"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil || p.ID == "" || p.PairingCode == "" {
+		writeError(w, http.StatusBadRequest, "invalid pairing request")
+		return
+	}
+
+	s.mu.Lock()
+	s.seq++
+	token := "token" + strconv.Itoa(s.seq)
+	s.tokens[token] = true
+	s.mu.Unlock()
+
+	writeData(w, http.StatusOK, []map[string]string{{
+		"token":       token,
+		"facade":      "merchant",
+		"pairingCode": p.PairingCode,
+	}})
+}
+
+// handleInvoices creates or lists invoices.
+func (s *Server) handleInvoices(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		s.createInvoice(w, r)
+	case http.MethodGet:
+		if !s.authorized(r.URL.Query().Get("token")) {
+			writeError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		s.listInvoices(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleInvoice retrieves a single invoice.
+func (s *Server) handleInvoice(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if !s.authorized(r.URL.Query().Get("token")) {
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	s.mu.Lock()
+	inv, ok := s.invoices[strings.TrimPrefix(r.URL.Path, "/invoices/")]
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "Object not found")
+		return
+	}
+
+	writeData(w, http.StatusOK, s.snapshot(inv))
+}
+
+// createInvoice creates a new invoice.
+func (s *Server) createInvoice(w http.ResponseWriter, r *http.Request) {
+	var p struct {
+		btcpay.CreateInvoiceParams
+		Token string `json:"token"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid invoice data")
+		return
+	}
+
+	if !s.authorized(p.Token) {
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := p.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	now := s.clock.Now()
+
+	s.mu.Lock()
+
+	s.seq++
+	id := "inv" + strconv.Itoa(s.seq)
+
+	inv := &invoice{
+		data: btcpay.Invoice{
+			ID:             id,
+			URL:            s.URL + "/i/" + id,
+			Status:         StatusNew,
+			Price:          p.Price,
+			Currency:       p.Currency,
+			ItemDesc:       p.ItemDesc,
+			OrderID:        p.OrderID,
+			POSData:        p.POSData,
+			RedirectURL:    p.RedirectURL,
+			Buyer:          p.Buyer,
+			InvoiceTime:    now.UnixNano() / int64(time.Millisecond),
+			ExpirationTime: now.Add(time.Minute*15).UnixNano() / int64(time.Millisecond),
+		},
+		notify:  p.NotificationURL,
+		created: now,
+	}
+
+	s.invoices[id] = inv
+	ipns := []ipn{s.newIPN(inv, btcpay.EventInvoiceCreated, nil)}
+
+	s.mu.Unlock()
+
+	s.deliver(ipns)
+
+	writeData(w, http.StatusOK, s.snapshot(inv))
+}
+
+// listInvoices lists invoices that match the status and order ID
+// filters.
+func (s *Server) listInvoices(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	s.mu.Lock()
+
+	invs := make([]btcpay.Invoice, 0, len(s.invoices))
+
+	for _, inv := range s.invoices {
+		if (q.Get("status") != "" && q.Get("status") != inv.data.Status) ||
+			(q.Get("orderId") != "" && q.Get("orderId") != inv.data.OrderID) {
+			continue
+		}
+
+		invs = append(invs, inv.data)
+	}
+
+	s.mu.Unlock()
+
+	sort.Slice(invs, func(i, j int) bool {
+		return invs[i].InvoiceTime < invs[j].InvoiceTime ||
+			(invs[i].InvoiceTime == invs[j].InvoiceTime && invs[i].ID < invs[j].ID)
+	})
+
+	for i := range invs {
+		invs[i].CurrentTime = s.clock.Now().UnixNano() / int64(time.Millisecond)
+	}
+
+	writeData(w, http.StatusOK, invs)
+}
+
+// snapshot returns a copy of the invoice data.
+func (s *Server) snapshot(inv *invoice) btcpay.Invoice {
+	s.mu.Lock()
+	data := inv.data
+	s.mu.Unlock()
+
+	data.CurrentTime = s.clock.Now().UnixNano() / int64(time.Millisecond)
+
+	return data
+}
+
+// authorized checks whether the token was issued by the server.
+func (s *Server) authorized(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.tokens[token]
+}
+
+// ipn is a pending invoice notification.
+type ipn struct {
+	target string
+	body   []byte
+}
+
+// transition changes the status of the invoice and returns the
+// notifications that should be delivered. The lock must be held.
+func (s *Server) transition(inv *invoice, status string) []ipn {
+	if inv.data.Status == status {
+		return nil
+	}
+
+	inv.data.Status = status
+
+	switch status {
+	case StatusPaid:
+		inv.data.AmountPaid = inv.data.Price
+
+		return []ipn{
+			s.newIPN(inv, btcpay.EventInvoiceReceivedPayment, nil),
+			s.newIPN(inv, btcpay.EventInvoiceProcessing, nil),
+		}
+	case StatusConfirmed:
+		return []ipn{s.newIPN(inv, btcpay.EventInvoicePaymentSettled, nil)}
+	case StatusComplete:
+		inv.data.AmountPaid = inv.data.Price
+		return []ipn{s.newIPN(inv, btcpay.EventInvoiceSettled, nil)}
+	case StatusExpired:
+		return []ipn{s.newIPN(inv, btcpay.EventInvoiceExpired, map[string]interface{}{
+			"partiallyPaid": inv.data.AmountPaid.IsPositive(),
+		})}
+	case StatusInvalid:
+		return []ipn{s.newIPN(inv, btcpay.EventInvoiceInvalid, nil)}
+	default:
+		return nil
+	}
+}
+
+// newIPN creates a notification of the invoice event. The lock must be
+// held.
+func (s *Server) newIPN(inv *invoice, typ btcpay.EventType, extra map[string]interface{}) ipn {
+	s.seq++
+
+	ev := map[string]interface{}{
+		"deliveryId": "delivery" + strconv.Itoa(s.seq),
+		"webhookId":  "btcpaytest",
+		"type":       typ,
+		"timestamp":  s.clock.Now().Unix(),
+		"invoiceId":  inv.data.ID,
+	}
+
+	for k, v := range extra {
+		ev[k] = v
+	}
+
+	b, _ := json.Marshal(ev) //nolint:errcheck // event fields are always encodable
+
+	return ipn{target: inv.notify, body: b}
+}
+
+// deliver sends the notifications to the IPN handler or their
+// notification URLs. Delivery failures are ignored.
+func (s *Server) deliver(ipns []ipn) {
+	for _, n := range ipns {
+		if s.ipnHandler != nil {
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(n.body))
+			req.Header.Set("Content-Type", "application/json")
+			s.ipnHandler.ServeHTTP(httptest.NewRecorder(), req)
+
+			continue
+		}
+
+		if n.target == "" {
+			continue
+		}
+
+		resp, err := s.hc.Post(n.target, "application/json", bytes.NewReader(n.body))
+		if err == nil {
+			resp.Body.Close()
+		}
+	}
+}
+
+// writeData writes the value wrapped in the legacy API data envelope.
+func writeData(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": v}) //nolint:errcheck // the client may be gone
+}
+
+// writeError writes a legacy API error response.
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg}) //nolint:errcheck // the client may be gone
+}
+
+// systemClock is a btcpay.Clock backed by the time package.
+type systemClock struct{}
+
+// Now returns the current time.
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// After waits for the duration to elapse.
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
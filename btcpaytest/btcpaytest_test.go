@@ -0,0 +1,206 @@
+package btcpaytest
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swithek/btcpay-go"
+)
+
+// manualClock is a clock that moves only when told to.
+type manualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (mc *manualClock) Now() time.Time {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	return mc.now
+}
+
+func (mc *manualClock) After(time.Duration) <-chan time.Time {
+	return make(chan time.Time)
+}
+
+func (mc *manualClock) add(d time.Duration) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.now = mc.now.Add(d)
+}
+
+// eventRecorder collects parsed invoice notifications.
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []btcpay.EventType
+}
+
+func (er *eventRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return
+	}
+
+	ev, err := btcpay.ParseIPN(b)
+	if err != nil {
+		return
+	}
+
+	er.mu.Lock()
+	er.events = append(er.events, ev.Meta().Type)
+	er.mu.Unlock()
+}
+
+func (er *eventRecorder) types() []btcpay.EventType {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+
+	return append([]btcpay.EventType(nil), er.events...)
+}
+
+func Test_Server_Pairing(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	client, err := btcpay.NewPairedClient(s.URL, "code123")
+	require.NoError(t, err)
+	assert.NotEmpty(t, client.Token())
+
+	inv, err := client.CreateInvoice(context.Background(), btcpay.CreateInvoiceParams{
+		Currency: "USD",
+		Price:    decimal.NewFromInt(10),
+		OrderID:  "o1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, StatusNew, inv.Status)
+	assert.Equal(t, "o1", inv.OrderID)
+
+	res, err := client.Invoice(context.Background(), inv.ID)
+	require.NoError(t, err)
+	assert.Equal(t, inv.ID, res.ID)
+
+	invs, err := client.Invoices(context.Background(), btcpay.InvoicesParams{OrderID: "o1"})
+	require.NoError(t, err)
+	assert.Len(t, invs, 1)
+
+	_, err = client.Invoice(context.Background(), "unknown")
+	assert.EqualError(t, err, "[404] Object not found")
+
+	client, err = btcpay.NewClient(s.URL, "unknown")
+	require.NoError(t, err)
+
+	_, err = client.Invoice(context.Background(), inv.ID)
+	assert.EqualError(t, err, "[401] Unauthorized")
+}
+
+func Test_Server_Timeline(t *testing.T) {
+	clk := &manualClock{now: time.Unix(1000, 0)}
+	rec := &eventRecorder{}
+
+	s := NewServer(
+		WithClock(clk),
+		WithIPNHandler(rec),
+		WithTimeline(
+			Transition{After: time.Minute, Status: StatusPaid},
+			Transition{After: time.Minute * 10, Status: StatusComplete},
+		),
+	)
+	defer s.Close()
+
+	s.AddToken("tok")
+
+	client, err := btcpay.NewClient(s.URL, "tok")
+	require.NoError(t, err)
+
+	inv, err := client.CreateInvoice(context.Background(), btcpay.CreateInvoiceParams{Currency: "USD", Price: decimal.NewFromInt(10)})
+	require.NoError(t, err)
+
+	clk.add(time.Minute)
+
+	res, err := client.Invoice(context.Background(), inv.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPaid, res.Status)
+	assert.True(t, res.AmountPaid.Equal(decimal.NewFromInt(10)))
+
+	clk.add(time.Minute * 9)
+	s.Advance()
+
+	res, ok := s.Invoice(inv.ID)
+	require.True(t, ok)
+	assert.Equal(t, StatusComplete, res.Status)
+
+	assert.Equal(t, []btcpay.EventType{
+		btcpay.EventInvoiceCreated,
+		btcpay.EventInvoiceReceivedPayment,
+		btcpay.EventInvoiceProcessing,
+		btcpay.EventInvoiceSettled,
+	}, rec.types())
+
+	_, ok = s.Invoice("unknown")
+	assert.False(t, ok)
+}
+
+func Test_Server_SetStatus(t *testing.T) {
+	rec := &eventRecorder{}
+
+	ipns := httptest.NewServer(rec)
+	defer ipns.Close()
+
+	s := NewServer(WithTimeline(Transition{After: time.Hour, Status: StatusPaid}))
+	defer s.Close()
+
+	s.AddToken("tok")
+
+	client, err := btcpay.NewClient(s.URL, "tok")
+	require.NoError(t, err)
+
+	inv, err := client.CreateInvoice(context.Background(), btcpay.CreateInvoiceParams{
+		Currency:        "USD",
+		Price:           decimal.NewFromInt(10),
+		NotificationURL: ipns.URL,
+	})
+	require.NoError(t, err)
+
+	assert.Error(t, s.SetStatus("unknown", StatusExpired))
+	require.NoError(t, s.SetStatus(inv.ID, StatusExpired))
+
+	res, err := client.Invoice(context.Background(), inv.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusExpired, res.Status)
+
+	assert.Equal(t, []btcpay.EventType{
+		btcpay.EventInvoiceCreated,
+		btcpay.EventInvoiceExpired,
+	}, rec.types())
+}
+
+func Test_Server_InjectError(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.AddToken("tok")
+	s.InjectError(http.MethodPost, "/invoices", 2, http.StatusServiceUnavailable, "maintenance")
+
+	client, err := btcpay.NewClient(s.URL, "tok")
+	require.NoError(t, err)
+
+	p := btcpay.CreateInvoiceParams{Currency: "USD", Price: decimal.NewFromInt(10)}
+
+	for i := 0; i < 2; i++ {
+		_, err = client.CreateInvoice(context.Background(), p)
+		assert.EqualError(t, err, "[503] maintenance")
+	}
+
+	_, err = client.CreateInvoice(context.Background(), p)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,395 @@
+package btcpaytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// redacted replaces secrets in recorded interactions.
+const redacted = "[REDACTED]"
+
+// Mode specifies whether a Recorder records or replays interactions.
+type Mode int
+
+// Available recorder modes.
+const (
+	// ModeReplay serves responses from the fixture file without
+	// contacting the server.
+	ModeReplay Mode = iota
+
+	// ModeRecord sends requests to the server and records them along
+	// with their responses. Recorded interactions are written to the
+	// fixture file by Save.
+	ModeRecord
+)
+
+// secretHeaders holds the headers that are never recorded.
+var secretHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Identity",
+	"X-Signature",
+	"X-Nonce",
+	"X-Nonce-Signature",
+}
+
+// secretFields holds the query parameters and JSON fields whose values
+// are scrubbed.
+var secretFields = map[string]bool{
+	"token":       true,
+	"pairingCode": true,
+	"password":    true,
+	"apiKey":      true,
+	"secret":      true,
+}
+
+// Interaction holds a recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest holds the scrubbed data of a recorded request.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse holds the scrubbed data of a recorded response.
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// RecorderOption configures the recorder.
+type RecorderOption func(r *Recorder)
+
+// WithTransport sets the round tripper that recorded requests are sent
+// with. Defaults to http.DefaultTransport.
+func WithTransport(rt http.RoundTripper) RecorderOption {
+	return func(r *Recorder) {
+		r.next = rt
+	}
+}
+
+// WithScrubber sets a function that removes additional secrets or
+// personal data from interactions before they are recorded. Requests
+// being replayed are scrubbed by it as well before they are matched.
+func WithScrubber(fn func(i *Interaction)) RecorderOption {
+	return func(r *Recorder) {
+		r.scrub = fn
+	}
+}
+
+// WithMatcher sets the function that decides whether a scrubbed request
+// matches a recorded one. By default, the method, URL and body must be
+// equal.
+func WithMatcher(fn func(req, recorded RecordedRequest) bool) RecorderOption {
+	return func(r *Recorder) {
+		r.match = fn
+	}
+}
+
+// Recorder is an http.RoundTripper that records interactions with a
+// real BTCPay server into a fixture file and replays them later, e.g.
+// in CI, so that tests can be based on actual server responses. It is
+// set on the client with btcpay.WithHTTPClient.
+//
+// Tokens, API keys, signatures and other secrets are scrubbed before
+// interactions are recorded. Replayed requests are matched against the
+// recorded ones in order; each recorded interaction is used once.
+// It is safe for concurrent use by multiple goroutines.
+type Recorder struct {
+	path  string
+	mode  Mode
+	next  http.RoundTripper
+	scrub func(i *Interaction)
+	match func(req, recorded RecordedRequest) bool
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewRecorder creates a new recorder that uses the fixture file at the
+// path. In replay mode, the file must exist.
+func NewRecorder(path string, mode Mode, opts ...RecorderOption) (*Recorder, error) {
+	r := &Recorder{
+		path:  path,
+		mode:  mode,
+		next:  http.DefaultTransport,
+		match: matchRequest,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if mode != ModeReplay {
+		return r, nil
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(b, &r.interactions); err != nil {
+		return nil, fmt.Errorf("invalid fixture file: %w", err)
+	}
+
+	r.used = make([]bool, len(r.interactions))
+
+	return r, nil
+}
+
+// RoundTrip records or replays the request.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	i := Interaction{Request: RecordedRequest{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: req.Header.Clone(),
+		Body:   string(body),
+	}}
+
+	if r.mode == ModeReplay {
+		r.scrubInteraction(&i)
+
+		return r.replay(req, i.Request)
+	}
+
+	out := req.Clone(req.Context())
+	out.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	// the transport decompresses responses itself, so that plain
+	// bodies are recorded
+	out.Header.Del("Accept-Encoding")
+
+	resp, err := r.next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	rb, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	i.Response = RecordedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       string(rb),
+	}
+
+	r.scrubInteraction(&i)
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, i)
+	r.mu.Unlock()
+
+	// the caller receives the actual response
+	resp.Body = ioutil.NopCloser(bytes.NewReader(rb))
+	resp.ContentLength = int64(len(rb))
+
+	return resp, nil
+}
+
+// Save writes the recorded interactions to the fixture file. It does
+// nothing in replay mode.
+func (r *Recorder) Save() error {
+	if r.mode == ModeReplay {
+		return nil
+	}
+
+	r.mu.Lock()
+	b, err := json.MarshalIndent(r.interactions, "", "  ")
+	r.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(r.path, append(b, '\n'), 0o600)
+}
+
+// Unused returns the recorded interactions that have not been replayed,
+// so that tests can check whether all expected requests were sent.
+func (r *Recorder) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ii []Interaction
+
+	for j, i := range r.interactions {
+		if j < len(r.used) && !r.used[j] {
+			ii = append(ii, i)
+		}
+	}
+
+	return ii
+}
+
+// replay returns the response of the first unused interaction that
+// matches the request.
+func (r *Recorder) replay(req *http.Request, rr RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for j, i := range r.interactions {
+		if r.used[j] || !r.match(rr, i.Request) {
+			continue
+		}
+
+		r.used[j] = true
+
+		h := i.Response.Header.Clone()
+		if h == nil {
+			h = make(http.Header)
+		}
+
+		return &http.Response{
+			Status:        strconv.Itoa(i.Response.StatusCode) + " " + http.StatusText(i.Response.StatusCode),
+			StatusCode:    i.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        h,
+			Body:          ioutil.NopCloser(bytes.NewReader([]byte(i.Response.Body))),
+			ContentLength: int64(len(i.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("no recorded interaction matches %s %s", rr.Method, rr.URL)
+}
+
+// scrubInteraction removes secrets from the interaction.
+func (r *Recorder) scrubInteraction(i *Interaction) {
+	for _, h := range secretHeaders {
+		i.Request.Header.Del(h)
+		i.Response.Header.Del(h)
+	}
+
+	i.Request.URL = scrubURL(i.Request.URL)
+	i.Request.Body = scrubBody(i.Request.Body)
+	i.Response.Body = scrubBody(i.Response.Body)
+
+	if r.scrub != nil {
+		r.scrub(i)
+	}
+}
+
+// matchRequest checks whether the method, URL and body of the requests
+// are equal.
+func matchRequest(req, recorded RecordedRequest) bool {
+	return req.Method == recorded.Method && req.URL == recorded.URL && req.Body == recorded.Body
+}
+
+// scrubURL replaces values of secret query parameters.
+func scrubURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	q := u.Query()
+	changed := false
+
+	for k := range q {
+		if secretFields[k] {
+			q.Set(k, redacted)
+			changed = true
+		}
+	}
+
+	if changed {
+		u.RawQuery = q.Encode()
+	}
+
+	return u.String()
+}
+
+// scrubBody replaces values of secret fields in the JSON body. Other
+// bodies are returned as they are.
+func scrubBody(body string) string {
+	var v interface{}
+
+	if body == "" || json.Unmarshal([]byte(body), &v) != nil || !scrubValue(v) {
+		return body
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		// unlikely to happen
+		return body
+	}
+
+	return string(b)
+}
+
+// scrubValue replaces values of secret fields in the decoded JSON
+// value. True is returned if anything was replaced.
+func scrubValue(v interface{}) bool {
+	changed := false
+
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		for k, fv := range vv {
+			if _, ok := fv.(string); ok && secretFields[k] {
+				vv[k] = redacted
+				changed = true
+
+				continue
+			}
+
+			changed = scrubValue(fv) || changed
+		}
+	case []interface{}:
+		for _, fv := range vv {
+			changed = scrubValue(fv) || changed
+		}
+	}
+
+	return changed
+}
+
+// readBody reads the request body and makes it readable again.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	b, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	return b, nil
+}
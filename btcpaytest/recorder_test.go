@@ -0,0 +1,100 @@
+package btcpaytest
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swithek/btcpay-go"
+)
+
+func Test_Recorder(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.AddToken("secret-token")
+
+	path := filepath.Join(t.TempDir(), "fixtures", "invoices.json")
+
+	// exercise runs the same calls against the recorder
+	exercise := func(rec *Recorder, token string) btcpay.Invoice {
+		client, err := btcpay.NewClient(s.URL, token, btcpay.WithHTTPClient(&http.Client{Transport: rec}))
+		require.NoError(t, err)
+
+		inv, err := client.CreateInvoice(context.Background(), btcpay.CreateInvoiceParams{
+			Currency: "USD",
+			Price:    decimal.NewFromInt(10),
+			OrderID:  "o1",
+		})
+		require.NoError(t, err)
+
+		res, err := client.Invoice(context.Background(), inv.ID)
+		require.NoError(t, err)
+		assert.Equal(t, inv.ID, res.ID)
+
+		return res
+	}
+
+	rec, err := NewRecorder(path, ModeRecord, WithScrubber(func(i *Interaction) {
+		i.Request.Header.Del("User-Agent")
+	}))
+	require.NoError(t, err)
+
+	recorded := exercise(rec, "secret-token")
+	require.NoError(t, rec.Save())
+
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "secret-token")
+	assert.NotContains(t, string(b), "X-Signature")
+	assert.NotContains(t, string(b), "User-Agent")
+	assert.Contains(t, string(b), recorded.ID)
+
+	// the server is not needed for replay, so a different token is
+	// accepted as well
+	s.Close()
+
+	rec, err = NewRecorder(path, ModeReplay)
+	require.NoError(t, err)
+
+	replayed := exercise(rec, "other-token")
+	assert.Equal(t, recorded, replayed)
+	assert.Empty(t, rec.Unused())
+	assert.NoError(t, rec.Save())
+
+	client, err := btcpay.NewClient(s.URL, "other-token", btcpay.WithHTTPClient(&http.Client{Transport: rec}))
+	require.NoError(t, err)
+
+	// every interaction is replayed once
+	_, err = client.Invoice(context.Background(), recorded.ID)
+	assert.Error(t, err)
+}
+
+func Test_NewRecorder(t *testing.T) {
+	_, err := NewRecorder(filepath.Join(t.TempDir(), "missing.json"), ModeReplay)
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "invalid.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte("{"), 0o600))
+
+	_, err = NewRecorder(path, ModeReplay)
+	assert.Error(t, err)
+}
+
+func Test_scrubURL(t *testing.T) {
+	assert.Equal(t, "http://test.com/invoices?status=new&token=%5BREDACTED%5D",
+		scrubURL("http://test.com/invoices?token=tok&status=new"))
+	assert.Equal(t, "http://test.com/invoices?status=new", scrubURL("http://test.com/invoices?status=new"))
+}
+
+func Test_scrubBody(t *testing.T) {
+	assert.Equal(t, `{"items":[{"password":"[REDACTED]"}],"token":"[REDACTED]"}`,
+		scrubBody(`{"token":"tok","items":[{"password":"p"}]}`))
+	assert.Equal(t, `{"price": 1}`, scrubBody(`{"price": 1}`))
+	assert.Equal(t, "text", scrubBody("text"))
+}
@@ -0,0 +1,211 @@
+package btcpay
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ResponseCache stores responses of idempotent GET requests, such as
+// invoice and exchange rate retrievals. See WithResponseCache.
+type ResponseCache interface {
+	// Get returns the response stored under the key. False is returned
+	// if there is none.
+	Get(ctx context.Context, key string) (CachedResponse, bool, error)
+
+	// Set stores the response under the key.
+	Set(ctx context.Context, key string, r CachedResponse) error
+}
+
+// CachedResponse holds the body of a successful response along with
+// its validators.
+type CachedResponse struct {
+	Body         []byte    `json:"body"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	StoredAt     time.Time `json:"storedAt"`
+}
+
+// responseCache holds the response cache settings of the client.
+type responseCache struct {
+	store ResponseCache
+	ttl   time.Duration
+}
+
+// WithResponseCache makes the BTCPay client cache responses of invoice
+// and exchange rate retrievals in the store. Cached responses are
+// served without contacting the server for the specified duration.
+// Once they expire, they are revalidated with the ETag and
+// Last-Modified headers, if the server provided them, and served again
+// if the server reports no changes. Store errors are ignored, so that
+// an unavailable cache doesn't fail requests.
+func WithResponseCache(store ResponseCache, ttl time.Duration) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.cache = &responseCache{store: store, ttl: ttl}
+	}
+}
+
+// WithoutCache makes a single request bypass the response cache. The
+// retrieved response is still cached for subsequent requests.
+func WithoutCache() RequestOption {
+	return func(o *requestOptions) {
+		o.noCache = true
+	}
+}
+
+// cacheable marks the request as one whose response may be cached.
+func cacheable() RequestOption {
+	return func(o *requestOptions) {
+		o.cacheable = true
+	}
+}
+
+// doCached executes the request, serving it from the response cache if
+// it is cacheable.
+func (c *Client) doCached(req *http.Request, o requestOptions) (*http.Response, error) {
+	if c.cache == nil || !o.cacheable || req.Method != http.MethodGet {
+		return c.do(req)
+	}
+
+	ctx := req.Context()
+	key := cacheKey(req)
+
+	cr, ok, err := c.cache.store.Get(ctx, key)
+	if err != nil {
+		ok = false
+	}
+
+	if ok && !o.noCache {
+		if c.getClock().Now().Before(cr.StoredAt.Add(c.cache.ttl)) {
+			return cachedResponse(req, cr.Body), nil
+		}
+
+		if cr.ETag != "" {
+			req.Header.Set("If-None-Match", cr.ETag)
+		}
+
+		if cr.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cr.LastModified)
+		}
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && ok:
+		resp.Body.Close()
+	case resp.StatusCode == http.StatusOK:
+		b, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if err != nil {
+			return nil, err
+		}
+
+		cr = CachedResponse{
+			Body:         b,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		}
+	default:
+		return resp, nil
+	}
+
+	cr.StoredAt = c.getClock().Now()
+	c.cache.store.Set(ctx, key, cr) //nolint:errcheck // the response is served regardless
+
+	return cachedResponse(req, cr.Body), nil
+}
+
+// cacheKey returns the cache key of the request. Credentials are part
+// of the key, since they determine what the server responds with, so
+// the key is hashed to keep them out of the store.
+func cacheKey(req *http.Request) string {
+	h := sha256.New()
+	h.Write([]byte(req.Method + " " + req.URL.String() + "\n" + //nolint:errcheck // hashes never return errors
+		req.Header.Get("Authorization") + "\n" + req.Header.Get("X-Identity")))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cachedResponse creates a successful response with the body.
+func cachedResponse(req *http.Request, body []byte) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// MemoryResponseCache is a ResponseCache that keeps responses in
+// memory. Once it is full, the oldest responses are evicted.
+// It is safe for concurrent use by multiple goroutines.
+type MemoryResponseCache struct {
+	max int
+
+	mu        sync.Mutex
+	responses map[string]CachedResponse
+}
+
+// NewMemoryResponseCache creates a new in-memory response cache that
+// holds up to max responses. A non-positive max keeps all responses.
+func NewMemoryResponseCache(max int) *MemoryResponseCache {
+	return &MemoryResponseCache{
+		max:       max,
+		responses: make(map[string]CachedResponse),
+	}
+}
+
+// Get returns the response stored under the key.
+func (mc *MemoryResponseCache) Get(_ context.Context, key string) (CachedResponse, bool, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	r, ok := mc.responses[key]
+
+	return r, ok, nil
+}
+
+// Set stores the response under the key.
+func (mc *MemoryResponseCache) Set(_ context.Context, key string, r CachedResponse) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if _, ok := mc.responses[key]; !ok && mc.max > 0 && len(mc.responses) >= mc.max {
+		mc.evictOldest()
+	}
+
+	mc.responses[key] = r
+
+	return nil
+}
+
+// evictOldest removes the response that was stored first.
+func (mc *MemoryResponseCache) evictOldest() {
+	var (
+		oldest string
+		at     time.Time
+	)
+
+	for k, r := range mc.responses {
+		if oldest == "" || r.StoredAt.Before(at) {
+			oldest, at = k, r.StoredAt
+		}
+	}
+
+	delete(mc.responses, oldest)
+}
@@ -0,0 +1,211 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cacheStub struct {
+	ResponseCache
+	err error
+}
+
+func (cs cacheStub) Get(ctx context.Context, key string) (CachedResponse, bool, error) {
+	if cs.err != nil {
+		return CachedResponse{}, false, cs.err
+	}
+
+	return cs.ResponseCache.Get(ctx, key)
+}
+
+func Test_WithResponseCache(t *testing.T) {
+	c := &Client{}
+	WithResponseCache(NewMemoryResponseCache(0), time.Minute)(c)
+	require.NotNil(t, c.cache)
+	assert.NotNil(t, c.cache.store)
+	assert.Equal(t, time.Minute, c.cache.ttl)
+}
+
+func Test_WithoutCache(t *testing.T) {
+	o := newRequestOptions([]RequestOption{WithoutCache()})
+	assert.True(t, o.noCache)
+}
+
+func Test_Client_doCached(t *testing.T) {
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	req, err := http.NewRequest(http.MethodGet, "http://test.com/api/v1/stores/s1/invoices/i1", nil)
+	require.NoError(t, err)
+
+	key := cacheKey(req)
+
+	fresh := CachedResponse{Body: []byte(`{"id":"cached"}`), ETag: `"v1"`, StoredAt: now.Add(-time.Second * 30)}
+	stale := CachedResponse{Body: []byte(`{"id":"cached"}`), ETag: `"v1"`, LastModified: "Fri, 01 Jan 2021 11:00:00 GMT",
+		StoredAt: now.Add(-time.Hour)}
+
+	cc := map[string]struct {
+		Cached   *CachedResponse
+		StoreErr error
+		Opts     []RequestOption
+		Resp     httpmock.Responder
+		Calls    int
+		Result   string
+		Stored   CachedResponse
+		Err      bool
+	}{
+		"Error returned by the server": {
+			Resp:  httpmock.NewStringResponder(http.StatusNotFound, `{"code":"not-found","message":"not found"}`),
+			Calls: 1,
+			Err:   true,
+		},
+		"Response is not cached": {
+			Resp: func(*http.Request) (*http.Response, error) {
+				resp := httpmock.NewStringResponse(http.StatusOK, `{"id":"i1"}`)
+				resp.Header.Set("ETag", `"v2"`)
+
+				return resp, nil
+			},
+			Calls:  1,
+			Result: "i1",
+			Stored: CachedResponse{Body: []byte(`{"id":"i1"}`), ETag: `"v2"`, StoredAt: now},
+		},
+		"Cache store returns an error": {
+			Cached:   &fresh,
+			StoreErr: assert.AnError,
+			Resp:     httpmock.NewStringResponder(http.StatusOK, `{"id":"i1"}`),
+			Calls:    1,
+			Result:   "i1",
+			Stored:   CachedResponse{Body: []byte(`{"id":"i1"}`), StoredAt: now},
+		},
+		"Fresh response is cached": {
+			Cached: &fresh,
+			Result: "cached",
+			Stored: fresh,
+		},
+		"Fresh response is bypassed": {
+			Cached: &fresh,
+			Opts:   []RequestOption{WithoutCache()},
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `{"id":"i1"}`),
+			Calls:  1,
+			Result: "i1",
+			Stored: CachedResponse{Body: []byte(`{"id":"i1"}`), StoredAt: now},
+		},
+		"Stale response is not modified": {
+			Cached: &stale,
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if r.Header.Get("If-None-Match") != `"v1"` || r.Header.Get("If-Modified-Since") != stale.LastModified {
+					return httpmock.NewStringResponse(http.StatusOK, `{"id":"invalid"}`), nil
+				}
+
+				return httpmock.NewStringResponse(http.StatusNotModified, ""), nil
+			},
+			Calls:  1,
+			Result: "cached",
+			Stored: CachedResponse{Body: stale.Body, ETag: stale.ETag, LastModified: stale.LastModified, StoredAt: now},
+		},
+		"Stale response is modified": {
+			Cached: &stale,
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `{"id":"i1"}`),
+			Calls:  1,
+			Result: "i1",
+			Stored: CachedResponse{Body: []byte(`{"id":"i1"}`), StoredAt: now},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			store := NewMemoryResponseCache(0)
+			if c.Cached != nil {
+				require.NoError(t, store.Set(context.Background(), key, *c.Cached))
+			}
+
+			mt := httpmock.NewMockTransport()
+
+			if c.Resp != nil {
+				mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/stores/s1/invoices/i1", c.Resp)
+			}
+
+			client, err := NewClient("http://test.com", "",
+				WithHTTPClient(&http.Client{Transport: mt}),
+				WithClock(fixedClock{now}),
+				WithResponseCache(cacheStub{ResponseCache: store, err: c.StoreErr}, time.Minute),
+			)
+			require.NoError(t, err)
+
+			inv, err := client.StoreInvoice(context.Background(), "s1", "i1", c.Opts...)
+			assert.Equal(t, c.Calls, mt.GetTotalCallCount())
+
+			if c.Err {
+				assert.Error(t, err)
+
+				_, ok, err := store.Get(context.Background(), key)
+				require.NoError(t, err)
+				assert.False(t, ok)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, c.Result, inv.ID)
+
+			cr, ok, err := store.Get(context.Background(), key)
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, c.Stored, cr)
+		})
+	}
+}
+
+func Test_Client_doCached_Legacy(t *testing.T) {
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder(http.MethodGet, "http://test.com/invoices/i1", httpmock.NewStringResponder(http.StatusOK, `{"data":{"id":"i1"}}`))
+	mt.RegisterResponder(http.MethodPost, "http://test.com/invoices", httpmock.NewStringResponder(http.StatusOK, `{"data":{"id":"i1"}}`))
+
+	client, err := NewClient("http://test.com", "token", WithHTTPClient(&http.Client{Transport: mt}),
+		WithResponseCache(NewMemoryResponseCache(0), time.Minute))
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		inv, err := client.Invoice(context.Background(), "i1")
+		require.NoError(t, err)
+		assert.Equal(t, "i1", inv.ID)
+
+		_, err = client.CreateInvoice(context.Background(), CreateInvoiceParams{Currency: "USD"})
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 1, mt.GetCallCountInfo()["GET http://test.com/invoices/i1"])
+	assert.Equal(t, 2, mt.GetCallCountInfo()["POST http://test.com/invoices"])
+}
+
+func Test_MemoryResponseCache(t *testing.T) {
+	now := time.Now()
+	mc := NewMemoryResponseCache(2)
+
+	require.NoError(t, mc.Set(context.Background(), "a", CachedResponse{StoredAt: now.Add(-time.Minute)}))
+	require.NoError(t, mc.Set(context.Background(), "b", CachedResponse{StoredAt: now}))
+	require.NoError(t, mc.Set(context.Background(), "a", CachedResponse{StoredAt: now.Add(-time.Minute)}))
+	require.NoError(t, mc.Set(context.Background(), "c", CachedResponse{StoredAt: now}))
+
+	_, ok, err := mc.Get(context.Background(), "a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, ok, err = mc.Get(context.Background(), "b")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	_, ok, err = mc.Get(context.Background(), "c")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
@@ -0,0 +1,109 @@
+package btcpay
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// Paths of the callback endpoints, relative to the callback base URL.
+const (
+	notificationPath = "/ipn"
+	redirectPath     = "/redirect"
+)
+
+// WithCallbackBaseURL makes the BTCPay client fill in callback URLs of
+// new invoices that don't specify their own, so that they don't have
+// to be configured separately in every environment. Notifications are
+// sent to the base URL followed by /ipn and buyers are redirected to
+// the base URL followed by /redirect. Both URLs carry the invoice's
+// order ID, if any, in the orderId query parameter; the redirect URL
+// carries the invoice ID, filled in by the server, in the invoiceId
+// query parameter as well. Greenfield invoices are notified through
+// webhooks, so only their redirect URL is filled in.
+func WithCallbackBaseURL(base string) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.callbackBase = base
+	}
+}
+
+// normalizeCallbackBase validates the callback base URL and trims its
+// trailing slashes.
+func normalizeCallbackBase(base string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+
+	if !u.IsAbs() || u.Host == "" {
+		return "", errors.New("callback base URL must be absolute")
+	}
+
+	if u.RawQuery != "" || u.Fragment != "" {
+		return "", errors.New("callback base URL must not have a query or fragment")
+	}
+
+	return strings.TrimRight(base, "/"), nil
+}
+
+// callbackURL returns the URL of the callback endpoint with the order ID
+// in its query.
+func (c *Client) callbackURL(path, orderID string, withInvoice bool) string {
+	u := c.callbackBase + path
+
+	var q []string
+
+	if orderID != "" {
+		q = append(q, "orderId="+url.QueryEscape(orderID))
+	}
+
+	if withInvoice {
+		// the placeholder must not be escaped, otherwise the server
+		// would not replace it
+		q = append(q, "invoiceId="+invoiceIDPlaceholder)
+	}
+
+	if len(q) > 0 {
+		u += "?" + strings.Join(q, "&")
+	}
+
+	return u
+}
+
+// withCallbacks fills in the missing callback URLs of the legacy API
+// invoice.
+func (c *Client) withCallbacks(p CreateInvoiceParams) CreateInvoiceParams {
+	if c.callbackBase == "" {
+		return p
+	}
+
+	if p.NotificationURL == "" {
+		p.NotificationURL = c.callbackURL(notificationPath, p.OrderID, false)
+	}
+
+	if p.RedirectURL == "" {
+		p.RedirectURL = c.callbackURL(redirectPath, p.OrderID, true)
+	}
+
+	return p
+}
+
+// withStoreCallbacks fills in the missing redirect URL of the Greenfield
+// invoice. The caller's checkout settings are not modified.
+func (c *Client) withStoreCallbacks(p StoreInvoiceParams) StoreInvoiceParams {
+	if c.callbackBase == "" || (p.Checkout != nil && p.Checkout.RedirectURL != "") {
+		return p
+	}
+
+	var ch InvoiceCheckout
+	if p.Checkout != nil {
+		ch = *p.Checkout
+	}
+
+	orderID, _ := p.Metadata["orderId"].(string)
+
+	ch.RedirectURL = c.callbackURL(redirectPath, orderID, true)
+	p.Checkout = &ch
+
+	return p
+}
@@ -0,0 +1,138 @@
+package btcpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithCallbackBaseURL(t *testing.T) {
+	c := &Client{}
+	WithCallbackBaseURL("https://shop.com")(c)
+	assert.Equal(t, "https://shop.com", c.callbackBase)
+
+	_, err := NewClient("http://test.com", "", WithCallbackBaseURL("/btcpay"))
+	assert.Error(t, err)
+}
+
+func Test_normalizeCallbackBase(t *testing.T) {
+	cc := map[string]struct {
+		Base   string
+		Result string
+		Err    bool
+	}{
+		"Invalid URL": {
+			Base: "http://%",
+			Err:  true,
+		},
+		"Relative URL": {
+			Base: "/btcpay",
+			Err:  true,
+		},
+		"URL with query": {
+			Base: "https://shop.com/btcpay?a=1",
+			Err:  true,
+		},
+		"Valid URL": {
+			Base:   "https://shop.com/btcpay/",
+			Result: "https://shop.com/btcpay",
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			res, err := normalizeCallbackBase(c.Base)
+			if c.Err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_withCallbacks(t *testing.T) {
+	c := &Client{}
+	assert.Equal(t, CreateInvoiceParams{OrderID: "o1"}, c.withCallbacks(CreateInvoiceParams{OrderID: "o1"}))
+
+	c.callbackBase = "https://shop.com/btcpay"
+	assert.Equal(t, CreateInvoiceParams{
+		NotificationURL: "https://shop.com/btcpay/ipn",
+		RedirectURL:     "https://shop.com/btcpay/redirect?invoiceId={InvoiceId}",
+	}, c.withCallbacks(CreateInvoiceParams{}))
+
+	assert.Equal(t, CreateInvoiceParams{
+		OrderID:         "o 1",
+		NotificationURL: "https://shop.com/btcpay/ipn?orderId=o+1",
+		RedirectURL:     "https://test.com",
+	}, c.withCallbacks(CreateInvoiceParams{OrderID: "o 1", RedirectURL: "https://test.com"}))
+}
+
+func Test_Client_withStoreCallbacks(t *testing.T) {
+	c := &Client{}
+	assert.Equal(t, StoreInvoiceParams{}, c.withStoreCallbacks(StoreInvoiceParams{}))
+
+	c.callbackBase = "https://shop.com"
+
+	ch := &InvoiceCheckout{DefaultLanguage: "en"}
+	p := c.withStoreCallbacks(StoreInvoiceParams{Metadata: map[string]interface{}{"orderId": "o1"}, Checkout: ch})
+	require.NotNil(t, p.Checkout)
+	assert.Equal(t, "https://shop.com/redirect?orderId=o1&invoiceId={InvoiceId}", p.Checkout.RedirectURL)
+	assert.Equal(t, "en", p.Checkout.DefaultLanguage)
+	assert.Empty(t, ch.RedirectURL)
+
+	ch.RedirectURL = "https://test.com"
+	p = c.withStoreCallbacks(StoreInvoiceParams{Checkout: ch})
+	assert.Same(t, ch, p.Checkout)
+}
+
+func Test_Client_CreateInvoice_Callbacks(t *testing.T) {
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder(http.MethodPost, "http://test.com/invoices", func(r *http.Request) (*http.Response, error) {
+		var p CreateInvoiceParams
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			return nil, err
+		}
+
+		if p.NotificationURL != "https://shop.com/ipn?orderId=o1" || p.RedirectURL != "https://shop.com/redirect?orderId=o1&invoiceId={InvoiceId}" {
+			return nil, errors.New("invalid request")
+		}
+
+		return httpmock.NewStringResponse(http.StatusOK, `{"data":{"id":"i1"}}`), nil
+	})
+	mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/stores/s1/invoices", func(r *http.Request) (*http.Response, error) {
+		var p StoreInvoiceParams
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			return nil, err
+		}
+
+		if p.Checkout == nil || p.Checkout.RedirectURL != "https://shop.com/redirect?invoiceId={InvoiceId}" {
+			return nil, errors.New("invalid request")
+		}
+
+		return httpmock.NewStringResponse(http.StatusOK, `{"id":"i2"}`), nil
+	})
+
+	client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}), WithCallbackBaseURL("https://shop.com/"))
+	require.NoError(t, err)
+
+	inv, err := client.CreateInvoice(context.Background(), CreateInvoiceParams{Currency: "USD", OrderID: "o1"})
+	require.NoError(t, err)
+	assert.Equal(t, "i1", inv.ID)
+
+	sinv, err := client.CreateStoreInvoice(context.Background(), "s1", StoreInvoiceParams{})
+	require.NoError(t, err)
+	assert.Equal(t, "i2", sinv.ID)
+}
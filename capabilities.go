@@ -0,0 +1,142 @@
+package btcpay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Server versions that introduced the gated features.
+var (
+	lazyPaymentMethodsVersion = [3]int{1, 3, 0}
+	refundsVersion            = [3]int{1, 5, 0}
+)
+
+// ErrUnsupportedByServer is returned when the server is too old to
+// support the requested feature. See Client.Capabilities.
+var ErrUnsupportedByServer = errors.New("unsupported by the server")
+
+// Capabilities holds the version and supported features of the BTCPay
+// server.
+type Capabilities struct {
+	// Version is the server's version. It is empty if the server has no
+	// Greenfield API.
+	Version string
+
+	// Greenfield reports whether the server has the Greenfield API.
+	Greenfield bool
+
+	// LazyPaymentMethods reports whether payment methods of Greenfield
+	// invoices can be activated lazily, see InvoiceCheckout.
+	LazyPaymentMethods bool
+
+	// Refunds reports whether Greenfield invoices can be refunded, see
+	// Client.RefundStoreInvoice.
+	Refunds bool
+}
+
+// Capabilities retrieves the version of the BTCPay server and derives
+// the features it supports. The result is cached for the lifetime of
+// the client; failed lookups are not. Servers that are older than the
+// Greenfield API are reported without any features. Servers whose
+// version cannot be parsed are assumed to support everything.
+func (c *Client) Capabilities(ctx context.Context, opts ...RequestOption) (Capabilities, error) {
+	c.mu.RLock()
+	cached := c.capabilities
+	c.mu.RUnlock()
+
+	if cached != nil {
+		return *cached, nil
+	}
+
+	var caps Capabilities
+
+	si, err := c.ServerInfo(ctx, opts...)
+	switch {
+	case isNotFound(err):
+		// the server has no Greenfield API
+	case err != nil:
+		return Capabilities{}, err
+	default:
+		v, ok := parseVersion(si.Version)
+
+		caps = Capabilities{
+			Version:            si.Version,
+			Greenfield:         true,
+			LazyPaymentMethods: !ok || !versionBefore(v, lazyPaymentMethodsVersion),
+			Refunds:            !ok || !versionBefore(v, refundsVersion),
+		}
+	}
+
+	c.mu.Lock()
+	c.capabilities = &caps
+	c.mu.Unlock()
+
+	return caps, nil
+}
+
+// unsupported replaces the not found error with ErrUnsupportedByServer
+// if the server lacks the feature. Other errors, and errors of servers
+// that support the feature, e.g. missing resources, are returned as
+// they are.
+func (c *Client) unsupported(ctx context.Context, err error, feature string, supports func(Capabilities) bool) error {
+	if !isNotFound(err) {
+		return err
+	}
+
+	caps, cerr := c.Capabilities(ctx)
+	if cerr != nil || supports(caps) {
+		return err
+	}
+
+	return fmt.Errorf("%s: %w", feature, ErrUnsupportedByServer)
+}
+
+// isNotFound checks whether the error was returned for a 404 response.
+func isNotFound(err error) bool {
+	var aerr *APIError
+
+	return errors.As(err, &aerr) && aerr.StatusCode == http.StatusNotFound
+}
+
+// parseVersion parses the major, minor and patch numbers of the
+// version, e.g. "1.5.3.0" or "v1.5.3". Additional numbers and
+// pre-release suffixes are ignored.
+func parseVersion(s string) ([3]int, bool) {
+	var v [3]int
+
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(s, "-+ "); i >= 0 {
+		s = s[:i]
+	}
+
+	pp := strings.Split(s, ".")
+	if len(pp) < 2 {
+		return v, false
+	}
+
+	for i := 0; i < len(v) && i < len(pp); i++ {
+		n, err := strconv.Atoi(pp[i])
+		if err != nil || n < 0 {
+			return v, false
+		}
+
+		v[i] = n
+	}
+
+	return v, true
+}
+
+// versionBefore checks whether the version v is older than min.
+func versionBefore(v, min [3]int) bool {
+	for i := range v {
+		if v[i] != min[i] {
+			return v[i] < min[i]
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,179 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_Capabilities(t *testing.T) {
+	cc := map[string]struct {
+		Resp httpmock.Responder
+		Exp  Capabilities
+		Err  bool
+	}{
+		"Error returned by the server": {
+			Resp: httpmock.NewStringResponder(http.StatusInternalServerError, `{"code":"err","message":"err"}`),
+			Err:  true,
+		},
+		"Server without Greenfield API": {
+			Resp: httpmock.NewStringResponder(http.StatusNotFound, ""),
+			Exp:  Capabilities{},
+		},
+		"Old server": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, `{"version":"1.2.4.0"}`),
+			Exp:  Capabilities{Version: "1.2.4.0", Greenfield: true},
+		},
+		"Server without refunds": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, `{"version":"1.4.9"}`),
+			Exp:  Capabilities{Version: "1.4.9", Greenfield: true, LazyPaymentMethods: true},
+		},
+		"Recent server": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, `{"version":"v1.11.0-rc1"}`),
+			Exp:  Capabilities{Version: "v1.11.0-rc1", Greenfield: true, LazyPaymentMethods: true, Refunds: true},
+		},
+		"Unknown version": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, `{"version":"dev"}`),
+			Exp:  Capabilities{Version: "dev", Greenfield: true, LazyPaymentMethods: true, Refunds: true},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/server/info", c.Resp)
+
+			caps, err := client.Capabilities(context.Background())
+			if c.Err {
+				assert.Error(t, err)
+
+				_, err = client.Capabilities(context.Background())
+				assert.Error(t, err)
+				assert.Equal(t, 2, mt.GetTotalCallCount())
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, c.Exp, caps)
+
+			caps, err = client.Capabilities(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, c.Exp, caps)
+			assert.Equal(t, 1, mt.GetTotalCallCount())
+		})
+	}
+}
+
+func Test_Client_unsupported(t *testing.T) {
+	lazy := true
+
+	cc := map[string]struct {
+		Version string
+		Call    func(c *Client) error
+		Resp    httpmock.Responder
+		Exp     error
+	}{
+		"Refund of a missing invoice": {
+			Version: "1.5.0",
+			Call: func(c *Client) error {
+				_, err := c.RefundStoreInvoice(context.Background(), "s1", "i1", RefundInvoiceParams{})
+				return err
+			},
+			Resp: httpmock.NewStringResponder(http.StatusNotFound, `{"code":"invoice-not-found","message":"not found"}`),
+			Exp:  &APIError{StatusCode: http.StatusNotFound, Code: "invoice-not-found", Message: "not found"},
+		},
+		"Refund on an old server": {
+			Version: "1.4.0",
+			Call: func(c *Client) error {
+				_, err := c.RefundStoreInvoice(context.Background(), "s1", "i1", RefundInvoiceParams{})
+				return err
+			},
+			Resp: httpmock.NewStringResponder(http.StatusNotFound, ""),
+			Exp:  ErrUnsupportedByServer,
+		},
+		"Invoice creation without Greenfield API": {
+			Call: func(c *Client) error {
+				_, err := c.CreateStoreInvoice(context.Background(), "s1", StoreInvoiceParams{Amount: decimal.NewFromInt(1)})
+				return err
+			},
+			Resp: httpmock.NewStringResponder(http.StatusNotFound, ""),
+			Exp:  ErrUnsupportedByServer,
+		},
+		"Lazy payment methods on an old server": {
+			Version: "1.2.0",
+			Call: func(c *Client) error {
+				_, err := c.CreateStoreInvoice(context.Background(), "s1", StoreInvoiceParams{
+					Amount:   decimal.NewFromInt(1),
+					Checkout: &InvoiceCheckout{LazyPaymentMethods: &lazy},
+				})
+				return err
+			},
+			Exp: ErrUnsupportedByServer,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			if c.Version != "" {
+				mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/server/info",
+					httpmock.NewStringResponder(http.StatusOK, `{"version":"`+c.Version+`"}`))
+			} else {
+				mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/server/info",
+					httpmock.NewStringResponder(http.StatusNotFound, ""))
+			}
+
+			if c.Resp != nil {
+				mt.RegisterResponder(http.MethodPost, "=~^http://test.com/api/v1/stores/s1/invoices", c.Resp)
+			}
+
+			err = c.Call(client)
+			if c.Exp == ErrUnsupportedByServer {
+				assert.ErrorIs(t, err, ErrUnsupportedByServer)
+				return
+			}
+
+			assert.Equal(t, c.Exp, err)
+		})
+	}
+}
+
+func Test_parseVersion(t *testing.T) {
+	v, ok := parseVersion("1.5.3.0")
+	assert.True(t, ok)
+	assert.Equal(t, [3]int{1, 5, 3}, v)
+
+	v, ok = parseVersion("v2.0-beta")
+	assert.True(t, ok)
+	assert.Equal(t, [3]int{2, 0, 0}, v)
+
+	_, ok = parseVersion("1")
+	assert.False(t, ok)
+
+	_, ok = parseVersion("1.x.0")
+	assert.False(t, ok)
+
+	assert.True(t, versionBefore([3]int{1, 4, 9}, [3]int{1, 5, 0}))
+	assert.False(t, versionBefore([3]int{1, 5, 0}, [3]int{1, 5, 0}))
+	assert.False(t, versionBefore([3]int{2, 0, 0}, [3]int{1, 5, 0}))
+}
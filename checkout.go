@@ -0,0 +1,85 @@
+package btcpay
+
+import (
+	"errors"
+	"net/url"
+)
+
+// CheckoutOptions holds settings of a checkout page link. Zero values
+// fall back to the invoice's and the store's settings.
+type CheckoutOptions struct {
+	// PaymentMethod is the ID of the payment method that is selected
+	// when the page opens, e.g. BTC or BTC-LightningNetwork. See
+	// CryptoInfo.PaymentMethodID.
+	PaymentMethod string
+
+	// Language is the language code of the page, e.g. de-DE.
+	Language string
+
+	// Modal renders the page for an embedded modal window instead of
+	// a standalone page.
+	Modal bool
+}
+
+// CheckoutURL returns the link of the invoice's checkout page with the
+// options applied.
+func (inv Invoice) CheckoutURL(o CheckoutOptions) (string, error) {
+	return checkoutURL(inv.URL, o)
+}
+
+// CheckoutURL returns the link of the invoice's checkout page with the
+// options applied.
+func (inv StoreInvoice) CheckoutURL(o CheckoutOptions) (string, error) {
+	return checkoutURL(inv.CheckoutLink, o)
+}
+
+// PaymentMethodID returns the ID of the payment method, e.g. BTC or
+// BTC-LightningNetwork, as used by the Greenfield API and the checkout
+// page.
+func (ci CryptoInfo) PaymentMethodID() string {
+	return paymentMethodID(ci.CryptoCode, ci.PaymentType)
+}
+
+// paymentMethodID converts the legacy API payment type of the
+// cryptocurrency to a payment method ID.
+func paymentMethodID(cryptoCode, paymentType string) string {
+	if paymentType == "LightningLike" {
+		return cryptoCode + "-LightningNetwork"
+	}
+
+	return cryptoCode
+}
+
+// checkoutURL adds the options to the query of the checkout link.
+func checkoutURL(link string, o CheckoutOptions) (string, error) {
+	if link == "" {
+		return "", errors.New("invoice has no checkout link")
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", err
+	}
+
+	if !u.IsAbs() {
+		return "", errors.New("checkout link must be absolute")
+	}
+
+	q := u.Query()
+
+	if o.PaymentMethod != "" {
+		q.Set("paymentMethodId", o.PaymentMethod)
+	}
+
+	if o.Language != "" {
+		q.Set("lang", o.Language)
+	}
+
+	if o.Modal {
+		q.Set("view", "modal")
+	}
+
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
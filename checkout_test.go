@@ -0,0 +1,67 @@
+package btcpay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Invoice_CheckoutURL(t *testing.T) {
+	cc := map[string]struct {
+		Link    string
+		Options CheckoutOptions
+		Result  string
+		Err     bool
+	}{
+		"Missing link": {
+			Err: true,
+		},
+		"Invalid link": {
+			Link: "http://%",
+			Err:  true,
+		},
+		"Relative link": {
+			Link: "/i/1",
+			Err:  true,
+		},
+		"Default checkout": {
+			Link:   "https://test.com/i/1",
+			Result: "https://test.com/i/1",
+		},
+		"All options": {
+			Link:    "https://test.com/invoice?id=1",
+			Options: CheckoutOptions{PaymentMethod: "BTC-LightningNetwork", Language: "de-DE", Modal: true},
+			Result:  "https://test.com/invoice?id=1&lang=de-DE&paymentMethodId=BTC-LightningNetwork&view=modal",
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			res, err := Invoice{URL: c.Link}.CheckoutURL(c.Options)
+			if c.Err {
+				assert.Error(t, err)
+				assert.Empty(t, res)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_StoreInvoice_CheckoutURL(t *testing.T) {
+	res, err := StoreInvoice{CheckoutLink: "https://test.com/i/1"}.CheckoutURL(CheckoutOptions{Language: "lt"})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://test.com/i/1?lang=lt", res)
+}
+
+func Test_CryptoInfo_PaymentMethodID(t *testing.T) {
+	assert.Equal(t, "BTC", CryptoInfo{CryptoCode: "BTC", PaymentType: "BTCLike"}.PaymentMethodID())
+	assert.Equal(t, "BTC-LightningNetwork", CryptoInfo{CryptoCode: "BTC", PaymentType: "LightningLike"}.PaymentMethodID())
+}
@@ -1,32 +1,90 @@
 package btcpay
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Client holds data that is needed to safely communicate with the
 // BTCPay server.
+// It is safe for concurrent use by multiple goroutines.
 type Client struct {
 	hc       *http.Client
 	header   map[string]string
 	host     string
 	pem      string
+	pemPass  string
 	clientID string
-	token    string
+	signer   Signer
+	apiKey   string
+	limiter  Limiter
+	breaker  *breaker
+	failover *failover
+	clock    Clock
+	timeout  time.Duration
+	proxy    *url.URL
+	dialer   Dialer
+	prefix   string
+
+	maxResponseBytes int64
+	uaSuffixes       []string
+	callbackBase     string
+	noRedaction      bool
+	strictDecoding   bool
+	bitPay           bool
+	decimalFormat    *DecimalFormat
+	encode           func(v interface{}) ([]byte, error)
+	sigDebug         *syncWriter
+	noCompression    bool
+	queryEncoding    QueryEncoding
+	canonicalSigs    bool
+	nonces           *nonceSource
+	errorDecoder     func(status int, body []byte) error
+	rates            *rateCache
+	cache            *responseCache
+
+	correlationHeader string
+	correlationFunc   func(ctx context.Context) string
+
+	tlsConfig  *tls.Config
+	pinnedCert []byte
+	tuning     *transportTuning
+
+	mu           sync.RWMutex
+	token        string
+	templates    map[string]InvoiceTemplate
+	capabilities *Capabilities
+
+	healthMu   sync.Mutex
+	keepalive  bool
+	unhealthy  bool
+	healthSubs map[chan bool]struct{}
+
+	tracer      trace.Tracer
+	meter       metric.Meter
+	instruments *instruments
+	metrics     Metrics
 }
 
 type setter func(c *Client)
 
-// WithHTTPClient sets a custom http client on the BTCPay client.
+// WithHTTPClient sets a custom http client on the BTCPay client. Its
+// redirect policy is not used by API requests; the client follows
+// redirects within the same host itself, so that signatures can be
+// computed again.
 func WithHTTPClient(hc *http.Client) setter { //nolint:golint // setter funcs cannot be created outside of this package
 	return func(c *Client) {
 		c.hc = hc
@@ -34,12 +92,24 @@ func WithHTTPClient(hc *http.Client) setter { //nolint:golint // setter funcs ca
 }
 
 // WithUserAgent sets a custom user agent string on the BTCPay client.
+// It replaces the default user agent, including its version and the
+// suffixes set with WithUserAgentSuffix.
 func WithUserAgent(ua string) setter { //nolint:golint // setter funcs cannot be created outside of this package
 	return func(c *Client) {
 		c.header["User-Agent"] = ua
 	}
 }
 
+// WithUserAgentSuffix appends a comment, e.g. the name and version of
+// the application, to the default user agent of the BTCPay client:
+// btcpay-go/<version> (<suffix>). Multiple suffixes are separated by
+// semicolons.
+func WithUserAgentSuffix(suffix string) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.uaSuffixes = append(c.uaSuffixes, suffix)
+	}
+}
+
 // WithPEM sets a custom PEM string on the BTCPay client.
 // If not set, it will be generated automatically.
 func WithPEM(pm string) setter { //nolint:golint // setter funcs cannot be created outside of this package
@@ -48,8 +118,62 @@ func WithPEM(pm string) setter { //nolint:golint // setter funcs cannot be creat
 	}
 }
 
-// NewClient creates a fresh instance of BTCPay client.
+// WithEncryptedPEM sets a custom PEM string, encrypted with the
+// provided passphrase, on the BTCPay client.
+func WithEncryptedPEM(pm, passphrase string) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.pem = pm
+		c.pemPass = passphrase
+	}
+}
+
+// WithSigner sets a custom signer of legacy API requests on the BTCPay
+// client. It takes precedence over the PEM string.
+func WithSigner(s Signer) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.signer = s
+	}
+}
+
+// WithAPIKey sets a Greenfield API key on the BTCPay client. It is
+// required by all methods that use the /api/v1 endpoints.
+func WithAPIKey(key string) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithJSONEncoder sets a custom function that encodes request payloads
+// on the BTCPay client. Payloads of legacy API requests must be encoded
+// as JSON objects.
+func WithJSONEncoder(fn func(v interface{}) ([]byte, error)) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.encode = fn
+	}
+}
+
+// WithPathPrefix adds a path prefix, e.g. /btcpay, to all endpoint
+// paths, for servers mounted under a sub-path or behind a gateway that
+// rewrites paths. It is appended to the host's own path, if any, and
+// is included in the URLs that legacy API requests are signed with.
+func WithPathPrefix(prefix string) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		if prefix = strings.Trim(prefix, "/"); prefix != "" {
+			c.host += "/" + prefix
+			c.prefix += "/" + prefix
+		}
+	}
+}
+
+// NewClient creates a fresh instance of BTCPay client. The host may
+// include a path prefix if the server is served from a sub-path; https
+// is used if the scheme is not specified.
 func NewClient(host, token string, ss ...setter) (*Client, error) {
+	host, err := normalizeHost(host)
+	if err != nil {
+		return nil, err
+	}
+
 	c := &Client{
 		hc: &http.Client{
 			Timeout: time.Second * 20,
@@ -58,33 +182,119 @@ func NewClient(host, token string, ss ...setter) (*Client, error) {
 			"Content-Type":     "application/json",
 			"Accept":           "application/json",
 			"X-Accept-Version": "2.0.0",
-			"User-Agent":       "btcpay-go",
 		},
-		host:  host,
-		token: token,
+		host:              host,
+		token:             token,
+		clock:             realClock{},
+		maxResponseBytes:  defaultMaxResponseBytes,
+		correlationHeader: defaultCorrelationHeader,
 	}
 
 	for _, s := range ss {
 		s(c)
 	}
 
-	var err error
+	if _, ok := c.header["User-Agent"]; !ok {
+		c.header["User-Agent"] = userAgent(c.uaSuffixes)
+	}
+
+	if c.breaker != nil {
+		c.breaker.now = c.getClock().Now
+	}
+
+	if c.callbackBase != "" {
+		if c.callbackBase, err = normalizeCallbackBase(c.callbackBase); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.failover != nil {
+		if err = c.failover.init(c.host, c.prefix, c.getClock().Now); err != nil {
+			return nil, err
+		}
+	}
 
-	if c.pem == "" {
-		c.pem, err = GeneratePEM()
+	if err = c.configureTransport(); err != nil {
+		return nil, err
+	}
+
+	if c.signer == nil {
+		if c.pemPass != "" {
+			c.pem, err = DecryptPEM(c.pem, c.pemPass)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if c.pem == "" {
+			c.pem, err = GeneratePEM()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		c.signer, err = NewPEMSigner(c.pem)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	c.clientID, err = generateSIN(c.pem)
+	if c.canonicalSigs {
+		c.signer, err = newCanonicalSigner(c.signer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c.clientID, err = generateSIN(c.signer.PublicKey())
 	if err != nil {
 		return nil, err
 	}
 
+	if c.meter != nil {
+		c.instruments, err = newInstruments(c.meter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return c, nil
 }
 
+// normalizeHost validates the host URL and converts it to the form
+// that endpoint paths can be appended to.
+func normalizeHost(host string) (string, error) {
+	host = strings.TrimSpace(host)
+	if host == "" {
+		return "", errors.New("host is not set")
+	}
+
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+
+	u, err := url.Parse(host)
+	if err != nil {
+		return "", fmt.Errorf("invalid host: %w", err)
+	}
+
+	switch {
+	case u.Scheme != "http" && u.Scheme != "https":
+		return "", fmt.Errorf("invalid host %q: scheme must be http or https", host)
+	case u.Host == "":
+		return "", fmt.Errorf("invalid host %q: host name is missing", host)
+	case u.RawQuery != "" || u.Fragment != "":
+		return "", fmt.Errorf("invalid host %q: query and fragment are not allowed", host)
+	}
+
+	// the pairing endpoint is commonly copied instead of the server's
+	// address
+	u.Path = strings.TrimSuffix(strings.TrimRight(u.Path, "/"), "/tokens")
+	u.RawPath = ""
+
+	return strings.TrimRight(u.String(), "/"), nil
+}
+
 // NewPairedClient creates a fresh instance of BTCPay client and pairs
 // it with the server.
 func NewPairedClient(host, code string, ss ...setter) (*Client, error) {
@@ -102,103 +312,278 @@ func NewPairedClient(host, code string, ss ...setter) (*Client, error) {
 
 // Token returns the active token used by the client.
 func (c *Client) Token() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	return c.token
 }
 
+// Repair pairs the client with the server again using the provided
+// pairing code. The active token is replaced only if pairing succeeds.
+func (c *Client) Repair(ctx context.Context, code string) error {
+	return c.pair(ctx, code)
+}
+
 // send sends an HTTP request to the specified endpoint.
-func (c *Client) send(ctx context.Context, method, endpoint string, params url.Values, payload interface{}, sig bool) (*http.Response, error) {
+func (c *Client) send(ctx context.Context, method string, endpoint route, params url.Values, payload interface{}, sig bool, opts ...RequestOption) (*http.Response, error) {
+	o := newRequestOptions(opts)
+
 	var (
 		body  string
-		query strings.Builder // query params order is important
+		token = c.Token()
 	)
 
+	if o.token != "" {
+		token = o.token
+	}
+
+	if sig && token == "" && c.bitPay {
+		return nil, errors.New("BitPay requires a facade token")
+	}
+
+	ik := ""
+	if ip, ok := payload.(idempotent); ok {
+		ik = ip.idempotencyKey()
+	}
+
 	if payload != nil {
-		d, err := json.Marshal(payload)
+		d, err := c.marshal(payload)
 		if err != nil {
 			return nil, err
 		}
 
-		if c.token != "" {
-			m := make(map[string]interface{})
-			if err = json.Unmarshal(d, &m); err != nil {
+		if ik != "" && c.bitPay {
+			// BitPay deduplicates invoices by their guid
+			d, err = injectField(d, "guid", ik)
+			if err != nil {
 				return nil, err
 			}
+		}
 
-			m["token"] = c.token
-
-			d, err = json.Marshal(m)
+		if token != "" {
+			d, err = injectField(d, "token", token)
 			if err != nil {
-				// unlikely to happen
 				return nil, err
 			}
 		}
 
 		body = string(d)
-	} else {
-		if c.token != "" {
-			query.WriteString("token=")
-			query.WriteString(c.token)
-		}
-	}
 
-	if len(params) > 0 {
-		if query.Len() > 0 {
-			query.WriteByte('&')
-		}
-
-		query.WriteString(params.Encode())
+		// the token is sent in the body instead
+		token = ""
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, c.host+endpoint, strings.NewReader(body))
+	req, err := http.NewRequestWithContext(withRoute(ctx, endpoint), method, c.baseURL()+endpoint.path, strings.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
 
-	req.URL.RawQuery = query.String()
+	// the signature covers the query string as it is sent
+	req.URL.RawQuery = CanonicalQuery(token, params, c.queryEncoding)
 
 	for k, v := range c.header {
 		req.Header.Set(k, v)
 	}
 
-	if sig {
-		pub, err := pubKey(c.pem)
-		if err != nil {
+	if ik != "" && !c.bitPay {
+		req.Header.Set("Idempotency-Key", ik)
+	}
+
+	if sig && !o.noSign {
+		if err = c.sign(req, body); err != nil {
 			return nil, err
 		}
+	}
 
-		req.Header.Set("X-Identity", pub)
+	resp, err := c.doWith(req, o)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.bitPay {
+		return checkBitPayEnvelope(resp)
+	}
 
-		sig, err := sign(c.pem, req.URL.String()+body)
+	return resp, nil
+}
+
+// injectField adds a string field to the encoded JSON object. The
+// object is not decoded, so the order and precision of its fields are
+// preserved.
+func injectField(d []byte, key, value string) ([]byte, error) {
+	d = bytes.TrimSpace(d)
+	if len(d) < 2 || d[0] != '{' || d[len(d)-1] != '}' {
+		return nil, errors.New("payload must be a JSON object")
+	}
+
+	k, err := json.Marshal(key)
+	if err != nil {
+		// unlikely to happen
+		return nil, err
+	}
+
+	v, err := json.Marshal(value)
+	if err != nil {
+		// unlikely to happen
+		return nil, err
+	}
+
+	res := make([]byte, 0, len(d)+len(k)+len(v)+2)
+	res = append(res, d[:len(d)-1]...)
+
+	if len(bytes.TrimSpace(d[1:len(d)-1])) > 0 {
+		res = append(res, ',')
+	}
+
+	res = append(res, k...)
+	res = append(res, ':')
+	res = append(res, v...)
+	res = append(res, '}')
+
+	return res, nil
+}
+
+// marshal encodes the request payload with the client's JSON encoder
+// and decimal format.
+func (c *Client) marshal(v interface{}) ([]byte, error) {
+	enc := c.encode
+	if enc == nil {
+		enc = json.Marshal
+	}
+
+	d, err := enc(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.decimalFormat == nil {
+		return d, nil
+	}
+
+	return formatDecimals(d, v, *c.decimalFormat)
+}
+
+// sendAPI sends an HTTP request to the specified Greenfield API endpoint.
+func (c *Client) sendAPI(ctx context.Context, method string, endpoint route, params url.Values, payload interface{}, opts ...RequestOption) (*http.Response, error) {
+	var body []byte
+
+	if payload != nil {
+		var err error
+
+		body, err = c.marshal(payload)
 		if err != nil {
 			return nil, err
 		}
-
-		req.Header.Set("X-Signature", sig)
 	}
 
-	resp, err := c.hc.Do(req)
+	req, err := http.NewRequestWithContext(withRoute(ctx, endpoint), method, c.baseURL()+endpoint.path, bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode >= 400 {
-		defer resp.Body.Close()
+	req.URL.RawQuery = params.Encode()
 
-		var rerr struct {
-			Error string `json:"error"`
-		}
+	for k, v := range c.header {
+		req.Header.Set(k, v)
+	}
 
-		err = json.NewDecoder(resp.Body).Decode(&rerr)
-		if err != nil {
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "token "+c.apiKey)
+	}
+
+	return c.doWith(req, newRequestOptions(opts))
+}
+
+// Do sends a request to an endpoint that is not wrapped by the client
+// and decodes the JSON response into out, unless it is nil. Endpoints
+// that start with /api/ are treated as Greenfield API endpoints and are
+// authorized with the API key, all others are signed legacy API
+// requests that carry the facade token. Responses are decoded as they
+// are, including the data envelope of legacy API responses.
+func (c *Client) Do(ctx context.Context, method, endpoint string, params url.Values, payload, out interface{}, opts ...RequestOption) error {
+	var (
+		resp *http.Response
+		err  error
+	)
+
+	if strings.HasPrefix(endpoint, "/api/") {
+		resp, err = c.sendAPI(ctx, method, route{path: endpoint}, params, payload, opts...)
+	} else {
+		resp, err = c.send(ctx, method, route{path: endpoint}, params, payload, true, opts...)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+
+	return c.decode(resp.Body, out)
+}
+
+// do executes the prepared HTTP request and checks whether the server
+// responded with an error.
+func (c *Client) do(req *http.Request) (_ *http.Response, err error) {
+	var status int
+
+	req, done := c.observe(req)
+	defer func() {
+		done(status, err)
+	}()
+
+	defer func() {
+		err = c.redactError(err)
+	}()
+
+	var trial bool
+
+	if c.breaker != nil {
+		if trial, err = c.breaker.allow(); err != nil {
 			return nil, err
 		}
+	}
+
+	c.acceptEncoding(req)
+
+	resp, err := c.failoverRoundTrip(req)
 
-		return nil, fmt.Errorf("[%d] %s", resp.StatusCode, rerr.Error)
+	if c.breaker != nil {
+		c.breaker.record(trial, resp, err)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	status = resp.StatusCode
+
+	if err = decompress(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	// the limit applies to the decompressed data, so that small
+	// compressed responses cannot exhaust the memory
+	resp.Body = limitBody(resp.Body, c.maxResponseBytes)
+
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+
+		return nil, c.decodeError(resp)
 	}
 
 	return resp, nil
 }
 
+// idempotent is implemented by payloads that carry an idempotency key.
+type idempotent interface {
+	idempotencyKey() string
+}
+
 // pair pairs the client with the BTCPay server.
 func (c *Client) pair(ctx context.Context, code string) error {
 	data := struct {
@@ -209,7 +594,7 @@ func (c *Client) pair(ctx context.Context, code string) error {
 		PairingCode: code,
 	}
 
-	resp, err := c.send(ctx, http.MethodPost, "/tokens", nil, data, false)
+	resp, err := c.send(ctx, http.MethodPost, newRoute("/tokens"), nil, data, false)
 	if err != nil {
 		return err
 	}
@@ -220,7 +605,7 @@ func (c *Client) pair(ctx context.Context, code string) error {
 		Token string `json:"token"`
 	}
 
-	if err = json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+	if err = c.decodeData(resp.Body, &tokens); err != nil {
 		return err
 	}
 
@@ -228,43 +613,100 @@ func (c *Client) pair(ctx context.Context, code string) error {
 		return errors.New("token data not returned")
 	}
 
+	c.mu.Lock()
 	c.token = tokens[0].Token
+	c.mu.Unlock()
 
 	return nil
 }
 
+// TransactionSpeed specifies the risk that the merchant is willing to
+// take by considering an invoice paid before its payment receives enough
+// confirmations. If not set, the store's speed policy is used.
+type TransactionSpeed string
+
+// Available transaction speeds.
+const (
+	// SpeedHigh considers an invoice confirmed as soon as its payment
+	// is seen on the network (0 confirmations). It carries the
+	// highest risk of a double spend.
+	SpeedHigh TransactionSpeed = "high"
+
+	// SpeedMedium considers an invoice confirmed after its payment
+	// receives 1 confirmation.
+	SpeedMedium TransactionSpeed = "medium"
+
+	// SpeedLowMedium considers an invoice confirmed after its payment
+	// receives 2 confirmations.
+	SpeedLowMedium TransactionSpeed = "lowmedium"
+
+	// SpeedLow considers an invoice confirmed after its payment
+	// receives 6 confirmations. It carries the lowest risk.
+	SpeedLow TransactionSpeed = "low"
+)
+
+// Valid checks whether the transaction speed is one of the known values.
+// An empty speed is valid.
+func (s TransactionSpeed) Valid() bool {
+	switch s {
+	case "", SpeedHigh, SpeedMedium, SpeedLowMedium, SpeedLow:
+		return true
+	default:
+		return false
+	}
+}
+
 // CreateInvoiceParams holds data used to initialize a new invoice.
 // More at: https://bitpay.com/api/#rest-api-resources-invoices-create-an-invoice
 type CreateInvoiceParams struct {
-	Currency              string          `json:"currency"`
-	Price                 decimal.Decimal `json:"price"`
-	OrderID               string          `json:"orderId,omitempty"`
-	ItemDesc              string          `json:"itemDesc,omitempty"`
-	ItemCode              string          `json:"itemCode,omitempty"`
-	NotificationEmail     string          `json:"notificationEmail,omitempty"`
-	NotificationURL       string          `json:"notificationURL,omitempty"`
-	RedirectURL           string          `json:"redirectURL,omitempty"`
-	POSData               string          `json:"posData,omitempty"`
-	TransactionSpeed      string          `json:"transactionSpeed,omitempty"`
-	FullNotifications     bool            `json:"fullNotifications,omitempty"`
-	ExtendedNotifications bool            `json:"extendedNotifications,omitempty"`
-	Physical              bool            `json:"physical,omitempty"`
-	Buyer                 InvoiceBuyer    `json:"buyer"`
-	PaymentCurrencies     []string        `json:"paymentCurrencies,omitempty"`
+	Currency              string           `json:"currency"`
+	Price                 decimal.Decimal  `json:"price"`
+	OrderID               string           `json:"orderId,omitempty"`
+	ItemDesc              string           `json:"itemDesc,omitempty"`
+	ItemCode              string           `json:"itemCode,omitempty"`
+	NotificationEmail     string           `json:"notificationEmail,omitempty"`
+	NotificationURL       string           `json:"notificationURL,omitempty"`
+	RedirectURL           string           `json:"redirectURL,omitempty"`
+	POSData               string           `json:"posData,omitempty"`
+	TransactionSpeed      TransactionSpeed `json:"transactionSpeed,omitempty"`
+	FullNotifications     bool             `json:"fullNotifications,omitempty"`
+	ExtendedNotifications bool             `json:"extendedNotifications,omitempty"`
+	Physical              bool             `json:"physical,omitempty"`
+	Buyer                 InvoiceBuyer     `json:"buyer"`
+	PaymentCurrencies     []string         `json:"paymentCurrencies,omitempty"`
+
+	// IdempotencyKey is sent as the Idempotency-Key header. BTCPay
+	// Server does not enforce the header on this endpoint, so requests
+	// with the same key create at most one invoice only if a proxy in
+	// front of the server, or the server itself, deduplicates them.
+	// Otherwise, retrying a request that may have reached the server
+	// can create a duplicate invoice.
+	//
+	// With WithBitPayCompatibility the key is sent as the invoice's
+	// guid instead, which BitPay uses to deduplicate invoices.
+	IdempotencyKey string `json:"-"`
+}
+
+// idempotencyKey returns the idempotency key of the invoice creation
+// request.
+func (p CreateInvoiceParams) idempotencyKey() string {
+	return p.IdempotencyKey
 }
 
 // InvoiceBuyer holds buyer information specified during invoice creation.
+// Fields that identify the buyer are Sensitive, so that they are
+// redacted when the buyer is logged.
 type InvoiceBuyer struct {
-	Name       string `json:"name,omitempty"`
-	Address1   string `json:"address1,omitempty"`
-	Address2   string `json:"address2,omitempty"`
-	Locality   string `json:"locality,omitempty"`
-	Region     string `json:"region,omitempty"`
-	PostalCode string `json:"postalCode,omitempty"`
-	Country    string `json:"country,omitempty"`
-	Email      string `json:"email,omitempty"`
-	Phone      string `json:"phone,omitempty"`
-	Notify     string `json:"notify,omitempty"`
+	Name       Sensitive `json:"name,omitempty"`
+	Address1   Sensitive `json:"address1,omitempty"`
+	Address2   Sensitive `json:"address2,omitempty"`
+	Locality   string    `json:"locality,omitempty"`
+	Region     string    `json:"region,omitempty"`
+	PostalCode Sensitive `json:"postalCode,omitempty"`
+	Country    string    `json:"country,omitempty"`
+	Email      Sensitive `json:"email,omitempty"`
+	Phone      Sensitive `json:"phone,omitempty"`
+	Notify     string    `json:"notify,omitempty"`
 }
 
 // Invoice holds invoice data retrieved from the payment processor.
@@ -290,45 +732,111 @@ type Invoice struct {
 	TransactionCurrency string          `json:"transactionCurrency"`
 	UnderpaidAmount     decimal.Decimal `json:"underpaidAmount"`
 	OverpaidAmount      decimal.Decimal `json:"overpaidAmount"`
+	CryptoInfo          []CryptoInfo    `json:"cryptoInfo"`
+}
+
+// CryptoInfo holds payment data of a single payment method of an
+// invoice.
+type CryptoInfo struct {
+	CryptoCode  string          `json:"cryptoCode"`
+	PaymentType string          `json:"paymentType"`
+	Rate        decimal.Decimal `json:"rate"`
+	Price       decimal.Decimal `json:"price"`
+	Paid        decimal.Decimal `json:"paid"`
+	Due         decimal.Decimal `json:"due"`
+	TotalDue    decimal.Decimal `json:"totalDue"`
+	NetworkFee  decimal.Decimal `json:"networkFee"`
+	Address     string          `json:"address"`
+	URL         string          `json:"url"`
+	PaymentURLs PaymentURLs     `json:"paymentUrls"`
+}
+
+// PaymentURLs holds payment URIs of a single payment method of an
+// invoice.
+type PaymentURLs struct {
+	BIP21  string `json:"BIP21"`
+	BIP72  string `json:"BIP72"`
+	BIP72b string `json:"BIP72b"`
+	BIP73  string `json:"BIP73"`
+	BOLT11 string `json:"BOLT11"`
+}
+
+// CreatedAt returns the time at which the invoice was created.
+func (inv Invoice) CreatedAt() time.Time {
+	return msToTime(inv.InvoiceTime)
+}
+
+// ExpiresAt returns the time at which the invoice expires.
+func (inv Invoice) ExpiresAt() time.Time {
+	return msToTime(inv.ExpirationTime)
+}
+
+// RetrievedAt returns the server time at which the invoice data was
+// retrieved.
+func (inv Invoice) RetrievedAt() time.Time {
+	return msToTime(inv.CurrentTime)
+}
+
+// TimeRemaining returns the duration left until the invoice expires.
+// Zero is returned if the invoice has already expired.
+func (inv Invoice) TimeRemaining() time.Duration {
+	d := time.Until(inv.ExpiresAt())
+	if d < 0 {
+		return 0
+	}
+
+	return d
+}
+
+// msToTime converts the Unix time in milliseconds to time.Time. Zero
+// time is returned if the provided value is zero.
+func msToTime(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, ms*int64(time.Millisecond))
 }
 
 // CreateInvoice creates a new invoice by the provided invoice
 // creation parameters.
-func (c *Client) CreateInvoice(ctx context.Context, p CreateInvoiceParams) (Invoice, error) {
-	resp, err := c.send(ctx, http.MethodPost, "/invoices", nil, p, true)
+func (c *Client) CreateInvoice(ctx context.Context, p CreateInvoiceParams, opts ...RequestOption) (Invoice, error) {
+	p = c.withCallbacks(p)
+
+	if err := p.Validate(); err != nil {
+		return Invoice{}, err
+	}
+
+	resp, err := c.send(ctx, http.MethodPost, newRoute("/invoices"), nil, p, true, opts...)
 	if err != nil {
 		return Invoice{}, err
 	}
 
 	defer resp.Body.Close()
 
-	var inv struct {
-		Data Invoice `json:"data"`
-	}
+	var inv Invoice
 
-	if err = json.NewDecoder(resp.Body).Decode(&inv); err != nil {
+	if err = c.decodeData(resp.Body, &inv); err != nil {
 		return Invoice{}, err
 	}
 
-	return inv.Data, nil
+	return inv, nil
 }
 
 // Invoice retrieves an invoice by the provided ID.
-func (c *Client) Invoice(ctx context.Context, id string) (Invoice, error) {
-	resp, err := c.send(ctx, http.MethodGet, "/invoices/"+id, nil, nil, true)
+func (c *Client) Invoice(ctx context.Context, id string, opts ...RequestOption) (Invoice, error) {
+	resp, err := c.send(ctx, http.MethodGet, newRoute("/invoices/:id", id), nil, nil, true, append([]RequestOption{cacheable()}, opts...)...)
 	if err != nil {
 		return Invoice{}, err
 	}
 
 	defer resp.Body.Close()
 
-	var inv struct {
-		Data Invoice `json:"data"`
-	}
+	var inv Invoice
 
-	if err = json.NewDecoder(resp.Body).Decode(&inv); err != nil {
+	if err = c.decodeData(resp.Body, &inv); err != nil {
 		return Invoice{}, err
 	}
 
-	return inv.Data, nil
+	return inv, nil
 }
@@ -1,6 +1,7 @@
 package btcpay
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -8,8 +9,10 @@ import (
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/jarcoal/httpmock"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -26,22 +29,244 @@ func Test_WithUserAgent(t *testing.T) {
 	assert.Equal(t, "test", c.header["User-Agent"])
 }
 
+func Test_WithUserAgentSuffix(t *testing.T) {
+	c := &Client{}
+	WithUserAgentSuffix("shop/1.2")(c)
+	WithUserAgentSuffix("test")(c)
+	assert.Equal(t, []string{"shop/1.2", "test"}, c.uaSuffixes)
+}
+
+func Test_NewClient_userAgent(t *testing.T) {
+	c, err := NewClient("http://test.com", "", WithUserAgentSuffix("shop/1.2"))
+	require.NoError(t, err)
+	assert.Equal(t, "btcpay-go/"+Version+" (shop/1.2)", c.header["User-Agent"])
+
+	c, err = NewClient("http://test.com", "", WithUserAgentSuffix("shop/1.2"), WithUserAgent("test"))
+	require.NoError(t, err)
+	assert.Equal(t, "test", c.header["User-Agent"])
+}
+
 func Test_WithPEM(t *testing.T) {
 	c := &Client{}
 	WithPEM("test")(c)
 	assert.Equal(t, "test", c.pem)
 }
 
+func Test_WithEncryptedPEM(t *testing.T) {
+	c := &Client{}
+	WithEncryptedPEM("test", "pass")(c)
+	assert.Equal(t, "test", c.pem)
+	assert.Equal(t, "pass", c.pemPass)
+}
+
+func Test_WithSigner(t *testing.T) {
+	c := &Client{}
+	WithSigner(signerStub{})(c)
+	assert.Equal(t, signerStub{}, c.signer)
+}
+
+func Test_WithAPIKey(t *testing.T) {
+	c := &Client{}
+	WithAPIKey("test")(c)
+	assert.Equal(t, "test", c.apiKey)
+}
+
+func Test_WithJSONEncoder(t *testing.T) {
+	c := &Client{}
+	WithJSONEncoder(func(interface{}) ([]byte, error) { return []byte("{}"), nil })(c)
+	require.NotNil(t, c.encode)
+
+	d, err := c.marshal(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "{}", string(d))
+}
+
+func Test_WithPathPrefix(t *testing.T) {
+	c := &Client{host: "http://test.com"}
+	WithPathPrefix("/")(c)
+	assert.Equal(t, "http://test.com", c.host)
+
+	WithPathPrefix("/btcpay/")(c)
+	assert.Equal(t, "http://test.com/btcpay", c.host)
+
+	WithPathPrefix("v1")(c)
+	assert.Equal(t, "http://test.com/btcpay/v1", c.host)
+
+	var sig bytes.Buffer
+
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder(http.MethodGet, "http://test.com/btcpay/invoices/1", httpmock.NewStringResponder(http.StatusOK, `{"data":{"id":"1"}}`))
+	mt.RegisterResponder(http.MethodGet, "http://test.com/btcpay/api/v1/health", httpmock.NewStringResponder(http.StatusOK, `{"synchronized":true}`))
+
+	client, err := NewClient("http://test.com/", "token", WithHTTPClient(&http.Client{Transport: mt}), WithPathPrefix("btcpay"), WithSignatureDebug(&sig))
+	require.NoError(t, err)
+
+	_, err = client.Invoice(context.Background(), "1")
+	require.NoError(t, err)
+	assert.Contains(t, sig.String(), "signed: http://test.com/btcpay/invoices/1?token=[REDACTED]\n")
+
+	_, err = client.Health(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, mt.GetTotalCallCount())
+}
+
 func Test_NewClient(t *testing.T) {
 	c, err := NewClient("test123", "test222")
 	assert.NoError(t, err)
 	require.NotNil(t, c)
 	assert.NotNil(t, c.hc)
 	assert.Len(t, c.header, 4)
-	assert.Equal(t, "test123", c.host)
+	assert.Equal(t, "https://test123", c.host)
 	assert.Equal(t, "test222", c.token)
 	assert.NotZero(t, c.pem)
+	assert.NotZero(t, c.signer)
 	assert.NotZero(t, c.clientID)
+
+	c, err = NewClient("test123", "test222", WithPEM("test"))
+	assert.Error(t, err)
+	assert.Nil(t, c)
+
+	epm, err := GenerateEncryptedPEM("pass")
+	require.NoError(t, err)
+
+	c, err = NewClient("test123", "test222", WithEncryptedPEM(epm, "wrong"))
+	assert.Error(t, err)
+	assert.Nil(t, c)
+
+	c, err = NewClient("test123", "test222", WithEncryptedPEM(epm, "pass"))
+	assert.NoError(t, err)
+	require.NotNil(t, c)
+	assert.NotContains(t, c.pem, "ENCRYPTED")
+
+	pm, err := GeneratePEM()
+	require.NoError(t, err)
+
+	s, err := NewPEMSigner(pm)
+	require.NoError(t, err)
+
+	c, err = NewClient("test123", "test222", WithPEM("test"), WithSigner(s))
+	assert.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, s, c.signer)
+
+	sin, err := generateSIN(s.PublicKey())
+	require.NoError(t, err)
+	assert.Equal(t, sin, c.clientID)
+}
+
+func Test_Client_send_Signer(t *testing.T) {
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder(http.MethodGet, "http://test.com/test", httpmock.NewStringResponder(http.StatusOK, ""))
+
+	pm, err := GeneratePEM()
+	require.NoError(t, err)
+
+	ps, err := NewPEMSigner(pm)
+	require.NoError(t, err)
+
+	client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}),
+		WithSigner(signerStub{pub: ps.PublicKey(), err: assert.AnError}))
+	require.NoError(t, err)
+
+	_, err = client.send(context.Background(), http.MethodGet, newRoute("/test"), nil, nil, true)
+	assert.Error(t, err)
+	assert.Zero(t, mt.GetTotalCallCount())
+
+	client, err = NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}),
+		WithSigner(signerStub{pub: ps.PublicKey(), sig: []byte{1, 2}}))
+	require.NoError(t, err)
+
+	mt.RegisterResponder(http.MethodGet, "http://test.com/test", func(r *http.Request) (*http.Response, error) {
+		if r.Header.Get("X-Identity") != ps.PublicKey() || r.Header.Get("X-Signature") != "0102" {
+			return nil, errors.New("invalid signature headers")
+		}
+
+		return httpmock.NewStringResponse(http.StatusOK, ""), nil
+	})
+
+	_, err = client.send(context.Background(), http.MethodGet, newRoute("/test"), nil, nil, true)
+	assert.NoError(t, err)
+}
+
+type signerStub struct {
+	pub string
+	sig []byte
+	err error
+}
+
+func (s signerStub) PublicKey() string {
+	return s.pub
+}
+
+func (s signerStub) Sign([]byte) ([]byte, error) {
+	return s.sig, s.err
+}
+
+func Test_normalizeHost(t *testing.T) {
+	cc := map[string]struct {
+		Host   string
+		Result string
+		Err    bool
+	}{
+		"Empty host": {
+			Host: " ",
+			Err:  true,
+		},
+		"Invalid URL": {
+			Host: "http://test.com:port",
+			Err:  true,
+		},
+		"Invalid scheme": {
+			Host: "ftp://test.com",
+			Err:  true,
+		},
+		"Missing host name": {
+			Host: "https:///path",
+			Err:  true,
+		},
+		"Query params": {
+			Host: "https://test.com?q=1",
+			Err:  true,
+		},
+		"Missing scheme": {
+			Host:   "test.com",
+			Result: "https://test.com",
+		},
+		"Trailing slash": {
+			Host:   "http://test.com/",
+			Result: "http://test.com",
+		},
+		"Sub-path": {
+			Host:   "https://test.com/btcpay/",
+			Result: "https://test.com/btcpay",
+		},
+		"Pairing endpoint": {
+			Host:   "https://test.com/btcpay/tokens",
+			Result: "https://test.com/btcpay",
+		},
+		"Root pairing endpoint": {
+			Host:   "test.com:8080/tokens/",
+			Result: "https://test.com:8080",
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			res, err := normalizeHost(c.Host)
+			if c.Err {
+				assert.Error(t, err)
+				assert.Empty(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
 }
 
 func Test_NewPairedClient(t *testing.T) {
@@ -67,12 +292,107 @@ func Test_Client_Token(t *testing.T) {
 	assert.Equal(t, "123", c.Token())
 }
 
+func Test_Client_Repair(t *testing.T) {
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder(http.MethodPost, "http://test.com/tokens", httpmock.NewErrorResponder(assert.AnError))
+
+	c, err := NewClient("http://test.com", "old123", WithHTTPClient(&http.Client{Transport: mt}))
+	require.NoError(t, err)
+
+	assert.Error(t, c.Repair(context.Background(), "test222"))
+	assert.Equal(t, "old123", c.Token())
+
+	// success
+	mt.RegisterResponder(http.MethodPost, "http://test.com/tokens", httpmock.NewStringResponder(http.StatusOK, `[{"token":"new123"}]`))
+
+	assert.NoError(t, c.Repair(context.Background(), "test222"))
+	assert.Equal(t, "new123", c.Token())
+}
+
+func Test_injectField(t *testing.T) {
+	cc := map[string]struct {
+		Payload string
+		Result  string
+		Err     bool
+	}{
+		"Invalid payload": {
+			Payload: "{",
+			Err:     true,
+		},
+		"Payload that is not an object": {
+			Payload: `["a"]`,
+			Err:     true,
+		},
+		"Empty object": {
+			Payload: "{}",
+			Result:  `{"token":"t\"1"}`,
+		},
+		"Object with fields": {
+			Payload: "{\"b\":1,\"a\":12345678901234567890123}\n",
+			Result:  `{"b":1,"a":12345678901234567890123,"token":"t\"1"}`,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			res, err := injectField([]byte(c.Payload), "token", `t"1`)
+			if c.Err {
+				assert.Error(t, err)
+				assert.Nil(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, string(res))
+		})
+	}
+}
+
+func Benchmark_injectField(b *testing.B) {
+	d, err := json.Marshal(CreateInvoiceParams{Currency: "USD", Price: decimal.NewFromInt(10), OrderID: "order123"})
+	require.NoError(b, err)
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err = injectField(d, "token", "token123"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_Client_send(b *testing.B) {
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder(http.MethodPost, "http://test.com/invoices", httpmock.NewStringResponder(http.StatusOK, ""))
+
+	client, err := NewClient("http://test.com", "token123", WithHTTPClient(&http.Client{Transport: mt}))
+	require.NoError(b, err)
+
+	p := CreateInvoiceParams{Currency: "USD", Price: decimal.NewFromInt(10), OrderID: "order123"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		resp, err := client.send(context.Background(), http.MethodPost, newRoute("/invoices"), nil, p, true)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		resp.Body.Close()
+	}
+}
+
 func Test_Client_send(t *testing.T) {
 	checkHeader := func(h http.Header, sig bool) error {
 		if h.Get("Content-Type") != "application/json" ||
 			h.Get("Accept") != "application/json" ||
 			h.Get("X-Accept-Version") != "2.0.0" ||
-			h.Get("User-Agent") != "btcpay-go" {
+			h.Get("User-Agent") != "btcpay-go/"+Version {
 			return errors.New("invalid header")
 		}
 
@@ -123,6 +443,7 @@ func Test_Client_send(t *testing.T) {
 			Resp:   httpmock.NewStringResponder(http.StatusUnauthorized, `{"error":"unauthorized123"`),
 			Sent:   true,
 			Err:    true,
+			ErrMsg: `[401] Unauthorized: {"error":"unauthorized123"`,
 		},
 		"Error response": {
 			Method: http.MethodPost,
@@ -131,6 +452,26 @@ func Test_Client_send(t *testing.T) {
 			Err:    true,
 			ErrMsg: "[401] unauthorized123",
 		},
+		"Greenfield error response": {
+			Method: http.MethodPost,
+			Resp:   httpmock.NewStringResponder(http.StatusNotFound, `{"code":"not-found","message":"not found123"}`),
+			Sent:   true,
+			Err:    true,
+			ErrMsg: "[404] not found123",
+		},
+		"Invalid Greenfield validation error response": {
+			Method: http.MethodPost,
+			Resp:   httpmock.NewStringResponder(http.StatusUnprocessableEntity, `[{"path":"amount"`),
+			Sent:   true,
+			Err:    true,
+		},
+		"Greenfield validation error response": {
+			Method: http.MethodPost,
+			Resp:   httpmock.NewStringResponder(http.StatusUnprocessableEntity, `[{"path":"amount","message":"invalid"},{"path":"currency","message":"required"}]`),
+			Sent:   true,
+			Err:    true,
+			ErrMsg: "[422] amount: invalid; currency: required",
+		},
 		"Successful execution with payload": {
 			Payload: CreateInvoiceParams{Currency: "USD"},
 			Method:  http.MethodPost,
@@ -185,16 +526,34 @@ func Test_Client_send(t *testing.T) {
 					panic(err)
 				}
 
-				m := make(map[string]interface{})
-				if err = json.Unmarshal(pl, &m); err != nil {
-					panic(err)
+				// the token is appended without changing the order of fields
+				pl = append(pl[:len(pl)-1], `,"token":"123"}`...)
+
+				if string(b) != string(pl) {
+					return nil, errors.New("invalid body")
 				}
 
-				m["token"] = "123"
+				return httpmock.NewStringResponse(http.StatusOK, ""), nil
+			},
+			Sent: true,
+			Err:  false,
+		},
+		"Successful execution with idempotency key": {
+			Payload: CreateInvoiceParams{Currency: "USD", IdempotencyKey: "key123"},
+			Method:  http.MethodPost,
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if r.Header.Get("Idempotency-Key") != "key123" {
+					return nil, errors.New("invalid idempotency header")
+				}
 
-				pl, err = json.Marshal(m)
+				b, err := ioutil.ReadAll(r.Body)
 				if err != nil {
-					panic(err)
+					return nil, err
+				}
+
+				pl, err := json.Marshal(CreateInvoiceParams{Currency: "USD"})
+				if err != nil {
+					return nil, errors.New("invalid payload")
 				}
 
 				if string(b) != string(pl) {
@@ -370,7 +729,7 @@ func Test_Client_send(t *testing.T) {
 			resp, err := client.send(
 				context.Background(),
 				c.Method,
-				"/testing",
+				newRoute("/testing"),
 				c.Params,
 				c.Payload,
 				c.Sig,
@@ -397,6 +756,236 @@ func Test_Client_send(t *testing.T) {
 	}
 }
 
+func Test_Client_sendAPI(t *testing.T) {
+	cc := map[string]struct {
+		Params  url.Values
+		Payload interface{}
+		APIKey  string
+		Method  string
+		Resp    httpmock.Responder
+		Sent    bool
+		Err     bool
+		ErrMsg  string
+	}{
+		"Invalid payload": {
+			Payload: func() {},
+			Method:  http.MethodPost,
+			Resp:    httpmock.NewStringResponder(http.StatusOK, ""),
+			Err:     true,
+		},
+		"Invalid method": {
+			Method: "[[123",
+			Resp:   httpmock.NewStringResponder(http.StatusOK, ""),
+			Err:    true,
+		},
+		"Error returned during request sending": {
+			Method: http.MethodPost,
+			Resp:   httpmock.NewErrorResponder(assert.AnError),
+			Sent:   true,
+			Err:    true,
+		},
+		"Error response": {
+			Method: http.MethodPost,
+			Resp:   httpmock.NewStringResponder(http.StatusForbidden, `{"code":"missing-permission","message":"forbidden123"}`),
+			Sent:   true,
+			Err:    true,
+			ErrMsg: "[403] forbidden123",
+		},
+		"Successful execution with payload, query params and API key": {
+			Params: func() url.Values {
+				p := url.Values{}
+				p.Set("q1", "v1")
+				return p
+			}(),
+			Payload: CreatePayoutParams{Destination: "abc"},
+			APIKey:  "key123",
+			Method:  http.MethodPost,
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if r.URL.RawQuery != "q1=v1" {
+					return nil, errors.New("invalid query params")
+				}
+
+				if r.Header.Get("Authorization") != "token key123" ||
+					r.Header.Get("Content-Type") != "application/json" {
+					return nil, errors.New("invalid header")
+				}
+
+				b, err := ioutil.ReadAll(r.Body)
+				if err != nil {
+					return nil, err
+				}
+
+				pl, err := json.Marshal(CreatePayoutParams{Destination: "abc"})
+				if err != nil {
+					return nil, err
+				}
+
+				if string(b) != string(pl) {
+					return nil, errors.New("invalid body")
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, ""), nil
+			},
+			Sent: true,
+		},
+		"Successful execution": {
+			Method: http.MethodPost,
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if len(r.URL.Query()) > 0 {
+					return nil, errors.New("invalid query params")
+				}
+
+				if r.Header.Get("Authorization") != "" {
+					return nil, errors.New("invalid header")
+				}
+
+				b, err := ioutil.ReadAll(r.Body)
+				if err != nil {
+					return nil, err
+				}
+
+				if len(b) > 0 {
+					return nil, errors.New("invalid body")
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, ""), nil
+			},
+			Sent: true,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}), WithAPIKey(c.APIKey))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodPost, "http://test.com/testing", c.Resp)
+
+			resp, err := client.sendAPI(
+				context.Background(),
+				c.Method,
+				newRoute("/testing"),
+				c.Params,
+				c.Payload,
+			)
+
+			if c.Sent {
+				assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodPost+" http://test.com/testing"])
+			}
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Nil(t, resp)
+
+				if c.ErrMsg != "" {
+					assert.EqualError(t, err, c.ErrMsg)
+				}
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NotNil(t, resp)
+		})
+	}
+}
+
+func Test_Client_Do(t *testing.T) {
+	cc := map[string]struct {
+		Endpoint string
+		Out      bool
+		Resp     httpmock.Responder
+		Err      bool
+		Result   map[string]interface{}
+	}{
+		"Error response": {
+			Endpoint: "/api/v1/testing",
+			Out:      true,
+			Resp:     httpmock.NewStringResponder(http.StatusForbidden, `{"code":"missing-permission","message":"forbidden123"}`),
+			Err:      true,
+		},
+		"Invalid response body": {
+			Endpoint: "/api/v1/testing",
+			Out:      true,
+			Resp:     httpmock.NewStringResponder(http.StatusOK, "{"),
+			Err:      true,
+		},
+		"Successful Greenfield API execution": {
+			Endpoint: "/api/v1/testing",
+			Out:      true,
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if r.Header.Get("Authorization") != "token key123" || r.Header.Get("X-Signature") != "" {
+					return nil, errors.New("invalid header")
+				}
+
+				if r.URL.RawQuery != "q1=v1" {
+					return nil, errors.New("invalid query params")
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"id":"123"}`), nil
+			},
+			Result: map[string]interface{}{"id": "123"},
+		},
+		"Successful legacy API execution": {
+			Endpoint: "/testing",
+			Out:      true,
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if r.Header.Get("Authorization") != "" || r.Header.Get("X-Signature") == "" {
+					return nil, errors.New("invalid header")
+				}
+
+				if r.URL.RawQuery != "token=tok&q1=v1" {
+					return nil, errors.New("invalid query params")
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"data":{"id":"123"}}`), nil
+			},
+			Result: map[string]interface{}{"data": map[string]interface{}{"id": "123"}},
+		},
+		"Successful execution without output": {
+			Endpoint: "/api/v1/testing",
+			Resp:     httpmock.NewStringResponder(http.StatusOK, "{"),
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "tok", WithHTTPClient(&http.Client{Transport: mt}), WithAPIKey("key123"))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com"+c.Endpoint, c.Resp)
+
+			var res map[string]interface{}
+
+			var out interface{}
+			if c.Out {
+				out = &res
+			}
+
+			err = client.Do(context.Background(), http.MethodGet, c.Endpoint, url.Values{"q1": {"v1"}}, nil, out)
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodGet+" http://test.com"+c.Endpoint])
+
+			if c.Err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
 func Test_Client_pair(t *testing.T) {
 	cc := map[string]struct {
 		Code   string
@@ -509,13 +1098,38 @@ func Test_Client_pair(t *testing.T) {
 	}
 }
 
+func Test_Invoice_Times(t *testing.T) {
+	inv := Invoice{
+		InvoiceTime:    1600000000000,
+		ExpirationTime: 1600000900500,
+		CurrentTime:    1600000060000,
+	}
+
+	assert.True(t, time.Unix(1600000000, 0).Equal(inv.CreatedAt()))
+	assert.True(t, time.Unix(1600000900, int64(time.Millisecond*500)).Equal(inv.ExpiresAt()))
+	assert.True(t, time.Unix(1600000060, 0).Equal(inv.RetrievedAt()))
+	assert.Zero(t, inv.TimeRemaining())
+	assert.Zero(t, Invoice{}.CreatedAt())
+
+	inv.ExpirationTime = time.Now().Add(time.Hour).UnixNano() / int64(time.Millisecond)
+	assert.InDelta(t, time.Hour, inv.TimeRemaining(), float64(time.Minute))
+}
+
 func Test_Client_CreateInvoice(t *testing.T) {
 	cc := map[string]struct {
 		Params CreateInvoiceParams
 		Resp   httpmock.Responder
 		Result Invoice
+		Sent   bool
 		Err    bool
 	}{
+		"Invalid params": {
+			Params: CreateInvoiceParams{
+				Currency: "US$",
+			},
+			Resp: httpmock.NewStringResponder(http.StatusOK, `{"data":{"id":"12345"}}`),
+			Err:  true,
+		},
 		"Error returned during request sending": {
 			Params: CreateInvoiceParams{
 				Currency: "USD",
@@ -541,7 +1155,8 @@ func Test_Client_CreateInvoice(t *testing.T) {
 
 				return httpmock.NewErrorResponder(assert.AnError)(r)
 			},
-			Err: true,
+			Sent: true,
+			Err:  true,
 		},
 		"Invalid response body": {
 			Params: CreateInvoiceParams{
@@ -568,7 +1183,8 @@ func Test_Client_CreateInvoice(t *testing.T) {
 
 				return httpmock.NewStringResponder(http.StatusOK, `{`)(r)
 			},
-			Err: true,
+			Sent: true,
+			Err:  true,
 		},
 		"Successful execution": {
 			Params: CreateInvoiceParams{
@@ -596,6 +1212,7 @@ func Test_Client_CreateInvoice(t *testing.T) {
 				return httpmock.NewStringResponder(http.StatusOK, `{"data":{"id":"12345"}}`)(r)
 			},
 			Result: Invoice{ID: "12345"},
+			Sent:   true,
 		},
 	}
 
@@ -613,7 +1230,11 @@ func Test_Client_CreateInvoice(t *testing.T) {
 
 			inv, err := client.CreateInvoice(context.Background(), c.Params)
 
-			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodPost+" http://test.com/invoices"])
+			if c.Sent {
+				assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodPost+" http://test.com/invoices"])
+			} else {
+				assert.Zero(t, mt.GetCallCountInfo()[http.MethodPost+" http://test.com/invoices"])
+			}
 
 			if c.Err {
 				assert.Error(t, err)
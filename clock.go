@@ -0,0 +1,76 @@
+package btcpay
+
+import (
+	"context"
+	"time"
+)
+
+// Clock provides the current time and timers. It allows time dependent
+// behavior, such as timeouts, retries and the circuit breaker, to be
+// controlled in tests.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// After returns a channel that receives the current time once the
+	// duration elapses.
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is a Clock backed by the time package.
+type realClock struct{}
+
+// Now returns the current time.
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// After waits for the duration to elapse.
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// WithClock sets a custom clock on the BTCPay client.
+func WithClock(clk Clock) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.clock = clk
+	}
+}
+
+// WithDefaultRequestTimeout sets the timeout applied to every request
+// that doesn't set its own with WithRequestTimeout. It is independent
+// of the HTTP client's timeout; the shortest of the two and the
+// context's deadline applies.
+func WithDefaultRequestTimeout(d time.Duration) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// getClock returns the client's clock.
+func (c *Client) getClock() Clock {
+	if c.clock == nil {
+		return realClock{}
+	}
+
+	return c.clock
+}
+
+// sleep pauses the current goroutine for the specified duration or
+// until the context is cancelled.
+func sleep(ctx context.Context, clk Clock, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-clk.After(d):
+		return nil
+	}
+}
+
+// fitsDeadline checks whether the duration elapses before the context's
+// deadline.
+func fitsDeadline(ctx context.Context, clk Clock, d time.Duration) bool {
+	dl, ok := ctx.Deadline()
+
+	return !ok || !clk.Now().Add(d).After(dl)
+}
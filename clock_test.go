@@ -0,0 +1,161 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedClock is a Clock that is stopped at a single point in time.
+type fixedClock struct {
+	now time.Time
+}
+
+func (fc fixedClock) Now() time.Time {
+	return fc.now
+}
+
+func (fc fixedClock) After(time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	ch <- fc.now
+
+	return ch
+}
+
+func Test_WithClock(t *testing.T) {
+	c := &Client{}
+	WithClock(fixedClock{})(c)
+	assert.Equal(t, fixedClock{}, c.clock)
+}
+
+func Test_WithDefaultRequestTimeout(t *testing.T) {
+	c := &Client{}
+	WithDefaultRequestTimeout(time.Second)(c)
+	assert.Equal(t, time.Second, c.timeout)
+}
+
+func Test_Client_getClock(t *testing.T) {
+	assert.Equal(t, realClock{}, (&Client{}).getClock())
+	assert.Equal(t, fixedClock{}, (&Client{clock: fixedClock{}}).getClock())
+}
+
+func Test_fitsDeadline(t *testing.T) {
+	now := time.Now()
+	clk := fixedClock{now: now}
+
+	assert.True(t, fitsDeadline(context.Background(), clk, time.Hour))
+
+	ctx, cancel := context.WithDeadline(context.Background(), now.Add(time.Minute))
+	defer cancel()
+
+	assert.True(t, fitsDeadline(ctx, clk, time.Second))
+	assert.True(t, fitsDeadline(ctx, clk, time.Minute))
+	assert.False(t, fitsDeadline(ctx, clk, time.Hour))
+}
+
+func Test_Client_doWith_Timeout(t *testing.T) {
+	cc := map[string]struct {
+		Now     time.Time
+		Default time.Duration
+		Opts    []RequestOption
+		Timeout time.Duration
+	}{
+		"No timeout": {
+			Now: time.Now(),
+		},
+		"Default timeout": {
+			Now:     time.Now().Add(time.Hour),
+			Default: time.Minute,
+			Timeout: time.Minute,
+		},
+		"Request timeout overrides default timeout": {
+			Now:     time.Now().Add(time.Hour),
+			Default: time.Minute,
+			Opts:    []RequestOption{WithRequestTimeout(time.Second)},
+			Timeout: time.Second,
+		},
+		"Clock in the past": {
+			Now:     time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			Default: time.Minute,
+			Timeout: time.Minute,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			var deadline time.Time
+
+			mt := httpmock.NewMockTransport()
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/health", func(r *http.Request) (*http.Response, error) {
+				deadline, _ = r.Context().Deadline()
+				return httpmock.NewStringResponse(http.StatusOK, "{}"), nil
+			})
+
+			client, err := NewClient("http://test.com", "",
+				WithHTTPClient(&http.Client{Transport: mt}),
+				WithClock(fixedClock{now: c.Now}),
+				WithDefaultRequestTimeout(c.Default),
+			)
+			require.NoError(t, err)
+
+			start := time.Now()
+
+			_, err = client.Health(context.Background(), c.Opts...)
+			require.NoError(t, err)
+
+			if c.Timeout == 0 {
+				assert.Zero(t, deadline)
+				return
+			}
+
+			assert.False(t, deadline.Before(start.Add(c.Timeout)))
+			assert.False(t, deadline.After(time.Now().Add(c.Timeout)))
+		})
+	}
+}
+
+func Test_Client_roundTrip_Deadline(t *testing.T) {
+	now := time.Now()
+
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder(http.MethodPost, "http://test.com/testing", func(r *http.Request) (*http.Response, error) {
+		resp := httpmock.NewStringResponse(http.StatusTooManyRequests, `{"error":"slow down"}`)
+		resp.Header.Set("Retry-After", "30")
+
+		return resp, nil
+	})
+
+	client := &Client{hc: &http.Client{Transport: mt}, clock: fixedClock{now: now}}
+
+	ctx, cancel := context.WithDeadline(context.Background(), now.Add(time.Second*10))
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://test.com/testing", strings.NewReader("body123"))
+	require.NoError(t, err)
+
+	resp, err := client.roundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, 1, mt.GetTotalCallCount())
+
+	// with enough time left the request is retried
+	ctx, cancel = context.WithDeadline(context.Background(), now.Add(time.Hour))
+	defer cancel()
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, "http://test.com/testing", strings.NewReader("body123"))
+	require.NoError(t, err)
+
+	_, err = client.roundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, 2+maxRateLimitRetries, mt.GetTotalCallCount())
+}
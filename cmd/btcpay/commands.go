@@ -0,0 +1,562 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/swithek/btcpay-go"
+)
+
+// pair pairs a new legacy API key with the server. The key is stored in
+// the PEM file, which is created if it does not exist.
+func (c *cli) pair(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("pair")
+	if err := parse(fs, args, 1); err != nil {
+		return err
+	}
+
+	if c.pemFile == "" {
+		return errors.New("PEM file is required to keep the paired key")
+	}
+
+	pem, err := ioutil.ReadFile(c.pemFile)
+	switch {
+	case os.IsNotExist(err):
+		gen, err := btcpay.GeneratePEM()
+		if err != nil {
+			return err
+		}
+
+		if err = ioutil.WriteFile(c.pemFile, []byte(gen), 0o600); err != nil {
+			return err
+		}
+
+		pem = []byte(gen)
+	case err != nil:
+		return err
+	}
+
+	client, err := btcpay.NewClient(c.host, "", btcpay.WithPEM(string(pem)))
+	if err != nil {
+		return err
+	}
+
+	if err = client.Repair(ctx, fs.Arg(0)); err != nil {
+		return err
+	}
+
+	res := struct {
+		Token string `json:"token"`
+	}{
+		Token: client.Token(),
+	}
+
+	return c.print(res, []string{"TOKEN"}, func() [][]string {
+		return [][]string{{res.Token}}
+	})
+}
+
+// invoice runs the invoice subcommands.
+func (c *cli) invoice(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "create":
+		return c.invoiceCreate(ctx, args[1:])
+	case "get":
+		return c.invoiceGet(ctx, args[1:])
+	case "list":
+		return c.invoiceList(ctx, args[1:])
+	case "cancel":
+		return c.invoiceCancel(ctx, args[1:])
+	default:
+		return errUsage
+	}
+}
+
+// invoiceCreate creates a new invoice through the legacy API.
+func (c *cli) invoiceCreate(ctx context.Context, args []string) error {
+	var p btcpay.CreateInvoiceParams
+
+	fs := c.newFlagSet("invoice create")
+	price := fs.String("price", "", "invoice price")
+	fs.StringVar(&p.Currency, "currency", "", "invoice currency")
+	fs.StringVar(&p.OrderID, "order-id", "", "order ID")
+	fs.StringVar(&p.ItemDesc, "desc", "", "item description")
+	fs.StringVar(&p.NotificationURL, "notification-url", "", "URL that receives invoice notifications")
+	fs.StringVar(&p.RedirectURL, "redirect-url", "", "URL that the buyer is redirected to after payment")
+
+	if err := parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	var err error
+
+	p.Price, err = decimal.NewFromString(*price)
+	if err != nil {
+		return fmt.Errorf("invalid price: %w", err)
+	}
+
+	if err = p.Validate(); err != nil {
+		return err
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	inv, err := client.CreateInvoice(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	return c.printInvoices(inv, inv)
+}
+
+// invoiceGet prints a single invoice.
+func (c *cli) invoiceGet(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("invoice get")
+	if err := parse(fs, args, 1); err != nil {
+		return err
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	inv, err := client.Invoice(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	return c.printInvoices(inv, inv)
+}
+
+// invoiceList lists invoices matching the filters.
+func (c *cli) invoiceList(ctx context.Context, args []string) error {
+	var p btcpay.InvoicesParams
+
+	fs := c.newFlagSet("invoice list")
+	fs.StringVar(&p.Status, "status", "", "invoice status")
+	fs.StringVar(&p.OrderID, "order-id", "", "order ID")
+	fs.IntVar(&p.Limit, "limit", 0, "maximum number of invoices")
+	fs.IntVar(&p.Offset, "offset", 0, "number of invoices to skip")
+	since := fs.Duration("since", 0, "only invoices created within the duration")
+
+	if err := parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	if *since > 0 {
+		p.DateStart = time.Now().Add(-*since)
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	invs, err := client.Invoices(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	return c.printInvoices(invs, invs...)
+}
+
+// invoiceCancel marks an invoice as invalid through the Greenfield API.
+func (c *cli) invoiceCancel(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("invoice cancel")
+	if err := parse(fs, args, 1); err != nil {
+		return err
+	}
+
+	storeID, err := c.store()
+	if err != nil {
+		return err
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	inv, err := client.MarkStoreInvoiceStatus(ctx, storeID, fs.Arg(0), "invalid")
+	if err != nil {
+		return err
+	}
+
+	return c.print(inv, []string{"ID", "STATUS", "AMOUNT", "CURRENCY"}, func() [][]string {
+		return [][]string{{inv.ID, inv.Status, inv.Amount.String(), inv.Currency}}
+	})
+}
+
+// printInvoices prints the value, with the invoices as table rows.
+func (c *cli) printInvoices(v interface{}, invs ...btcpay.Invoice) error {
+	return c.print(v, []string{"ID", "STATUS", "PRICE", "CURRENCY", "ORDER ID", "URL"}, func() [][]string {
+		rows := make([][]string, len(invs))
+		for i, inv := range invs {
+			rows[i] = []string{inv.ID, inv.Status, inv.Price.String(), inv.Currency, inv.OrderID, inv.URL}
+		}
+
+		return rows
+	})
+}
+
+// rates prints exchange rates of the currency pairs.
+func (c *cli) rates(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("rates")
+	if err := fs.Parse(args); err != nil || fs.NArg() == 0 {
+		return errUsage
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	rr, err := client.Rates(ctx, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	return c.print(rr, []string{"PAIR", "RATE", "NAME"}, func() [][]string {
+		rows := make([][]string, len(rr))
+		for i, r := range rr {
+			rows[i] = []string{r.CurrencyPair, r.Rate.String(), r.Name}
+		}
+
+		return rows
+	})
+}
+
+// refund refunds an invoice through a pull payment.
+func (c *cli) refund(ctx context.Context, args []string) error {
+	p := btcpay.RefundInvoiceParams{RefundVariant: btcpay.RefundRateThen}
+
+	fs := c.newFlagSet("refund")
+	fs.StringVar(&p.PaymentMethod, "payment-method", "BTC", "payment method of the refund")
+	fs.Var((*refundVariant)(&p.RefundVariant), "variant", "refund variant: RateThen, CurrentRate, Fiat, OverpaidAmount or Custom")
+	amount := fs.String("amount", "", "custom refund amount")
+	fs.StringVar(&p.CustomCurrency, "currency", "", "custom refund currency")
+	fs.StringVar(&p.Description, "desc", "", "refund description")
+
+	if err := parse(fs, args, 1); err != nil {
+		return err
+	}
+
+	if *amount != "" {
+		a, err := decimal.NewFromString(*amount)
+		if err != nil {
+			return fmt.Errorf("invalid amount: %w", err)
+		}
+
+		p.CustomAmount = &a
+	}
+
+	storeID, err := c.store()
+	if err != nil {
+		return err
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	pp, err := client.RefundStoreInvoice(ctx, storeID, fs.Arg(0), p)
+	if err != nil {
+		return err
+	}
+
+	return c.print(pp, []string{"PULL PAYMENT", "AMOUNT", "CURRENCY", "LINK"}, func() [][]string {
+		return [][]string{{pp.ID, pp.Amount.String(), pp.Currency, pp.ViewLink}}
+	})
+}
+
+// refundVariant is a flag value of a refund variant.
+type refundVariant btcpay.RefundVariant
+
+// String returns the refund variant.
+func (v *refundVariant) String() string {
+	return string(*v)
+}
+
+// Set sets the refund variant if it is one of the known values.
+func (v *refundVariant) Set(s string) error {
+	switch rv := btcpay.RefundVariant(s); rv {
+	case btcpay.RefundRateThen, btcpay.RefundCurrentRate, btcpay.RefundFiat, btcpay.RefundOverpaidAmount, btcpay.RefundCustom:
+		*v = refundVariant(rv)
+		return nil
+	default:
+		return errors.New("unknown refund variant")
+	}
+}
+
+// webhook runs the webhook subcommands.
+func (c *cli) webhook(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "list":
+		return c.webhookList(ctx, args[1:])
+	case "deliveries":
+		return c.webhookDeliveries(ctx, args[1:])
+	case "redeliver":
+		return c.webhookRedeliver(ctx, args[1:])
+	case "send":
+		return c.webhookSend(ctx, args[1:])
+	case "listen":
+		return c.webhookListen(ctx, args[1:])
+	default:
+		return errUsage
+	}
+}
+
+// webhookList lists webhooks of the store.
+func (c *cli) webhookList(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("webhook list")
+	if err := parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	storeID, err := c.store()
+	if err != nil {
+		return err
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	whs, err := client.Webhooks(ctx, storeID)
+	if err != nil {
+		return err
+	}
+
+	return c.print(whs, []string{"ID", "URL", "ENABLED"}, func() [][]string {
+		rows := make([][]string, len(whs))
+		for i, wh := range whs {
+			rows[i] = []string{wh.ID, wh.URL, strconv.FormatBool(wh.Enabled)}
+		}
+
+		return rows
+	})
+}
+
+// webhookDeliveries lists recent deliveries of a webhook.
+func (c *cli) webhookDeliveries(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("webhook deliveries")
+	count := fs.Int("count", 0, "maximum number of deliveries")
+
+	if err := parse(fs, args, 1); err != nil {
+		return err
+	}
+
+	storeID, err := c.store()
+	if err != nil {
+		return err
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	dd, err := client.WebhookDeliveries(ctx, storeID, fs.Arg(0), *count)
+	if err != nil {
+		return err
+	}
+
+	return c.print(dd, []string{"ID", "TIME", "STATUS", "HTTP CODE", "ERROR"}, func() [][]string {
+		rows := make([][]string, len(dd))
+		for i, d := range dd {
+			rows[i] = []string{d.ID, time.Unix(d.Timestamp, 0).UTC().Format(time.RFC3339), d.Status, strconv.Itoa(d.HTTPCode), d.ErrorMessage}
+		}
+
+		return rows
+	})
+}
+
+// webhookRedeliver redelivers a webhook delivery.
+func (c *cli) webhookRedeliver(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("webhook redeliver")
+	if err := parse(fs, args, 2); err != nil {
+		return err
+	}
+
+	storeID, err := c.store()
+	if err != nil {
+		return err
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	id, err := client.RedeliverWebhook(ctx, storeID, fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	res := struct {
+		DeliveryID string `json:"deliveryId"`
+	}{
+		DeliveryID: id,
+	}
+
+	return c.print(res, []string{"DELIVERY"}, func() [][]string {
+		return [][]string{{id}}
+	})
+}
+
+// webhookSend sends a test event, signed like BTCPay webhook
+// deliveries, to a webhook handler.
+func (c *cli) webhookSend(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("webhook send")
+	u := fs.String("url", "", "URL of the webhook handler")
+	typ := fs.String("type", string(btcpay.EventInvoiceSettled), "event type")
+	secret := fs.String("secret", "", "webhook secret used to sign the event")
+
+	if err := parse(fs, args, 1); err != nil {
+		return err
+	}
+
+	if *u == "" {
+		return errUsage
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+
+	ev := btcpay.EventMeta{
+		DeliveryID:         hex.EncodeToString(id),
+		WebhookID:          "test",
+		OriginalDeliveryID: hex.EncodeToString(id),
+		Type:               btcpay.EventType(*typ),
+		Timestamp:          time.Now().Unix(),
+		StoreID:            c.storeID,
+		InvoiceID:          fs.Arg(0),
+	}
+
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, *u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if *secret != "" {
+		mac := hmac.New(sha256.New, []byte(*secret))
+		mac.Write(body) //nolint:errcheck // hash writes never fail
+		req.Header.Set("BTCPay-Sig", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	res := struct {
+		DeliveryID string `json:"deliveryId"`
+		Status     int    `json:"status"`
+	}{
+		DeliveryID: ev.DeliveryID,
+		Status:     resp.StatusCode,
+	}
+
+	if err = c.print(res, []string{"DELIVERY", "STATUS"}, func() [][]string {
+		return [][]string{{res.DeliveryID, strconv.Itoa(res.Status)}}
+	}); err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("handler responded with %s", resp.Status)
+	}
+
+	return nil
+}
+
+// webhookListen prints events received by a local webhook handler
+// until the process is stopped.
+func (c *cli) webhookListen(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("webhook listen")
+	addr := fs.String("addr", "127.0.0.1:8080", "address to listen on")
+
+	if err := parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stderr, "listening on http://%s\n", l.Addr())
+
+	var mu sync.Mutex
+
+	srv := &http.Server{
+		Handler: btcpay.NewIPNHandler(func(_ context.Context, ev btcpay.Event) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			m := ev.Meta()
+
+			return c.print(ev, []string{"TYPE", "INVOICE", "DELIVERY"}, func() [][]string {
+				return [][]string{{string(m.Type), m.InvoiceID, m.DeliveryID}}
+			})
+		}),
+		ReadHeaderTimeout: time.Second * 10,
+	}
+
+	errc := make(chan error, 1)
+
+	go func() {
+		errc <- srv.Serve(l)
+	}()
+
+	select {
+	case err = <-errc:
+		return err
+	case <-c.signals:
+	}
+
+	sctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	return srv.Shutdown(sctx)
+}
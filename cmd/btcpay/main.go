@@ -0,0 +1,214 @@
+// Command btcpay is a command-line client of the BTCPay server built on
+// top of the btcpay package. It is meant for scripting and debugging,
+// so every command goes through the same code paths as applications
+// that use the package.
+//
+// Connection settings are read from flags or, if not set, from the
+// BTCPAY_HOST, BTCPAY_TOKEN, BTCPAY_PEM_FILE, BTCPAY_API_KEY and
+// BTCPAY_STORE environment variables. Legacy API commands (pair,
+// invoice create/get/list and rates) need a token and PEM; Greenfield
+// API commands (invoice cancel, refund and webhook) need an API key and
+// store ID.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/swithek/btcpay-go"
+)
+
+// usage is printed when the command is invoked incorrectly.
+const usage = `Usage: btcpay [flags] <command> [arguments]
+
+Commands:
+  pair <code>                         pair with the server and print the token
+  invoice create -price P -currency C create an invoice
+  invoice get <id>                    print an invoice
+  invoice list                        list invoices
+  invoice cancel <id>                 mark an invoice as invalid
+  rates <pair>...                     print exchange rates, e.g. BTC_USD
+  refund <invoice id>                 refund an invoice through a pull payment
+  webhook list                        list webhooks of the store
+  webhook deliveries <id>             list recent deliveries of a webhook
+  webhook redeliver <id> <delivery>   redeliver a webhook delivery
+  webhook send -url U <invoice id>    send a test event to a webhook handler
+  webhook listen                      print events received by a local handler
+
+Flags:
+`
+
+// errUsage is returned when the command is invoked incorrectly.
+var errUsage = errors.New("invalid usage")
+
+// cli holds the settings and output streams of a single invocation.
+type cli struct {
+	stdout io.Writer
+	stderr io.Writer
+
+	host    string
+	token   string
+	pemFile string
+	apiKey  string
+	storeID string
+	output  string
+
+	// signals is closed when the process is asked to stop.
+	signals <-chan struct{}
+}
+
+func main() {
+	stop := make(chan struct{})
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+
+	go func() {
+		<-sig
+		close(stop)
+	}()
+
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr, os.Getenv, stop))
+}
+
+// run executes the command specified by the arguments and returns the
+// process exit code.
+func run(args []string, stdout, stderr io.Writer, getenv func(string) string, stop <-chan struct{}) int {
+	c := &cli{stdout: stdout, stderr: stderr, signals: stop}
+
+	fs := flag.NewFlagSet("btcpay", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+
+	fs.StringVar(&c.host, "host", getenv("BTCPAY_HOST"), "server URL")
+	fs.StringVar(&c.token, "token", getenv("BTCPAY_TOKEN"), "legacy API token")
+	fs.StringVar(&c.pemFile, "pem-file", getenv("BTCPAY_PEM_FILE"), "path to the PEM file of the legacy API key")
+	fs.StringVar(&c.apiKey, "api-key", getenv("BTCPAY_API_KEY"), "Greenfield API key")
+	fs.StringVar(&c.storeID, "store", getenv("BTCPAY_STORE"), "store ID")
+	fs.StringVar(&c.output, "output", "table", "output format: table or json")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if c.output != "table" && c.output != "json" {
+		fmt.Fprintln(stderr, "output must be table or json")
+		return 2
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	cmds := map[string]func(ctx context.Context, args []string) error{
+		"pair":    c.pair,
+		"invoice": c.invoice,
+		"rates":   c.rates,
+		"refund":  c.refund,
+		"webhook": c.webhook,
+	}
+
+	cmd, ok := cmds[fs.Arg(0)]
+	if !ok {
+		fs.Usage()
+		return 2
+	}
+
+	if err := cmd(context.Background(), fs.Args()[1:]); err != nil {
+		if errors.Is(err, errUsage) {
+			fs.Usage()
+			return 2
+		}
+
+		fmt.Fprintln(stderr, "error:", err)
+
+		return 1
+	}
+
+	return 0
+}
+
+// client creates a BTCPay client with the configured credentials.
+func (c *cli) client() (*btcpay.Client, error) {
+	if c.host == "" {
+		return nil, errors.New("server URL is required")
+	}
+
+	var pem []byte
+
+	if c.pemFile != "" {
+		var err error
+
+		pem, err = ioutil.ReadFile(c.pemFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// an empty PEM is generated by the client
+	return btcpay.NewClient(c.host, c.token, btcpay.WithPEM(string(pem)), btcpay.WithAPIKey(c.apiKey))
+}
+
+// store returns the configured store ID.
+func (c *cli) store() (string, error) {
+	if c.storeID == "" {
+		return "", errors.New("store ID is required")
+	}
+
+	return c.storeID, nil
+}
+
+// print writes the value as JSON or, in table output, the header and
+// rows produced by the function.
+func (c *cli) print(v interface{}, header []string, rows func() [][]string) error {
+	if c.output == "json" {
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(v)
+	}
+
+	tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	for _, r := range rows() {
+		fmt.Fprintln(tw, strings.Join(r, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+// newFlagSet creates a flag set of a subcommand whose errors are
+// reported through the usage of the whole command.
+func (c *cli) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+
+	return fs
+}
+
+// parse parses the flags of a subcommand and checks the number of its
+// positional arguments.
+func parse(fs *flag.FlagSet, args []string, nargs int) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	if fs.NArg() != nargs {
+		return errUsage
+	}
+
+	return nil
+}
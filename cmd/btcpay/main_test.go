@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swithek/btcpay-go"
+	"github.com/swithek/btcpay-go/btcpaytest"
+)
+
+// execute runs the command with the provided environment and returns
+// its exit code and outputs.
+func execute(env map[string]string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+
+	code := run(args, &stdout, &stderr, func(k string) string { return env[k] }, nil)
+
+	return code, stdout.String(), stderr.String()
+}
+
+func Test_run_Usage(t *testing.T) {
+	cc := map[string][]string{
+		"No command":            nil,
+		"Unknown command":       {"test"},
+		"Unknown flag":          {"-test", "rates"},
+		"Invalid output":        {"-output", "xml", "rates", "BTC_USD"},
+		"Missing subcommand":    {"invoice"},
+		"Unknown subcommand":    {"webhook", "test"},
+		"Missing argument":      {"invoice", "get"},
+		"Too many arguments":    {"invoice", "get", "1", "2"},
+		"Missing pairs":         {"rates"},
+		"Invalid refund option": {"refund", "-variant", "test", "i1"},
+		"Missing handler URL":   {"webhook", "send", "i1"},
+	}
+
+	for cn, args := range cc {
+		args := args
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			code, stdout, stderr := execute(nil, args...)
+			assert.Equal(t, 2, code)
+			assert.Empty(t, stdout)
+			assert.NotEmpty(t, stderr)
+		})
+	}
+}
+
+func Test_run_Errors(t *testing.T) {
+	code, _, stderr := execute(nil, "invoice", "get", "i1")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "error: server URL is required\n", stderr)
+
+	code, _, stderr = execute(map[string]string{"BTCPAY_HOST": "http://test.com"}, "refund", "i1")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "error: store ID is required\n", stderr)
+
+	code, _, stderr = execute(map[string]string{"BTCPAY_HOST": "http://test.com"}, "pair", "code1")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "error: PEM file is required to keep the paired key\n", stderr)
+
+	code, _, stderr = execute(nil, "invoice", "create", "-price", "x", "-currency", "USD")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "invalid price")
+}
+
+func Test_run_Invoices(t *testing.T) {
+	s := btcpaytest.NewServer()
+	defer s.Close()
+
+	pemFile := filepath.Join(t.TempDir(), "key.pem")
+	env := map[string]string{"BTCPAY_HOST": s.URL, "BTCPAY_PEM_FILE": pemFile}
+
+	code, stdout, stderr := execute(env, "-output", "json", "pair", "code1")
+	require.Equal(t, 0, code, stderr)
+
+	var tok struct {
+		Token string `json:"token"`
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(stdout), &tok))
+	require.NotEmpty(t, tok.Token)
+
+	pem, err := ioutil.ReadFile(pemFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(pem), "PRIVATE KEY")
+
+	env["BTCPAY_TOKEN"] = tok.Token
+
+	code, stdout, stderr = execute(env, "-output", "json", "invoice", "create", "-price", "10", "-currency", "USD", "-order-id", "o1")
+	require.Equal(t, 0, code, stderr)
+
+	var inv btcpay.Invoice
+
+	require.NoError(t, json.Unmarshal([]byte(stdout), &inv))
+	assert.Equal(t, "o1", inv.OrderID)
+
+	code, stdout, stderr = execute(env, "invoice", "get", inv.ID)
+	require.Equal(t, 0, code, stderr)
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, []string{"ID", "STATUS", "PRICE", "CURRENCY", "ORDER", "ID", "URL"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{inv.ID, "new", "10", "USD", "o1", inv.URL}, strings.Fields(lines[1]))
+
+	code, stdout, stderr = execute(env, "invoice", "list", "-order-id", "o1")
+	require.Equal(t, 0, code, stderr)
+	assert.Len(t, strings.Split(strings.TrimSpace(stdout), "\n"), 2)
+
+	code, _, stderr = execute(env, "invoice", "get", "unknown")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "error: [404] Object not found\n", stderr)
+}
+
+func Test_run_Rates(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rates" || r.URL.Query().Get("currencyPairs") != "BTC_EUR,BTC_USD" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write([]byte(`{"data":[{"currencyPair":"BTC_USD","name":"US Dollar","rate":20000},{"currencyPair":"BTC_EUR","name":"Euro","rate":18000}]}`)) //nolint:errcheck // test response
+	}))
+	defer srv.Close()
+
+	code, stdout, stderr := execute(map[string]string{"BTCPAY_HOST": srv.URL}, "rates", "btc_usd", "BTC_EUR")
+	require.Equal(t, 0, code, stderr)
+	assert.Equal(t, "PAIR     RATE   NAME\nBTC_USD  20000  US Dollar\nBTC_EUR  18000  Euro\n", stdout)
+}
+
+func Test_run_Refund(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p map[string]interface{}
+
+		if r.URL.Path != "/api/v1/stores/s1/invoices/i1/refund" || r.Header.Get("Authorization") != "token k1" ||
+			json.NewDecoder(r.Body).Decode(&p) != nil || p["refundVariant"] != "Custom" || p["customAmount"] != "5" || p["paymentMethod"] != "BTC" {
+			http.Error(w, `{"code":"invalid","message":"invalid request"}`, http.StatusBadRequest)
+			return
+		}
+
+		w.Write([]byte(`{"id":"pp1","amount":"5","currency":"USD","viewLink":"http://test.com/pp1"}`)) //nolint:errcheck // test response
+	}))
+	defer srv.Close()
+
+	env := map[string]string{"BTCPAY_HOST": srv.URL, "BTCPAY_API_KEY": "k1", "BTCPAY_STORE": "s1"}
+
+	code, stdout, stderr := execute(env, "refund", "-variant", "Custom", "-amount", "5", "-currency", "USD", "i1")
+	require.Equal(t, 0, code, stderr)
+	assert.Equal(t, "PULL PAYMENT  AMOUNT  CURRENCY  LINK\npp1           5       USD       http://test.com/pp1\n", stdout)
+
+	code, _, stderr = execute(env, "refund", "i1")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "error: [400] invalid request\n", stderr)
+}
+
+func Test_run_WebhookSend(t *testing.T) {
+	var got btcpay.Event
+
+	h := btcpay.NewIPNHandler(func(_ context.Context, ev btcpay.Event) error {
+		got = ev
+		return nil
+	})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write(b) //nolint:errcheck // hash writes never fail
+
+		if r.Header.Get("BTCPay-Sig") != "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		r.Body = ioutil.NopCloser(bytes.NewReader(b))
+		h.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	code, stdout, stderr := execute(map[string]string{"BTCPAY_STORE": "s1"}, "webhook", "send", "-url", srv.URL, "-secret", "secret", "-type", "InvoiceExpired", "i1")
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "200")
+
+	require.NotNil(t, got)
+	assert.Equal(t, btcpay.EventInvoiceExpired, got.Meta().Type)
+	assert.Equal(t, "i1", got.Meta().InvoiceID)
+	assert.Equal(t, "s1", got.Meta().StoreID)
+
+	code, _, stderr = execute(nil, "webhook", "send", "-url", srv.URL, "-secret", "wrong", "i1")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "401")
+}
@@ -0,0 +1,104 @@
+package btcpay
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// WithoutCompression disables compressed responses. By default the
+// BTCPay client asks the server for gzip or deflate compressed
+// responses and decompresses them transparently.
+func WithoutCompression() setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.noCompression = true
+	}
+}
+
+// acceptEncoding sets the Accept-Encoding header of the request. The
+// header is always set explicitly, so that the HTTP transport does not
+// handle compression on its own.
+func (c *Client) acceptEncoding(req *http.Request) {
+	if req.Header.Get("Accept-Encoding") != "" {
+		return
+	}
+
+	if c.noCompression {
+		req.Header.Set("Accept-Encoding", "identity")
+		return
+	}
+
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+}
+
+// decompress replaces the body of a compressed response with a reader
+// of the decompressed data.
+func decompress(resp *http.Response) error {
+	var (
+		r   io.Reader
+		err error
+	)
+
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		r, err = gzip.NewReader(resp.Body)
+	case "deflate":
+		r, err = newDeflateReader(resp.Body)
+	default:
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	resp.Body = &decompressedBody{r: r, body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+
+	return nil
+}
+
+// newDeflateReader creates a reader of deflate encoded data. The HTTP
+// spec requires the data to be zlib wrapped, however some servers send
+// raw deflate streams, so both are supported.
+func newDeflateReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+
+	h, err := br.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+
+	if h[0]&0x0f == 8 && (uint16(h[0])<<8|uint16(h[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+
+	return flate.NewReader(br), nil
+}
+
+// decompressedBody reads the decompressed data of a response body.
+type decompressedBody struct {
+	r    io.Reader
+	body io.ReadCloser
+}
+
+// Read reads decompressed data.
+func (b *decompressedBody) Read(p []byte) (int, error) {
+	return b.r.Read(p)
+}
+
+// Close closes the decompressor and the underlying body.
+func (b *decompressedBody) Close() error {
+	if c, ok := b.r.(io.Closer); ok {
+		c.Close()
+	}
+
+	return b.body.Close()
+}
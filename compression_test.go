@@ -0,0 +1,115 @@
+package btcpay
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithoutCompression(t *testing.T) {
+	c := &Client{}
+	WithoutCompression()(c)
+	assert.True(t, c.noCompression)
+}
+
+func Test_Client_do_Compression(t *testing.T) {
+	compress := func(fn func(w io.Writer) io.WriteCloser) []byte {
+		var buf bytes.Buffer
+
+		w := fn(&buf)
+		_, err := w.Write([]byte(`{"id":"s1"}`))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		return buf.Bytes()
+	}
+
+	cc := map[string]struct {
+		Setters  []setter
+		Accept   string
+		Encoding string
+		Body     []byte
+		Err      bool
+	}{
+		"Invalid gzip data": {
+			Accept:   "gzip, deflate",
+			Encoding: "gzip",
+			Body:     []byte("test"),
+			Err:      true,
+		},
+		"Successful gzip decompression": {
+			Accept:   "gzip, deflate",
+			Encoding: "gzip",
+			Body: compress(func(w io.Writer) io.WriteCloser {
+				return gzip.NewWriter(w)
+			}),
+		},
+		"Successful zlib deflate decompression": {
+			Accept:   "gzip, deflate",
+			Encoding: "deflate",
+			Body: compress(func(w io.Writer) io.WriteCloser {
+				return zlib.NewWriter(w)
+			}),
+		},
+		"Successful raw deflate decompression": {
+			Accept:   "gzip, deflate",
+			Encoding: "deflate",
+			Body: compress(func(w io.Writer) io.WriteCloser {
+				fw, err := flate.NewWriter(w, flate.DefaultCompression)
+				require.NoError(t, err)
+
+				return fw
+			}),
+		},
+		"Uncompressed response": {
+			Accept: "gzip, deflate",
+			Body:   []byte(`{"id":"s1"}`),
+		},
+		"Disabled compression": {
+			Setters: []setter{WithoutCompression()},
+			Accept:  "identity",
+			Body:    []byte(`{"id":"s1"}`),
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", append(c.Setters, WithHTTPClient(&http.Client{Transport: mt}))...)
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/stores/s1", func(r *http.Request) (*http.Response, error) {
+				assert.Equal(t, c.Accept, r.Header.Get("Accept-Encoding"))
+
+				resp := httpmock.NewBytesResponse(http.StatusOK, c.Body)
+				if c.Encoding != "" {
+					resp.Header.Set("Content-Encoding", c.Encoding)
+				}
+
+				return resp, nil
+			})
+
+			res, err := client.Store(context.Background(), "s1")
+			if c.Err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, Store{ID: "s1"}, res)
+		})
+	}
+}
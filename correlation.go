@@ -0,0 +1,74 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+)
+
+// defaultCorrelationHeader is the header that carries the correlation
+// ID of a request unless a different one is configured.
+const defaultCorrelationHeader = "X-Request-ID"
+
+// correlationKey is the context key of the correlation ID.
+type correlationKey struct{}
+
+// ContextWithCorrelationID returns a copy of the context that carries
+// the correlation ID. Requests sent with the context have the ID set in
+// their correlation header and it is included in the signature debug
+// output and trace spans, so that calls to the server can be tied to
+// the upstream request that caused them.
+func ContextWithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationKey{}, id)
+}
+
+// CorrelationIDFromContext returns the correlation ID that was set with
+// ContextWithCorrelationID or an empty string if it was not set.
+func CorrelationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationKey{}).(string)
+	return id
+}
+
+// WithCorrelationHeader sets the header that carries the correlation ID
+// of every request (X-Request-ID by default). An empty name disables
+// the header, but the ID is still included in the debug and trace
+// output.
+func WithCorrelationHeader(name string) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.correlationHeader = name
+	}
+}
+
+// WithCorrelationIDFunc sets a function that extracts the correlation ID
+// from the request's context when it was not set with
+// ContextWithCorrelationID, e.g. to reuse the request ID stored by an
+// HTTP middleware under its own context key.
+func WithCorrelationIDFunc(fn func(ctx context.Context) string) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.correlationFunc = fn
+	}
+}
+
+// correlationID returns the correlation ID carried by the context.
+func (c *Client) correlationID(ctx context.Context) string {
+	if id := CorrelationIDFromContext(ctx); id != "" {
+		return id
+	}
+
+	if c.correlationFunc != nil {
+		return c.correlationFunc(ctx)
+	}
+
+	return ""
+}
+
+// setCorrelationHeader sets the correlation header of the request if
+// its context carries a correlation ID.
+func (c *Client) setCorrelationHeader(req *http.Request) {
+	if c.correlationHeader == "" {
+		return
+	}
+
+	if id := c.correlationID(req.Context()); id != "" {
+		req.Header.Set(c.correlationHeader, id)
+	}
+}
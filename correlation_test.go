@@ -0,0 +1,136 @@
+package btcpay
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type middlewareKey struct{}
+
+func Test_CorrelationIDFromContext(t *testing.T) {
+	assert.Equal(t, "", CorrelationIDFromContext(context.Background()))
+	assert.Equal(t, "r1", CorrelationIDFromContext(ContextWithCorrelationID(context.Background(), "r1")))
+}
+
+func Test_WithCorrelationHeader(t *testing.T) {
+	c := &Client{}
+	WithCorrelationHeader("X-Correlation-ID")(c)
+	assert.Equal(t, "X-Correlation-ID", c.correlationHeader)
+}
+
+func Test_WithCorrelationIDFunc(t *testing.T) {
+	c := &Client{}
+	WithCorrelationIDFunc(func(context.Context) string { return "r1" })(c)
+	require.NotNil(t, c.correlationFunc)
+	assert.Equal(t, "r1", c.correlationID(context.Background()))
+}
+
+func Test_Client_setCorrelationHeader(t *testing.T) {
+	fromMiddleware := WithCorrelationIDFunc(func(ctx context.Context) string {
+		id, _ := ctx.Value(middlewareKey{}).(string)
+		return id
+	})
+
+	cc := map[string]struct {
+		Setters []setter
+		Ctx     context.Context
+		Opts    []RequestOption
+		Header  string
+		Exp     string
+	}{
+		"No correlation ID": {
+			Ctx:    context.Background(),
+			Header: "X-Request-ID",
+		},
+		"Default header": {
+			Ctx:    ContextWithCorrelationID(context.Background(), "r1"),
+			Header: "X-Request-ID",
+			Exp:    "r1",
+		},
+		"Custom header": {
+			Setters: []setter{WithCorrelationHeader("X-Correlation-ID")},
+			Ctx:     ContextWithCorrelationID(context.Background(), "r1"),
+			Header:  "X-Correlation-ID",
+			Exp:     "r1",
+		},
+		"Disabled header": {
+			Setters: []setter{WithCorrelationHeader("")},
+			Ctx:     ContextWithCorrelationID(context.Background(), "r1"),
+			Header:  "X-Request-ID",
+		},
+		"ID extracted by the custom func": {
+			Setters: []setter{fromMiddleware},
+			Ctx:     context.WithValue(context.Background(), middlewareKey{}, "r2"),
+			Header:  "X-Request-ID",
+			Exp:     "r2",
+		},
+		"ID set in the context takes precedence": {
+			Setters: []setter{fromMiddleware},
+			Ctx:     ContextWithCorrelationID(context.WithValue(context.Background(), middlewareKey{}, "r2"), "r1"),
+			Header:  "X-Request-ID",
+			Exp:     "r1",
+		},
+		"ID overridden by the request option": {
+			Ctx:    ContextWithCorrelationID(context.Background(), "r1"),
+			Opts:   []RequestOption{WithHeader("X-Request-ID", "r3")},
+			Header: "X-Request-ID",
+			Exp:    "r3",
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			var legacy, greenfield string
+
+			mt := httpmock.NewMockTransport()
+			mt.RegisterResponder(http.MethodGet, "http://test.com/invoices/1", func(r *http.Request) (*http.Response, error) {
+				legacy = r.Header.Get(c.Header)
+				return httpmock.NewStringResponse(http.StatusOK, `{"data":{"id":"1"}}`), nil
+			})
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/health", func(r *http.Request) (*http.Response, error) {
+				greenfield = r.Header.Get(c.Header)
+				return httpmock.NewStringResponse(http.StatusOK, `{"synchronized":true}`), nil
+			})
+
+			client, err := NewClient("http://test.com", "token", append([]setter{WithHTTPClient(&http.Client{Transport: mt})}, c.Setters...)...)
+			require.NoError(t, err)
+
+			_, err = client.Invoice(c.Ctx, "1", c.Opts...)
+			require.NoError(t, err)
+
+			_, err = client.Health(c.Ctx, c.Opts...)
+			require.NoError(t, err)
+
+			assert.Equal(t, c.Exp, legacy)
+			assert.Equal(t, c.Exp, greenfield)
+		})
+	}
+}
+
+func Test_Client_correlationID_signatureDebug(t *testing.T) {
+	var sig bytes.Buffer
+
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder(http.MethodGet, "http://test.com/invoices/1", httpmock.NewStringResponder(http.StatusOK, `{"data":{"id":"1"}}`))
+
+	client, err := NewClient("http://test.com", "token", WithHTTPClient(&http.Client{Transport: mt}), WithSignatureDebug(&sig))
+	require.NoError(t, err)
+
+	_, err = client.Invoice(context.Background(), "1")
+	require.NoError(t, err)
+	assert.NotContains(t, sig.String(), "correlation ID")
+
+	_, err = client.Invoice(ContextWithCorrelationID(context.Background(), "r1"), "1")
+	require.NoError(t, err)
+	assert.Contains(t, sig.String(), "GET /invoices/1\ncorrelation ID: r1\nsigned: ")
+}
@@ -1,40 +1,69 @@
 package btcpay
 
 import (
-	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/asn1"
 	"encoding/hex"
 	"encoding/pem"
 	"errors"
 	"hash"
+	"math/big"
 
-	"github.com/btcsuite/btcd/btcec"
-	"github.com/btcsuite/btcutil/base58"
-	"golang.org/x/crypto/ripemd160"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/swithek/btcpay-go/internal/ripemd160"
+	"github.com/tyler-smith/go-bip39"
 )
 
-// sign uses the private key in the PEM string to sign the provided value.
-func sign(pm, v string) (string, error) {
-	pk, err := privKey(pm)
-	if err != nil {
-		return "", err
-	}
+// Signer signs legacy API requests on behalf of the client identity.
+// It allows the identity key to be kept outside of the process memory,
+// e.g. in an HSM or a cloud KMS.
+type Signer interface {
+	// PublicKey returns the hex encoded compressed secp256k1 public key.
+	PublicKey() string
 
-	hash := sha256.New()
-	if _, err = hash.Write([]byte(v)); err != nil {
-		return "", err
-	}
+	// Sign signs the SHA-256 hash of the message and returns a DER
+	// encoded ECDSA signature.
+	Sign(msg []byte) ([]byte, error)
+}
+
+// pemSigner is the default signer that uses a private key decoded from
+// a PEM string.
+type pemSigner struct {
+	priv *btcec.PrivateKey
+}
 
-	sig, err := pk.Sign(hash.Sum(nil))
+// NewPEMSigner creates a signer that uses the private key in the PEM
+// string.
+func NewPEMSigner(pm string) (Signer, error) {
+	priv, err := privKey(pm)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return hex.EncodeToString(sig.Serialize()), nil
+	return pemSigner{priv: priv}, nil
+}
+
+// PublicKey returns the hex encoded compressed public key.
+func (s pemSigner) PublicKey() string {
+	return hex.EncodeToString(s.priv.PubKey().SerializeCompressed())
 }
 
-// ecPrivateKey provides compatibility with the btcec package.
+// Sign signs the SHA-256 hash of the message. Signatures are
+// deterministic (RFC6979) and low-S normalized.
+func (s pemSigner) Sign(msg []byte) ([]byte, error) {
+	hash := sha256.Sum256(msg)
+
+	return ecdsa.Sign(s.priv, hash[:]).Serialize(), nil
+}
+
+// ecPrivateKey is the ASN.1 structure of a SEC1 private key.
 type ecPrivateKey struct {
 	Version       int
 	PrivateKey    []byte
@@ -44,19 +73,127 @@ type ecPrivateKey struct {
 
 // GeneratePEM generates a new PEM string.
 func GeneratePEM() (string, error) {
-	priv, err := btcec.NewPrivateKey(btcec.S256())
+	priv, err := btcec.NewPrivateKey()
+	if err != nil {
+		return "", err
+	}
+
+	return encodePEM(priv)
+}
+
+// GenerateEncryptedPEM generates a new PEM string encrypted with the
+// provided passphrase.
+func GenerateEncryptedPEM(passphrase string) (string, error) {
+	pm, err := GeneratePEM()
+	if err != nil {
+		return "", err
+	}
+
+	return EncryptPEM(pm, passphrase)
+}
+
+// EncryptPEM encrypts the private key in the PEM string with the
+// provided passphrase. The result is a standard encrypted EC PRIVATE
+// KEY block (AES-256-CBC) that is compatible with OpenSSL.
+func EncryptPEM(pm, passphrase string) (string, error) {
+	b := keyBlock(pm)
+	if b == nil {
+		return "", errors.New("private key not found")
+	}
+
+	//nolint:staticcheck // encrypted blocks are needed for OpenSSL compatibility
+	eb, err := x509.EncryptPEMBlock(rand.Reader, b.Type, b.Bytes, []byte(passphrase), x509.PEMCipherAES256)
+	if err != nil {
+		return "", err
+	}
+
+	return string(pem.EncodeToMemory(eb)), nil
+}
+
+// DecryptPEM decrypts the private key in the PEM string with the
+// provided passphrase.
+func DecryptPEM(pm, passphrase string) (string, error) {
+	b := keyBlock(pm)
+	if b == nil {
+		return "", errors.New("private key not found")
+	}
+
+	//nolint:staticcheck // encrypted blocks are needed for OpenSSL compatibility
+	if !x509.IsEncryptedPEMBlock(b) {
+		return "", errors.New("private key is not encrypted")
+	}
+
+	//nolint:staticcheck // encrypted blocks are needed for OpenSSL compatibility
+	der, err := x509.DecryptPEMBlock(b, []byte(passphrase))
 	if err != nil {
 		return "", err
 	}
 
-	ecd := priv.PubKey().ToECDSA()
-	oid := asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+	// padding checks don't detect all invalid passphrases
+	if _, err = parseECPrivateKey(&pem.Block{Type: b.Type, Bytes: der}); err != nil {
+		return "", x509.IncorrectPasswordError
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: b.Type, Bytes: der})), nil
+}
+
+// identityKeyPath is the BIP32 derivation path (m/0'/0) of the
+// identity key derived from a seed.
+var identityKeyPath = []uint32{hdkeychain.HardenedKeyStart, 0}
 
+// GeneratePEMFromSeed deterministically derives a PEM string from the
+// provided BIP32 seed, which must be between 16 and 64 bytes long.
+// The same seed always produces the same client identity (SIN).
+func GeneratePEMFromSeed(seed []byte) (string, error) {
+	key, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		return "", err
+	}
+
+	for _, i := range identityKeyPath {
+		key, err = key.Derive(i)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	priv, err := key.ECPrivKey()
+	if err != nil {
+		return "", err
+	}
+
+	return encodePEM(priv)
+}
+
+// GeneratePEMFromMnemonic deterministically derives a PEM string from
+// the provided BIP39 mnemonic and its optional passphrase.
+func GeneratePEMFromMnemonic(mnemonic, passphrase string) (string, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	return GeneratePEMFromSeed(seed)
+}
+
+// GenerateMnemonic generates a new 24 word BIP39 mnemonic that can be
+// used to derive a PEM string.
+func GenerateMnemonic() (string, error) {
+	ent, err := bip39.NewEntropy(256)
+	if err != nil {
+		return "", err
+	}
+
+	return bip39.NewMnemonic(ent)
+}
+
+// encodePEM encodes the private key as a PEM string.
+func encodePEM(priv *btcec.PrivateKey) (string, error) {
 	der, err := asn1.Marshal(ecPrivateKey{
 		Version:       1,
-		PrivateKey:    priv.D.Bytes(),
-		NamedCurveOID: oid,
-		PublicKey:     asn1.BitString{Bytes: elliptic.Marshal(btcec.S256(), ecd.X, ecd.Y)},
+		PrivateKey:    priv.Serialize(),
+		NamedCurveOID: secp256k1OID,
+		PublicKey:     asn1.BitString{Bytes: priv.PubKey().SerializeUncompressed()},
 	})
 	if err != nil {
 		return "", err
@@ -67,14 +204,9 @@ func GeneratePEM() (string, error) {
 	return string(v), nil
 }
 
-// generateSIN generates a SIN string from the provided PEM string.
-func generateSIN(pm string) (string, error) {
-	pk, err := privKey(pm)
-	if err != nil {
-		return "", err
-	}
-
-	pub := hex.EncodeToString(pk.PubKey().SerializeCompressed())
+// generateSIN generates a SIN string from the provided hex encoded
+// public key.
+func generateSIN(pub string) (string, error) {
 	hx, err := hexHash(sha256.New(), pub)
 	if err != nil {
 		return "", err
@@ -123,30 +255,150 @@ func hexHash(hash hash.Hash, v string) (string, error) {
 	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
-// privKey extracts a private key from the provided PEM string.
+// ASN.1 object identifiers of the secp256k1 curve and of the EC public
+// key algorithm used by PKCS#8.
+var (
+	secp256k1OID   = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+	ecPublicKeyOID = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+)
+
+// pkcs8 is the ASN.1 structure of an unencrypted PKCS#8 private key.
+type pkcs8 struct {
+	Version    int
+	Algo       pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+// PublicKeyFromPEM returns the hex encoded compressed public key of the
+// private key in the PEM string.
+func PublicKeyFromPEM(pm string) (string, error) {
+	priv, err := privKey(pm)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(priv.PubKey().SerializeCompressed()), nil
+}
+
+// SINFromPEM returns the client identity (SIN) derived from the private
+// key in the PEM string. It is the same SIN that the client registers
+// with the server during pairing.
+func SINFromPEM(pm string) (string, error) {
+	pub, err := PublicKeyFromPEM(pm)
+	if err != nil {
+		return "", err
+	}
+
+	return generateSIN(pub)
+}
+
+// ValidatePEM checks whether the PEM string holds an unencrypted
+// secp256k1 private key that can be used to sign requests.
+func ValidatePEM(pm string) error {
+	_, err := privKey(pm)
+	return err
+}
+
+// privKey extracts a private key from the provided PEM string. Both
+// SEC1 (EC PRIVATE KEY) and unencrypted PKCS#8 (PRIVATE KEY) blocks are
+// accepted, e.g. keys generated by OpenSSL or other BitPay libraries.
 func privKey(pm string) (*btcec.PrivateKey, error) {
-	b, _ := pem.Decode([]byte(pm))
+	b := keyBlock(pm)
 	if b == nil {
 		return nil, errors.New("private key not found")
 	}
 
-	var ecpk ecPrivateKey
+	//nolint:staticcheck // encrypted blocks are needed for OpenSSL compatibility
+	if x509.IsEncryptedPEMBlock(b) {
+		return nil, errors.New("private key is encrypted")
+	}
 
-	if _, err := asn1.Unmarshal(b.Bytes, &ecpk); err != nil {
+	ecpk, err := parseECPrivateKey(b)
+	if err != nil {
 		return nil, err
 	}
 
-	priv, _ := btcec.PrivKeyFromBytes(btcec.S256(), ecpk.PrivateKey)
+	if len(ecpk.NamedCurveOID) > 0 && !ecpk.NamedCurveOID.Equal(secp256k1OID) {
+		return nil, errors.New("private key is not a secp256k1 key")
+	}
+
+	d := new(big.Int).SetBytes(ecpk.PrivateKey)
+	if d.Sign() == 0 || d.Cmp(btcec.S256().N) >= 0 {
+		return nil, errors.New("private key is out of range")
+	}
+
+	priv, _ := btcec.PrivKeyFromBytes(ecpk.PrivateKey)
+
+	// the embedded public key is optional, but must match if present
+	if len(ecpk.PublicKey.Bytes) > 0 {
+		var pub *btcec.PublicKey
+
+		pub, err = btcec.ParsePubKey(ecpk.PublicKey.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		if !pub.IsEqual(priv.PubKey()) {
+			return nil, errors.New("public key does not match the private key")
+		}
+	}
 
 	return priv, nil
 }
 
-// pubKey extracts a compressed public key from the provided PEM string.
-func pubKey(pm string) (string, error) {
-	pk, err := privKey(pm)
-	if err != nil {
-		return "", err
+// keyBlock returns the first PEM block of the string that is not an EC
+// PARAMETERS block, which OpenSSL places before the key itself.
+func keyBlock(pm string) *pem.Block {
+	rest := []byte(pm)
+
+	for {
+		var b *pem.Block
+
+		b, rest = pem.Decode(rest)
+		if b == nil || b.Type != "EC PARAMETERS" {
+			return b
+		}
+	}
+}
+
+// parseECPrivateKey decodes a SEC1 or PKCS#8 private key block. The x509
+// package does not support the secp256k1 curve, so the structures are
+// decoded directly.
+func parseECPrivateKey(b *pem.Block) (ecPrivateKey, error) {
+	var ecpk ecPrivateKey
+
+	if b.Type != "PRIVATE KEY" {
+		_, err := asn1.Unmarshal(b.Bytes, &ecpk)
+		return ecpk, err
+	}
+
+	var p8 pkcs8
+
+	if _, err := asn1.Unmarshal(b.Bytes, &p8); err != nil {
+		return ecPrivateKey{}, err
+	}
+
+	if !p8.Algo.Algorithm.Equal(ecPublicKeyOID) {
+		return ecPrivateKey{}, errors.New("private key is not an EC key")
+	}
+
+	var curve asn1.ObjectIdentifier
+
+	if _, err := asn1.Unmarshal(p8.Algo.Parameters.FullBytes, &curve); err != nil {
+		return ecPrivateKey{}, err
+	}
+
+	if _, err := asn1.Unmarshal(p8.PrivateKey, &ecpk); err != nil {
+		return ecPrivateKey{}, err
+	}
+
+	// the curve is usually specified only by the algorithm parameters
+	switch {
+	case len(ecpk.NamedCurveOID) == 0:
+		ecpk.NamedCurveOID = curve
+	case !ecpk.NamedCurveOID.Equal(curve):
+		return ecPrivateKey{}, errors.New("private key curves do not match")
 	}
 
-	return hex.EncodeToString(pk.PubKey().SerializeCompressed()), nil
+	return ecpk, nil
 }
@@ -0,0 +1,267 @@
+// Package currency provides metadata of fiat and cryptocurrencies
+// accepted by BTCPay servers.
+package currency
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// codeRe matches well-formed currency codes.
+var codeRe = regexp.MustCompile(`^[A-Za-z0-9]{3,5}$`)
+
+// Currency holds metadata of a single currency.
+type Currency struct {
+	// Code is the ISO 4217 code of a fiat currency or the ticker of a
+	// cryptocurrency.
+	Code string
+
+	Name string
+
+	// Decimals is the number of digits after the decimal point of the
+	// currency's smallest unit.
+	Decimals int32
+
+	Crypto bool
+}
+
+// Fiat holds all active ISO 4217 currencies.
+var Fiat = []Currency{
+	{Code: "AED", Name: "United Arab Emirates Dirham", Decimals: 2},
+	{Code: "AFN", Name: "Afghan Afghani", Decimals: 2},
+	{Code: "ALL", Name: "Albanian Lek", Decimals: 2},
+	{Code: "AMD", Name: "Armenian Dram", Decimals: 2},
+	{Code: "ANG", Name: "Netherlands Antillean Guilder", Decimals: 2},
+	{Code: "AOA", Name: "Angolan Kwanza", Decimals: 2},
+	{Code: "ARS", Name: "Argentine Peso", Decimals: 2},
+	{Code: "AUD", Name: "Australian Dollar", Decimals: 2},
+	{Code: "AWG", Name: "Aruban Florin", Decimals: 2},
+	{Code: "AZN", Name: "Azerbaijani Manat", Decimals: 2},
+	{Code: "BAM", Name: "Bosnia-Herzegovina Convertible Mark", Decimals: 2},
+	{Code: "BBD", Name: "Barbadian Dollar", Decimals: 2},
+	{Code: "BDT", Name: "Bangladeshi Taka", Decimals: 2},
+	{Code: "BGN", Name: "Bulgarian Lev", Decimals: 2},
+	{Code: "BHD", Name: "Bahraini Dinar", Decimals: 3},
+	{Code: "BIF", Name: "Burundian Franc", Decimals: 0},
+	{Code: "BMD", Name: "Bermudan Dollar", Decimals: 2},
+	{Code: "BND", Name: "Brunei Dollar", Decimals: 2},
+	{Code: "BOB", Name: "Bolivian Boliviano", Decimals: 2},
+	{Code: "BRL", Name: "Brazilian Real", Decimals: 2},
+	{Code: "BSD", Name: "Bahamian Dollar", Decimals: 2},
+	{Code: "BTN", Name: "Bhutanese Ngultrum", Decimals: 2},
+	{Code: "BWP", Name: "Botswanan Pula", Decimals: 2},
+	{Code: "BYN", Name: "Belarusian Ruble", Decimals: 2},
+	{Code: "BZD", Name: "Belize Dollar", Decimals: 2},
+	{Code: "CAD", Name: "Canadian Dollar", Decimals: 2},
+	{Code: "CDF", Name: "Congolese Franc", Decimals: 2},
+	{Code: "CHF", Name: "Swiss Franc", Decimals: 2},
+	{Code: "CLF", Name: "Chilean Unit of Account (UF)", Decimals: 4},
+	{Code: "CLP", Name: "Chilean Peso", Decimals: 0},
+	{Code: "CNY", Name: "Chinese Yuan", Decimals: 2},
+	{Code: "COP", Name: "Colombian Peso", Decimals: 2},
+	{Code: "CRC", Name: "Costa Rican Colón", Decimals: 2},
+	{Code: "CUP", Name: "Cuban Peso", Decimals: 2},
+	{Code: "CVE", Name: "Cape Verdean Escudo", Decimals: 2},
+	{Code: "CZK", Name: "Czech Koruna", Decimals: 2},
+	{Code: "DJF", Name: "Djiboutian Franc", Decimals: 0},
+	{Code: "DKK", Name: "Danish Krone", Decimals: 2},
+	{Code: "DOP", Name: "Dominican Peso", Decimals: 2},
+	{Code: "DZD", Name: "Algerian Dinar", Decimals: 2},
+	{Code: "EGP", Name: "Egyptian Pound", Decimals: 2},
+	{Code: "ERN", Name: "Eritrean Nakfa", Decimals: 2},
+	{Code: "ETB", Name: "Ethiopian Birr", Decimals: 2},
+	{Code: "EUR", Name: "Euro", Decimals: 2},
+	{Code: "FJD", Name: "Fijian Dollar", Decimals: 2},
+	{Code: "FKP", Name: "Falkland Islands Pound", Decimals: 2},
+	{Code: "GBP", Name: "British Pound", Decimals: 2},
+	{Code: "GEL", Name: "Georgian Lari", Decimals: 2},
+	{Code: "GHS", Name: "Ghanaian Cedi", Decimals: 2},
+	{Code: "GIP", Name: "Gibraltar Pound", Decimals: 2},
+	{Code: "GMD", Name: "Gambian Dalasi", Decimals: 2},
+	{Code: "GNF", Name: "Guinean Franc", Decimals: 0},
+	{Code: "GTQ", Name: "Guatemalan Quetzal", Decimals: 2},
+	{Code: "GYD", Name: "Guyanaese Dollar", Decimals: 2},
+	{Code: "HKD", Name: "Hong Kong Dollar", Decimals: 2},
+	{Code: "HNL", Name: "Honduran Lempira", Decimals: 2},
+	{Code: "HTG", Name: "Haitian Gourde", Decimals: 2},
+	{Code: "HUF", Name: "Hungarian Forint", Decimals: 2},
+	{Code: "IDR", Name: "Indonesian Rupiah", Decimals: 2},
+	{Code: "ILS", Name: "Israeli New Shekel", Decimals: 2},
+	{Code: "INR", Name: "Indian Rupee", Decimals: 2},
+	{Code: "IQD", Name: "Iraqi Dinar", Decimals: 3},
+	{Code: "IRR", Name: "Iranian Rial", Decimals: 2},
+	{Code: "ISK", Name: "Icelandic Króna", Decimals: 0},
+	{Code: "JMD", Name: "Jamaican Dollar", Decimals: 2},
+	{Code: "JOD", Name: "Jordanian Dinar", Decimals: 3},
+	{Code: "JPY", Name: "Japanese Yen", Decimals: 0},
+	{Code: "KES", Name: "Kenyan Shilling", Decimals: 2},
+	{Code: "KGS", Name: "Kyrgystani Som", Decimals: 2},
+	{Code: "KHR", Name: "Cambodian Riel", Decimals: 2},
+	{Code: "KMF", Name: "Comorian Franc", Decimals: 0},
+	{Code: "KPW", Name: "North Korean Won", Decimals: 2},
+	{Code: "KRW", Name: "South Korean Won", Decimals: 0},
+	{Code: "KWD", Name: "Kuwaiti Dinar", Decimals: 3},
+	{Code: "KYD", Name: "Cayman Islands Dollar", Decimals: 2},
+	{Code: "KZT", Name: "Kazakhstani Tenge", Decimals: 2},
+	{Code: "LAK", Name: "Laotian Kip", Decimals: 2},
+	{Code: "LBP", Name: "Lebanese Pound", Decimals: 2},
+	{Code: "LKR", Name: "Sri Lankan Rupee", Decimals: 2},
+	{Code: "LRD", Name: "Liberian Dollar", Decimals: 2},
+	{Code: "LSL", Name: "Lesotho Loti", Decimals: 2},
+	{Code: "LYD", Name: "Libyan Dinar", Decimals: 3},
+	{Code: "MAD", Name: "Moroccan Dirham", Decimals: 2},
+	{Code: "MDL", Name: "Moldovan Leu", Decimals: 2},
+	{Code: "MGA", Name: "Malagasy Ariary", Decimals: 2},
+	{Code: "MKD", Name: "Macedonian Denar", Decimals: 2},
+	{Code: "MMK", Name: "Myanmar Kyat", Decimals: 2},
+	{Code: "MNT", Name: "Mongolian Tugrik", Decimals: 2},
+	{Code: "MOP", Name: "Macanese Pataca", Decimals: 2},
+	{Code: "MRU", Name: "Mauritanian Ouguiya", Decimals: 2},
+	{Code: "MUR", Name: "Mauritian Rupee", Decimals: 2},
+	{Code: "MVR", Name: "Maldivian Rufiyaa", Decimals: 2},
+	{Code: "MWK", Name: "Malawian Kwacha", Decimals: 2},
+	{Code: "MXN", Name: "Mexican Peso", Decimals: 2},
+	{Code: "MYR", Name: "Malaysian Ringgit", Decimals: 2},
+	{Code: "MZN", Name: "Mozambican Metical", Decimals: 2},
+	{Code: "NAD", Name: "Namibian Dollar", Decimals: 2},
+	{Code: "NGN", Name: "Nigerian Naira", Decimals: 2},
+	{Code: "NIO", Name: "Nicaraguan Córdoba", Decimals: 2},
+	{Code: "NOK", Name: "Norwegian Krone", Decimals: 2},
+	{Code: "NPR", Name: "Nepalese Rupee", Decimals: 2},
+	{Code: "NZD", Name: "New Zealand Dollar", Decimals: 2},
+	{Code: "OMR", Name: "Omani Rial", Decimals: 3},
+	{Code: "PAB", Name: "Panamanian Balboa", Decimals: 2},
+	{Code: "PEN", Name: "Peruvian Sol", Decimals: 2},
+	{Code: "PGK", Name: "Papua New Guinean Kina", Decimals: 2},
+	{Code: "PHP", Name: "Philippine Peso", Decimals: 2},
+	{Code: "PKR", Name: "Pakistani Rupee", Decimals: 2},
+	{Code: "PLN", Name: "Polish Zloty", Decimals: 2},
+	{Code: "PYG", Name: "Paraguayan Guarani", Decimals: 0},
+	{Code: "QAR", Name: "Qatari Riyal", Decimals: 2},
+	{Code: "RON", Name: "Romanian Leu", Decimals: 2},
+	{Code: "RSD", Name: "Serbian Dinar", Decimals: 2},
+	{Code: "RUB", Name: "Russian Ruble", Decimals: 2},
+	{Code: "RWF", Name: "Rwandan Franc", Decimals: 0},
+	{Code: "SAR", Name: "Saudi Riyal", Decimals: 2},
+	{Code: "SBD", Name: "Solomon Islands Dollar", Decimals: 2},
+	{Code: "SCR", Name: "Seychellois Rupee", Decimals: 2},
+	{Code: "SDG", Name: "Sudanese Pound", Decimals: 2},
+	{Code: "SEK", Name: "Swedish Krona", Decimals: 2},
+	{Code: "SGD", Name: "Singapore Dollar", Decimals: 2},
+	{Code: "SHP", Name: "St. Helena Pound", Decimals: 2},
+	{Code: "SLE", Name: "Sierra Leonean Leone", Decimals: 2},
+	{Code: "SOS", Name: "Somali Shilling", Decimals: 2},
+	{Code: "SRD", Name: "Surinamese Dollar", Decimals: 2},
+	{Code: "SSP", Name: "South Sudanese Pound", Decimals: 2},
+	{Code: "STN", Name: "São Tomé & Príncipe Dobra", Decimals: 2},
+	{Code: "SVC", Name: "Salvadoran Colón", Decimals: 2},
+	{Code: "SYP", Name: "Syrian Pound", Decimals: 2},
+	{Code: "SZL", Name: "Swazi Lilangeni", Decimals: 2},
+	{Code: "THB", Name: "Thai Baht", Decimals: 2},
+	{Code: "TJS", Name: "Tajikistani Somoni", Decimals: 2},
+	{Code: "TMT", Name: "Turkmenistani Manat", Decimals: 2},
+	{Code: "TND", Name: "Tunisian Dinar", Decimals: 3},
+	{Code: "TOP", Name: "Tongan Paʻanga", Decimals: 2},
+	{Code: "TRY", Name: "Turkish Lira", Decimals: 2},
+	{Code: "TTD", Name: "Trinidad & Tobago Dollar", Decimals: 2},
+	{Code: "TWD", Name: "New Taiwan Dollar", Decimals: 2},
+	{Code: "TZS", Name: "Tanzanian Shilling", Decimals: 2},
+	{Code: "UAH", Name: "Ukrainian Hryvnia", Decimals: 2},
+	{Code: "UGX", Name: "Ugandan Shilling", Decimals: 0},
+	{Code: "USD", Name: "US Dollar", Decimals: 2},
+	{Code: "UYU", Name: "Uruguayan Peso", Decimals: 2},
+	{Code: "UZS", Name: "Uzbekistani Som", Decimals: 2},
+	{Code: "VES", Name: "Venezuelan Bolívar", Decimals: 2},
+	{Code: "VND", Name: "Vietnamese Dong", Decimals: 0},
+	{Code: "VUV", Name: "Vanuatu Vatu", Decimals: 0},
+	{Code: "WST", Name: "Samoan Tala", Decimals: 2},
+	{Code: "XAF", Name: "Central African CFA Franc", Decimals: 0},
+	{Code: "XCD", Name: "East Caribbean Dollar", Decimals: 2},
+	{Code: "XOF", Name: "West African CFA Franc", Decimals: 0},
+	{Code: "XPF", Name: "CFP Franc", Decimals: 0},
+	{Code: "YER", Name: "Yemeni Rial", Decimals: 2},
+	{Code: "ZAR", Name: "South African Rand", Decimals: 2},
+	{Code: "ZMW", Name: "Zambian Kwacha", Decimals: 2},
+	{Code: "ZWL", Name: "Zimbabwean Dollar", Decimals: 2},
+}
+
+// Crypto holds cryptocurrencies supported by BTCPay servers and its
+// plugins.
+var Crypto = []Currency{
+	{Code: "BTC", Name: "Bitcoin", Decimals: 8, Crypto: true},
+	{Code: "SATS", Name: "Satoshi", Decimals: 0, Crypto: true},
+	{Code: "LBTC", Name: "Liquid Bitcoin", Decimals: 8, Crypto: true},
+	{Code: "LTC", Name: "Litecoin", Decimals: 8, Crypto: true},
+	{Code: "DASH", Name: "Dash", Decimals: 8, Crypto: true},
+	{Code: "DOGE", Name: "Dogecoin", Decimals: 8, Crypto: true},
+	{Code: "GRS", Name: "Groestlcoin", Decimals: 8, Crypto: true},
+	{Code: "MONA", Name: "Monacoin", Decimals: 8, Crypto: true},
+	{Code: "VIA", Name: "Viacoin", Decimals: 8, Crypto: true},
+	{Code: "BTG", Name: "Bitcoin Gold", Decimals: 8, Crypto: true},
+	{Code: "XMR", Name: "Monero", Decimals: 12, Crypto: true},
+	{Code: "ZEC", Name: "Zcash", Decimals: 8, Crypto: true},
+	{Code: "ETH", Name: "Ethereum", Decimals: 18, Crypto: true},
+	{Code: "USDT", Name: "Tether", Decimals: 6, Crypto: true},
+}
+
+// currencies holds all known currencies by their code.
+var currencies = func() map[string]Currency {
+	m := make(map[string]Currency, len(Fiat)+len(Crypto))
+
+	for _, cc := range [][]Currency{Fiat, Crypto} {
+		for _, c := range cc {
+			m[c.Code] = c
+		}
+	}
+
+	return m
+}()
+
+// Lookup returns metadata of the currency with the provided code.
+// The code is case insensitive.
+func Lookup(code string) (Currency, bool) {
+	c, ok := currencies[strings.ToUpper(code)]
+	return c, ok
+}
+
+// ValidateCurrency checks whether the code is a well-formed currency
+// code. The code is case insensitive, as it is for the server. Only the
+// format of codes that are not in the Fiat and Crypto lists is checked,
+// since the server may support more currencies.
+func ValidateCurrency(code string) error {
+	if _, ok := Lookup(code); ok {
+		return nil
+	}
+
+	if !codeRe.MatchString(code) {
+		return errors.New("invalid code")
+	}
+
+	return nil
+}
+
+// Round rounds the amount to the precision of the currency. Amounts of
+// unknown currencies are returned unchanged.
+func Round(amount decimal.Decimal, code string) decimal.Decimal {
+	c, ok := Lookup(code)
+	if !ok {
+		return amount
+	}
+
+	return amount.Round(c.Decimals)
+}
+
+// Format renders the amount with the precision of the currency followed
+// by its code, e.g. "10.50 USD". Amounts of unknown currencies are
+// rendered as they are.
+func Format(amount decimal.Decimal, code string) string {
+	c, ok := Lookup(code)
+	if !ok {
+		return amount.String() + " " + code
+	}
+
+	return amount.StringFixed(c.Decimals) + " " + c.Code
+}
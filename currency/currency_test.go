@@ -0,0 +1,100 @@
+package currency
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_tables(t *testing.T) {
+	seen := make(map[string]bool)
+
+	for _, cc := range [][]Currency{Fiat, Crypto} {
+		for _, c := range cc {
+			assert.Regexp(t, codeRe, c.Code)
+			assert.NotEmpty(t, c.Name, c.Code)
+			assert.False(t, seen[c.Code], c.Code)
+
+			seen[c.Code] = true
+		}
+	}
+}
+
+func Test_Lookup(t *testing.T) {
+	c, ok := Lookup("jpy")
+	assert.True(t, ok)
+	assert.Equal(t, Currency{Code: "JPY", Name: "Japanese Yen", Decimals: 0}, c)
+
+	c, ok = Lookup("BTC")
+	assert.True(t, ok)
+	assert.Equal(t, Currency{Code: "BTC", Name: "Bitcoin", Decimals: 8, Crypto: true}, c)
+
+	c, ok = Lookup("ABCD")
+	assert.False(t, ok)
+	assert.Zero(t, c)
+}
+
+func Test_ValidateCurrency(t *testing.T) {
+	cc := map[string]struct {
+		Code   string
+		ErrMsg string
+	}{
+		"Empty code": {
+			ErrMsg: "invalid code",
+		},
+		"Too short code": {
+			Code:   "US",
+			ErrMsg: "invalid code",
+		},
+		"Code with symbols": {
+			Code:   "US$",
+			ErrMsg: "invalid code",
+		},
+		"Lowercase code": {
+			Code: "usd",
+		},
+		"Unknown code": {
+			Code: "ABC1",
+		},
+		"Fiat code": {
+			Code: "EUR",
+		},
+		"Crypto code": {
+			Code: "SATS",
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateCurrency(c.Code)
+			if c.ErrMsg != "" {
+				assert.EqualError(t, err, c.ErrMsg)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_Round(t *testing.T) {
+	amount := decimal.RequireFromString("10.123456789")
+
+	assert.Equal(t, "10.12", Round(amount, "USD").String())
+	assert.Equal(t, "10", Round(amount, "JPY").String())
+	assert.Equal(t, "10.12345679", Round(amount, "BTC").String())
+	assert.Equal(t, amount, Round(amount, "ABCD"))
+}
+
+func Test_Format(t *testing.T) {
+	assert.Equal(t, "10.50 USD", Format(decimal.RequireFromString("10.5"), "USD"))
+	assert.Equal(t, "1.500 KWD", Format(decimal.RequireFromString("1.5"), "kwd"))
+	assert.Equal(t, "1000 JPY", Format(decimal.RequireFromString("999.6"), "JPY"))
+	assert.Equal(t, "0.00010000 BTC", Format(decimal.RequireFromString("0.0001"), "BTC"))
+	assert.Equal(t, "1.5 ABCD", Format(decimal.RequireFromString("1.5"), "ABCD"))
+}
@@ -0,0 +1,331 @@
+package btcpay
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/shopspring/decimal"
+	"github.com/swithek/btcpay-go/currency"
+)
+
+// DecimalRounding specifies how amounts in request payloads are rounded.
+type DecimalRounding int
+
+// Available decimal rounding modes.
+const (
+	// DecimalExact leaves amounts as they are.
+	DecimalExact DecimalRounding = iota
+
+	// DecimalFixed rounds amounts to a fixed number of decimal places.
+	DecimalFixed
+
+	// DecimalCurrency rounds amounts to the precision of the payload's
+	// currency. Amounts of payloads without a known currency are left
+	// as they are.
+	DecimalCurrency
+)
+
+// DecimalFormat holds settings of decimal.Decimal serialization in
+// request payloads.
+type DecimalFormat struct {
+	// AsNumber encodes amounts as JSON numbers instead of strings.
+	AsNumber bool
+
+	Rounding DecimalRounding
+
+	// Places is the number of decimal places used by DecimalFixed.
+	Places int32
+}
+
+// WithDecimalFormat sets how decimal.Decimal fields of request payloads
+// are serialized. Rounded amounts are rendered in fixed-point notation
+// with all of their decimal places. Values of interface fields, such as
+// invoice metadata, are not affected.
+func WithDecimalFormat(f DecimalFormat) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.decimalFormat = &f
+	}
+}
+
+// decimalType is the reflected type of decimal values.
+var decimalType = reflect.TypeOf(decimal.Decimal{})
+
+// decimalPathsCache holds JSON paths of decimal fields by payload type.
+var decimalPathsCache sync.Map
+
+// formatDecimals re-encodes the decimal fields of the encoded payload
+// according to the format. The payload is streamed, so the order and
+// precision of other fields are preserved.
+func formatDecimals(d []byte, payload interface{}, f DecimalFormat) ([]byte, error) {
+	t := reflect.TypeOf(payload)
+
+	paths, ok := decimalPathsCache.Load(t)
+	if !ok {
+		pp := make(map[string]bool)
+		collectDecimalPaths(t, "", pp, make(map[reflect.Type]bool))
+
+		paths, _ = decimalPathsCache.LoadOrStore(t, pp)
+	}
+
+	if len(paths.(map[string]bool)) == 0 {
+		return d, nil
+	}
+
+	places := int32(-1)
+
+	switch f.Rounding {
+	case DecimalFixed:
+		places = f.Places
+	case DecimalCurrency:
+		var p struct {
+			Currency string `json:"currency"`
+		}
+
+		// payloads that aren't objects have no currency
+		_ = json.Unmarshal(d, &p)
+
+		if c, ok := currency.Lookup(p.Currency); ok {
+			places = c.Decimals
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(d))
+	dec.UseNumber()
+
+	var buf bytes.Buffer
+
+	w := decimalWriter{
+		dec:    dec,
+		buf:    &buf,
+		paths:  paths.(map[string]bool),
+		number: f.AsNumber,
+		places: places,
+	}
+
+	if err := w.value(""); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// collectDecimalPaths collects JSON paths of all decimal fields of the
+// type. Object keys are separated by dots and array elements are
+// denoted by [].
+func collectDecimalPaths(t reflect.Type, path string, paths map[string]bool, seen map[reflect.Type]bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == decimalType {
+		paths[path] = true
+		return
+	}
+
+	if seen[t] {
+		return
+	}
+
+	seen[t] = true
+	defer delete(seen, t)
+
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" && !f.Anonymous {
+				continue
+			}
+
+			tag := f.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+
+			name := strings.Split(tag, ",")[0]
+
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+
+			if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+				collectDecimalPaths(ft, path, paths, seen)
+				continue
+			}
+
+			if name == "" {
+				name = f.Name
+			}
+
+			collectDecimalPaths(f.Type, path+"."+name, paths, seen)
+		}
+	case reflect.Slice, reflect.Array:
+		collectDecimalPaths(t.Elem(), path+"[]", paths, seen)
+	case reflect.Map:
+		collectDecimalPaths(t.Elem(), path+".*", paths, seen)
+	}
+}
+
+// decimalWriter copies JSON tokens while re-encoding decimal values.
+type decimalWriter struct {
+	dec    *json.Decoder
+	buf    *bytes.Buffer
+	paths  map[string]bool
+	number bool
+	places int32
+}
+
+// value copies a single JSON value found at the path.
+func (w decimalWriter) value(path string) error {
+	tok, err := w.dec.Token()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return io.ErrUnexpectedEOF
+		}
+
+		return err
+	}
+
+	switch tok := tok.(type) {
+	case json.Delim:
+		switch tok {
+		case '{':
+			return w.object(path)
+		case '[':
+			return w.array(path)
+		default:
+			return fmt.Errorf("unexpected delimiter %q", tok)
+		}
+	case json.Number:
+		if w.paths[path] {
+			return w.decimal(string(tok))
+		}
+
+		w.buf.WriteString(string(tok))
+
+		return nil
+	case string:
+		if w.paths[path] {
+			return w.decimal(tok)
+		}
+	}
+
+	b, err := json.Marshal(tok)
+	if err != nil {
+		return err
+	}
+
+	w.buf.Write(b)
+
+	return nil
+}
+
+// object copies the rest of a JSON object.
+func (w decimalWriter) object(path string) error {
+	w.buf.WriteByte('{')
+
+	for i := 0; w.dec.More(); i++ {
+		if i > 0 {
+			w.buf.WriteByte(',')
+		}
+
+		tok, err := w.dec.Token()
+		if err != nil {
+			return err
+		}
+
+		key, _ := tok.(string)
+
+		b, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+
+		w.buf.Write(b)
+		w.buf.WriteByte(':')
+
+		kpath := path + "." + key
+		if !w.paths[kpath] && w.hasPrefix(path+".*") {
+			kpath = path + ".*"
+		}
+
+		if err = w.value(kpath); err != nil {
+			return err
+		}
+	}
+
+	// closing delimiter
+	if _, err := w.dec.Token(); err != nil {
+		return err
+	}
+
+	w.buf.WriteByte('}')
+
+	return nil
+}
+
+// array copies the rest of a JSON array.
+func (w decimalWriter) array(path string) error {
+	w.buf.WriteByte('[')
+
+	for i := 0; w.dec.More(); i++ {
+		if i > 0 {
+			w.buf.WriteByte(',')
+		}
+
+		if err := w.value(path + "[]"); err != nil {
+			return err
+		}
+	}
+
+	// closing delimiter
+	if _, err := w.dec.Token(); err != nil {
+		return err
+	}
+
+	w.buf.WriteByte(']')
+
+	return nil
+}
+
+// hasPrefix checks whether any decimal path starts with the prefix.
+func (w decimalWriter) hasPrefix(prefix string) bool {
+	for p := range w.paths {
+		if strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// decimal writes the decimal value in the configured format.
+func (w decimalWriter) decimal(v string) error {
+	d, err := decimal.NewFromString(v)
+	if err != nil {
+		return err
+	}
+
+	s := d.String()
+	if w.places >= 0 {
+		s = d.StringFixed(w.places)
+	}
+
+	if w.number {
+		w.buf.WriteString(s)
+		return nil
+	}
+
+	w.buf.WriteByte('"')
+	w.buf.WriteString(s)
+	w.buf.WriteByte('"')
+
+	return nil
+}
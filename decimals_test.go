@@ -0,0 +1,135 @@
+package btcpay
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type decimalsEmbedStub struct {
+	Fee decimal.Decimal `json:"fee"`
+}
+
+type decimalsItemStub struct {
+	Price decimal.Decimal `json:"price"`
+}
+
+type decimalsStub struct {
+	decimalsEmbedStub
+	Currency string                     `json:"currency"`
+	OrderID  string                     `json:"orderId"`
+	Amount   decimal.Decimal            `json:"amount"`
+	Tip      *decimal.Decimal           `json:"tip,omitempty"`
+	Items    []decimalsItemStub         `json:"items"`
+	Rates    map[string]decimal.Decimal `json:"rates"`
+	Count    int64                      `json:"count"`
+	Ignored  decimal.Decimal            `json:"-"`
+	Self     *decimalsStub              `json:"self,omitempty"`
+}
+
+func Test_WithDecimalFormat(t *testing.T) {
+	c := &Client{}
+	WithDecimalFormat(DecimalFormat{AsNumber: true})(c)
+	require.NotNil(t, c.decimalFormat)
+	assert.True(t, c.decimalFormat.AsNumber)
+}
+
+func Test_collectDecimalPaths(t *testing.T) {
+	paths := make(map[string]bool)
+	collectDecimalPaths(reflect.TypeOf(&decimalsStub{}), "", paths, make(map[reflect.Type]bool))
+
+	assert.Equal(t, map[string]bool{
+		".fee":           true,
+		".amount":        true,
+		".tip":           true,
+		".items[].price": true,
+		".rates.*":       true,
+	}, paths)
+}
+
+func Test_formatDecimals(t *testing.T) {
+	tip := decimal.RequireFromString("0.5")
+
+	payload := decimalsStub{
+		decimalsEmbedStub: decimalsEmbedStub{Fee: decimal.RequireFromString("0.001")},
+		Currency:          "USD",
+		OrderID:           "10.123",
+		Amount:            decimal.RequireFromString("10.125"),
+		Tip:               &tip,
+		Items:             []decimalsItemStub{{Price: decimal.RequireFromString("1.2345")}},
+		Rates:             map[string]decimal.Decimal{"BTC": decimal.RequireFromString("30000.1")},
+		Count:             12345678901234567,
+	}
+
+	cc := map[string]struct {
+		Payload interface{}
+		Format  DecimalFormat
+		Result  string
+		Err     bool
+	}{
+		"Payload without decimals": {
+			Payload: struct {
+				A string `json:"a"`
+			}{"1.5"},
+			Format: DecimalFormat{AsNumber: true},
+			Result: `{"a":"1.5"}`,
+		},
+		"Exact strings": {
+			Payload: payload,
+			Result:  `{"fee":"0.001","currency":"USD","orderId":"10.123","amount":"10.125","tip":"0.5","items":[{"price":"1.2345"}],"rates":{"BTC":"30000.1"},"count":12345678901234567}`,
+		},
+		"Exact numbers": {
+			Payload: payload,
+			Format:  DecimalFormat{AsNumber: true},
+			Result:  `{"fee":0.001,"currency":"USD","orderId":"10.123","amount":10.125,"tip":0.5,"items":[{"price":1.2345}],"rates":{"BTC":30000.1},"count":12345678901234567}`,
+		},
+		"Fixed places": {
+			Payload: payload,
+			Format:  DecimalFormat{Rounding: DecimalFixed, Places: 3},
+			Result:  `{"fee":"0.001","currency":"USD","orderId":"10.123","amount":"10.125","tip":"0.500","items":[{"price":"1.235"}],"rates":{"BTC":"30000.100"},"count":12345678901234567}`,
+		},
+		"Currency places": {
+			Payload: payload,
+			Format:  DecimalFormat{Rounding: DecimalCurrency, AsNumber: true},
+			Result:  `{"fee":0.00,"currency":"USD","orderId":"10.123","amount":10.13,"tip":0.50,"items":[{"price":1.23}],"rates":{"BTC":30000.10},"count":12345678901234567}`,
+		},
+		"Unknown currency": {
+			Payload: decimalsStub{Currency: "ABCD", Amount: decimal.RequireFromString("1.12345")},
+			Format:  DecimalFormat{Rounding: DecimalCurrency},
+			Result:  `{"fee":"0","currency":"ABCD","orderId":"","amount":"1.12345","items":null,"rates":null,"count":0}`,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			d, err := json.Marshal(c.Payload)
+			require.NoError(t, err)
+
+			res, err := formatDecimals(d, c.Payload, c.Format)
+			if c.Err {
+				assert.Error(t, err)
+				assert.Nil(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, string(res))
+		})
+	}
+}
+
+func Test_formatDecimals_InvalidPayload(t *testing.T) {
+	_, err := formatDecimals([]byte(`{"amount":"abc"}`), decimalsStub{}, DecimalFormat{})
+	assert.Error(t, err)
+
+	_, err = formatDecimals([]byte(`{"amount":"1"`), decimalsStub{}, DecimalFormat{})
+	assert.Error(t, err)
+}
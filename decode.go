@@ -0,0 +1,119 @@
+package btcpay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// defaultMaxResponseBytes is the default maximum size of a response
+// body.
+const defaultMaxResponseBytes = 10 << 20
+
+// WithMaxResponseBytes sets the maximum size of response bodies read by
+// the BTCPay client. Reading a larger body fails with an error.
+// A non-positive value disables the limit.
+func WithMaxResponseBytes(n int64) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.maxResponseBytes = n
+	}
+}
+
+// WithStrictDecoding makes the BTCPay client reject responses that
+// contain fields unknown to the decoded types.
+func WithStrictDecoding() setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.strictDecoding = true
+	}
+}
+
+// decode decodes the JSON value read from r into v.
+func (c *Client) decode(r io.Reader, v interface{}) error {
+	dec := json.NewDecoder(r)
+
+	if c.strictDecoding {
+		dec.DisallowUnknownFields()
+	}
+
+	return dec.Decode(v)
+}
+
+// decodeData decodes the JSON value read from r into v. Both bare values
+// and values wrapped in a {"data": ...} envelope are accepted, since
+// BTCPay versions and BitPay compatible servers differ in which
+// responses they wrap. A single object is accepted where a list is
+// expected as well.
+func (c *Client) decodeData(r io.Reader, v interface{}) error {
+	var raw json.RawMessage
+
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return err
+	}
+
+	raw = unwrapData(raw)
+
+	if raw[0] == '{' && isSlicePtr(v) {
+		raw = append(append([]byte{'['}, raw...), ']')
+	}
+
+	return c.decode(bytes.NewReader(raw), v)
+}
+
+// unwrapData returns the value of the data field if the JSON value is an
+// object that has one, or the JSON value itself otherwise.
+func unwrapData(raw json.RawMessage) json.RawMessage {
+	if raw[0] != '{' {
+		return raw
+	}
+
+	var env map[string]json.RawMessage
+
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return raw
+	}
+
+	if d, ok := env["data"]; ok {
+		return d
+	}
+
+	return raw
+}
+
+// isSlicePtr checks whether v is a pointer to a slice.
+func isSlicePtr(v interface{}) bool {
+	t := reflect.TypeOf(v)
+	return t != nil && t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Slice
+}
+
+// limitBody limits the number of bytes that can be read from the
+// response body.
+func limitBody(rc io.ReadCloser, n int64) io.ReadCloser {
+	if n <= 0 {
+		return rc
+	}
+
+	return &limitedBody{ReadCloser: rc, r: io.LimitReader(rc, n+1), n: n}
+}
+
+// limitedBody is a response body that returns an error instead of
+// silently truncating the data once its size limit is exceeded.
+type limitedBody struct {
+	io.ReadCloser
+	r    io.Reader
+	n    int64
+	read int64
+}
+
+// Read reads data from the underlying body.
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	b.read += int64(n)
+
+	if b.read > b.n {
+		return n - int(b.read-b.n), fmt.Errorf("response body exceeds %d bytes", b.n)
+	}
+
+	return n, err
+}
@@ -0,0 +1,195 @@
+package btcpay
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithMaxResponseBytes(t *testing.T) {
+	c := &Client{}
+	WithMaxResponseBytes(10)(c)
+	assert.Equal(t, int64(10), c.maxResponseBytes)
+}
+
+func Test_WithStrictDecoding(t *testing.T) {
+	c := &Client{}
+	WithStrictDecoding()(c)
+	assert.True(t, c.strictDecoding)
+}
+
+func Test_limitBody(t *testing.T) {
+	cc := map[string]struct {
+		Limit  int64
+		Result string
+		Err    bool
+	}{
+		"Limit disabled": {
+			Limit:  0,
+			Result: "1234567890",
+		},
+		"Body within limit": {
+			Limit:  20,
+			Result: "1234567890",
+		},
+		"Body of exact limit size": {
+			Limit:  10,
+			Result: "1234567890",
+		},
+		"Body exceeding limit": {
+			Limit:  5,
+			Result: "12345",
+			Err:    true,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := ioutil.ReadAll(limitBody(ioutil.NopCloser(strings.NewReader("1234567890")), c.Limit))
+			assert.Equal(t, c.Result, string(b))
+
+			if c.Err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_Client_decode(t *testing.T) {
+	cc := map[string]struct {
+		Setters []setter
+		Status  int
+		Body    string
+		Err     bool
+	}{
+		"Response exceeding size limit": {
+			Setters: []setter{WithMaxResponseBytes(10)},
+			Status:  http.StatusOK,
+			Body:    `{"synchronized":true}`,
+			Err:     true,
+		},
+		"Error response exceeding size limit": {
+			Setters: []setter{WithMaxResponseBytes(10)},
+			Status:  http.StatusInternalServerError,
+			Body:    `{"code":"test","message":"test"}`,
+			Err:     true,
+		},
+		"Unknown fields with strict decoding": {
+			Setters: []setter{WithStrictDecoding()},
+			Status:  http.StatusOK,
+			Body:    `{"synchronized":true,"test":1}`,
+			Err:     true,
+		},
+		"Unknown fields": {
+			Status: http.StatusOK,
+			Body:   `{"synchronized":true,"test":1}`,
+		},
+		"Known fields with strict decoding": {
+			Setters: []setter{WithStrictDecoding()},
+			Status:  http.StatusOK,
+			Body:    `{"synchronized":true}`,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/health", httpmock.NewStringResponder(c.Status, c.Body))
+
+			client, err := NewClient("http://test.com", "", append(c.Setters, WithHTTPClient(&http.Client{Transport: mt}))...)
+			require.NoError(t, err)
+
+			res, err := client.Health(context.Background())
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, Health{Synchronized: true}, res)
+		})
+	}
+}
+
+func Test_Client_decodeData(t *testing.T) {
+	cc := map[string]struct {
+		Body   string
+		Strict bool
+		Err    bool
+		Result []Ledger
+	}{
+		"Invalid JSON": {
+			Body: `{"data":`,
+			Err:  true,
+		},
+		"Invalid value": {
+			Body: `{"data":"test"}`,
+			Err:  true,
+		},
+		"Bare array": {
+			Body:   ` [{"currency":"BTC"}]`,
+			Result: []Ledger{{Currency: "BTC"}},
+		},
+		"Wrapped array": {
+			Body:   `{"facade":"merchant", "data": [{"currency":"BTC"}]}`,
+			Strict: true,
+			Result: []Ledger{{Currency: "BTC"}},
+		},
+		"Bare object": {
+			Body:   `{"currency":"BTC"}`,
+			Result: []Ledger{{Currency: "BTC"}},
+		},
+		"Wrapped object": {
+			Body:   `{"data": {"currency":"BTC"}}`,
+			Result: []Ledger{{Currency: "BTC"}},
+		},
+		"Null data": {
+			Body: `{"data":null}`,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			client := &Client{strictDecoding: c.Strict}
+
+			var res []Ledger
+
+			err := client.decodeData(strings.NewReader(c.Body), &res)
+			if c.Err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+
+	var inv Invoice
+
+	err := (&Client{}).decodeData(strings.NewReader(`{"id":"123"}`), &inv)
+	assert.NoError(t, err)
+	assert.Equal(t, "123", inv.ID)
+}
@@ -0,0 +1,163 @@
+package btcpay
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Dispatcher routes invoice events to the handlers registered for their
+// types and, optionally, the prefixes of their invoices' order IDs, see
+// ForOrders. Its Dispatch method can be passed to NewIPNHandler.
+// It is safe for concurrent use by multiple goroutines.
+type Dispatcher struct {
+	prefix string
+	routes *dispatchRoutes
+}
+
+// dispatchRoutes holds the routes shared by a dispatcher and its
+// order-specific views.
+type dispatchRoutes struct {
+	mu      sync.RWMutex
+	list    []dispatchRoute
+	onError func(ev Event, err error)
+}
+
+// dispatchRoute is a single registered handler.
+type dispatchRoute struct {
+	typ    EventType
+	prefix string
+	fn     func(ctx context.Context, ev Event) error
+}
+
+// NewDispatcher creates a new event dispatcher without any handlers.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{routes: &dispatchRoutes{}}
+}
+
+// ForOrders returns a view of the dispatcher whose handlers only
+// receive events of invoices whose order IDs start with the prefix.
+// Handlers registered through the view are added to the dispatcher.
+func (d *Dispatcher) ForOrders(prefix string) *Dispatcher {
+	return &Dispatcher{prefix: prefix, routes: d.routes}
+}
+
+// OnError sets a function that is called with every error returned by,
+// or panic recovered from, a handler.
+func (d *Dispatcher) OnError(fn func(ev Event, err error)) {
+	d.routes.mu.Lock()
+	defer d.routes.mu.Unlock()
+
+	d.routes.onError = fn
+}
+
+// On registers the handler for events of the type. An empty type
+// matches all events.
+func (d *Dispatcher) On(typ EventType, fn func(ctx context.Context, ev Event) error) {
+	d.routes.mu.Lock()
+	defer d.routes.mu.Unlock()
+
+	d.routes.list = append(d.routes.list, dispatchRoute{typ: typ, prefix: d.prefix, fn: fn})
+}
+
+// OnCreated registers the handler for InvoiceCreated events.
+func (d *Dispatcher) OnCreated(fn func(ctx context.Context, ev *InvoiceCreated) error) {
+	d.On(EventInvoiceCreated, func(ctx context.Context, ev Event) error {
+		return fn(ctx, ev.(*InvoiceCreated))
+	})
+}
+
+// OnReceivedPayment registers the handler for InvoiceReceivedPayment
+// events.
+func (d *Dispatcher) OnReceivedPayment(fn func(ctx context.Context, ev *InvoiceReceivedPayment) error) {
+	d.On(EventInvoiceReceivedPayment, func(ctx context.Context, ev Event) error {
+		return fn(ctx, ev.(*InvoiceReceivedPayment))
+	})
+}
+
+// OnPaymentSettled registers the handler for InvoicePaymentSettled
+// events.
+func (d *Dispatcher) OnPaymentSettled(fn func(ctx context.Context, ev *InvoicePaymentSettled) error) {
+	d.On(EventInvoicePaymentSettled, func(ctx context.Context, ev Event) error {
+		return fn(ctx, ev.(*InvoicePaymentSettled))
+	})
+}
+
+// OnProcessing registers the handler for InvoiceProcessing events.
+func (d *Dispatcher) OnProcessing(fn func(ctx context.Context, ev *InvoiceProcessing) error) {
+	d.On(EventInvoiceProcessing, func(ctx context.Context, ev Event) error {
+		return fn(ctx, ev.(*InvoiceProcessing))
+	})
+}
+
+// OnExpired registers the handler for InvoiceExpired events.
+func (d *Dispatcher) OnExpired(fn func(ctx context.Context, ev *InvoiceExpired) error) {
+	d.On(EventInvoiceExpired, func(ctx context.Context, ev Event) error {
+		return fn(ctx, ev.(*InvoiceExpired))
+	})
+}
+
+// OnSettled registers the handler for InvoiceSettled events.
+func (d *Dispatcher) OnSettled(fn func(ctx context.Context, ev *InvoiceSettled) error) {
+	d.On(EventInvoiceSettled, func(ctx context.Context, ev Event) error {
+		return fn(ctx, ev.(*InvoiceSettled))
+	})
+}
+
+// OnInvalid registers the handler for InvoiceInvalid events.
+func (d *Dispatcher) OnInvalid(fn func(ctx context.Context, ev *InvoiceInvalid) error) {
+	d.On(EventInvoiceInvalid, func(ctx context.Context, ev Event) error {
+		return fn(ctx, ev.(*InvoiceInvalid))
+	})
+}
+
+// Dispatch passes the event, as parsed by ParseIPN, to all matching
+// handlers in the order of their registration. Every handler is called,
+// even if a previous one fails; panics are recovered and reported as
+// errors. The first error is returned, so that the server retries the
+// delivery, which means that handlers must be idempotent. Events
+// without matching handlers are ignored.
+func (d *Dispatcher) Dispatch(ctx context.Context, ev Event) error {
+	m := ev.Meta()
+	orderID := m.OrderID()
+
+	d.routes.mu.RLock()
+	routes := d.routes.list
+	onError := d.routes.onError
+	d.routes.mu.RUnlock()
+
+	var first error
+
+	for _, r := range routes {
+		if (r.typ != "" && r.typ != m.Type) || !strings.HasPrefix(orderID, r.prefix) {
+			continue
+		}
+
+		err := callHandler(ctx, r.fn, ev)
+		if err == nil {
+			continue
+		}
+
+		if onError != nil {
+			onError(ev, err)
+		}
+
+		if first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
+
+// callHandler calls the handler, converting its panic into an error.
+func callHandler(ctx context.Context, fn func(ctx context.Context, ev Event) error, ev Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s handler panicked: %v", ev.Meta().Type, r)
+		}
+	}()
+
+	return fn(ctx, ev)
+}
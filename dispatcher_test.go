@@ -0,0 +1,145 @@
+package btcpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Dispatcher_Dispatch(t *testing.T) {
+	meta := func(typ EventType, orderID string) EventMeta {
+		return EventMeta{Type: typ, InvoiceID: "i1", Metadata: json.RawMessage(`{"orderId":"` + orderID + `"}`)}
+	}
+
+	cc := map[string]struct {
+		Event  Event
+		Calls  []string
+		Errors int
+		Err    bool
+	}{
+		"No matching handlers": {
+			Event: &InvoiceCreated{EventMeta: meta(EventInvoiceCreated, "shop-1")},
+			Calls: []string{"any"},
+		},
+		"Settled event of a shop order": {
+			Event: &InvoiceSettled{EventMeta: meta(EventInvoiceSettled, "shop-1"), OverPaid: true},
+			Calls: []string{"settled", "shop settled", "any"},
+		},
+		"Settled event of a subscription order": {
+			Event: &InvoiceSettled{EventMeta: meta(EventInvoiceSettled, "sub-1")},
+			Calls: []string{"settled", "any"},
+		},
+		"Failing handlers": {
+			Event:  &InvoiceExpired{EventMeta: meta(EventInvoiceExpired, "sub-1")},
+			Calls:  []string{"sub expired", "any"},
+			Errors: 2,
+			Err:    true,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				calls  []string
+				errs   []error
+				called = func(name string) {
+					calls = append(calls, name)
+				}
+			)
+
+			d := NewDispatcher()
+			d.OnError(func(_ Event, err error) {
+				errs = append(errs, err)
+			})
+
+			d.OnSettled(func(_ context.Context, ev *InvoiceSettled) error {
+				called("settled")
+				return nil
+			})
+
+			d.ForOrders("shop-").OnSettled(func(_ context.Context, ev *InvoiceSettled) error {
+				assert.True(t, ev.OverPaid)
+				called("shop settled")
+
+				return nil
+			})
+
+			d.ForOrders("sub-").OnExpired(func(_ context.Context, ev *InvoiceExpired) error {
+				called("sub expired")
+				panic("boom")
+			})
+
+			d.On("", func(_ context.Context, ev Event) error {
+				called("any")
+
+				if ev.Meta().Type == EventInvoiceExpired {
+					return errors.New("failed")
+				}
+
+				return nil
+			})
+
+			err := d.Dispatch(context.Background(), c.Event)
+			assert.Equal(t, c.Calls, calls)
+			assert.Len(t, errs, c.Errors)
+
+			if c.Err {
+				assert.EqualError(t, err, "InvoiceExpired handler panicked: boom")
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_Dispatcher_typedHandlers(t *testing.T) {
+	var types []EventType
+
+	d := NewDispatcher()
+	record := func(ev Event) error {
+		types = append(types, ev.Meta().Type)
+		return nil
+	}
+
+	d.OnCreated(func(_ context.Context, ev *InvoiceCreated) error { return record(ev) })
+	d.OnReceivedPayment(func(_ context.Context, ev *InvoiceReceivedPayment) error { return record(ev) })
+	d.OnPaymentSettled(func(_ context.Context, ev *InvoicePaymentSettled) error { return record(ev) })
+	d.OnProcessing(func(_ context.Context, ev *InvoiceProcessing) error { return record(ev) })
+	d.OnExpired(func(_ context.Context, ev *InvoiceExpired) error { return record(ev) })
+	d.OnSettled(func(_ context.Context, ev *InvoiceSettled) error { return record(ev) })
+	d.OnInvalid(func(_ context.Context, ev *InvoiceInvalid) error { return record(ev) })
+
+	ee := []Event{
+		&InvoiceCreated{EventMeta: EventMeta{Type: EventInvoiceCreated}},
+		&InvoiceReceivedPayment{EventMeta: EventMeta{Type: EventInvoiceReceivedPayment}},
+		&InvoicePaymentSettled{EventMeta: EventMeta{Type: EventInvoicePaymentSettled}},
+		&InvoiceProcessing{EventMeta: EventMeta{Type: EventInvoiceProcessing}},
+		&InvoiceExpired{EventMeta: EventMeta{Type: EventInvoiceExpired}},
+		&InvoiceSettled{EventMeta: EventMeta{Type: EventInvoiceSettled}},
+		&InvoiceInvalid{EventMeta: EventMeta{Type: EventInvoiceInvalid}},
+	}
+
+	for _, ev := range ee {
+		assert.NoError(t, d.Dispatch(context.Background(), ev))
+	}
+
+	assert.Equal(t, []EventType{
+		EventInvoiceCreated, EventInvoiceReceivedPayment, EventInvoicePaymentSettled,
+		EventInvoiceProcessing, EventInvoiceExpired, EventInvoiceSettled, EventInvoiceInvalid,
+	}, types)
+}
+
+func Test_EventMeta_OrderID(t *testing.T) {
+	ev, err := ParseIPN([]byte(`{"type":"InvoiceSettled","invoiceId":"i1","metadata":{"orderId":"o1"}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "o1", ev.Meta().OrderID())
+	assert.Equal(t, "", EventMeta{}.OrderID())
+}
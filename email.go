@@ -0,0 +1,77 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+)
+
+// EmailSettings holds SMTP settings used to send emails.
+// More at: https://docs.btcpayserver.org/API/Greenfield/v1/#tag/Stores-Email
+type EmailSettings struct {
+	Server                  string    `json:"server"`
+	Port                    int64     `json:"port"`
+	Login                   string    `json:"login"`
+	Password                Sensitive `json:"password"`
+	From                    string    `json:"from"`
+	DisableCertificateCheck bool      `json:"disableCertificateCheck"`
+}
+
+// EmailParams holds data used to send an email.
+type EmailParams struct {
+	Email   Sensitive `json:"email"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+}
+
+// StoreEmailSettings retrieves SMTP settings of the specified store.
+func (c *Client) StoreEmailSettings(ctx context.Context, storeID string, opts ...RequestOption) (EmailSettings, error) {
+	return c.sendEmailSettings(ctx, http.MethodGet, newRoute("/api/v1/stores/:id/email", storeID), nil, opts)
+}
+
+// UpdateStoreEmailSettings updates SMTP settings of the specified store.
+// Emails to buyers, such as payment receipts, are sent only once the
+// store's SMTP settings are configured.
+func (c *Client) UpdateStoreEmailSettings(ctx context.Context, storeID string, s EmailSettings, opts ...RequestOption) (EmailSettings, error) {
+	return c.sendEmailSettings(ctx, http.MethodPut, newRoute("/api/v1/stores/:id/email", storeID), s, opts)
+}
+
+// SendStoreEmail sends an email using SMTP settings of the specified
+// store. It is useful for checking whether the settings work.
+func (c *Client) SendStoreEmail(ctx context.Context, storeID string, p EmailParams, opts ...RequestOption) error {
+	resp, err := c.sendAPI(ctx, http.MethodPost, newRoute("/api/v1/stores/:id/email/send", storeID), nil, p, opts...)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// ServerEmailSettings retrieves SMTP settings of the server. They are
+// used by stores that have no SMTP settings of their own.
+func (c *Client) ServerEmailSettings(ctx context.Context, opts ...RequestOption) (EmailSettings, error) {
+	return c.sendEmailSettings(ctx, http.MethodGet, newRoute("/api/v1/server/email"), nil, opts)
+}
+
+// UpdateServerEmailSettings updates SMTP settings of the server.
+func (c *Client) UpdateServerEmailSettings(ctx context.Context, s EmailSettings, opts ...RequestOption) (EmailSettings, error) {
+	return c.sendEmailSettings(ctx, http.MethodPut, newRoute("/api/v1/server/email"), s, opts)
+}
+
+// sendEmailSettings sends an email settings request and decodes the
+// returned settings.
+func (c *Client) sendEmailSettings(ctx context.Context, method string, endpoint route, payload interface{}, opts []RequestOption) (EmailSettings, error) {
+	resp, err := c.sendAPI(ctx, method, endpoint, nil, payload, opts...)
+	if err != nil {
+		return EmailSettings{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var s EmailSettings
+
+	if err = c.decode(resp.Body, &s); err != nil {
+		return EmailSettings{}, err
+	}
+
+	return s, nil
+}
@@ -0,0 +1,314 @@
+package btcpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_StoreEmailSettings(t *testing.T) {
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result EmailSettings
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, "{"),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `{"server":"smtp.test.com","port":587,"from":"f1@test.com"}`),
+			Result: EmailSettings{Server: "smtp.test.com", Port: 587, From: "f1@test.com"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/stores/s1/email", c.Resp)
+
+			res, err := client.StoreEmailSettings(context.Background(), "s1")
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodGet+" http://test.com/api/v1/stores/s1/email"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_UpdateStoreEmailSettings(t *testing.T) {
+	check := func(r *http.Request) error {
+		var p map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			return err
+		}
+
+		if p["server"] != "smtp.test.com" || p["port"] != float64(587) {
+			return errors.New("invalid payload")
+		}
+
+		return nil
+	}
+
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result EmailSettings
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, "{"), nil
+			},
+			Err: true,
+		},
+		"Successful execution": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"server":"smtp.test.com","port":587,"from":"f1@test.com"}`), nil
+			},
+			Result: EmailSettings{Server: "smtp.test.com", Port: 587, From: "f1@test.com"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodPut, "http://test.com/api/v1/stores/s1/email", c.Resp)
+
+			res, err := client.UpdateStoreEmailSettings(context.Background(), "s1", EmailSettings{Server: "smtp.test.com", Port: 587})
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodPut+" http://test.com/api/v1/stores/s1/email"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_SendStoreEmail(t *testing.T) {
+	check := func(r *http.Request) error {
+		var p map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			return err
+		}
+
+		if p["email"] != "u1@test.com" || p["subject"] != "s1" {
+			return errors.New("invalid payload")
+		}
+
+		return nil
+	}
+
+	cc := map[string]struct {
+		Resp httpmock.Responder
+		Err  bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, ""), nil
+			},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/stores/s1/email/send", c.Resp)
+
+			err = client.SendStoreEmail(context.Background(), "s1", EmailParams{Email: "u1@test.com", Subject: "s1", Body: "b1"})
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodPost+" http://test.com/api/v1/stores/s1/email/send"])
+
+			if c.Err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_Client_ServerEmailSettings(t *testing.T) {
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result EmailSettings
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, "{"),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `{"server":"smtp.test.com","port":587,"from":"f1@test.com"}`),
+			Result: EmailSettings{Server: "smtp.test.com", Port: 587, From: "f1@test.com"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/server/email", c.Resp)
+
+			res, err := client.ServerEmailSettings(context.Background())
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodGet+" http://test.com/api/v1/server/email"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_UpdateServerEmailSettings(t *testing.T) {
+	check := func(r *http.Request) error {
+		var p map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			return err
+		}
+
+		if p["server"] != "smtp.test.com" || p["port"] != float64(587) {
+			return errors.New("invalid payload")
+		}
+
+		return nil
+	}
+
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result EmailSettings
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, "{"), nil
+			},
+			Err: true,
+		},
+		"Successful execution": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"server":"smtp.test.com","port":587,"from":"f1@test.com"}`), nil
+			},
+			Result: EmailSettings{Server: "smtp.test.com", Port: 587, From: "f1@test.com"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodPut, "http://test.com/api/v1/server/email", c.Resp)
+
+			res, err := client.UpdateServerEmailSettings(context.Background(), EmailSettings{Server: "smtp.test.com", Port: 587})
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodPut+" http://test.com/api/v1/server/email"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package btcpay
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// maxErrorSnippet is the maximum number of bytes of an undecodable
+// error response body included in the error.
+const maxErrorSnippet = 256
+
+// APIError is returned when the server responds with a 4xx or 5xx
+// status code.
+type APIError struct {
+	StatusCode int
+
+	// Code is the machine readable error code of Greenfield API errors.
+	Code string
+
+	// Message is the error message returned by the server or, if the
+	// body could not be decoded, the status text followed by the
+	// beginning of the body.
+	Message string
+
+	// Fields holds validation errors of specific request fields.
+	Fields []FieldError
+}
+
+// FieldError holds a validation error of a single request field.
+type FieldError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// Error returns the status code and message of the error.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("[%d] %s", e.StatusCode, e.Message)
+}
+
+// WithErrorDecoder sets a custom decoder of error responses, e.g. for
+// servers behind proxies that return their own error formats. If the
+// decoder returns nil, the default decoding is used.
+func WithErrorDecoder(fn func(status int, body []byte) error) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.errorDecoder = fn
+	}
+}
+
+// decodeError decodes the error returned by the server. Both legacy
+// and Greenfield API error formats are supported; other bodies, such as
+// HTML error pages of reverse proxies, are included in the error as
+// they are.
+func (c *Client) decodeError(resp *http.Response) error {
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if c.errorDecoder != nil {
+		if err = c.errorDecoder(resp.StatusCode, b); err != nil {
+			return err
+		}
+	}
+
+	aerr := &APIError{StatusCode: resp.StatusCode}
+
+	// Greenfield API validation errors are returned as an array
+	if len(b) > 0 && b[0] == '[' {
+		if json.Unmarshal(b, &aerr.Fields) == nil {
+			msgs := make([]string, len(aerr.Fields))
+			for i, f := range aerr.Fields {
+				msgs[i] = f.Path + ": " + f.Message
+			}
+
+			aerr.Message = strings.Join(msgs, "; ")
+
+			return aerr
+		}
+
+		aerr.Fields = nil
+	}
+
+	var rerr struct {
+		Code    string `json:"code"`
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+
+	if json.Unmarshal(b, &rerr) == nil && (rerr.Error != "" || rerr.Message != "") {
+		aerr.Code = rerr.Code
+		aerr.Message = rerr.Error
+
+		if aerr.Message == "" {
+			aerr.Message = rerr.Message
+		}
+
+		return aerr
+	}
+
+	aerr.Message = http.StatusText(resp.StatusCode)
+	if aerr.Message == "" {
+		aerr.Message = "unexpected status"
+	}
+
+	if snip := snippet(b); snip != "" {
+		aerr.Message += ": " + snip
+	}
+
+	return aerr
+}
+
+// snippet returns the beginning of the body as a single line of text.
+func snippet(b []byte) string {
+	truncated := len(b) > maxErrorSnippet
+	if truncated {
+		b = b[:maxErrorSnippet]
+	}
+
+	// a multi-byte character may have been cut off
+	s := strings.Join(strings.Fields(strings.ToValidUTF8(string(b), "")), " ")
+	if truncated && s != "" {
+		s += "..."
+	}
+
+	return s
+}
@@ -0,0 +1,110 @@
+package btcpay
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WithErrorDecoder(t *testing.T) {
+	c := &Client{}
+	WithErrorDecoder(func(int, []byte) error { return nil })(c)
+	assert.NotNil(t, c.errorDecoder)
+}
+
+func Test_APIError_Error(t *testing.T) {
+	assert.EqualError(t, &APIError{StatusCode: 404, Message: "not found"}, "[404] not found")
+}
+
+func Test_Client_decodeError(t *testing.T) {
+	cc := map[string]struct {
+		Decoder func(status int, body []byte) error
+		Status  int
+		Body    string
+		Err     error
+	}{
+		"Custom decoder": {
+			Decoder: func(status int, body []byte) error {
+				return assert.AnError
+			},
+			Status: http.StatusBadGateway,
+			Body:   "test",
+			Err:    assert.AnError,
+		},
+		"Custom decoder fallback": {
+			Decoder: func(status int, body []byte) error {
+				return nil
+			},
+			Status: http.StatusBadGateway,
+			Body:   "test",
+			Err:    &APIError{StatusCode: http.StatusBadGateway, Message: "Bad Gateway: test"},
+		},
+		"Empty body": {
+			Status: http.StatusBadGateway,
+			Err:    &APIError{StatusCode: http.StatusBadGateway, Message: "Bad Gateway"},
+		},
+		"Empty body with unknown status": {
+			Status: 599,
+			Err:    &APIError{StatusCode: 599, Message: "unexpected status"},
+		},
+		"HTML body": {
+			Status: http.StatusBadGateway,
+			Body:   "<html>\n  <body>502 Bad Gateway</body>\n</html>",
+			Err:    &APIError{StatusCode: http.StatusBadGateway, Message: "Bad Gateway: <html> <body>502 Bad Gateway</body> </html>"},
+		},
+		"Long body": {
+			Status: http.StatusInternalServerError,
+			Body:   strings.Repeat("a", maxErrorSnippet-1) + "ąb",
+			Err:    &APIError{StatusCode: http.StatusInternalServerError, Message: "Internal Server Error: " + strings.Repeat("a", maxErrorSnippet-1) + "..."},
+		},
+		"JSON body without message": {
+			Status: http.StatusInternalServerError,
+			Body:   `{}`,
+			Err:    &APIError{StatusCode: http.StatusInternalServerError, Message: "Internal Server Error: {}"},
+		},
+		"Invalid validation errors": {
+			Status: http.StatusUnprocessableEntity,
+			Body:   `[1]`,
+			Err:    &APIError{StatusCode: http.StatusUnprocessableEntity, Message: "Unprocessable Entity: [1]"},
+		},
+		"Validation errors": {
+			Status: http.StatusUnprocessableEntity,
+			Body:   `[{"path":"amount","message":"invalid"}]`,
+			Err: &APIError{
+				StatusCode: http.StatusUnprocessableEntity,
+				Message:    "amount: invalid",
+				Fields:     []FieldError{{Path: "amount", Message: "invalid"}},
+			},
+		},
+		"Greenfield error": {
+			Status: http.StatusNotFound,
+			Body:   `{"code":"not-found","message":"not found"}`,
+			Err:    &APIError{StatusCode: http.StatusNotFound, Code: "not-found", Message: "not found"},
+		},
+		"Legacy error": {
+			Status: http.StatusUnauthorized,
+			Body:   `{"error":"unauthorized"}`,
+			Err:    &APIError{StatusCode: http.StatusUnauthorized, Message: "unauthorized"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			client := &Client{errorDecoder: c.Decoder}
+
+			err := client.decodeError(&http.Response{
+				StatusCode: c.Status,
+				Body:       ioutil.NopCloser(strings.NewReader(c.Body)),
+			})
+
+			assert.Equal(t, c.Err, err)
+		})
+	}
+}
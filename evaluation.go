@@ -0,0 +1,166 @@
+package btcpay
+
+import (
+	"context"
+	"errors"
+
+	"github.com/shopspring/decimal"
+	"github.com/swithek/btcpay-go/currency"
+)
+
+// PaymentAction specifies what should be done to settle an invoice that
+// was not paid exactly.
+type PaymentAction string
+
+// Available payment actions.
+const (
+	// PaymentActionNone means that nothing needs to be done, e.g. the
+	// invoice was paid exactly or the buyer can still pay the rest of it.
+	PaymentActionNone PaymentAction = "none"
+
+	// PaymentActionTopUp means that a new invoice of the missing amount
+	// should be requested from the buyer.
+	PaymentActionTopUp PaymentAction = "topUp"
+
+	// PaymentActionRefund means that the overpaid amount should be
+	// refunded through a pull payment.
+	PaymentActionRefund PaymentAction = "refund"
+)
+
+// PaymentEvaluation describes how much of a legacy API invoice was paid.
+type PaymentEvaluation struct {
+	Invoice Invoice
+	State   PaymentState
+	Action  PaymentAction
+
+	// CryptoCode and PaymentType identify the payment method in whose
+	// cryptocurrency CryptoDifference is specified.
+	CryptoCode  string
+	PaymentType string
+
+	// CryptoDifference and FiatDifference hold the paid amount minus
+	// the due amount in the cryptocurrency and the invoice currency.
+	// They are positive for overpayments and negative for
+	// underpayments.
+	CryptoDifference decimal.Decimal
+	FiatDifference   decimal.Decimal
+}
+
+// EvaluatePayment classifies the payment state of the invoice and
+// recommends an action that settles it. The state is taken from the
+// invoice's exception status when the server provides one, so that
+// payment tolerance is respected. Underpaid invoices that can still be
+// paid need no action.
+func EvaluatePayment(inv Invoice) PaymentEvaluation {
+	e := PaymentEvaluation{
+		Invoice: inv,
+		State:   PaymentStateUnpaid,
+		Action:  PaymentActionNone,
+	}
+
+	if len(inv.CryptoInfo) == 0 {
+		return e
+	}
+
+	// every payment method reports the total paid using all methods
+	ci := inv.CryptoInfo[0]
+
+	for _, c := range inv.CryptoInfo {
+		if c.Paid.IsPositive() {
+			ci = c
+			break
+		}
+	}
+
+	e.CryptoCode = ci.CryptoCode
+	e.PaymentType = ci.PaymentType
+
+	if !ci.Paid.IsPositive() {
+		return e
+	}
+
+	e.CryptoDifference = ci.Paid.Sub(ci.TotalDue)
+	e.FiatDifference = currency.Round(e.CryptoDifference.Mul(ci.Rate), inv.Currency)
+
+	switch inv.ExceptionStatus {
+	case "paidOver":
+		e.State = PaymentStateOverpaid
+	case "paidPartial":
+		e.State = PaymentStateUnderpaid
+	case false, nil:
+		e.State = PaymentStateExact
+	default:
+		e.State = stateOf(e.CryptoDifference)
+	}
+
+	switch {
+	case e.State == PaymentStateOverpaid:
+		e.Action = PaymentActionRefund
+	case e.State == PaymentStateUnderpaid && inv.Status != "new":
+		e.Action = PaymentActionTopUp
+	}
+
+	return e
+}
+
+// paymentMethod returns the Greenfield payment method ID of the
+// evaluated payment method, e.g. BTC or BTC-LightningNetwork.
+func (e PaymentEvaluation) paymentMethod() string {
+	return paymentMethodID(e.CryptoCode, e.PaymentType)
+}
+
+// PaymentResolution holds the result of a recommended payment action.
+type PaymentResolution struct {
+	Action PaymentAction
+
+	// TopUp is the invoice of the missing amount, if one was created.
+	TopUp *Invoice
+
+	// Refund is the pull payment of the overpaid amount, if one was
+	// created.
+	Refund *PullPayment
+}
+
+// ResolvePayment applies the recommended action of the evaluation. Top-up
+// invoices are created through the legacy API with the order ID, item
+// description and buyer of the original invoice; repeated calls create
+// at most one top-up invoice per invoice. Refunds are created through
+// the Greenfield API, so the store ID and an API key are needed.
+func (c *Client) ResolvePayment(ctx context.Context, storeID string, e PaymentEvaluation, opts ...RequestOption) (PaymentResolution, error) {
+	res := PaymentResolution{Action: e.Action}
+
+	switch e.Action {
+	case PaymentActionTopUp:
+		inv, err := c.CreateInvoice(ctx, CreateInvoiceParams{
+			Currency:       e.Invoice.Currency,
+			Price:          e.FiatDifference.Neg(),
+			OrderID:        e.Invoice.OrderID,
+			ItemDesc:       e.Invoice.ItemDesc,
+			POSData:        e.Invoice.POSData,
+			RedirectURL:    e.Invoice.RedirectURL,
+			Buyer:          e.Invoice.Buyer,
+			IdempotencyKey: "top-up-" + e.Invoice.ID,
+		}, opts...)
+		if err != nil {
+			return PaymentResolution{}, err
+		}
+
+		res.TopUp = &inv
+	case PaymentActionRefund:
+		if storeID == "" {
+			return PaymentResolution{}, errors.New("store ID is required")
+		}
+
+		pp, err := c.RefundStoreInvoice(ctx, storeID, e.Invoice.ID, RefundInvoiceParams{
+			PaymentMethod: e.paymentMethod(),
+			RefundVariant: RefundOverpaidAmount,
+		}, opts...)
+		if err != nil {
+			return PaymentResolution{}, err
+		}
+
+		res.Refund = &pp
+	}
+
+	return res, nil
+}
@@ -0,0 +1,216 @@
+package btcpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_EvaluatePayment(t *testing.T) {
+	info := func(paid string) []CryptoInfo {
+		return []CryptoInfo{
+			{CryptoCode: "BTC", PaymentType: "BTCLike", Rate: decimal.NewFromInt(20000), TotalDue: decimal.RequireFromString("0.0005")},
+			{CryptoCode: "BTC", PaymentType: "LightningLike", Rate: decimal.NewFromInt(20000), TotalDue: decimal.RequireFromString("0.0005"), Paid: decimal.RequireFromString(paid)},
+		}
+	}
+
+	cc := map[string]struct {
+		Invoice     Invoice
+		State       PaymentState
+		Action      PaymentAction
+		PaymentType string
+		Crypto      string
+		Fiat        string
+	}{
+		"No payment methods": {
+			Invoice: Invoice{Status: "new"},
+			State:   PaymentStateUnpaid,
+			Action:  PaymentActionNone,
+			Crypto:  "0",
+			Fiat:    "0",
+		},
+		"Unpaid invoice": {
+			Invoice:     Invoice{Status: "expired", Currency: "USD", CryptoInfo: info("0")},
+			State:       PaymentStateUnpaid,
+			Action:      PaymentActionNone,
+			PaymentType: "BTCLike",
+			Crypto:      "0",
+			Fiat:        "0",
+		},
+		"Exact payment": {
+			Invoice:     Invoice{Status: "complete", Currency: "USD", ExceptionStatus: false, CryptoInfo: info("0.0005")},
+			State:       PaymentStateExact,
+			Action:      PaymentActionNone,
+			PaymentType: "LightningLike",
+			Crypto:      "0",
+			Fiat:        "0",
+		},
+		"Payment within tolerance": {
+			Invoice:     Invoice{Status: "complete", Currency: "USD", ExceptionStatus: false, CryptoInfo: info("0.00049")},
+			State:       PaymentStateExact,
+			Action:      PaymentActionNone,
+			PaymentType: "LightningLike",
+			Crypto:      "-0.00001",
+			Fiat:        "-0.2",
+		},
+		"Partial payment of a new invoice": {
+			Invoice:     Invoice{Status: "new", Currency: "USD", ExceptionStatus: "paidPartial", CryptoInfo: info("0.0002")},
+			State:       PaymentStateUnderpaid,
+			Action:      PaymentActionNone,
+			PaymentType: "LightningLike",
+			Crypto:      "-0.0003",
+			Fiat:        "-6",
+		},
+		"Partial payment of an expired invoice": {
+			Invoice:     Invoice{Status: "expired", Currency: "USD", ExceptionStatus: "paidPartial", CryptoInfo: info("0.0002")},
+			State:       PaymentStateUnderpaid,
+			Action:      PaymentActionTopUp,
+			PaymentType: "LightningLike",
+			Crypto:      "-0.0003",
+			Fiat:        "-6",
+		},
+		"Overpayment": {
+			Invoice:     Invoice{Status: "complete", Currency: "JPY", ExceptionStatus: "paidOver", CryptoInfo: info("0.000612345")},
+			State:       PaymentStateOverpaid,
+			Action:      PaymentActionRefund,
+			PaymentType: "LightningLike",
+			Crypto:      "0.000112345",
+			Fiat:        "2",
+		},
+		"Unknown exception status": {
+			Invoice:     Invoice{Status: "invalid", Currency: "USD", ExceptionStatus: "marked", CryptoInfo: info("0.0004")},
+			State:       PaymentStateUnderpaid,
+			Action:      PaymentActionTopUp,
+			PaymentType: "LightningLike",
+			Crypto:      "-0.0001",
+			Fiat:        "-2",
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			e := EvaluatePayment(c.Invoice)
+			assert.Equal(t, c.State, e.State)
+			assert.Equal(t, c.Action, e.Action)
+			assert.Equal(t, c.PaymentType, e.PaymentType)
+			assert.Equal(t, c.Crypto, e.CryptoDifference.String())
+			assert.Equal(t, c.Fiat, e.FiatDifference.String())
+		})
+	}
+}
+
+func Test_Client_ResolvePayment(t *testing.T) {
+	topUp := PaymentEvaluation{
+		Invoice:        Invoice{ID: "i1", Currency: "USD", OrderID: "o1", ItemDesc: "item", Buyer: InvoiceBuyer{Email: "a@test.com"}},
+		Action:         PaymentActionTopUp,
+		FiatDifference: decimal.NewFromInt(-6),
+	}
+
+	refund := PaymentEvaluation{
+		Invoice:     Invoice{ID: "i1", Currency: "USD"},
+		Action:      PaymentActionRefund,
+		CryptoCode:  "BTC",
+		PaymentType: "LightningLike",
+	}
+
+	cc := map[string]struct {
+		StoreID    string
+		Evaluation PaymentEvaluation
+		Resp       httpmock.Responder
+		Result     PaymentResolution
+		Err        bool
+	}{
+		"No action": {
+			Evaluation: PaymentEvaluation{Action: PaymentActionNone},
+			Result:     PaymentResolution{Action: PaymentActionNone},
+		},
+		"Error returned during top-up invoice creation": {
+			Evaluation: topUp,
+			Resp:       httpmock.NewErrorResponder(assert.AnError),
+			Err:        true,
+		},
+		"Successful top-up": {
+			Evaluation: topUp,
+			Resp: func(r *http.Request) (*http.Response, error) {
+				var p CreateInvoiceParams
+				if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+					return nil, err
+				}
+
+				if r.Header.Get("Idempotency-Key") != "top-up-i1" || p.Price.String() != "6" || p.Currency != "USD" ||
+					p.OrderID != "o1" || p.ItemDesc != "item" || p.Buyer.Email != "a@test.com" {
+					return nil, errors.New("invalid request")
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"data":{"id":"i2","price":6}}`), nil
+			},
+			Result: PaymentResolution{Action: PaymentActionTopUp, TopUp: &Invoice{ID: "i2", Price: decimal.NewFromInt(6)}},
+		},
+		"Missing store ID": {
+			Evaluation: refund,
+			Err:        true,
+		},
+		"Error returned during refund creation": {
+			StoreID:    "s1",
+			Evaluation: refund,
+			Resp:       httpmock.NewErrorResponder(assert.AnError),
+			Err:        true,
+		},
+		"Successful refund": {
+			StoreID:    "s1",
+			Evaluation: refund,
+			Resp: func(r *http.Request) (*http.Response, error) {
+				var p RefundInvoiceParams
+				if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+					return nil, err
+				}
+
+				if p.PaymentMethod != "BTC-LightningNetwork" || p.RefundVariant != RefundOverpaidAmount {
+					return nil, errors.New("invalid request")
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"id":"pp1"}`), nil
+			},
+			Result: PaymentResolution{Action: PaymentActionRefund, Refund: &PullPayment{ID: "pp1"}},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}), WithAPIKey("k1"))
+			require.NoError(t, err)
+
+			if c.Resp != nil {
+				mt.RegisterResponder(http.MethodPost, "http://test.com/invoices", c.Resp)
+				mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/stores/s1/invoices/i1/refund", c.Resp)
+			}
+
+			res, err := client.ResolvePayment(context.Background(), c.StoreID, c.Evaluation)
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
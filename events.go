@@ -0,0 +1,153 @@
+package btcpay
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// EventType specifies the kind of an invoice event.
+type EventType string
+
+// Invoice event types sent by the BTCPay server.
+const (
+	EventInvoiceCreated         EventType = "InvoiceCreated"
+	EventInvoiceReceivedPayment EventType = "InvoiceReceivedPayment"
+	EventInvoicePaymentSettled  EventType = "InvoicePaymentSettled"
+	EventInvoiceProcessing      EventType = "InvoiceProcessing"
+	EventInvoiceExpired         EventType = "InvoiceExpired"
+	EventInvoiceSettled         EventType = "InvoiceSettled"
+	EventInvoiceInvalid         EventType = "InvoiceInvalid"
+)
+
+// Event is implemented by all invoice event payloads. Use a type switch
+// to access event specific data.
+type Event interface {
+	// Meta returns data shared by all events.
+	Meta() EventMeta
+}
+
+// EventMeta holds data shared by all invoice events.
+type EventMeta struct {
+	DeliveryID         string    `json:"deliveryId"`
+	WebhookID          string    `json:"webhookId"`
+	OriginalDeliveryID string    `json:"originalDeliveryId"`
+	IsRedelivery       bool      `json:"isRedelivery"`
+	Type               EventType `json:"type"`
+	Timestamp          int64     `json:"timestamp"`
+	StoreID            string    `json:"storeId"`
+	InvoiceID          string    `json:"invoiceId"`
+
+	// Metadata is the metadata of the invoice. It is only sent by
+	// recent servers.
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+}
+
+// Meta returns data shared by all events.
+func (m EventMeta) Meta() EventMeta {
+	return m
+}
+
+// OrderID returns the orderId metadata field of the event's invoice, or
+// an empty string if it is not known.
+func (m EventMeta) OrderID() string {
+	return StoreInvoice{Metadata: m.Metadata}.OrderID()
+}
+
+// InvoicePayment holds data of a single payment made towards an invoice.
+type InvoicePayment struct {
+	ID           string          `json:"id"`
+	ReceivedDate int64           `json:"receivedDate"`
+	Value        decimal.Decimal `json:"value"`
+	Fee          decimal.Decimal `json:"fee"`
+	Status       string          `json:"status"`
+	Destination  string          `json:"destination"`
+}
+
+// InvoiceCreated is sent when a new invoice is created.
+type InvoiceCreated struct {
+	EventMeta
+}
+
+// InvoiceReceivedPayment is sent when a payment is detected on an
+// invoice.
+type InvoiceReceivedPayment struct {
+	EventMeta
+	AfterExpiration bool           `json:"afterExpiration"`
+	PaymentMethod   string         `json:"paymentMethod"`
+	Payment         InvoicePayment `json:"payment"`
+}
+
+// InvoicePaymentSettled is sent when a payment made towards an invoice
+// is settled.
+type InvoicePaymentSettled struct {
+	EventMeta
+	AfterExpiration bool           `json:"afterExpiration"`
+	PaymentMethod   string         `json:"paymentMethod"`
+	Payment         InvoicePayment `json:"payment"`
+}
+
+// InvoiceProcessing is sent when an invoice is paid in full and is
+// waiting for payment confirmations.
+type InvoiceProcessing struct {
+	EventMeta
+	OverPaid bool `json:"overPaid"`
+}
+
+// InvoiceExpired is sent when an invoice expires before being paid in
+// full.
+type InvoiceExpired struct {
+	EventMeta
+	PartiallyPaid bool `json:"partiallyPaid"`
+}
+
+// InvoiceSettled is sent when an invoice is paid in full and its
+// payments are confirmed.
+type InvoiceSettled struct {
+	EventMeta
+	ManuallyMarked bool `json:"manuallyMarked"`
+	OverPaid       bool `json:"overPaid"`
+}
+
+// InvoiceInvalid is sent when an invoice becomes invalid.
+type InvoiceInvalid struct {
+	EventMeta
+	ManuallyMarked bool `json:"manuallyMarked"`
+}
+
+// ParseIPN parses the provided invoice notification payload and returns
+// its typed event.
+func ParseIPN(data []byte) (Event, error) {
+	var meta EventMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+
+	var ev Event
+
+	switch meta.Type {
+	case EventInvoiceCreated:
+		ev = &InvoiceCreated{}
+	case EventInvoiceReceivedPayment:
+		ev = &InvoiceReceivedPayment{}
+	case EventInvoicePaymentSettled:
+		ev = &InvoicePaymentSettled{}
+	case EventInvoiceProcessing:
+		ev = &InvoiceProcessing{}
+	case EventInvoiceExpired:
+		ev = &InvoiceExpired{}
+	case EventInvoiceSettled:
+		ev = &InvoiceSettled{}
+	case EventInvoiceInvalid:
+		ev = &InvoiceInvalid{}
+	default:
+		return nil, fmt.Errorf("unknown event type %q", meta.Type)
+	}
+
+	if err := json.Unmarshal(data, ev); err != nil {
+		return nil, err
+	}
+
+	return ev, nil
+}
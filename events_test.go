@@ -0,0 +1,98 @@
+package btcpay
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseIPN(t *testing.T) {
+	meta := func(tp EventType) EventMeta {
+		return EventMeta{
+			DeliveryID: "d1",
+			Type:       tp,
+			StoreID:    "s1",
+			InvoiceID:  "i1",
+		}
+	}
+
+	cc := map[string]struct {
+		Data   string
+		Result Event
+		Err    bool
+		ErrMsg string
+	}{
+		"Invalid JSON": {
+			Data: `{`,
+			Err:  true,
+		},
+		"Unknown event type": {
+			Data:   `{"type":"Unknown"}`,
+			Err:    true,
+			ErrMsg: `unknown event type "Unknown"`,
+		},
+		"Invalid event data": {
+			Data: `{"type":"InvoiceSettled","overPaid":"yes"}`,
+			Err:  true,
+		},
+		"Successful InvoiceCreated parsing": {
+			Data:   `{"deliveryId":"d1","type":"InvoiceCreated","storeId":"s1","invoiceId":"i1"}`,
+			Result: &InvoiceCreated{EventMeta: meta(EventInvoiceCreated)},
+		},
+		"Successful InvoiceReceivedPayment parsing": {
+			Data: `{"deliveryId":"d1","type":"InvoiceReceivedPayment","storeId":"s1","invoiceId":"i1",` +
+				`"afterExpiration":true,"paymentMethod":"BTC","payment":{"id":"p1","value":"0.5"}}`,
+			Result: &InvoiceReceivedPayment{
+				EventMeta:       meta(EventInvoiceReceivedPayment),
+				AfterExpiration: true,
+				PaymentMethod:   "BTC",
+				Payment:         InvoicePayment{ID: "p1", Value: decimal.RequireFromString("0.5")},
+			},
+		},
+		"Successful InvoicePaymentSettled parsing": {
+			Data:   `{"deliveryId":"d1","type":"InvoicePaymentSettled","storeId":"s1","invoiceId":"i1","paymentMethod":"BTC"}`,
+			Result: &InvoicePaymentSettled{EventMeta: meta(EventInvoicePaymentSettled), PaymentMethod: "BTC"},
+		},
+		"Successful InvoiceProcessing parsing": {
+			Data:   `{"deliveryId":"d1","type":"InvoiceProcessing","storeId":"s1","invoiceId":"i1","overPaid":true}`,
+			Result: &InvoiceProcessing{EventMeta: meta(EventInvoiceProcessing), OverPaid: true},
+		},
+		"Successful InvoiceExpired parsing": {
+			Data:   `{"deliveryId":"d1","type":"InvoiceExpired","storeId":"s1","invoiceId":"i1","partiallyPaid":true}`,
+			Result: &InvoiceExpired{EventMeta: meta(EventInvoiceExpired), PartiallyPaid: true},
+		},
+		"Successful InvoiceSettled parsing": {
+			Data:   `{"deliveryId":"d1","type":"InvoiceSettled","storeId":"s1","invoiceId":"i1","manuallyMarked":true}`,
+			Result: &InvoiceSettled{EventMeta: meta(EventInvoiceSettled), ManuallyMarked: true},
+		},
+		"Successful InvoiceInvalid parsing": {
+			Data:   `{"deliveryId":"d1","type":"InvoiceInvalid","storeId":"s1","invoiceId":"i1","manuallyMarked":true}`,
+			Result: &InvoiceInvalid{EventMeta: meta(EventInvoiceInvalid), ManuallyMarked: true},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			ev, err := ParseIPN([]byte(c.Data))
+			if c.Err {
+				assert.Error(t, err)
+				assert.Nil(t, ev)
+
+				if c.ErrMsg != "" {
+					assert.EqualError(t, err, c.ErrMsg)
+				}
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, ev)
+			assert.Equal(t, c.Result.Meta(), ev.Meta())
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package btcpay
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// EventStore records keys of processed invoice events, so that
+// duplicate deliveries can be detected.
+type EventStore interface {
+	// Add records the key. False is returned if the key was already
+	// recorded. The check and the write must be atomic.
+	Add(ctx context.Context, key string) (bool, error)
+
+	// Has checks whether the key is recorded.
+	Has(ctx context.Context, key string) (bool, error)
+
+	// Remove removes the key.
+	Remove(ctx context.Context, key string) error
+}
+
+// MemoryEventStore is an EventStore that keeps keys in memory. It is
+// suitable for a single process only.
+// It is safe for concurrent use by multiple goroutines.
+type MemoryEventStore struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu   sync.Mutex
+	keys map[string]*list.Element
+
+	// order holds memoryEvents ordered by their expiration time. Since
+	// all keys share the same TTL, it is the order of their addition.
+	order *list.List
+}
+
+// memoryEvent is a key recorded by MemoryEventStore.
+type memoryEvent struct {
+	key string
+	exp time.Time
+}
+
+// NewMemoryEventStore creates a new in-memory event store. Keys are
+// forgotten once the TTL elapses; a non-positive TTL keeps them
+// forever.
+func NewMemoryEventStore(ttl time.Duration) *MemoryEventStore {
+	return &MemoryEventStore{
+		ttl:   ttl,
+		now:   time.Now,
+		keys:  make(map[string]*list.Element),
+		order: list.New(),
+	}
+}
+
+// Add records the key unless it is already recorded.
+func (s *MemoryEventStore) Add(_ context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.evict(now)
+
+	if _, ok := s.keys[key]; ok {
+		return false, nil
+	}
+
+	var exp time.Time
+	if s.ttl > 0 {
+		exp = now.Add(s.ttl)
+	}
+
+	s.keys[key] = s.order.PushBack(memoryEvent{key: key, exp: exp})
+
+	return true, nil
+}
+
+// Has checks whether the key is recorded.
+func (s *MemoryEventStore) Has(_ context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evict(s.now())
+
+	_, ok := s.keys[key]
+
+	return ok, nil
+}
+
+// Remove removes the key.
+func (s *MemoryEventStore) Remove(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.keys[key]; ok {
+		s.order.Remove(e)
+		delete(s.keys, key)
+	}
+
+	return nil
+}
+
+// evict removes expired keys. Only the keys that are expired are
+// visited. The lock must be held.
+func (s *MemoryEventStore) evict(now time.Time) {
+	if s.ttl <= 0 {
+		return
+	}
+
+	for e := s.order.Front(); e != nil; e = s.order.Front() {
+		ev := e.Value.(memoryEvent)
+		if now.Before(ev.exp) {
+			return
+		}
+
+		s.order.Remove(e)
+		delete(s.keys, ev.key)
+	}
+}
+
+// RedisClient is the subset of Redis commands used by RedisEventStore.
+// Clients of popular Redis libraries can be adapted to it with a few
+// lines of code.
+type RedisClient interface {
+	// SetNX sets the key with the TTL only if it does not exist. True
+	// is returned if the key was set.
+	SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error)
+
+	// Exists checks whether the key exists.
+	Exists(ctx context.Context, key string) (bool, error)
+
+	// Del removes the key.
+	Del(ctx context.Context, key string) error
+}
+
+// RedisEventStore is an EventStore backed by Redis. It allows events to
+// be deduplicated across multiple processes.
+type RedisEventStore struct {
+	rc     RedisClient
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedisEventStore creates a new Redis event store. Keys are prefixed
+// with the prefix and expire once the TTL elapses; a non-positive TTL
+// keeps them forever.
+func NewRedisEventStore(rc RedisClient, prefix string, ttl time.Duration) *RedisEventStore {
+	if ttl < 0 {
+		ttl = 0
+	}
+
+	return &RedisEventStore{
+		rc:     rc,
+		prefix: prefix,
+		ttl:    ttl,
+	}
+}
+
+// Add records the key unless it is already recorded.
+func (s *RedisEventStore) Add(ctx context.Context, key string) (bool, error) {
+	return s.rc.SetNX(ctx, s.prefix+key, "1", s.ttl)
+}
+
+// Has checks whether the key is recorded.
+func (s *RedisEventStore) Has(ctx context.Context, key string) (bool, error) {
+	return s.rc.Exists(ctx, s.prefix+key)
+}
+
+// Remove removes the key.
+func (s *RedisEventStore) Remove(ctx context.Context, key string) error {
+	return s.rc.Del(ctx, s.prefix+key)
+}
@@ -0,0 +1,133 @@
+package btcpay
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_MemoryEventStore(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	s := NewMemoryEventStore(time.Minute)
+	s.now = func() time.Time { return now }
+
+	ctx := context.Background()
+
+	ok, err := s.Add(ctx, "k1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = s.Add(ctx, "k1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = s.Has(ctx, "k1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	require.NoError(t, s.Remove(ctx, "k1"))
+
+	ok, err = s.Has(ctx, "k1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = s.Add(ctx, "k1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	now = now.Add(time.Minute)
+
+	ok, err = s.Has(ctx, "k1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// only the expired keys are evicted
+	_, err = s.Add(ctx, "k1")
+	require.NoError(t, err)
+
+	now = now.Add(time.Second * 30)
+
+	_, err = s.Add(ctx, "k2")
+	require.NoError(t, err)
+
+	require.NoError(t, s.Remove(ctx, "k3"))
+
+	now = now.Add(time.Second * 30)
+
+	ok, err = s.Has(ctx, "k1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = s.Has(ctx, "k2")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Len(t, s.keys, 1)
+	assert.Equal(t, 1, s.order.Len())
+
+	require.NoError(t, s.Remove(ctx, "k2"))
+	assert.Empty(t, s.keys)
+	assert.Zero(t, s.order.Len())
+
+	s = NewMemoryEventStore(0)
+
+	_, err = s.Add(ctx, "k1")
+	require.NoError(t, err)
+
+	ok, err = s.Has(ctx, "k1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func Test_RedisEventStore(t *testing.T) {
+	rc := &redisStub{keys: make(map[string]time.Duration)}
+
+	s := NewRedisEventStore(rc, "btcpay:", -time.Second)
+	assert.Zero(t, s.ttl)
+
+	s = NewRedisEventStore(rc, "btcpay:", time.Hour)
+	ctx := context.Background()
+
+	ok, err := s.Add(ctx, "k1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, map[string]time.Duration{"btcpay:k1": time.Hour}, rc.keys)
+
+	ok, err = s.Add(ctx, "k1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = s.Has(ctx, "k1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	require.NoError(t, s.Remove(ctx, "k1"))
+	assert.Empty(t, rc.keys)
+}
+
+type redisStub struct {
+	keys map[string]time.Duration
+}
+
+func (rs *redisStub) SetNX(_ context.Context, key, _ string, ttl time.Duration) (bool, error) {
+	if _, ok := rs.keys[key]; ok {
+		return false, nil
+	}
+
+	rs.keys[key] = ttl
+
+	return true, nil
+}
+
+func (rs *redisStub) Exists(_ context.Context, key string) (bool, error) {
+	_, ok := rs.keys[key]
+	return ok, nil
+}
+
+func (rs *redisStub) Del(_ context.Context, key string) error {
+	delete(rs.keys, key)
+	return nil
+}
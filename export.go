@@ -0,0 +1,231 @@
+package btcpay
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ExportFormat specifies the file format of exported data.
+type ExportFormat string
+
+// Available export formats.
+const (
+	ExportCSV  ExportFormat = "csv"
+	ExportXLSX ExportFormat = "xlsx"
+)
+
+// InvoiceColumn describes a single column of exported invoices.
+type InvoiceColumn struct {
+	Header string
+	Value  func(inv Invoice) string
+}
+
+// DefaultInvoiceColumns are the columns used when none are specified
+// for invoice export.
+var DefaultInvoiceColumns = []InvoiceColumn{
+	{Header: "ID", Value: func(inv Invoice) string { return inv.ID }},
+	{Header: "Order ID", Value: func(inv Invoice) string { return inv.OrderID }},
+	{Header: "Status", Value: func(inv Invoice) string { return inv.Status }},
+	{Header: "Price", Value: func(inv Invoice) string { return inv.Price.String() }},
+	{Header: "Currency", Value: func(inv Invoice) string { return inv.Currency }},
+	{Header: "Amount Paid", Value: func(inv Invoice) string { return inv.AmountPaid.String() }},
+	{Header: "Created At", Value: func(inv Invoice) string { return inv.CreatedAt().UTC().Format(time.RFC3339) }},
+}
+
+// ExportInvoices writes invoices that match the provided params into w
+// in the specified format. All pages of invoices are retrieved and
+// written as they arrive. DefaultInvoiceColumns are used if no columns
+// are provided.
+func (c *Client) ExportInvoices(ctx context.Context, p InvoicesParams, w io.Writer, f ExportFormat, cols []InvoiceColumn, opts ...RequestOption) error {
+	if len(cols) == 0 {
+		cols = DefaultInvoiceColumns
+	}
+
+	var tw tableWriter
+
+	switch f {
+	case ExportCSV:
+		tw = &csvWriter{w: csv.NewWriter(w)}
+	case ExportXLSX:
+		tw = &xlsxWriter{z: zip.NewWriter(w)}
+	default:
+		return errors.New("unsupported export format")
+	}
+
+	row := make([]string, len(cols))
+
+	for i, col := range cols {
+		row[i] = col.Header
+	}
+
+	if err := tw.Write(row); err != nil {
+		return err
+	}
+
+	it := c.InvoiceIterator(ctx, p, opts...)
+
+	for it.Next() {
+		for i, col := range cols {
+			row[i] = col.Value(it.Invoice())
+		}
+
+		if err := tw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	return tw.Close()
+}
+
+// tableWriter writes rows of a table.
+type tableWriter interface {
+	Write(row []string) error
+	Close() error
+}
+
+// csvWriter writes rows in the CSV format.
+type csvWriter struct {
+	w   *csv.Writer
+	row []string
+}
+
+// Write writes a single row. Cells that spreadsheet applications would
+// evaluate as formulas are prefixed with an apostrophe, since values
+// such as order IDs and buyer details are controlled by third parties.
+func (cw *csvWriter) Write(row []string) error {
+	cw.row = append(cw.row[:0], row...)
+
+	for i, v := range cw.row {
+		if isFormula(v) {
+			cw.row[i] = "'" + v
+		}
+	}
+
+	return cw.w.Write(cw.row)
+}
+
+// isFormula checks whether the cell value would be interpreted as a
+// formula. Numbers, including negative ones, are left intact.
+func isFormula(v string) bool {
+	if v == "" {
+		return false
+	}
+
+	switch v[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		_, err := strconv.ParseFloat(v, 64)
+		return err != nil
+	default:
+		return false
+	}
+}
+
+// Close flushes the buffered data.
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// xlsxParts are the static parts of an XLSX file with a single
+// worksheet.
+var xlsxParts = []struct {
+	name string
+	data string
+}{
+	{
+		name: "[Content_Types].xml",
+		data: xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`,
+	},
+	{
+		name: "_rels/.rels",
+		data: xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`,
+	},
+	{
+		name: "xl/workbook.xml",
+		data: xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Invoices" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+	},
+	{
+		name: "xl/_rels/workbook.xml.rels",
+		data: xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`,
+	},
+}
+
+// xlsxWriter writes rows into a single worksheet of an XLSX file. All
+// cells are written as inline strings.
+type xlsxWriter struct {
+	z     *zip.Writer
+	sheet *bufio.Writer
+}
+
+// Write writes a single row.
+func (xw *xlsxWriter) Write(row []string) error {
+	if xw.sheet == nil {
+		for _, p := range xlsxParts {
+			fw, err := xw.z.Create(p.name)
+			if err != nil {
+				return err
+			}
+
+			if _, err = io.WriteString(fw, p.data); err != nil {
+				return err
+			}
+		}
+
+		fw, err := xw.z.Create("xl/worksheets/sheet1.xml")
+		if err != nil {
+			return err
+		}
+
+		xw.sheet = bufio.NewWriter(fw)
+		xw.sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	}
+
+	xw.sheet.WriteString("<row>")
+
+	for _, v := range row {
+		xw.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+
+		if err := xml.EscapeText(xw.sheet, []byte(v)); err != nil {
+			return err
+		}
+
+		xw.sheet.WriteString("</t></is></c>")
+	}
+
+	_, err := xw.sheet.WriteString("</row>")
+
+	return err
+}
+
+// Close finishes the worksheet and the file.
+func (xw *xlsxWriter) Close() error {
+	xw.sheet.WriteString("</sheetData></worksheet>")
+
+	if err := xw.sheet.Flush(); err != nil {
+		return err
+	}
+
+	return xw.z.Close()
+}
@@ -0,0 +1,127 @@
+package btcpay
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_ExportInvoices(t *testing.T) {
+	body := `{"data":[{"id":"i1","orderId":"o<1>","status":"complete","price":"1.5","currency":"USD","amountPaid":"0.0001","invoiceTime":1577836800000}]}`
+
+	cc := map[string]struct {
+		Format  ExportFormat
+		Columns []InvoiceColumn
+		Resp    httpmock.Responder
+		Check   func(t *testing.T, b []byte)
+		Err     bool
+	}{
+		"Unsupported format": {
+			Format: "pdf",
+			Resp:   httpmock.NewStringResponder(http.StatusOK, body),
+			Err:    true,
+		},
+		"Error returned during request sending": {
+			Format: ExportCSV,
+			Resp:   httpmock.NewErrorResponder(assert.AnError),
+			Err:    true,
+		},
+		"Successful CSV export": {
+			Format: ExportCSV,
+			Resp:   httpmock.NewStringResponder(http.StatusOK, body),
+			Check: func(t *testing.T, b []byte) {
+				assert.Equal(t, "ID,Order ID,Status,Price,Currency,Amount Paid,Created At\n"+
+					"i1,o<1>,complete,1.5,USD,0.0001,2020-01-01T00:00:00Z\n", string(b))
+			},
+		},
+		"Successful CSV export with custom columns": {
+			Format: ExportCSV,
+			Columns: []InvoiceColumn{
+				{Header: "Invoice", Value: func(inv Invoice) string { return inv.ID + ", " + inv.Status }},
+			},
+			Resp: httpmock.NewStringResponder(http.StatusOK, body),
+			Check: func(t *testing.T, b []byte) {
+				assert.Equal(t, "Invoice\n\"i1, complete\"\n", string(b))
+			},
+		},
+		"Successful CSV export with formula cells": {
+			Format: ExportCSV,
+			Columns: []InvoiceColumn{
+				{Header: "=Header", Value: func(inv Invoice) string { return "=HYPERLINK(\"http://evil.com\")" }},
+				{Header: "Plus", Value: func(inv Invoice) string { return "+1+1" }},
+				{Header: "Minus", Value: func(inv Invoice) string { return "-1+cmd|' /C calc'!A0" }},
+				{Header: "At", Value: func(inv Invoice) string { return "@SUM(1)" }},
+				{Header: "Tab", Value: func(inv Invoice) string { return "\t=1" }},
+				{Header: "Negative", Value: func(inv Invoice) string { return "-1.5" }},
+				{Header: "Inner", Value: func(inv Invoice) string { return "a=b" }},
+			},
+			Resp: httpmock.NewStringResponder(http.StatusOK, body),
+			Check: func(t *testing.T, b []byte) {
+				assert.Equal(t, "'=Header,Plus,Minus,At,Tab,Negative,Inner\n"+
+					"\"'=HYPERLINK(\"\"http://evil.com\"\")\",'+1+1,'-1+cmd|' /C calc'!A0,'@SUM(1),'\t=1,-1.5,a=b\n", string(b))
+			},
+		},
+		"Successful XLSX export": {
+			Format: ExportXLSX,
+			Resp:   httpmock.NewStringResponder(http.StatusOK, body),
+			Check: func(t *testing.T, b []byte) {
+				zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+				require.NoError(t, err)
+
+				files := make(map[string]string)
+
+				for _, f := range zr.File {
+					rc, err := f.Open()
+					require.NoError(t, err)
+
+					d, err := ioutil.ReadAll(rc)
+					require.NoError(t, err)
+					require.NoError(t, rc.Close())
+
+					files[f.Name] = string(d)
+				}
+
+				assert.Len(t, files, 5)
+				assert.Contains(t, files, "[Content_Types].xml")
+				assert.Contains(t, files, "xl/workbook.xml")
+
+				sheet := files["xl/worksheets/sheet1.xml"]
+				assert.Contains(t, sheet, `<row><c t="inlineStr"><is><t xml:space="preserve">ID</t></is></c>`)
+				assert.Contains(t, sheet, `<t xml:space="preserve">o&lt;1&gt;</t>`)
+				assert.Contains(t, sheet, "</row></sheetData></worksheet>")
+			},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/invoices", c.Resp)
+
+			var buf bytes.Buffer
+
+			err = client.ExportInvoices(context.Background(), InvoicesParams{}, &buf, c.Format, c.Columns)
+			if c.Err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			c.Check(t, buf.Bytes())
+		})
+	}
+}
@@ -0,0 +1,339 @@
+package btcpay
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Primary host check settings.
+const (
+	// healthCheckInterval specifies how often the primary host is
+	// checked while requests are sent to a fallback host.
+	healthCheckInterval = time.Second * 30
+
+	// defaultHealthCheckTimeout is the timeout of a check when the
+	// client has no default request timeout.
+	defaultHealthCheckTimeout = time.Second * 10
+)
+
+// WithFallbackHosts sets standby hosts, e.g. a Tor address of the same
+// server, that requests are sent to when the primary host is
+// unreachable or its gateway reports that the server is down. Hosts are
+// tried in the provided order. While a fallback host is in use, the
+// health endpoint of the primary host is checked every 30 seconds and
+// requests are sent to it again once it responds. Requests that are not
+// idempotent, e.g. invoice creation, are resent only if the connection
+// to the host could not be made at all, even if they carry an
+// idempotency key, since BTCPay Server does not enforce it.
+// The path prefix, if any, applies to all hosts.
+func WithFallbackHosts(hosts ...string) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		if c.failover == nil {
+			c.failover = &failover{}
+		}
+
+		c.failover.hosts = append(c.failover.hosts, hosts...)
+	}
+}
+
+// failover tracks which of the client's hosts requests are sent to.
+// The first host is the primary one.
+type failover struct {
+	hosts []string
+	now   func() time.Time
+
+	mu        sync.Mutex
+	active    int
+	checkedAt time.Time
+	checking  bool
+}
+
+// init normalizes the fallback hosts and adds the primary host in
+// front of them.
+func (f *failover) init(primary, prefix string, now func() time.Time) error {
+	hosts := []string{primary}
+
+	for _, h := range f.hosts {
+		h, err := normalizeHost(h)
+		if err != nil {
+			return err
+		}
+
+		hosts = append(hosts, h+prefix)
+	}
+
+	f.hosts = hosts
+	f.now = now
+
+	return nil
+}
+
+// current returns the index of the active host. The second value is
+// true if the primary host should be checked.
+func (f *failover) current() (int, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.active == 0 || f.checking || f.now().Sub(f.checkedAt) < healthCheckInterval {
+		return f.active, false
+	}
+
+	f.checking = true
+
+	return f.active, true
+}
+
+// indexOf returns the index of the host that the URL belongs to or -1
+// if there's none. The longest matching host wins, since hosts may
+// differ by their path only.
+func (f *failover) indexOf(u string) int {
+	idx := -1
+
+	for i, h := range f.hosts {
+		if belongsTo(u, h) && (idx < 0 || len(h) > len(f.hosts[idx])) {
+			idx = i
+		}
+	}
+
+	return idx
+}
+
+// belongsTo checks whether the URL points to the host. The host must be
+// followed by a path, query or nothing at all, so that e.g.
+// https://a.com does not match https://a.com.evil.
+func belongsTo(u, host string) bool {
+	if !strings.HasPrefix(u, host) {
+		return false
+	}
+
+	rest := u[len(host):]
+
+	return rest == "" || rest[0] == '/' || rest[0] == '?' || rest[0] == '#'
+}
+
+// fail switches to the host that follows the failed one, unless
+// another request has already done so.
+func (f *failover) fail(i int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.active != i {
+		return
+	}
+
+	f.active = (i + 1) % len(f.hosts)
+
+	if i == 0 {
+		f.checkedAt = f.now()
+	}
+}
+
+// recover records the result of a primary host check.
+func (f *failover) recover(ok bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.checking = false
+	f.checkedAt = f.now()
+
+	if ok {
+		f.active = 0
+	}
+}
+
+// baseURL returns the URL of the host that requests should be sent to.
+func (c *Client) baseURL() string {
+	if c.failover == nil {
+		return c.host
+	}
+
+	i, check := c.failover.current()
+	if check {
+		go c.checkPrimary()
+	}
+
+	return c.failover.hosts[i]
+}
+
+// checkPrimary checks whether the primary host is reachable again.
+func (c *Client) checkPrimary() {
+	ok := false
+
+	defer func() {
+		c.failover.recover(ok)
+	}()
+
+	timeout := c.timeout
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.failover.hosts[0]+"/api/v1/health", nil)
+	if err != nil {
+		return
+	}
+
+	req.Header.Set("User-Agent", c.header["User-Agent"])
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return
+	}
+
+	defer resp.Body.Close()
+
+	ok = resp.StatusCode == http.StatusOK
+}
+
+// failoverRoundTrip sends the request and, while the hosts that it is
+// sent to are unreachable, resends it to the next fallback host. Legacy
+// API requests are signed again, since the signature covers the URL.
+func (c *Client) failoverRoundTrip(req *http.Request) (*http.Response, error) {
+	if c.failover == nil {
+		return c.followRedirects(req)
+	}
+
+	from := c.failover.indexOf(req.URL.String())
+	if from < 0 {
+		return c.followRedirects(req)
+	}
+
+	for i := from; ; {
+		resp, err := c.followRedirects(req)
+
+		next := (i + 1) % len(c.failover.hosts)
+
+		// bodies that cannot be read again are never resent
+		if next == from || !isUnreachable(req, resp, err) || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+			return resp, err
+		}
+
+		c.failover.fail(i)
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		req, err = c.rebase(req, c.failover.hosts[i], c.failover.hosts[next])
+		if err != nil {
+			return nil, err
+		}
+
+		i = next
+	}
+}
+
+// rebase returns a copy of the request that is sent to another host.
+func (c *Client) rebase(req *http.Request, from, to string) (*http.Request, error) {
+	u, err := url.Parse(to + strings.TrimPrefix(req.URL.String(), from))
+	if err != nil {
+		return nil, err
+	}
+
+	return c.retarget(req, u)
+}
+
+// retarget returns a copy of the request that is sent to the URL. Legacy
+// API requests are signed again.
+func (c *Client) retarget(req *http.Request, u *url.URL) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	r.URL = u
+	r.Host = ""
+
+	var body []byte
+
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+
+		body, err = ioutil.ReadAll(rc)
+		rc.Close()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if r.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.Header.Get("X-Signature") != "" {
+		if err := c.sign(r, string(body)); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// isUnreachable checks whether the request failed because the server
+// could not be reached and can be resent to another host. Requests that
+// are not idempotent are resent only if no connection was made, since
+// the server may have processed them already. Cancelled requests are
+// never treated as unreachable.
+func isUnreachable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	if !isIdempotent(req) {
+		return err != nil && isConnectError(err)
+	}
+
+	if err != nil {
+		return true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// isIdempotent checks whether the request can be sent more than once
+// without side effects. Idempotency-Key headers are not taken into
+// account, since the server may not enforce them.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// isConnectError checks whether the error occurred before a connection
+// to the server was made.
+func isConnectError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return false
+	}
+
+	switch opErr.Op {
+	case "dial", "proxyconnect", "socks connect":
+		return true
+	default:
+		return false
+	}
+}
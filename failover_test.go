@@ -0,0 +1,257 @@
+package btcpay
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithFallbackHosts(t *testing.T) {
+	c := &Client{}
+	WithFallbackHosts("b.com")(c)
+	WithFallbackHosts("c.onion")(c)
+	require.NotNil(t, c.failover)
+	assert.Equal(t, []string{"b.com", "c.onion"}, c.failover.hosts)
+
+	_, err := NewClient("http://a.com", "", WithFallbackHosts("ftp://b.com"))
+	assert.Error(t, err)
+
+	client, err := NewClient("http://a.com", "", WithFallbackHosts("b.com/", "http://c.onion"), WithPathPrefix("btcpay"),
+		WithHTTPClient(&http.Client{Transport: httpmock.NewMockTransport()}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http://a.com/btcpay", "https://b.com/btcpay", "http://c.onion/btcpay"}, client.failover.hosts)
+	assert.True(t, client.hasOnionHost())
+}
+
+func Test_Client_failoverRoundTrip(t *testing.T) {
+	down := httpmock.NewErrorResponder(assert.AnError)
+	health := httpmock.NewStringResponder(http.StatusOK, `{"synchronized":true}`)
+
+	cc := map[string]struct {
+		Primary  httpmock.Responder
+		Fallback httpmock.Responder
+		Cancel   bool
+		Host     string
+		Err      bool
+	}{
+		"Primary host is reachable": {
+			Primary: health,
+			Host:    "http://a.com",
+		},
+		"Primary host responds with a client error": {
+			Primary: httpmock.NewStringResponder(http.StatusNotFound, ""),
+			Host:    "http://a.com",
+			Err:     true,
+		},
+		"Request is cancelled": {
+			Primary: down,
+			Cancel:  true,
+			Host:    "http://a.com",
+			Err:     true,
+		},
+		"Primary host is unreachable": {
+			Primary:  down,
+			Fallback: health,
+			Host:     "http://b.com",
+		},
+		"Primary gateway reports an error": {
+			Primary:  httpmock.NewStringResponder(http.StatusBadGateway, ""),
+			Fallback: health,
+			Host:     "http://b.com",
+		},
+		"All hosts are unreachable": {
+			Primary:  down,
+			Fallback: down,
+			Host:     "http://b.com",
+			Err:      true,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			mt.RegisterResponder(http.MethodGet, "http://a.com/api/v1/health", c.Primary)
+
+			if c.Fallback != nil {
+				mt.RegisterResponder(http.MethodGet, "http://b.com/api/v1/health", c.Fallback)
+			}
+
+			client, err := NewClient("http://a.com", "", WithHTTPClient(&http.Client{Transport: mt}), WithFallbackHosts("http://b.com"))
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if c.Cancel {
+				cancel()
+			} else {
+				defer cancel()
+			}
+
+			_, err = client.Health(ctx)
+			if c.Err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, c.Host, client.failover.hosts[client.failover.active])
+		})
+	}
+}
+
+func Test_Client_failoverRoundTrip_Signed(t *testing.T) {
+	var sig bytes.Buffer
+
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder(http.MethodPost, "http://a.com/invoices", httpmock.NewErrorResponder(&net.OpError{Op: "dial", Err: assert.AnError}))
+	mt.RegisterResponder(http.MethodPost, "http://b.com/invoices", func(r *http.Request) (*http.Response, error) {
+		if r.Header.Get("X-Signature") == "" {
+			return httpmock.NewStringResponse(http.StatusUnauthorized, ""), nil
+		}
+
+		return httpmock.NewStringResponse(http.StatusOK, `{"data":{"id":"i1"}}`), nil
+	})
+
+	client, err := NewClient("http://a.com", "token", WithHTTPClient(&http.Client{Transport: mt}), WithFallbackHosts("http://b.com"),
+		WithSignatureDebug(&sig))
+	require.NoError(t, err)
+
+	inv, err := client.CreateInvoice(context.Background(), CreateInvoiceParams{Currency: "USD"})
+	require.NoError(t, err)
+	assert.Equal(t, "i1", inv.ID)
+	assert.Contains(t, sig.String(), "signed: http://a.com/invoices{")
+	assert.Contains(t, sig.String(), "signed: http://b.com/invoices{")
+
+	// the fallback host is used right away
+	_, err = client.CreateInvoice(context.Background(), CreateInvoiceParams{Currency: "USD"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, mt.GetCallCountInfo()["POST http://a.com/invoices"])
+	assert.Equal(t, 2, mt.GetCallCountInfo()["POST http://b.com/invoices"])
+}
+
+func Test_Client_checkPrimary(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder(http.MethodGet, "http://a.com/api/v1/health", httpmock.NewStringResponder(http.StatusServiceUnavailable, ""))
+	mt.RegisterResponder(http.MethodGet, "http://b.com/api/v1/health", httpmock.NewStringResponder(http.StatusOK, `{"synchronized":true}`))
+
+	client, err := NewClient("http://a.com", "", WithHTTPClient(&http.Client{Transport: mt}), WithFallbackHosts("http://b.com"),
+		WithClock(fixedClock{now: now}))
+	require.NoError(t, err)
+
+	_, err = client.Health(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "http://b.com", client.baseURL())
+
+	// the primary host is still down
+	client.failover.mu.Lock()
+	client.failover.checkedAt = now.Add(-healthCheckInterval)
+	client.failover.mu.Unlock()
+
+	assert.Equal(t, "http://b.com", client.baseURL())
+	assert.Eventually(t, func() bool {
+		client.failover.mu.Lock()
+		defer client.failover.mu.Unlock()
+
+		return !client.failover.checking
+	}, time.Second, time.Millisecond*10)
+	assert.Equal(t, "http://b.com", client.baseURL())
+	assert.Equal(t, 2, mt.GetCallCountInfo()["GET http://a.com/api/v1/health"])
+
+	// the primary host has recovered
+	mt.RegisterResponder(http.MethodGet, "http://a.com/api/v1/health", httpmock.NewStringResponder(http.StatusOK, `{"synchronized":true}`))
+
+	client.failover.mu.Lock()
+	client.failover.checkedAt = now.Add(-healthCheckInterval)
+	client.failover.mu.Unlock()
+
+	client.baseURL()
+	assert.Eventually(t, func() bool {
+		return client.baseURL() == "http://a.com"
+	}, time.Second, time.Millisecond*10)
+}
+
+func Test_Client_failoverRoundTrip_NotIdempotent(t *testing.T) {
+	cc := map[string]struct {
+		Primary httpmock.Responder
+		Params  CreateInvoiceParams
+		Resent  bool
+	}{
+		"Connection error": {
+			Primary: httpmock.NewErrorResponder(&net.OpError{Op: "dial", Err: assert.AnError}),
+			Resent:  true,
+		},
+		"DNS error": {
+			Primary: httpmock.NewErrorResponder(&net.DNSError{Err: "no such host", Name: "a.com"}),
+			Resent:  true,
+		},
+		"Error after the request was sent": {
+			Primary: httpmock.NewErrorResponder(&net.OpError{Op: "read", Err: assert.AnError}),
+		},
+		"Gateway error": {
+			Primary: httpmock.NewStringResponder(http.StatusGatewayTimeout, ""),
+		},
+		"Gateway error with an idempotency key": {
+			Primary: httpmock.NewStringResponder(http.StatusGatewayTimeout, ""),
+			Params:  CreateInvoiceParams{Currency: "USD", IdempotencyKey: "k1"},
+		},
+		"Connection error with an idempotency key": {
+			Primary: httpmock.NewErrorResponder(&net.OpError{Op: "dial", Err: assert.AnError}),
+			Params:  CreateInvoiceParams{Currency: "USD", IdempotencyKey: "k1"},
+			Resent:  true,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			mt.RegisterResponder(http.MethodPost, "http://a.com/invoices", c.Primary)
+			mt.RegisterResponder(http.MethodPost, "http://b.com/invoices", httpmock.NewStringResponder(http.StatusOK, `{"data":{"id":"i1"}}`))
+
+			client, err := NewClient("http://a.com", "token", WithHTTPClient(&http.Client{Transport: mt}), WithFallbackHosts("http://b.com"))
+			require.NoError(t, err)
+
+			if c.Params.Currency == "" {
+				c.Params.Currency = "USD"
+			}
+
+			_, err = client.CreateInvoice(context.Background(), c.Params)
+			if c.Resent {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, mt.GetCallCountInfo()["POST http://b.com/invoices"])
+
+				return
+			}
+
+			assert.Error(t, err)
+			assert.Zero(t, mt.GetCallCountInfo()["POST http://b.com/invoices"])
+		})
+	}
+}
+
+func Test_failover_indexOf(t *testing.T) {
+	f := &failover{hosts: []string{"https://a.com", "https://a.com/btcpay", "http://b.onion"}}
+
+	assert.Equal(t, 0, f.indexOf("https://a.com"))
+	assert.Equal(t, 0, f.indexOf("https://a.com/invoices?token=t"))
+	assert.Equal(t, 1, f.indexOf("https://a.com/btcpay/invoices"))
+	assert.Equal(t, 0, f.indexOf("https://a.com/btcpayx"))
+	assert.Equal(t, -1, f.indexOf("https://a.com.evil/invoices"))
+	assert.Equal(t, -1, f.indexOf("https://a.community"))
+	assert.Equal(t, 2, f.indexOf("http://b.onion/"))
+}
@@ -3,10 +3,24 @@ module github.com/swithek/btcpay-go
 go 1.15
 
 require (
-	github.com/btcsuite/btcd v0.21.0-beta.0.20200914143047-c693bd8bc510
-	github.com/btcsuite/btcutil v1.0.2
+	github.com/btcsuite/btcd v0.24.2
+	github.com/btcsuite/btcd/btcec/v2 v2.3.2
+	github.com/btcsuite/btcd/btcutil v1.1.5
+	github.com/btcsuite/btcd/btcutil/psbt v1.1.8
+	github.com/btcsuite/btcd/chaincfg/chainhash v1.1.0
+	github.com/gorilla/websocket v1.5.0
 	github.com/jarcoal/httpmock v1.0.6
+	github.com/prometheus/client_golang v1.16.0
 	github.com/shopspring/decimal v1.2.0
-	github.com/stretchr/testify v1.6.1
-	golang.org/x/crypto v0.0.0-20200510223506-06a226fb4e37
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
+	github.com/stretchr/testify v1.8.4
+	github.com/tyler-smith/go-bip39 v1.1.0
+	go.opentelemetry.io/otel v1.16.0
+	go.opentelemetry.io/otel/metric v1.16.0
+	go.opentelemetry.io/otel/trace v1.16.0
+	golang.org/x/crypto v0.0.0-20211117183948-ae814b36b871
+	golang.org/x/sync v0.3.0
+	golang.org/x/sys v0.10.0 // indirect
+	golang.org/x/time v0.3.0
+	gopkg.in/yaml.v3 v3.0.1
 )
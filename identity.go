@@ -0,0 +1,151 @@
+package btcpay
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Identity bundles credentials of a paired legacy API client.
+type Identity struct {
+	PEM   string `json:"pem"`
+	SIN   string `json:"sin"`
+	Token string `json:"token"`
+}
+
+// ExportIdentity returns the identity of the client. It fails if the
+// client uses a custom signer, since its private key is not available.
+func (c *Client) ExportIdentity() (Identity, error) {
+	if c.pem == "" {
+		return Identity{}, errors.New("private key of a custom signer cannot be exported")
+	}
+
+	return Identity{
+		PEM:   c.pem,
+		SIN:   c.clientID,
+		Token: c.Token(),
+	}, nil
+}
+
+// NewClientFromIdentity creates a fresh instance of BTCPay client that
+// uses the provided identity.
+func NewClientFromIdentity(host string, id Identity, ss ...setter) (*Client, error) {
+	if id.PEM == "" {
+		return nil, errors.New("identity PEM is required")
+	}
+
+	c, err := NewClient(host, id.Token, append(ss, WithPEM(id.PEM))...)
+	if err != nil {
+		return nil, err
+	}
+
+	if id.SIN != "" && id.SIN != c.clientID {
+		return nil, errors.New("identity SIN does not match its PEM")
+	}
+
+	return c, nil
+}
+
+// scrypt key derivation parameters.
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+// encryptedIdentity holds an encrypted identity along with the data
+// needed to decrypt it.
+type encryptedIdentity struct {
+	KDF   string `json:"kdf"`
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+// Encrypt serializes the identity and encrypts it with a key derived
+// from the passphrase. The result can be decrypted with
+// DecryptIdentity.
+func (id Identity) Encrypt(passphrase string) ([]byte, error) {
+	d, err := json.Marshal(id)
+	if err != nil {
+		// unlikely to happen
+		return nil, err
+	}
+
+	ei := encryptedIdentity{
+		KDF:  "scrypt",
+		Salt: make([]byte, 16),
+	}
+
+	if _, err = io.ReadFull(rand.Reader, ei.Salt); err != nil {
+		return nil, err
+	}
+
+	var nonce [24]byte
+	if _, err = io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return nil, err
+	}
+
+	key, err := deriveKey(passphrase, ei.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	ei.Nonce = nonce[:]
+	ei.Data = secretbox.Seal(nil, d, &nonce, key)
+
+	return json.Marshal(ei)
+}
+
+// DecryptIdentity decrypts the identity encrypted with the
+// Identity.Encrypt method.
+func DecryptIdentity(data []byte, passphrase string) (Identity, error) {
+	var ei encryptedIdentity
+	if err := json.Unmarshal(data, &ei); err != nil {
+		return Identity{}, err
+	}
+
+	if ei.KDF != "scrypt" || len(ei.Nonce) != 24 {
+		return Identity{}, errors.New("unsupported encryption parameters")
+	}
+
+	key, err := deriveKey(passphrase, ei.Salt)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	var nonce [24]byte
+
+	copy(nonce[:], ei.Nonce)
+
+	d, ok := secretbox.Open(nil, ei.Data, &nonce, key)
+	if !ok {
+		return Identity{}, errors.New("invalid passphrase")
+	}
+
+	var id Identity
+	if err = json.Unmarshal(d, &id); err != nil {
+		return Identity{}, err
+	}
+
+	return id, nil
+}
+
+// deriveKey derives an encryption key from the passphrase.
+func deriveKey(passphrase string, salt []byte) (*[32]byte, error) {
+	k, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+
+	var key [32]byte
+
+	copy(key[:], k)
+
+	return &key, nil
+}
@@ -0,0 +1,99 @@
+package btcpay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_ExportIdentity(t *testing.T) {
+	c, err := NewClient("http://test.com", "tok")
+	require.NoError(t, err)
+
+	id, err := c.ExportIdentity()
+	assert.NoError(t, err)
+	assert.Equal(t, Identity{PEM: c.pem, SIN: c.clientID, Token: "tok"}, id)
+
+	c, err = NewClient("http://test.com", "tok", WithSigner(signerStub{pub: "02" + "79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"}))
+	require.NoError(t, err)
+
+	_, err = c.ExportIdentity()
+	assert.Error(t, err)
+}
+
+func Test_NewClientFromIdentity(t *testing.T) {
+	pm, err := GeneratePEM()
+	require.NoError(t, err)
+
+	s, err := NewPEMSigner(pm)
+	require.NoError(t, err)
+
+	sin, err := generateSIN(s.PublicKey())
+	require.NoError(t, err)
+
+	cc := map[string]struct {
+		Identity Identity
+		Err      bool
+	}{
+		"Missing PEM": {
+			Identity: Identity{SIN: sin, Token: "tok"},
+			Err:      true,
+		},
+		"Invalid PEM": {
+			Identity: Identity{PEM: "test", SIN: sin, Token: "tok"},
+			Err:      true,
+		},
+		"Mismatched SIN": {
+			Identity: Identity{PEM: pm, SIN: "test", Token: "tok"},
+			Err:      true,
+		},
+		"Successful creation without SIN": {
+			Identity: Identity{PEM: pm, Token: "tok"},
+		},
+		"Successful creation": {
+			Identity: Identity{PEM: pm, SIN: sin, Token: "tok"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			client, err := NewClientFromIdentity("http://test.com", c.Identity)
+			if c.Err {
+				assert.Error(t, err)
+				assert.Nil(t, client)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, pm, client.pem)
+			assert.Equal(t, sin, client.clientID)
+			assert.Equal(t, "tok", client.Token())
+		})
+	}
+}
+
+func Test_Identity_Encrypt(t *testing.T) {
+	id := Identity{PEM: "pem", SIN: "sin", Token: "tok"}
+
+	data, err := id.Encrypt("pass")
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "tok")
+
+	res, err := DecryptIdentity(data, "pass")
+	assert.NoError(t, err)
+	assert.Equal(t, id, res)
+
+	_, err = DecryptIdentity(data, "wrong")
+	assert.Error(t, err)
+
+	_, err = DecryptIdentity([]byte("{"), "pass")
+	assert.Error(t, err)
+
+	_, err = DecryptIdentity([]byte(`{"kdf":"test"}`), "pass")
+	assert.Error(t, err)
+}
@@ -0,0 +1,202 @@
+// Package ripemd160 implements the RIPEMD-160 hash algorithm, which is
+// needed to derive client identities (SINs) of the legacy API. It
+// replaces the deprecated golang.org/x/crypto/ripemd160 package and
+// must not be used for anything else.
+package ripemd160
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+// Size is the size of the checksum in bytes.
+const Size = 20
+
+// BlockSize is the block size of the hash algorithm in bytes.
+const BlockSize = 64
+
+// initial state of the hash.
+var iv = [5]uint32{0x67452301, 0xefcdab89, 0x98badcfe, 0x10325476, 0xc3d2e1f0}
+
+// Message word selection, rotation amounts and constants of the left
+// and right lines, one row per round.
+var (
+	rl = [80]uint8{
+		0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15,
+		7, 4, 13, 1, 10, 6, 15, 3, 12, 0, 9, 5, 2, 14, 11, 8,
+		3, 10, 14, 4, 9, 15, 8, 1, 2, 7, 0, 6, 13, 11, 5, 12,
+		1, 9, 11, 10, 0, 8, 12, 4, 13, 3, 7, 15, 14, 5, 6, 2,
+		4, 0, 5, 9, 7, 12, 2, 10, 14, 1, 3, 8, 11, 6, 15, 13,
+	}
+	rr = [80]uint8{
+		5, 14, 7, 0, 9, 2, 11, 4, 13, 6, 15, 8, 1, 10, 3, 12,
+		6, 11, 3, 7, 0, 13, 5, 10, 14, 15, 8, 12, 4, 9, 1, 2,
+		15, 5, 1, 3, 7, 14, 6, 9, 11, 8, 12, 2, 10, 0, 4, 13,
+		8, 6, 4, 1, 3, 11, 15, 0, 5, 12, 2, 13, 9, 7, 10, 14,
+		12, 15, 10, 4, 1, 5, 8, 7, 6, 2, 13, 14, 0, 3, 9, 11,
+	}
+	sl = [80]uint8{
+		11, 14, 15, 12, 5, 8, 7, 9, 11, 13, 14, 15, 6, 7, 9, 8,
+		7, 6, 8, 13, 11, 9, 7, 15, 7, 12, 15, 9, 11, 7, 13, 12,
+		11, 13, 6, 7, 14, 9, 13, 15, 14, 8, 13, 6, 5, 12, 7, 5,
+		11, 12, 14, 15, 14, 15, 9, 8, 9, 14, 5, 6, 8, 6, 5, 12,
+		9, 15, 5, 11, 6, 8, 13, 12, 5, 12, 13, 14, 11, 8, 5, 6,
+	}
+	sr = [80]uint8{
+		8, 9, 9, 11, 13, 15, 15, 5, 7, 7, 8, 11, 14, 14, 12, 6,
+		9, 13, 15, 7, 12, 8, 9, 11, 7, 7, 12, 7, 6, 15, 13, 11,
+		9, 7, 15, 11, 8, 6, 6, 14, 12, 13, 5, 14, 13, 13, 7, 5,
+		15, 5, 8, 11, 14, 14, 6, 14, 6, 9, 12, 9, 12, 5, 15, 8,
+		8, 5, 12, 9, 12, 5, 14, 6, 8, 13, 6, 5, 15, 13, 11, 11,
+	}
+	kl = [5]uint32{0x00000000, 0x5a827999, 0x6ed9eba1, 0x8f1bbcdc, 0xa953fd4e}
+	kr = [5]uint32{0x50a28be6, 0x5c4dd124, 0x6d703ef3, 0x7a6d76e9, 0x00000000}
+)
+
+// digest holds the state of a running hash.
+type digest struct {
+	s   [5]uint32
+	buf [BlockSize]byte
+	n   int
+	len uint64
+}
+
+// New returns a new hash.Hash computing the RIPEMD-160 checksum.
+func New() hash.Hash {
+	d := &digest{}
+	d.Reset()
+
+	return d
+}
+
+// Sum returns the RIPEMD-160 checksum of the data.
+func Sum(data []byte) [Size]byte {
+	d := &digest{s: iv}
+	d.Write(data) //nolint:errcheck // writes never fail
+
+	var sum [Size]byte
+
+	copy(sum[:], d.Sum(nil))
+
+	return sum
+}
+
+// Reset resets the hash to its initial state.
+func (d *digest) Reset() {
+	d.s = iv
+	d.n = 0
+	d.len = 0
+}
+
+// Size returns the size of the checksum in bytes.
+func (d *digest) Size() int {
+	return Size
+}
+
+// BlockSize returns the block size of the hash algorithm in bytes.
+func (d *digest) BlockSize() int {
+	return BlockSize
+}
+
+// Write adds more data to the running hash. It never returns an error.
+func (d *digest) Write(p []byte) (int, error) {
+	n := len(p)
+	d.len += uint64(n)
+
+	if d.n > 0 {
+		c := copy(d.buf[d.n:], p)
+		d.n += c
+		p = p[c:]
+
+		if d.n < BlockSize {
+			return n, nil
+		}
+
+		d.block(d.buf[:])
+		d.n = 0
+	}
+
+	for len(p) >= BlockSize {
+		d.block(p[:BlockSize])
+		p = p[BlockSize:]
+	}
+
+	d.n = copy(d.buf[:], p)
+
+	return n, nil
+}
+
+// Sum appends the current checksum to b without changing the state of
+// the running hash.
+func (d *digest) Sum(b []byte) []byte {
+	cp := *d
+
+	// the message is padded with a single bit, zeros and its length in
+	// bits, so that the length is a multiple of the block size
+	pad := make([]byte, BlockSize+8)
+	pad[0] = 0x80
+
+	l := cp.len
+	padLen := BlockSize - int(l%BlockSize)
+
+	if padLen < 9 {
+		padLen += BlockSize
+	}
+
+	binary.LittleEndian.PutUint64(pad[padLen-8:], l<<3)
+	cp.Write(pad[:padLen]) //nolint:errcheck // writes never fail
+
+	var sum [Size]byte
+
+	for i, v := range cp.s {
+		binary.LittleEndian.PutUint32(sum[i*4:], v)
+	}
+
+	return append(b, sum[:]...)
+}
+
+// block processes a single block of data.
+func (d *digest) block(p []byte) {
+	var x [16]uint32
+
+	for i := range x {
+		x[i] = binary.LittleEndian.Uint32(p[i*4:])
+	}
+
+	al, bl, cl, dl, el := d.s[0], d.s[1], d.s[2], d.s[3], d.s[4]
+	ar, br, cr, dr, er := al, bl, cl, dl, el
+
+	for j := 0; j < 80; j++ {
+		round := j / 16
+
+		t := bits.RotateLeft32(al+f(round, bl, cl, dl)+x[rl[j]]+kl[round], int(sl[j])) + el
+		al, el, dl, cl, bl = el, dl, bits.RotateLeft32(cl, 10), bl, t
+
+		t = bits.RotateLeft32(ar+f(4-round, br, cr, dr)+x[rr[j]]+kr[round], int(sr[j])) + er
+		ar, er, dr, cr, br = er, dr, bits.RotateLeft32(cr, 10), br, t
+	}
+
+	t := d.s[1] + cl + dr
+	d.s[1] = d.s[2] + dl + er
+	d.s[2] = d.s[3] + el + ar
+	d.s[3] = d.s[4] + al + br
+	d.s[4] = d.s[0] + bl + cr
+	d.s[0] = t
+}
+
+// f is the boolean function of the round.
+func f(round int, x, y, z uint32) uint32 {
+	switch round {
+	case 0:
+		return x ^ y ^ z
+	case 1:
+		return (x & y) | (^x & z)
+	case 2:
+		return (x | ^y) ^ z
+	case 3:
+		return (x & z) | (y &^ z)
+	default:
+		return x ^ (y | ^z)
+	}
+}
@@ -0,0 +1,58 @@
+package ripemd160
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Sum(t *testing.T) {
+	// test vectors from the RIPEMD-160 specification
+	cc := map[string]string{
+		"":                           "9c1185a5c5e9fc54612808977ee8f548b2258d31",
+		"a":                          "0bdc9d2d256b3ee9daae347be6f4dc835a467ffe",
+		"abc":                        "8eb208f7e05d987a9b044a8e98c6b087f15a0bfc",
+		"message digest":             "5d0689ef49d2fae572b881b123a85ffa21595f36",
+		"abcdefghijklmnopqrstuvwxyz": "f71c27109c692c1b56bbdceb5b9d2865b3708dbc",
+		"abcdbcdecdefdefgefghfghighijhijkijkljklmklmnlmnomnopnopq":       "12a053384a9c0c88e405a06c27dcf49ada62eb2b",
+		"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789": "b0e20b6e3116640286ed3a87a5713079b21f5189",
+		strings.Repeat("1234567890", 8):                                  "9b752e45573d4b39f4dbd3323cab82bf63326bfb",
+		strings.Repeat("a", 1000000):                                     "52783243c1697bdbe16d37f97f68f08325dc1528",
+	}
+
+	for in, out := range cc {
+		sum := Sum([]byte(in))
+		assert.Equal(t, out, hex.EncodeToString(sum[:]), "input of %d bytes", len(in))
+	}
+}
+
+func Test_New(t *testing.T) {
+	in := []byte(strings.Repeat("1234567890", 8))
+
+	// writes split across block boundaries must not change the result
+	for _, n := range []int{1, 3, 55, 56, 63, 64, 65} {
+		h := New()
+
+		for p := in; len(p) > 0; {
+			k := n
+			if k > len(p) {
+				k = len(p)
+			}
+
+			h.Write(p[:k]) //nolint:errcheck // writes never fail
+			p = p[k:]
+		}
+
+		assert.Equal(t, "9b752e45573d4b39f4dbd3323cab82bf63326bfb", hex.EncodeToString(h.Sum(nil)))
+		assert.Equal(t, "9b752e45573d4b39f4dbd3323cab82bf63326bfb", hex.EncodeToString(h.Sum(nil)))
+	}
+
+	h := New()
+	h.Write([]byte("abc")) //nolint:errcheck // writes never fail
+	h.Reset()
+	assert.Equal(t, "9c1185a5c5e9fc54612808977ee8f548b2258d31", hex.EncodeToString(h.Sum(nil)))
+	assert.Equal(t, Size, h.Size())
+	assert.Equal(t, BlockSize, h.BlockSize())
+}
@@ -0,0 +1,138 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// defaultInvoicePageSize is the number of invoices retrieved per page
+// by the invoice iterator.
+const defaultInvoicePageSize = 100
+
+// InvoicesParams holds data used to filter invoices.
+type InvoicesParams struct {
+	DateStart time.Time
+	DateEnd   time.Time
+	Status    string
+	OrderID   string
+	Limit     int
+	Offset    int
+}
+
+// values converts the params to URL query values.
+func (p InvoicesParams) values() url.Values {
+	params := url.Values{}
+
+	if !p.DateStart.IsZero() {
+		params.Set("dateStart", p.DateStart.Format("2006-01-02"))
+	}
+
+	if !p.DateEnd.IsZero() {
+		params.Set("dateEnd", p.DateEnd.Format("2006-01-02"))
+	}
+
+	if p.Status != "" {
+		params.Set("status", p.Status)
+	}
+
+	if p.OrderID != "" {
+		params.Set("orderId", p.OrderID)
+	}
+
+	if p.Limit > 0 {
+		params.Set("limit", strconv.Itoa(p.Limit))
+	}
+
+	if p.Offset > 0 {
+		params.Set("offset", strconv.Itoa(p.Offset))
+	}
+
+	return params
+}
+
+// Invoices retrieves invoices that match the provided params.
+func (c *Client) Invoices(ctx context.Context, p InvoicesParams, opts ...RequestOption) ([]Invoice, error) {
+	resp, err := c.send(ctx, http.MethodGet, newRoute("/invoices"), p.values(), nil, true, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var ii []Invoice
+
+	if err = c.decodeData(resp.Body, &ii); err != nil {
+		return nil, err
+	}
+
+	return ii, nil
+}
+
+// InvoiceIterator iterates over invoices that match the provided params,
+// retrieving them page by page.
+type InvoiceIterator struct {
+	c    *Client
+	ctx  context.Context
+	p    InvoicesParams
+	opts []RequestOption
+
+	page []Invoice
+	inv  Invoice
+	done bool
+	err  error
+}
+
+// InvoiceIterator returns an iterator over invoices that match the
+// provided params. The params limit is used as the page size and
+// defaults to 100; the offset is used as the starting point.
+func (c *Client) InvoiceIterator(ctx context.Context, p InvoicesParams, opts ...RequestOption) *InvoiceIterator {
+	if p.Limit <= 0 {
+		p.Limit = defaultInvoicePageSize
+	}
+
+	return &InvoiceIterator{c: c, ctx: ctx, p: p, opts: opts}
+}
+
+// Next advances the iterator to the next invoice. False is returned once
+// all invoices have been iterated over or an error occurs.
+func (it *InvoiceIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	if len(it.page) == 0 {
+		if it.done {
+			return false
+		}
+
+		it.page, it.err = it.c.Invoices(it.ctx, it.p, it.opts...)
+		if it.err != nil {
+			return false
+		}
+
+		// a short page is the last one
+		it.done = len(it.page) < it.p.Limit
+		it.p.Offset += len(it.page)
+
+		if len(it.page) == 0 {
+			return false
+		}
+	}
+
+	it.inv, it.page = it.page[0], it.page[1:]
+
+	return true
+}
+
+// Invoice returns the current invoice.
+func (it *InvoiceIterator) Invoice() Invoice {
+	return it.inv
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *InvoiceIterator) Err() error {
+	return it.err
+}
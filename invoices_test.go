@@ -0,0 +1,200 @@
+package btcpay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_Invoices(t *testing.T) {
+	checkQuery := func(r *http.Request) error {
+		q := r.URL.Query()
+		if q.Get("dateStart") != "2020-01-01" ||
+			q.Get("dateEnd") != "2020-02-01" ||
+			q.Get("status") != "complete" ||
+			q.Get("orderId") != "o1" ||
+			q.Get("limit") != "10" ||
+			q.Get("offset") != "20" {
+			return errors.New("invalid query params")
+		}
+
+		return nil
+	}
+
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result []Invoice
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := checkQuery(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, "{"), nil
+			},
+			Err: true,
+		},
+		"Successful execution": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := checkQuery(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"data":[{"id":"i1","orderId":"o1"}]}`), nil
+			},
+			Result: []Invoice{{ID: "i1", OrderID: "o1"}},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/invoices", c.Resp)
+
+			res, err := client.Invoices(context.Background(), InvoicesParams{
+				DateStart: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+				DateEnd:   time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC),
+				Status:    "complete",
+				OrderID:   "o1",
+				Limit:     10,
+				Offset:    20,
+			})
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodGet+" http://test.com/invoices"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Nil(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_InvoiceIterator(t *testing.T) {
+	// page returns a response with invoices from the offset in the
+	// query up to the total count
+	page := func(total int, fail bool) httpmock.Responder {
+		return func(r *http.Request) (*http.Response, error) {
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+			if fail && offset > 0 {
+				return nil, assert.AnError
+			}
+
+			var ii []string
+
+			for i := offset; i < total && i < offset+limit; i++ {
+				ii = append(ii, fmt.Sprintf(`{"id":"i%d"}`, i))
+			}
+
+			return httpmock.NewStringResponse(http.StatusOK, `{"data":[`+strings.Join(ii, ",")+`]}`), nil
+		}
+	}
+
+	cc := map[string]struct {
+		Params InvoicesParams
+		Resp   httpmock.Responder
+		Calls  int
+		Result int
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Params: InvoicesParams{Limit: 2},
+			Resp:   page(5, true),
+			Calls:  2,
+			Result: 2,
+			Err:    true,
+		},
+		"No invoices": {
+			Resp:  page(0, false),
+			Calls: 1,
+		},
+		"Last page full": {
+			Params: InvoicesParams{Limit: 2},
+			Resp:   page(4, false),
+			Calls:  3,
+			Result: 4,
+		},
+		"Last page short": {
+			Params: InvoicesParams{Limit: 2},
+			Resp:   page(5, false),
+			Calls:  3,
+			Result: 5,
+		},
+		"Starting offset": {
+			Params: InvoicesParams{Limit: 2, Offset: 3},
+			Resp:   page(5, false),
+			Calls:  2,
+			Result: 2,
+		},
+		"Default page size": {
+			Resp:   page(150, false),
+			Calls:  2,
+			Result: 150,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/invoices", c.Resp)
+
+			it := client.InvoiceIterator(context.Background(), c.Params)
+
+			var ids []string
+
+			for it.Next() {
+				ids = append(ids, it.Invoice().ID)
+			}
+
+			assert.False(t, it.Next())
+			assert.Equal(t, c.Calls, mt.GetTotalCallCount())
+			assert.Len(t, ids, c.Result)
+
+			for i, id := range ids {
+				assert.Equal(t, fmt.Sprintf("i%d", c.Params.Offset+i), id)
+			}
+
+			if c.Err {
+				assert.Error(t, it.Err())
+				return
+			}
+
+			assert.NoError(t, it.Err())
+		})
+	}
+}
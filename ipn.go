@@ -0,0 +1,218 @@
+package btcpay
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// maxIPNBytes is the maximum size of an invoice notification payload.
+const maxIPNBytes = 1 << 20
+
+// DeliveryMode specifies how duplicate invoice notifications are
+// handled.
+type DeliveryMode int
+
+// Available delivery modes.
+const (
+	// AtLeastOnce records an event once it is processed successfully.
+	// Duplicates that arrive while the event is still being processed
+	// are processed again.
+	AtLeastOnce DeliveryMode = iota
+
+	// ExactlyOnce claims an event before it is processed, so that
+	// concurrent duplicates are dropped. The claim is released if the
+	// processing fails, so that the event can be retried.
+	ExactlyOnce
+)
+
+// IPNOption modifies an invoice notification handler.
+type IPNOption func(h *ipnHandler)
+
+// WithWebhookSecret makes the handler verify the BTCPay-Sig header of
+// every notification, an HMAC-SHA256 of the payload keyed with the
+// webhook's secret. Notifications with a missing or invalid signature
+// are rejected with 401 Unauthorized before they are parsed.
+func WithWebhookSecret(secret string) IPNOption {
+	return func(h *ipnHandler) {
+		h.secret = []byte(secret)
+	}
+}
+
+// WithEventStore makes the handler deduplicate events with the store.
+func WithEventStore(s EventStore) IPNOption {
+	return func(h *ipnHandler) {
+		h.store = s
+	}
+}
+
+// WithDeliveryMode sets the delivery mode of deduplicated events.
+// Defaults to AtLeastOnce.
+func WithDeliveryMode(m DeliveryMode) IPNOption {
+	return func(h *ipnHandler) {
+		h.mode = m
+	}
+}
+
+// WithEventKey sets the function that returns the deduplication key of
+// an event. Defaults to EventIDKey.
+func WithEventKey(fn func(ev Event) string) IPNOption {
+	return func(h *ipnHandler) {
+		h.key = fn
+	}
+}
+
+// EventIDKey returns the ID of the event's original delivery, so that
+// both automatic retries and manual redeliveries are deduplicated.
+func EventIDKey(ev Event) string {
+	m := ev.Meta()
+
+	if m.OriginalDeliveryID != "" {
+		return m.OriginalDeliveryID
+	}
+
+	return m.DeliveryID
+}
+
+// InvoiceStatusKey returns the invoice ID and the event type, so that
+// every status change of an invoice is processed once, no matter how
+// many events report it. Payment events include the payment ID, since
+// an invoice may receive multiple payments.
+func InvoiceStatusKey(ev Event) string {
+	m := ev.Meta()
+	key := m.InvoiceID + ":" + string(m.Type)
+
+	switch e := ev.(type) {
+	case *InvoiceReceivedPayment:
+		key += ":" + e.Payment.ID
+	case *InvoicePaymentSettled:
+		key += ":" + e.Payment.ID
+	}
+
+	return key
+}
+
+// ipnHandler handles invoice notifications.
+type ipnHandler struct {
+	fn     func(ctx context.Context, ev Event) error
+	store  EventStore
+	mode   DeliveryMode
+	key    func(ev Event) string
+	secret []byte
+}
+
+// NewIPNHandler creates an HTTP handler that parses invoice
+// notifications and passes them to fn. If fn fails, the server is
+// responded to with an error, so that it retries the delivery.
+//
+// Without WithWebhookSecret the handler accepts any payload, so anyone
+// who can reach it can forge events, e.g. mark an invoice as settled,
+// or claim the deduplication key of a genuine event before it arrives.
+// Unsigned use is only safe if the handler is not publicly reachable or
+// the events are confirmed by retrieving the invoice from the server.
+func NewIPNHandler(fn func(ctx context.Context, ev Event) error, opts ...IPNOption) http.Handler {
+	h := &ipnHandler{
+		fn:  fn,
+		key: EventIDKey,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// ServeHTTP handles a single invoice notification.
+func (h *ipnHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	b, err := ioutil.ReadAll(limitBody(r.Body, maxIPNBytes))
+	if err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	if h.secret != nil && !validWebhookSig(r.Header.Get("BTCPay-Sig"), b, h.secret) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	ev, err := ParseIPN(b)
+	if err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	if err = h.process(r.Context(), ev); err != nil {
+		http.Error(w, "processing failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// validWebhookSig checks whether the BTCPay-Sig header value is the
+// HMAC of the payload.
+func validWebhookSig(sig string, b, secret []byte) bool {
+	if !strings.HasPrefix(sig, "sha256=") {
+		return false
+	}
+
+	mac, err := hex.DecodeString(strings.TrimPrefix(sig, "sha256="))
+	if err != nil {
+		return false
+	}
+
+	h := hmac.New(sha256.New, secret)
+	h.Write(b) //nolint:errcheck // hash writes never fail
+
+	return hmac.Equal(mac, h.Sum(nil))
+}
+
+// process passes the event to the handler's function unless it is a
+// duplicate.
+func (h *ipnHandler) process(ctx context.Context, ev Event) error {
+	if h.store == nil {
+		return h.fn(ctx, ev)
+	}
+
+	key := h.key(ev)
+
+	if h.mode == ExactlyOnce {
+		ok, err := h.store.Add(ctx, key)
+		if err != nil || !ok {
+			return err
+		}
+
+		if err = h.fn(ctx, ev); err != nil {
+			if rerr := h.store.Remove(ctx, key); rerr != nil {
+				return rerr
+			}
+
+			return err
+		}
+
+		return nil
+	}
+
+	ok, err := h.store.Has(ctx, key)
+	if err != nil || ok {
+		return err
+	}
+
+	if err = h.fn(ctx, ev); err != nil {
+		return err
+	}
+
+	_, err = h.store.Add(ctx, key)
+
+	return err
+}
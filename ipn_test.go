@@ -0,0 +1,254 @@
+package btcpay
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_EventIDKey(t *testing.T) {
+	assert.Equal(t, "d1", EventIDKey(&InvoiceCreated{EventMeta{DeliveryID: "d1"}}))
+	assert.Equal(t, "d1", EventIDKey(&InvoiceCreated{EventMeta{DeliveryID: "d2", OriginalDeliveryID: "d1"}}))
+}
+
+func Test_InvoiceStatusKey(t *testing.T) {
+	assert.Equal(t, "i1:InvoiceSettled", InvoiceStatusKey(&InvoiceSettled{
+		EventMeta: EventMeta{InvoiceID: "i1", Type: EventInvoiceSettled},
+	}))
+	assert.Equal(t, "i1:InvoiceReceivedPayment:p1", InvoiceStatusKey(&InvoiceReceivedPayment{
+		EventMeta: EventMeta{InvoiceID: "i1", Type: EventInvoiceReceivedPayment},
+		Payment:   InvoicePayment{ID: "p1"},
+	}))
+	assert.Equal(t, "i1:InvoicePaymentSettled:p1", InvoiceStatusKey(&InvoicePaymentSettled{
+		EventMeta: EventMeta{InvoiceID: "i1", Type: EventInvoicePaymentSettled},
+		Payment:   InvoicePayment{ID: "p1"},
+	}))
+}
+
+func Test_NewIPNHandler(t *testing.T) {
+	s := NewMemoryEventStore(0)
+	h := NewIPNHandler(nil, WithEventStore(s), WithDeliveryMode(ExactlyOnce), WithEventKey(InvoiceStatusKey), WithWebhookSecret("secret")).(*ipnHandler)
+	assert.Equal(t, s, h.store)
+	assert.Equal(t, []byte("secret"), h.secret)
+	assert.Equal(t, ExactlyOnce, h.mode)
+	assert.NotNil(t, h.key)
+}
+
+func Test_ipnHandler_ServeHTTP(t *testing.T) {
+	const payload = `{"deliveryId":"d1","type":"InvoiceSettled","invoiceId":"i1"}`
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(payload)) //nolint:errcheck // hash writes never fail
+	sig := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	cc := map[string]struct {
+		Method   string
+		Body     string
+		Secret   string
+		Sig      string
+		Store    EventStore
+		NoStore  bool
+		Mode     DeliveryMode
+		Seen     bool
+		FnErr    error
+		Code     int
+		Calls    int
+		Recorded bool
+	}{
+		"Invalid method": {
+			Method: http.MethodGet,
+			Body:   payload,
+			Code:   http.StatusMethodNotAllowed,
+		},
+		"Invalid payload": {
+			Method: http.MethodPost,
+			Body:   "{",
+			Code:   http.StatusBadRequest,
+		},
+		"Missing signature": {
+			Method: http.MethodPost,
+			Body:   payload,
+			Secret: "secret",
+			Code:   http.StatusUnauthorized,
+		},
+		"Invalid signature format": {
+			Method: http.MethodPost,
+			Body:   payload,
+			Secret: "secret",
+			Sig:    "sha256=zz",
+			Code:   http.StatusUnauthorized,
+		},
+		"Signature of a different payload": {
+			Method: http.MethodPost,
+			Body:   `{"deliveryId":"d1","type":"InvoiceSettled","invoiceId":"i2"}`,
+			Secret: "secret",
+			Sig:    sig,
+			Code:   http.StatusUnauthorized,
+		},
+		"Signature made with a different secret": {
+			Method: http.MethodPost,
+			Body:   payload,
+			Secret: "other",
+			Sig:    sig,
+			Code:   http.StatusUnauthorized,
+		},
+		"Successful processing with signature": {
+			Method:   http.MethodPost,
+			Body:     payload,
+			Secret:   "secret",
+			Sig:      sig,
+			Code:     http.StatusOK,
+			Calls:    1,
+			Recorded: true,
+		},
+		"Processing failure without store": {
+			Method:  http.MethodPost,
+			NoStore: true,
+			Body:    payload,
+			FnErr:   assert.AnError,
+			Code:    http.StatusInternalServerError,
+			Calls:   1,
+		},
+		"Successful processing without store": {
+			Method:  http.MethodPost,
+			NoStore: true,
+			Body:    payload,
+			Code:    http.StatusOK,
+			Calls:   1,
+		},
+		"At-least-once store error": {
+			Method: http.MethodPost,
+			Body:   payload,
+			Store:  &eventStoreStub{err: assert.AnError},
+			Code:   http.StatusInternalServerError,
+		},
+		"At-least-once duplicate": {
+			Method:   http.MethodPost,
+			Body:     payload,
+			Seen:     true,
+			Code:     http.StatusOK,
+			Recorded: true,
+		},
+		"At-least-once processing failure": {
+			Method: http.MethodPost,
+			Body:   payload,
+			FnErr:  assert.AnError,
+			Code:   http.StatusInternalServerError,
+			Calls:  1,
+		},
+		"At-least-once successful processing": {
+			Method:   http.MethodPost,
+			Body:     payload,
+			Code:     http.StatusOK,
+			Calls:    1,
+			Recorded: true,
+		},
+		"Exactly-once store error": {
+			Method: http.MethodPost,
+			Body:   payload,
+			Store:  &eventStoreStub{err: assert.AnError},
+			Mode:   ExactlyOnce,
+			Code:   http.StatusInternalServerError,
+		},
+		"Exactly-once duplicate": {
+			Method:   http.MethodPost,
+			Body:     payload,
+			Mode:     ExactlyOnce,
+			Seen:     true,
+			Code:     http.StatusOK,
+			Recorded: true,
+		},
+		"Exactly-once processing failure": {
+			Method: http.MethodPost,
+			Body:   payload,
+			Mode:   ExactlyOnce,
+			FnErr:  assert.AnError,
+			Code:   http.StatusInternalServerError,
+			Calls:  1,
+		},
+		"Exactly-once successful processing": {
+			Method:   http.MethodPost,
+			Body:     payload,
+			Mode:     ExactlyOnce,
+			Code:     http.StatusOK,
+			Calls:    1,
+			Recorded: true,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			var opts []IPNOption
+
+			ms := NewMemoryEventStore(0)
+
+			switch {
+			case c.Store != nil:
+				opts = append(opts, WithEventStore(c.Store))
+			case !c.NoStore:
+				opts = append(opts, WithEventStore(ms))
+			}
+
+			if c.Secret != "" {
+				opts = append(opts, WithWebhookSecret(c.Secret))
+			}
+
+			if c.Seen {
+				_, err := ms.Add(context.Background(), "d1")
+				assert.NoError(t, err)
+			}
+
+			var calls int
+
+			h := NewIPNHandler(func(ctx context.Context, ev Event) error {
+				calls++
+
+				assert.Equal(t, "i1", ev.Meta().InvoiceID)
+
+				return c.FnErr
+			}, append(opts, WithDeliveryMode(c.Mode))...)
+
+			req := httptest.NewRequest(c.Method, "/", strings.NewReader(c.Body))
+			if c.Sig != "" {
+				req.Header.Set("BTCPay-Sig", c.Sig)
+			}
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, c.Code, rec.Code)
+			assert.Equal(t, c.Calls, calls)
+
+			ok, err := ms.Has(context.Background(), "d1")
+			assert.NoError(t, err)
+			assert.Equal(t, c.Recorded, ok)
+		})
+	}
+}
+
+type eventStoreStub struct {
+	err error
+}
+
+func (s *eventStoreStub) Add(context.Context, string) (bool, error) {
+	return false, s.err
+}
+
+func (s *eventStoreStub) Has(context.Context, string) (bool, error) {
+	return false, s.err
+}
+
+func (s *eventStoreStub) Remove(context.Context, string) error {
+	return s.err
+}
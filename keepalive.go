@@ -0,0 +1,124 @@
+package btcpay
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// StartKeepalive starts pinging the server's health endpoint in the
+// background at the specified interval, until the context is
+// cancelled. The server is healthy while it responds and is
+// synchronized with its nodes. See Healthy and HealthChanges.
+// An error is returned if the keepalive is already running.
+func (c *Client) StartKeepalive(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return errors.New("keepalive interval must be positive")
+	}
+
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+
+	if c.keepalive {
+		return errors.New("keepalive is already running")
+	}
+
+	c.keepalive = true
+
+	go c.runKeepalive(ctx, interval)
+
+	return nil
+}
+
+// Healthy reports whether the server was healthy at the last keepalive
+// ping. The server is assumed to be healthy until it is pinged.
+func (c *Client) Healthy() bool {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+
+	return !c.unhealthy
+}
+
+// HealthChanges subscribes to changes of the server's health detected
+// by the keepalive. The channel receives the new state on every
+// change; if the subscriber falls behind, only the latest state is
+// kept. The returned function cancels the subscription and closes the
+// channel.
+func (c *Client) HealthChanges() (<-chan bool, func()) {
+	ch := make(chan bool, 1)
+
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+
+	if c.healthSubs == nil {
+		c.healthSubs = make(map[chan bool]struct{})
+	}
+
+	c.healthSubs[ch] = struct{}{}
+
+	return ch, func() {
+		c.healthMu.Lock()
+		defer c.healthMu.Unlock()
+
+		if _, ok := c.healthSubs[ch]; ok {
+			delete(c.healthSubs, ch)
+			close(ch)
+		}
+	}
+}
+
+// runKeepalive pings the server until the context is cancelled.
+func (c *Client) runKeepalive(ctx context.Context, interval time.Duration) {
+	defer func() {
+		c.healthMu.Lock()
+		c.keepalive = false
+		c.healthMu.Unlock()
+	}()
+
+	for {
+		c.setHealthy(c.ping(ctx))
+
+		if sleep(ctx, c.getClock(), interval) != nil {
+			return
+		}
+	}
+}
+
+// ping checks whether the server is healthy. Results of cancelled pings
+// are ignored.
+func (c *Client) ping(ctx context.Context) bool {
+	timeout := c.timeout
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+
+	h, err := c.Health(ctx, WithRequestTimeout(timeout))
+	if ctx.Err() != nil {
+		return c.Healthy()
+	}
+
+	return err == nil && h.Synchronized
+}
+
+// setHealthy records the server's health and notifies the subscribers
+// if it changed.
+func (c *Client) setHealthy(ok bool) {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+
+	if c.unhealthy == !ok {
+		return
+	}
+
+	c.unhealthy = !ok
+
+	for ch := range c.healthSubs {
+		// only the latest state is kept
+		select {
+		case <-ch:
+		default:
+		}
+
+		ch <- ok
+	}
+}
@@ -0,0 +1,109 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tickClock is a clock whose timers fire only when told to.
+type tickClock struct {
+	ticks chan time.Time
+}
+
+func (tc tickClock) Now() time.Time {
+	return time.Now()
+}
+
+func (tc tickClock) After(time.Duration) <-chan time.Time {
+	return tc.ticks
+}
+
+func Test_Client_StartKeepalive(t *testing.T) {
+	var calls int32
+
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/health", func(*http.Request) (*http.Response, error) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 2:
+			return httpmock.NewStringResponse(http.StatusServiceUnavailable, ""), nil
+		case 3:
+			return httpmock.NewStringResponse(http.StatusOK, `{"synchronized":false}`), nil
+		default:
+			return httpmock.NewStringResponse(http.StatusOK, `{"synchronized":true}`), nil
+		}
+	})
+
+	clk := tickClock{ticks: make(chan time.Time)}
+
+	client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}), WithClock(clk))
+	require.NoError(t, err)
+
+	changes, unsubscribe := client.HealthChanges()
+	assert.True(t, client.Healthy())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assert.Error(t, client.StartKeepalive(ctx, 0))
+	require.NoError(t, client.StartKeepalive(ctx, time.Second))
+	assert.Error(t, client.StartKeepalive(ctx, time.Second))
+
+	// the first ping happens right away and the server is healthy
+	clk.ticks <- time.Now()
+
+	assert.False(t, <-changes)
+	assert.False(t, client.Healthy())
+
+	// the server is still not synchronized
+	clk.ticks <- time.Now()
+	clk.ticks <- time.Now()
+
+	assert.True(t, <-changes)
+	assert.True(t, client.Healthy())
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+
+	unsubscribe()
+	unsubscribe()
+
+	_, ok := <-changes
+	assert.False(t, ok)
+
+	cancel()
+
+	// the keepalive can be started again once it stops
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+
+	assert.Eventually(t, func() bool {
+		return client.StartKeepalive(ctx, time.Second) == nil
+	}, time.Second, time.Millisecond*10)
+}
+
+func Test_Client_setHealthy(t *testing.T) {
+	client := &Client{}
+
+	changes, unsubscribe := client.HealthChanges()
+	defer unsubscribe()
+
+	client.setHealthy(true)
+	client.setHealthy(false)
+	client.setHealthy(true)
+	client.setHealthy(false)
+
+	// only the latest state is kept
+	assert.False(t, <-changes)
+	assert.False(t, client.Healthy())
+
+	select {
+	case <-changes:
+		assert.Fail(t, "unexpected change")
+	default:
+	}
+}
@@ -0,0 +1,75 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Ledger holds the balance of a single currency ledger.
+type Ledger struct {
+	Currency string          `json:"currency"`
+	Balance  decimal.Decimal `json:"balance"`
+}
+
+// LedgerEntry holds data of a single ledger entry.
+// More at: https://bitpay.com/api/#rest-api-resources-ledgers
+type LedgerEntry struct {
+	ID                  string          `json:"id"`
+	Type                string          `json:"type"`
+	Amount              decimal.Decimal `json:"amount"`
+	Code                int64           `json:"code"`
+	Description         string          `json:"description"`
+	Timestamp           time.Time       `json:"timestamp"`
+	TxType              string          `json:"txType"`
+	Scale               int64           `json:"scale"`
+	InvoiceID           string          `json:"invoiceId"`
+	Buyer               InvoiceBuyer    `json:"buyerFields"`
+	InvoiceAmount       decimal.Decimal `json:"invoiceAmount"`
+	InvoiceCurrency     string          `json:"invoiceCurrency"`
+	TransactionCurrency string          `json:"transactionCurrency"`
+}
+
+// Ledgers retrieves balances of all ledgers.
+func (c *Client) Ledgers(ctx context.Context, opts ...RequestOption) ([]Ledger, error) {
+	resp, err := c.send(ctx, http.MethodGet, newRoute("/ledgers"), nil, nil, true, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var ll []Ledger
+
+	if err = c.decodeData(resp.Body, &ll); err != nil {
+		return nil, err
+	}
+
+	return ll, nil
+}
+
+// LedgerEntries retrieves entries of the specified currency ledger that
+// were recorded within the provided date range.
+func (c *Client) LedgerEntries(ctx context.Context, currency string, dateStart, dateEnd time.Time, opts ...RequestOption) ([]LedgerEntry, error) {
+	params := url.Values{}
+	params.Set("startDate", dateStart.Format("2006-01-02"))
+	params.Set("endDate", dateEnd.Format("2006-01-02"))
+
+	resp, err := c.send(ctx, http.MethodGet, newRoute("/ledgers/:id", currency), params, nil, true, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var ee []LedgerEntry
+
+	if err = c.decodeData(resp.Body, &ee); err != nil {
+		return nil, err
+	}
+
+	return ee, nil
+}
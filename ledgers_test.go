@@ -0,0 +1,140 @@
+package btcpay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_Ledgers(t *testing.T) {
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result []Ledger
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, "{"),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `{"data":[{"currency":"BTC","balance":1.5}]}`),
+			Result: []Ledger{{Currency: "BTC", Balance: decimal.RequireFromString("1.5")}},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/ledgers", c.Resp)
+
+			ll, err := client.Ledgers(context.Background())
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodGet+" http://test.com/ledgers"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Nil(t, ll)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, ll)
+		})
+	}
+}
+
+func Test_Client_LedgerEntries(t *testing.T) {
+	checkQuery := func(r *http.Request) error {
+		if r.URL.Query().Get("startDate") != "2020-01-01" ||
+			r.URL.Query().Get("endDate") != "2020-02-01" {
+			return errors.New("invalid query params")
+		}
+
+		return nil
+	}
+
+	cc := map[string]struct {
+		Currency string
+		Resp     httpmock.Responder
+		Result   []LedgerEntry
+		Err      bool
+	}{
+		"Error returned during request sending": {
+			Currency: "BTC",
+			Resp:     httpmock.NewErrorResponder(assert.AnError),
+			Err:      true,
+		},
+		"Invalid response body": {
+			Currency: "BTC",
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := checkQuery(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, "{"), nil
+			},
+			Err: true,
+		},
+		"Successful execution": {
+			Currency: "BTC",
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := checkQuery(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"data":[{"id":"e1","code":1000,"invoiceId":"i1"}]}`), nil
+			},
+			Result: []LedgerEntry{{ID: "e1", Code: 1000, InvoiceID: "i1"}},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/ledgers/"+c.Currency, c.Resp)
+
+			ee, err := client.LedgerEntries(
+				context.Background(),
+				c.Currency,
+				time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC),
+			)
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodGet+" http://test.com/ledgers/"+c.Currency])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Nil(t, ee)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, ee)
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/shopspring/decimal"
+)
+
+// LightningClient provides access to a Lightning Network node that is
+// used by the BTCPay server. All amounts are specified in millisatoshis.
+// More at: https://docs.btcpayserver.org/API/Greenfield/v1/#tag/Lightning-(Internal-Node)
+type LightningClient struct {
+	c        *Client
+	endpoint route
+}
+
+// ServerLightning returns a client of the internal Lightning node of the
+// specified cryptocurrency.
+func (c *Client) ServerLightning(cryptoCode string) *LightningClient {
+	return &LightningClient{
+		c:        c,
+		endpoint: newRoute("/api/v1/server/lightning/:id", cryptoCode),
+	}
+}
+
+// StoreLightning returns a client of the Lightning node configured for
+// the specified store and cryptocurrency.
+func (c *Client) StoreLightning(storeID, cryptoCode string) *LightningClient {
+	return &LightningClient{
+		c:        c,
+		endpoint: newRoute("/api/v1/stores/:id/lightning/:id", storeID, cryptoCode),
+	}
+}
+
+// LightningNodeInfo holds information about a Lightning node.
+type LightningNodeInfo struct {
+	NodeURIs              []string `json:"nodeURIs"`
+	BlockHeight           int64    `json:"blockHeight"`
+	Alias                 string   `json:"alias"`
+	Color                 string   `json:"color"`
+	Version               string   `json:"version"`
+	PeersCount            int64    `json:"peersCount"`
+	ActiveChannelsCount   int64    `json:"activeChannelsCount"`
+	InactiveChannelsCount int64    `json:"inactiveChannelsCount"`
+	PendingChannelsCount  int64    `json:"pendingChannelsCount"`
+}
+
+// LightningChannel holds data of a single Lightning channel.
+type LightningChannel struct {
+	RemoteNode   string          `json:"remoteNode"`
+	IsPublic     bool            `json:"isPublic"`
+	IsActive     bool            `json:"isActive"`
+	Capacity     decimal.Decimal `json:"capacity"`
+	LocalBalance decimal.Decimal `json:"localBalance"`
+	ChannelPoint string          `json:"channelPoint"`
+}
+
+// OpenChannelParams holds data used to open a new Lightning channel.
+type OpenChannelParams struct {
+	NodeURI       string          `json:"nodeURI"`
+	ChannelAmount decimal.Decimal `json:"channelAmount"`
+	FeeRate       int64           `json:"feeRate,omitempty"`
+}
+
+// CreateLightningInvoiceParams holds data used to create a new
+// Lightning invoice.
+type CreateLightningInvoiceParams struct {
+	Amount              decimal.Decimal `json:"amount"`
+	Description         string          `json:"description,omitempty"`
+	DescriptionHashOnly bool            `json:"descriptionHashOnly,omitempty"`
+	Expiry              int64           `json:"expiry"`
+	PrivateRouteHints   bool            `json:"privateRouteHints,omitempty"`
+}
+
+// LightningInvoice holds Lightning invoice data.
+type LightningInvoice struct {
+	ID             string          `json:"id"`
+	Status         string          `json:"status"`
+	BOLT11         string          `json:"BOLT11"`
+	PaidAt         int64           `json:"paidAt"`
+	ExpiresAt      int64           `json:"expiresAt"`
+	Amount         decimal.Decimal `json:"amount"`
+	AmountReceived decimal.Decimal `json:"amountReceived"`
+	PaymentHash    string          `json:"paymentHash"`
+	Preimage       string          `json:"preimage"`
+}
+
+// PayLightningInvoiceParams holds data used to pay a BOLT11 invoice.
+// Amount must be set only if the invoice does not specify it.
+type PayLightningInvoiceParams struct {
+	BOLT11        string           `json:"BOLT11"`
+	Amount        *decimal.Decimal `json:"amount,omitempty"`
+	MaxFeePercent *decimal.Decimal `json:"maxFeePercent,omitempty"`
+	MaxFeeFlat    *decimal.Decimal `json:"maxFeeFlat,omitempty"`
+	SendTimeout   int64            `json:"sendTimeout,omitempty"`
+}
+
+// LightningPayment holds data of a Lightning payment sent by the node.
+type LightningPayment struct {
+	ID          string          `json:"id"`
+	Status      string          `json:"status"`
+	BOLT11      string          `json:"BOLT11"`
+	PaymentHash string          `json:"paymentHash"`
+	Preimage    string          `json:"preimage"`
+	CreatedAt   int64           `json:"createdAt"`
+	TotalAmount decimal.Decimal `json:"totalAmount"`
+	FeeAmount   decimal.Decimal `json:"feeAmount"`
+}
+
+// Info retrieves information about the Lightning node.
+func (lc *LightningClient) Info(ctx context.Context, opts ...RequestOption) (LightningNodeInfo, error) {
+	resp, err := lc.c.sendAPI(ctx, http.MethodGet, lc.endpoint.join("/info"), nil, nil, opts...)
+	if err != nil {
+		return LightningNodeInfo{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var info LightningNodeInfo
+
+	if err = lc.c.decode(resp.Body, &info); err != nil {
+		return LightningNodeInfo{}, err
+	}
+
+	return info, nil
+}
+
+// Channels retrieves all channels of the Lightning node.
+func (lc *LightningClient) Channels(ctx context.Context, opts ...RequestOption) ([]LightningChannel, error) {
+	resp, err := lc.c.sendAPI(ctx, http.MethodGet, lc.endpoint.join("/channels"), nil, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var chs []LightningChannel
+
+	if err = lc.c.decode(resp.Body, &chs); err != nil {
+		return nil, err
+	}
+
+	return chs, nil
+}
+
+// OpenChannel opens a new channel with the specified remote node.
+func (lc *LightningClient) OpenChannel(ctx context.Context, p OpenChannelParams, opts ...RequestOption) error {
+	resp, err := lc.c.sendAPI(ctx, http.MethodPost, lc.endpoint.join("/channels"), nil, p, opts...)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// CreateInvoice creates a new Lightning invoice.
+func (lc *LightningClient) CreateInvoice(ctx context.Context, p CreateLightningInvoiceParams, opts ...RequestOption) (LightningInvoice, error) {
+	resp, err := lc.c.sendAPI(ctx, http.MethodPost, lc.endpoint.join("/invoices"), nil, p, opts...)
+	if err != nil {
+		return LightningInvoice{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var inv LightningInvoice
+
+	if err = lc.c.decode(resp.Body, &inv); err != nil {
+		return LightningInvoice{}, err
+	}
+
+	return inv, nil
+}
+
+// Invoice retrieves a Lightning invoice by the provided ID.
+func (lc *LightningClient) Invoice(ctx context.Context, id string, opts ...RequestOption) (LightningInvoice, error) {
+	resp, err := lc.c.sendAPI(ctx, http.MethodGet, lc.endpoint.join("/invoices/:id", id), nil, nil, opts...)
+	if err != nil {
+		return LightningInvoice{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var inv LightningInvoice
+
+	if err = lc.c.decode(resp.Body, &inv); err != nil {
+		return LightningInvoice{}, err
+	}
+
+	return inv, nil
+}
+
+// PayInvoice pays the provided BOLT11 invoice.
+func (lc *LightningClient) PayInvoice(ctx context.Context, p PayLightningInvoiceParams, opts ...RequestOption) (LightningPayment, error) {
+	resp, err := lc.c.sendAPI(ctx, http.MethodPost, lc.endpoint.join("/invoices/pay"), nil, p, opts...)
+	if err != nil {
+		return LightningPayment{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var pm LightningPayment
+
+	if err = lc.c.decode(resp.Body, &pm); err != nil {
+		return LightningPayment{}, err
+	}
+
+	return pm, nil
+}
+
+// Payment retrieves a payment sent by the node by its payment hash.
+func (lc *LightningClient) Payment(ctx context.Context, paymentHash string, opts ...RequestOption) (LightningPayment, error) {
+	resp, err := lc.c.sendAPI(ctx, http.MethodGet, lc.endpoint.join("/payments/:id", paymentHash), nil, nil, opts...)
+	if err != nil {
+		return LightningPayment{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var pm LightningPayment
+
+	if err = lc.c.decode(resp.Body, &pm); err != nil {
+		return LightningPayment{}, err
+	}
+
+	return pm, nil
+}
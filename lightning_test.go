@@ -0,0 +1,411 @@
+package btcpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_ServerLightning_Info(t *testing.T) {
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result LightningNodeInfo
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, "{"),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `{"alias":"node1","blockHeight":100}`),
+			Result: LightningNodeInfo{Alias: "node1", BlockHeight: 100},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/server/lightning/BTC/info", c.Resp)
+
+			res, err := client.ServerLightning("BTC").Info(context.Background())
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodGet+" http://test.com/api/v1/server/lightning/BTC/info"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_StoreLightning_Channels(t *testing.T) {
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result []LightningChannel
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, "["),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `[{"remoteNode":"n1","capacity":"1000"}]`),
+			Result: []LightningChannel{{RemoteNode: "n1", Capacity: decimal.NewFromInt(1000)}},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/stores/s1/lightning/BTC/channels", c.Resp)
+
+			res, err := client.StoreLightning("s1", "BTC").Channels(context.Background())
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodGet+" http://test.com/api/v1/stores/s1/lightning/BTC/channels"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Nil(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_StoreLightning_OpenChannel(t *testing.T) {
+	check := func(r *http.Request) error {
+		var p OpenChannelParams
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			return err
+		}
+
+		if p.NodeURI != "n1@host" {
+			return errors.New("invalid body")
+		}
+
+		return nil
+	}
+
+	cc := map[string]struct {
+		Resp httpmock.Responder
+		Err  bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, ""), nil
+			},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/stores/s1/lightning/BTC/channels", c.Resp)
+
+			err = client.StoreLightning("s1", "BTC").OpenChannel(context.Background(), OpenChannelParams{NodeURI: "n1@host"})
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodPost+" http://test.com/api/v1/stores/s1/lightning/BTC/channels"])
+
+			if c.Err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_Client_StoreLightning_CreateInvoice(t *testing.T) {
+	check := func(r *http.Request) error {
+		var p CreateLightningInvoiceParams
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			return err
+		}
+
+		if p.Description != "d1" {
+			return errors.New("invalid body")
+		}
+
+		return nil
+	}
+
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result LightningInvoice
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, "{"), nil
+			},
+			Err: true,
+		},
+		"Successful execution": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"id":"i1","BOLT11":"lnbc1"}`), nil
+			},
+			Result: LightningInvoice{ID: "i1", BOLT11: "lnbc1"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/stores/s1/lightning/BTC/invoices", c.Resp)
+
+			res, err := client.StoreLightning("s1", "BTC").CreateInvoice(context.Background(), CreateLightningInvoiceParams{Description: "d1"})
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodPost+" http://test.com/api/v1/stores/s1/lightning/BTC/invoices"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_StoreLightning_Invoice(t *testing.T) {
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result LightningInvoice
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, "{"),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `{"id":"i1","status":"Paid"}`),
+			Result: LightningInvoice{ID: "i1", Status: "Paid"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/stores/s1/lightning/BTC/invoices/i1", c.Resp)
+
+			res, err := client.StoreLightning("s1", "BTC").Invoice(context.Background(), "i1")
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodGet+" http://test.com/api/v1/stores/s1/lightning/BTC/invoices/i1"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_StoreLightning_PayInvoice(t *testing.T) {
+	check := func(r *http.Request) error {
+		var p PayLightningInvoiceParams
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			return err
+		}
+
+		if p.BOLT11 != "lnbc1" {
+			return errors.New("invalid body")
+		}
+
+		return nil
+	}
+
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result LightningPayment
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, "{"), nil
+			},
+			Err: true,
+		},
+		"Successful execution": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"id":"p1","status":"Complete"}`), nil
+			},
+			Result: LightningPayment{ID: "p1", Status: "Complete"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/stores/s1/lightning/BTC/invoices/pay", c.Resp)
+
+			res, err := client.StoreLightning("s1", "BTC").PayInvoice(context.Background(), PayLightningInvoiceParams{BOLT11: "lnbc1"})
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodPost+" http://test.com/api/v1/stores/s1/lightning/BTC/invoices/pay"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_StoreLightning_Payment(t *testing.T) {
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result LightningPayment
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, "{"),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `{"id":"p1","paymentHash":"h1"}`),
+			Result: LightningPayment{ID: "p1", PaymentHash: "h1"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/stores/s1/lightning/BTC/payments/h1", c.Resp)
+
+			res, err := client.StoreLightning("s1", "BTC").Payment(context.Background(), "h1")
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodGet+" http://test.com/api/v1/stores/s1/lightning/BTC/payments/h1"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
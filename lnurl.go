@@ -0,0 +1,318 @@
+package btcpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/bech32"
+)
+
+// LNURLPay holds the parameters of an LNURL-pay service, e.g. the one
+// behind a Lightning Address. All amounts are specified in
+// millisatoshis.
+// More at: https://github.com/lnurl/luds/blob/luds/06.md
+type LNURLPay struct {
+	Callback       string `json:"callback"`
+	MinSendable    int64  `json:"minSendable"`
+	MaxSendable    int64  `json:"maxSendable"`
+	Metadata       string `json:"metadata"`
+	Tag            string `json:"tag"`
+	CommentAllowed int    `json:"commentAllowed"`
+}
+
+// PullPaymentLNURL holds the LNURL-withdraw link of a pull payment.
+type PullPaymentLNURL struct {
+	LNURLBech32 string `json:"lnurlBech32"`
+	LNURLURI    string `json:"lnurlUri"`
+}
+
+// lnurlResponse holds the common fields of LNURL service responses.
+type lnurlResponse struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// ResolveLNURLPay retrieves the parameters of the LNURL-pay service
+// specified by a Lightning Address (user@domain), a bech32 encoded
+// LNURL or a plain URL.
+func (c *Client) ResolveLNURLPay(ctx context.Context, target string, opts ...RequestOption) (LNURLPay, error) {
+	u, err := lnurlTarget(target)
+	if err != nil {
+		return LNURLPay{}, err
+	}
+
+	var lp LNURLPay
+
+	if err = c.lnurlGet(ctx, u, &lp, opts...); err != nil {
+		return LNURLPay{}, err
+	}
+
+	if lp.Tag != "payRequest" {
+		return LNURLPay{}, fmt.Errorf("unexpected LNURL tag %q", lp.Tag)
+	}
+
+	if lp.Callback == "" {
+		return LNURLPay{}, errors.New("LNURL callback is missing")
+	}
+
+	return lp, nil
+}
+
+// LNURLPayInvoice resolves the LNURL-pay service specified by the
+// target and requests a BOLT11 invoice of the provided amount (in
+// millisatoshis) from it. The comment is sent only if the service
+// accepts comments.
+func (c *Client) LNURLPayInvoice(ctx context.Context, target string, amount int64, comment string, opts ...RequestOption) (string, error) {
+	lp, err := c.ResolveLNURLPay(ctx, target, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	if amount < lp.MinSendable || amount > lp.MaxSendable {
+		return "", fmt.Errorf("amount must be between %d and %d millisatoshis", lp.MinSendable, lp.MaxSendable)
+	}
+
+	u, err := url.Parse(lp.Callback)
+	if err != nil {
+		return "", err
+	}
+
+	params := u.Query()
+	params.Set("amount", strconv.FormatInt(amount, 10))
+
+	if comment != "" && lp.CommentAllowed > 0 {
+		if len(comment) > lp.CommentAllowed {
+			comment = comment[:lp.CommentAllowed]
+		}
+
+		params.Set("comment", comment)
+	}
+
+	u.RawQuery = params.Encode()
+
+	var res struct {
+		PR string `json:"pr"`
+	}
+
+	if err = c.lnurlGet(ctx, u.String(), &res, opts...); err != nil {
+		return "", err
+	}
+
+	if res.PR == "" {
+		return "", errors.New("LNURL invoice is missing")
+	}
+
+	return res.PR, nil
+}
+
+// PullPaymentLNURL retrieves the LNURL-withdraw link of the specified
+// pull payment.
+func (c *Client) PullPaymentLNURL(ctx context.Context, pullPaymentID string, opts ...RequestOption) (PullPaymentLNURL, error) {
+	resp, err := c.sendAPI(ctx, http.MethodGet, newRoute("/api/v1/pull-payments/:id/lnurl", pullPaymentID), nil, nil, opts...)
+	if err != nil {
+		return PullPaymentLNURL{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var l PullPaymentLNURL
+
+	if err = c.decode(resp.Body, &l); err != nil {
+		return PullPaymentLNURL{}, err
+	}
+
+	return l, nil
+}
+
+// CreateLNURLWithdraw creates a new pull payment in the specified store
+// and retrieves its LNURL-withdraw link. The pull payment is limited to
+// Lightning payouts if no payment methods are specified.
+func (c *Client) CreateLNURLWithdraw(ctx context.Context, storeID string, p CreatePullPaymentParams, opts ...RequestOption) (PullPayment, PullPaymentLNURL, error) {
+	if len(p.PaymentMethods) == 0 {
+		p.PaymentMethods = []string{"BTC-LightningNetwork"}
+	}
+
+	pp, err := c.CreatePullPayment(ctx, storeID, p, opts...)
+	if err != nil {
+		return PullPayment{}, PullPaymentLNURL{}, err
+	}
+
+	l, err := c.PullPaymentLNURL(ctx, pp.ID, opts...)
+	if err != nil {
+		return PullPayment{}, PullPaymentLNURL{}, err
+	}
+
+	return pp, l, nil
+}
+
+// lnurlGet retrieves and decodes the response of an LNURL service.
+// Unlike the BTCPay API requests, it is sent without authentication and
+// bypasses the rate limiter and circuit breaker.
+func (c *Client) lnurlGet(ctx context.Context, u string, out interface{}, opts ...RequestOption) error {
+	o := newRequestOptions(opts)
+	if o.timeout <= 0 {
+		o.timeout = c.timeout
+	}
+
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.header["User-Agent"])
+
+	for k, v := range o.header {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	d, err := ioutil.ReadAll(limitBody(resp.Body, c.maxResponseBytes))
+	if err != nil {
+		return err
+	}
+
+	var lr lnurlResponse
+
+	if err = json.Unmarshal(d, &lr); err != nil {
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("LNURL service error: %s", http.StatusText(resp.StatusCode))
+		}
+
+		return err
+	}
+
+	if strings.EqualFold(lr.Status, "ERROR") {
+		return fmt.Errorf("LNURL service error: %s", lr.Reason)
+	}
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("LNURL service error: %s", http.StatusText(resp.StatusCode))
+	}
+
+	return json.Unmarshal(d, out)
+}
+
+// lnurlTarget converts a Lightning Address, bech32 encoded LNURL or
+// lightning: URI into the URL of the LNURL service.
+func lnurlTarget(target string) (string, error) {
+	target = strings.TrimSpace(target)
+
+	if len(target) > 10 && strings.EqualFold(target[:10], "lightning:") {
+		target = target[10:]
+	}
+
+	if i := strings.LastIndexByte(target, '@'); i > 0 && !strings.Contains(target, "/") {
+		user, domain := target[:i], strings.ToLower(target[i+1:])
+		if domain == "" {
+			return "", errors.New("invalid Lightning Address")
+		}
+
+		scheme := "https"
+		if strings.HasSuffix(domain, ".onion") {
+			scheme = "http"
+		}
+
+		return scheme + "://" + domain + "/.well-known/lnurlp/" + url.PathEscape(user), nil
+	}
+
+	if strings.HasPrefix(strings.ToLower(target), "lnurl1") {
+		return decodeLNURL(target)
+	}
+
+	if validURL(target) {
+		return target, nil
+	}
+
+	return "", errors.New("invalid LNURL target")
+}
+
+// decodeLNURL decodes a bech32 encoded LNURL. LNURLs are usually longer
+// than the 90 characters allowed by bech32, so its length is not
+// checked.
+func decodeLNURL(s string) (string, error) {
+	s = strings.ToLower(s)
+
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 1 || sep+7 > len(s) {
+		return "", errors.New("invalid LNURL")
+	}
+
+	const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+	data := make([]byte, len(s)-sep-1)
+
+	for i, ch := range s[sep+1:] {
+		j := strings.IndexRune(charset, ch)
+		if j < 0 {
+			return "", errors.New("invalid LNURL character")
+		}
+
+		data[i] = byte(j)
+	}
+
+	if bech32Polymod(s[:sep], data) != 1 {
+		return "", errors.New("invalid LNURL checksum")
+	}
+
+	b, err := bech32.ConvertBits(data[:len(data)-6], 5, 8, false)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// bech32Polymod calculates the bech32 checksum of the human-readable
+// part and data.
+func bech32Polymod(hrp string, data []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+
+	step := func(v byte) {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+
+	for i := 0; i < len(hrp); i++ {
+		step(hrp[i] >> 5)
+	}
+
+	step(0)
+
+	for i := 0; i < len(hrp); i++ {
+		step(hrp[i] & 31)
+	}
+
+	for _, v := range data {
+		step(v)
+	}
+
+	return chk
+}
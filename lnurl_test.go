@@ -0,0 +1,379 @@
+package btcpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/jarcoal/httpmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeLNURL(t *testing.T, u string) string {
+	t.Helper()
+
+	d, err := bech32.ConvertBits([]byte(u), 8, 5, true)
+	require.NoError(t, err)
+
+	s, err := bech32.Encode("lnurl", d)
+	require.NoError(t, err)
+
+	return strings.ToUpper(s)
+}
+
+func Test_lnurlTarget(t *testing.T) {
+	longURL := "https://test.com/lnurlp/" + strings.Repeat("a", 100)
+
+	cc := map[string]struct {
+		Target string
+		Result string
+		Err    bool
+	}{
+		"Invalid target": {
+			Target: "test",
+			Err:    true,
+		},
+		"Invalid Lightning Address": {
+			Target: "user@",
+			Err:    true,
+		},
+		"Invalid LNURL": {
+			Target: "lnurl1qqqqqqqqq",
+			Err:    true,
+		},
+		"Lightning Address": {
+			Target: " user@Test.com ",
+			Result: "https://test.com/.well-known/lnurlp/user",
+		},
+		"Onion Lightning Address": {
+			Target: "user@test.onion",
+			Result: "http://test.onion/.well-known/lnurlp/user",
+		},
+		"Bech32 LNURL": {
+			Target: encodeLNURL(t, longURL),
+			Result: longURL,
+		},
+		"Lightning URI": {
+			Target: "lightning:" + encodeLNURL(t, longURL),
+			Result: longURL,
+		},
+		"Plain URL": {
+			Target: "https://test.com/lnurlp/1",
+			Result: "https://test.com/lnurlp/1",
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			res, err := lnurlTarget(c.Target)
+			if c.Err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_decodeLNURL(t *testing.T) {
+	s := encodeLNURL(t, "https://test.com/lnurlp/1")
+
+	_, err := decodeLNURL(s[:len(s)-1] + "Q")
+	assert.Error(t, err)
+
+	_, err = decodeLNURL(s[:10] + "b" + s[11:])
+	assert.Error(t, err)
+
+	res, err := decodeLNURL(s)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://test.com/lnurlp/1", res)
+}
+
+func Test_Client_ResolveLNURLPay(t *testing.T) {
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result LNURLPay
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, "{"),
+			Err:  true,
+		},
+		"Error status code": {
+			Resp: httpmock.NewStringResponder(http.StatusNotFound, "not found"),
+			Err:  true,
+		},
+		"Error response": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, `{"status":"ERROR","reason":"unknown user"}`),
+			Err:  true,
+		},
+		"Invalid tag": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, `{"tag":"withdrawRequest","callback":"https://test.com/cb"}`),
+			Err:  true,
+		},
+		"Missing callback": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, `{"tag":"payRequest"}`),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, `{"tag":"payRequest","callback":"https://test.com/cb","minSendable":1000,"maxSendable":2000,"metadata":"[]","commentAllowed":10}`),
+			Result: LNURLPay{
+				Callback:       "https://test.com/cb",
+				MinSendable:    1000,
+				MaxSendable:    2000,
+				Metadata:       "[]",
+				Tag:            "payRequest",
+				CommentAllowed: 10,
+			},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://btcpay.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "https://test.com/.well-known/lnurlp/user", c.Resp)
+
+			res, err := client.ResolveLNURLPay(context.Background(), "user@test.com")
+
+			assert.Equal(t, 1, mt.GetTotalCallCount())
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_LNURLPayInvoice(t *testing.T) {
+	cc := map[string]struct {
+		Amount  int64
+		Comment string
+		Query   string
+		Resp    httpmock.Responder
+		Result  string
+		Err     bool
+	}{
+		"Amount too small": {
+			Amount: 999,
+			Err:    true,
+		},
+		"Amount too large": {
+			Amount: 2001,
+			Err:    true,
+		},
+		"Error returned during request sending": {
+			Amount: 1000,
+			Query:  "amount=1000&k=v",
+			Resp:   httpmock.NewErrorResponder(assert.AnError),
+			Err:    true,
+		},
+		"Error response": {
+			Amount: 1000,
+			Query:  "amount=1000&k=v",
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `{"status":"ERROR","reason":"test"}`),
+			Err:    true,
+		},
+		"Missing invoice": {
+			Amount: 1000,
+			Query:  "amount=1000&k=v",
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `{"routes":[]}`),
+			Err:    true,
+		},
+		"Successful execution": {
+			Amount:  2000,
+			Comment: "thanks for all the fish",
+			Query:   "amount=2000&comment=thanks+for&k=v",
+			Resp:    httpmock.NewStringResponder(http.StatusOK, `{"pr":"lnbc1","routes":[]}`),
+			Result:  "lnbc1",
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://btcpay.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "https://test.com/lnurlp/1", httpmock.NewStringResponder(http.StatusOK,
+				`{"tag":"payRequest","callback":"https://test.com/cb?k=v","minSendable":1000,"maxSendable":2000,"commentAllowed":10}`))
+
+			if c.Resp != nil {
+				mt.RegisterResponderWithQuery(http.MethodGet, "https://test.com/cb", c.Query, c.Resp)
+			}
+
+			res, err := client.LNURLPayInvoice(context.Background(), "https://test.com/lnurlp/1", c.Amount, c.Comment)
+
+			if c.Resp != nil {
+				assert.Equal(t, 2, mt.GetTotalCallCount())
+			} else {
+				assert.Equal(t, 1, mt.GetTotalCallCount())
+			}
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_PullPaymentLNURL(t *testing.T) {
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result PullPaymentLNURL
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, "{"),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `{"lnurlBech32":"LNURL1","lnurlUri":"lnurlw://test.com"}`),
+			Result: PullPaymentLNURL{LNURLBech32: "LNURL1", LNURLURI: "lnurlw://test.com"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/pull-payments/pp1/lnurl", c.Resp)
+
+			res, err := client.PullPaymentLNURL(context.Background(), "pp1")
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodGet+" http://test.com/api/v1/pull-payments/pp1/lnurl"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_CreateLNURLWithdraw(t *testing.T) {
+	checkBody := func(r *http.Request) error {
+		var p CreatePullPaymentParams
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			return err
+		}
+
+		if len(p.PaymentMethods) != 1 || p.PaymentMethods[0] != "BTC-LightningNetwork" {
+			return errors.New("invalid body")
+		}
+
+		return nil
+	}
+
+	cc := map[string]struct {
+		CreateResp httpmock.Responder
+		LNURLResp  httpmock.Responder
+		Result     PullPaymentLNURL
+		Err        bool
+	}{
+		"Error returned during pull payment creation": {
+			CreateResp: httpmock.NewErrorResponder(assert.AnError),
+			Err:        true,
+		},
+		"Error returned during LNURL retrieval": {
+			CreateResp: httpmock.NewStringResponder(http.StatusOK, `{"id":"pp1"}`),
+			LNURLResp:  httpmock.NewErrorResponder(assert.AnError),
+			Err:        true,
+		},
+		"Successful execution": {
+			CreateResp: func(r *http.Request) (*http.Response, error) {
+				if err := checkBody(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"id":"pp1"}`), nil
+			},
+			LNURLResp: httpmock.NewStringResponder(http.StatusOK, `{"lnurlBech32":"LNURL1"}`),
+			Result:    PullPaymentLNURL{LNURLBech32: "LNURL1"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/stores/s1/pull-payments", c.CreateResp)
+
+			if c.LNURLResp != nil {
+				mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/pull-payments/pp1/lnurl", c.LNURLResp)
+			}
+
+			pp, l, err := client.CreateLNURLWithdraw(context.Background(), "s1", CreatePullPaymentParams{
+				Currency: "BTC",
+				Amount:   decimal.NewFromInt(1),
+			})
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, pp)
+				assert.Zero(t, l)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "pp1", pp.ID)
+			assert.Equal(t, c.Result, l)
+		})
+	}
+}
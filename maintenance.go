@@ -0,0 +1,83 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// InvoiceStatus specifies the status of a Greenfield invoice.
+type InvoiceStatus string
+
+// Available invoice statuses.
+const (
+	InvoiceStatusNew        InvoiceStatus = "New"
+	InvoiceStatusProcessing InvoiceStatus = "Processing"
+	InvoiceStatusExpired    InvoiceStatus = "Expired"
+	InvoiceStatusInvalid    InvoiceStatus = "Invalid"
+	InvoiceStatusSettled    InvoiceStatus = "Settled"
+)
+
+// ExpireStaleStoreInvoices archives invoices of the store that were
+// created more than olderThan ago and have one of the provided statuses
+// (new invoices if none are provided). New invoices are marked as
+// invalid first, so that they cannot be paid later. Invoices are
+// processed one by one, respecting the client's rate limits; processing
+// stops on the first error. Invoices that were expired before the error
+// occurred are returned as well.
+func (c *Client) ExpireStaleStoreInvoices(ctx context.Context, storeID string, olderThan time.Duration, statuses []InvoiceStatus, opts ...RequestOption) ([]StoreInvoice, error) {
+	if len(statuses) == 0 {
+		statuses = []InvoiceStatus{InvoiceStatusNew}
+	}
+
+	cutoff := c.getClock().Now().Add(-olderThan).Unix()
+
+	params := url.Values{}
+	params.Set("endDate", strconv.FormatInt(cutoff, 10))
+
+	match := make(map[InvoiceStatus]bool, len(statuses))
+
+	for _, s := range statuses {
+		params.Add("status", string(s))
+		match[s] = true
+	}
+
+	resp, err := c.sendAPI(ctx, http.MethodGet, newRoute("/api/v1/stores/:id/invoices", storeID), params, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var invs []StoreInvoice
+
+	if err = c.decode(resp.Body, &invs); err != nil {
+		return nil, err
+	}
+
+	var expired []StoreInvoice
+
+	for _, inv := range invs {
+		// older servers ignore the filters
+		if inv.Archived || inv.CreatedTime > cutoff || !match[InvoiceStatus(inv.Status)] {
+			continue
+		}
+
+		if InvoiceStatus(inv.Status) == InvoiceStatusNew {
+			if inv, err = c.MarkStoreInvoiceStatus(ctx, storeID, inv.ID, string(InvoiceStatusInvalid), opts...); err != nil {
+				return expired, err
+			}
+		}
+
+		if err = c.ArchiveStoreInvoice(ctx, storeID, inv.ID, opts...); err != nil {
+			return expired, err
+		}
+
+		inv.Archived = true
+		expired = append(expired, inv)
+	}
+
+	return expired, nil
+}
@@ -0,0 +1,104 @@
+package btcpay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_ExpireStaleStoreInvoices(t *testing.T) {
+	list := `[
+		{"id":"i1","status":"New","createdTime":100},
+		{"id":"i2","status":"Expired","createdTime":200},
+		{"id":"i3","status":"New","createdTime":950},
+		{"id":"i4","status":"Settled","createdTime":100},
+		{"id":"i5","status":"New","createdTime":100,"archived":true}
+	]`
+
+	cc := map[string]struct {
+		ListResp    httpmock.Responder
+		MarkResp    httpmock.Responder
+		ArchiveResp httpmock.Responder
+		Err         bool
+		Result      []StoreInvoice
+	}{
+		"Error returned during invoice listing": {
+			ListResp: httpmock.NewErrorResponder(assert.AnError),
+			Err:      true,
+		},
+		"Invalid invoice list": {
+			ListResp: httpmock.NewStringResponder(http.StatusOK, "{"),
+			Err:      true,
+		},
+		"Error returned during invoice invalidation": {
+			ListResp: httpmock.NewStringResponder(http.StatusOK, list),
+			MarkResp: httpmock.NewErrorResponder(assert.AnError),
+			Err:      true,
+		},
+		"Error returned during invoice archiving": {
+			ListResp:    httpmock.NewStringResponder(http.StatusOK, list),
+			MarkResp:    httpmock.NewStringResponder(http.StatusOK, `{"id":"i1","status":"Invalid","createdTime":100}`),
+			ArchiveResp: httpmock.NewErrorResponder(assert.AnError),
+			Err:         true,
+		},
+		"Successful execution": {
+			ListResp: func(r *http.Request) (*http.Response, error) {
+				q := r.URL.Query()
+				if q.Get("endDate") != "940" || len(q["status"]) != 2 || q["status"][0] != "New" || q["status"][1] != "Expired" {
+					return nil, errors.New("invalid query params")
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, list), nil
+			},
+			MarkResp: func(r *http.Request) (*http.Response, error) {
+				return httpmock.NewStringResponse(http.StatusOK, `{"id":"i1","status":"Invalid","createdTime":100}`), nil
+			},
+			ArchiveResp: httpmock.NewStringResponder(http.StatusOK, ""),
+			Result: []StoreInvoice{
+				{ID: "i1", Status: "Invalid", CreatedTime: 100, Archived: true},
+				{ID: "i2", Status: "Expired", CreatedTime: 200, Archived: true},
+			},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}), WithClock(fixedClock{now: time.Unix(1000, 0)}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/stores/s1/invoices", c.ListResp)
+
+			if c.MarkResp != nil {
+				mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/stores/s1/invoices/i1/status", c.MarkResp)
+			}
+
+			if c.ArchiveResp != nil {
+				mt.RegisterResponder(http.MethodDelete, "http://test.com/api/v1/stores/s1/invoices/i1", c.ArchiveResp)
+				mt.RegisterResponder(http.MethodDelete, "http://test.com/api/v1/stores/s1/invoices/i2", c.ArchiveResp)
+			}
+
+			res, err := client.ExpireStaleStoreInvoices(context.Background(), "s1", time.Minute, []InvoiceStatus{InvoiceStatusNew, InvoiceStatusExpired})
+			if c.Err {
+				assert.Error(t, err)
+				assert.Empty(t, res)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+			assert.Equal(t, 4, mt.GetTotalCallCount())
+		})
+	}
+}
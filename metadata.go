@@ -0,0 +1,68 @@
+package btcpay
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// MetadataAs decodes the invoice's metadata into the provided value.
+func (inv StoreInvoice) MetadataAs(out interface{}) error {
+	if len(inv.Metadata) == 0 || string(inv.Metadata) == "null" {
+		return errors.New("metadata is empty")
+	}
+
+	return json.Unmarshal(inv.Metadata, out)
+}
+
+// OrderID returns the orderId metadata field of the invoice, or an
+// empty string if it is not set or is not a string.
+func (inv StoreInvoice) OrderID() string {
+	return inv.metadataString("orderId")
+}
+
+// BuyerEmail returns the buyerEmail metadata field of the invoice, or
+// an empty string if it is not set or is not a string.
+func (inv StoreInvoice) BuyerEmail() Sensitive {
+	return Sensitive(inv.metadataString("buyerEmail"))
+}
+
+// POSData returns the posData metadata field of the invoice. Strings are
+// returned as they are and other values, e.g. objects, as JSON, so that
+// they can be decoded further. An empty string is returned if the field
+// is not set.
+func (inv StoreInvoice) POSData() string {
+	v := inv.metadataField("posData")
+	if len(v) == 0 || string(v) == "null" {
+		return ""
+	}
+
+	var s string
+	if json.Unmarshal(v, &s) == nil {
+		return s
+	}
+
+	return string(v)
+}
+
+// metadataString returns the string metadata field.
+func (inv StoreInvoice) metadataString(key string) string {
+	var s string
+
+	if json.Unmarshal(inv.metadataField(key), &s) != nil {
+		return ""
+	}
+
+	return s
+}
+
+// metadataField returns the raw metadata field or nil if the metadata
+// is not an object or the field is not set.
+func (inv StoreInvoice) metadataField(key string) json.RawMessage {
+	var md map[string]json.RawMessage
+
+	if json.Unmarshal(inv.Metadata, &md) != nil {
+		return nil
+	}
+
+	return md[key]
+}
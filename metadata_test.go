@@ -0,0 +1,65 @@
+package btcpay
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_StoreInvoice_MetadataAs(t *testing.T) {
+	var md struct {
+		OrderID string `json:"orderId"`
+		Items   []int  `json:"items"`
+	}
+
+	assert.Error(t, StoreInvoice{}.MetadataAs(&md))
+	assert.Error(t, StoreInvoice{Metadata: json.RawMessage("null")}.MetadataAs(&md))
+	assert.Error(t, StoreInvoice{Metadata: json.RawMessage("[]")}.MetadataAs(&md))
+
+	require.NoError(t, StoreInvoice{Metadata: json.RawMessage(`{"orderId":"o1","items":[1,2]}`)}.MetadataAs(&md))
+	assert.Equal(t, "o1", md.OrderID)
+	assert.Equal(t, []int{1, 2}, md.Items)
+}
+
+func Test_StoreInvoice_metadataAccessors(t *testing.T) {
+	cc := map[string]struct {
+		Metadata   string
+		OrderID    string
+		BuyerEmail Sensitive
+		POSData    string
+	}{
+		"No metadata": {},
+		"Invalid metadata": {
+			Metadata: `[]`,
+		},
+		"Fields of unexpected types": {
+			Metadata: `{"orderId":1,"buyerEmail":null,"posData":null}`,
+		},
+		"String POS data": {
+			Metadata:   `{"orderId":"o1","buyerEmail":"a@test.com","posData":"{\"a\":1}"}`,
+			OrderID:    "o1",
+			BuyerEmail: "a@test.com",
+			POSData:    `{"a":1}`,
+		},
+		"Object POS data": {
+			Metadata: `{"orderId":"o1","posData":{"a":1}}`,
+			OrderID:  "o1",
+			POSData:  `{"a":1}`,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			inv := StoreInvoice{Metadata: json.RawMessage(c.Metadata)}
+			assert.Equal(t, c.OrderID, inv.OrderID())
+			assert.Equal(t, c.BuyerEmail, inv.BuyerEmail())
+			assert.Equal(t, c.POSData, inv.POSData())
+		})
+	}
+}
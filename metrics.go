@@ -0,0 +1,103 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// minIDLength is the minimum length of a path segment that is treated
+// as a resource ID by endpoint labels.
+const minIDLength = 16
+
+// staticSegments are the path segments of API endpoints that are long
+// enough to be mistaken for resource IDs.
+var staticSegments = map[string]bool{
+	"payment-requests": true,
+	"LightningNetwork": true,
+}
+
+// Metrics records metrics of the requests sent by a BTCPay client. It
+// is a lightweight alternative to OpenTelemetry metrics, see WithMeter.
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	// ObserveRequest records a completed request. The endpoint is the
+	// route of the request, e.g. /api/v1/stores/:id/invoices, with
+	// resource IDs replaced by ":id", so that it can be used as a
+	// metric label. The status is 0 and the error is
+	// set if no response was received.
+	ObserveRequest(method, endpoint string, status int, err error, d time.Duration)
+}
+
+// WithMetrics makes the BTCPay client record metrics of every API call
+// with the collector, e.g. PrometheusMetrics.
+func WithMetrics(m Metrics) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.metrics = m
+	}
+}
+
+// route is the path of an API endpoint along with the template that it
+// was built from, which labels the endpoint's requests in metrics and
+// traces.
+type route struct {
+	path     string
+	template string
+}
+
+// newRoute builds the path of an endpoint by substituting the IDs for
+// the ":id" segments of the template in order.
+func newRoute(template string, ids ...string) route {
+	return route{}.join(template, ids...)
+}
+
+// join appends the template, with the IDs substituted, to the route.
+func (r route) join(template string, ids ...string) route {
+	path := template
+
+	for _, id := range ids {
+		path = strings.Replace(path, ":id", id, 1)
+	}
+
+	return route{path: r.path + path, template: r.template + template}
+}
+
+// routeKey is the context key of the route template of a request.
+type routeKey struct{}
+
+// withRoute returns a copy of the context that carries the route's
+// template, unless it is unknown.
+func withRoute(ctx context.Context, r route) context.Context {
+	if r.template == "" {
+		return ctx
+	}
+
+	return context.WithValue(ctx, routeKey{}, r.template)
+}
+
+// routeLabel returns the route template of the request. Requests sent
+// to endpoints that are not wrapped by the client are labeled with
+// endpointLabel.
+func routeLabel(req *http.Request) string {
+	if t, ok := req.Context().Value(routeKey{}).(string); ok {
+		return t
+	}
+
+	return endpointLabel(req.URL.Path)
+}
+
+// endpointLabel replaces resource IDs, such as store and invoice IDs,
+// and email addresses in the path with ":id". Short segments, e.g.
+// crypto codes, and known static segments are kept.
+func endpointLabel(path string) string {
+	ss := strings.Split(path, "/")
+
+	for i, s := range ss {
+		if (len(s) >= minIDLength && !staticSegments[s]) || strings.Contains(s, "@") {
+			ss[i] = ":id"
+		}
+	}
+
+	return strings.Join(ss, "/")
+}
@@ -0,0 +1,94 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type metricsStub struct {
+	method   string
+	endpoint string
+	status   int
+	err      error
+}
+
+func (m *metricsStub) ObserveRequest(method, endpoint string, status int, err error, _ time.Duration) {
+	m.method, m.endpoint, m.status, m.err = method, endpoint, status, err
+}
+
+func Test_WithMetrics(t *testing.T) {
+	m := &metricsStub{}
+	c := &Client{}
+	WithMetrics(m)(c)
+	assert.Equal(t, m, c.metrics)
+}
+
+func Test_endpointLabel(t *testing.T) {
+	assert.Equal(t, "/api/v1/stores/:id/invoices/:id/refund",
+		endpointLabel("/api/v1/stores/7Vw8bEhTxGLrAQ1v4s5uEphKzJcn8QLVsNUWYrq1PBDV/invoices/RuUbEJp9xcfYNZKtY8pAfN/refund"))
+	assert.Equal(t, "/api/v1/stores/:id/payment-methods/onchain/BTC",
+		endpointLabel("/api/v1/stores/7Vw8bEhTxGLrAQ1v4s5uEphKzJcn8QLVsNUWYrq1PBDV/payment-methods/onchain/BTC"))
+	assert.Equal(t, "/api/v1/users/:id", endpointLabel("/api/v1/users/a@test.com"))
+	assert.Equal(t, "/invoices", endpointLabel("/invoices"))
+	assert.Equal(t, "/api/v1/stores/:id/payment-requests",
+		endpointLabel("/api/v1/stores/7Vw8bEhTxGLrAQ1v4s5uEphKzJcn8QLVsNUWYrq1PBDV/payment-requests"))
+	assert.Equal(t, "/api/v1/stores/:id/payment-methods/LightningNetwork/BTC",
+		endpointLabel("/api/v1/stores/7Vw8bEhTxGLrAQ1v4s5uEphKzJcn8QLVsNUWYrq1PBDV/payment-methods/LightningNetwork/BTC"))
+}
+
+func Test_newRoute(t *testing.T) {
+	r := newRoute("/api/v1/stores/:id/lightning/:id", "s1", "BTC").join("/invoices/:id", "i1")
+	assert.Equal(t, route{
+		path:     "/api/v1/stores/s1/lightning/BTC/invoices/i1",
+		template: "/api/v1/stores/:id/lightning/:id/invoices/:id",
+	}, r)
+}
+
+func Test_Client_observe_metrics(t *testing.T) {
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder(http.MethodGet, "http://test.com/invoices/RuUbEJp9xcfYNZKtY8pAfN",
+		httpmock.NewStringResponder(http.StatusNotFound, `{"error":"not found"}`))
+
+	m := &metricsStub{}
+
+	client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}), WithMetrics(m))
+	require.NoError(t, err)
+
+	_, err = client.Invoice(context.Background(), "RuUbEJp9xcfYNZKtY8pAfN")
+	require.Error(t, err)
+
+	assert.Equal(t, http.MethodGet, m.method)
+	assert.Equal(t, "/invoices/:id", m.endpoint)
+	assert.Equal(t, http.StatusNotFound, m.status)
+	assert.Equal(t, err, m.err)
+}
+
+func Test_Client_observe_metrics_Route(t *testing.T) {
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder(http.MethodGet, "=~^http://test.com/",
+		httpmock.NewStringResponder(http.StatusOK, `[]`))
+
+	m := &metricsStub{}
+
+	client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}), WithMetrics(m))
+	require.NoError(t, err)
+
+	// the template is used no matter what the IDs look like
+	_, err = client.PaymentRequests(context.Background(), "s1")
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v1/stores/:id/payment-requests", m.endpoint)
+
+	_, err = client.PaymentRequests(context.Background(), "7Vw8bEhTxGLrAQ1v4s5uEphKzJcn8QLVsNUWYrq1PBDV")
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v1/stores/:id/payment-requests", m.endpoint)
+
+	// endpoints that are not wrapped by the client have no template
+	require.NoError(t, client.Do(context.Background(), http.MethodGet, "/api/v1/stores/7Vw8bEhTxGLrAQ1v4s5uEphKzJcn8QLVsNUWYrq1PBDV/payment-requests", nil, nil, nil))
+	assert.Equal(t, "/api/v1/stores/:id/payment-requests", m.endpoint)
+}
@@ -0,0 +1,165 @@
+package btcpay
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultNonceMaxAge is the default maximum age of an accepted nonce.
+const defaultNonceMaxAge = time.Minute * 5
+
+// WithSignatureNonce makes the BTCPay client add a nonce to every signed
+// legacy API request, so that a verifying proxy in front of the server
+// can reject replayed requests. The nonce is a strictly increasing
+// timestamp in milliseconds, sent in the X-Nonce header. Since the
+// server verifies X-Signature against the URL and body only, the nonce
+// is signed separately along with them and the signature is sent in the
+// X-Nonce-Signature header. See NonceVerifier.
+func WithSignatureNonce() setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.nonces = &nonceSource{}
+	}
+}
+
+// nonceSource generates strictly increasing nonces.
+type nonceSource struct {
+	mu   sync.Mutex
+	last int64
+}
+
+// next returns a nonce that is based on the current time and greater
+// than all previous ones.
+func (ns *nonceSource) next(now time.Time) int64 {
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+
+	n := now.UnixNano() / int64(time.Millisecond)
+	if n <= ns.last {
+		n = ns.last + 1
+	}
+
+	ns.last = n
+
+	return n
+}
+
+// nonceMessage returns the message that the nonce signature covers.
+func nonceMessage(nonce, url, body string) string {
+	return nonce + "\n" + url + body
+}
+
+// NonceVerifier verifies nonces of signed legacy API requests and
+// rejects requests that were seen before. It doesn't depend on the
+// server and is meant to be used by proxies in front of it. The zero
+// value is ready to use. It is safe for concurrent use by multiple
+// goroutines.
+type NonceVerifier struct {
+	// MaxAge is the maximum age of a nonce. Requests with older nonces,
+	// or nonces from the future beyond the same margin, are rejected.
+	// Seen nonces are remembered for this long. Defaults to 5 minutes.
+	MaxAge time.Duration
+
+	// Clock provides the current time. Defaults to the system clock.
+	Clock Clock
+
+	mu      sync.Mutex
+	seen    map[string]map[int64]struct{}
+	sweptAt int64
+}
+
+// VerifyRequest verifies the nonce signature of the request with the
+// public key in its X-Identity header and checks that the nonce is
+// fresh and was not used before by the same identity. The URL must be
+// the full URL that the client sent the request to, including the query
+// string. X-Signature is not verified, it is left to the server.
+func (v *NonceVerifier) VerifyRequest(url, body string, h http.Header) error {
+	id := h.Get("X-Identity")
+	if id == "" {
+		return errors.New("identity is missing")
+	}
+
+	ns := h.Get("X-Nonce")
+	if ns == "" {
+		return errors.New("nonce is missing")
+	}
+
+	n, err := strconv.ParseInt(ns, 10, 64)
+	if err != nil {
+		return errors.New("invalid nonce")
+	}
+
+	if err = VerifySignature(id, nonceMessage(ns, url, body), h.Get("X-Nonce-Signature")); err != nil {
+		return err
+	}
+
+	return v.use(id, n)
+}
+
+// use records the nonce of the identity unless it is stale or was
+// already used.
+func (v *NonceVerifier) use(id string, n int64) error {
+	maxAge := v.MaxAge
+	if maxAge <= 0 {
+		maxAge = defaultNonceMaxAge
+	}
+
+	clk := v.Clock
+	if clk == nil {
+		clk = realClock{}
+	}
+
+	now := clk.Now().UnixNano() / int64(time.Millisecond)
+	age := maxAge.Milliseconds()
+
+	if n < now-age || n > now+age {
+		return errors.New("nonce is stale")
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.seen == nil {
+		v.seen = make(map[string]map[int64]struct{})
+	}
+
+	// nonces that are too old to be accepted don't have to be
+	// remembered; all identities are swept at most once per max age,
+	// so that memory stays bounded without scanning on every request
+	if now-v.sweptAt >= age {
+		v.sweep(now - age)
+		v.sweptAt = now
+	}
+
+	seen := v.seen[id]
+	if seen == nil {
+		seen = make(map[int64]struct{})
+		v.seen[id] = seen
+	}
+
+	if _, ok := seen[n]; ok {
+		return errors.New("nonce was already used")
+	}
+
+	seen[n] = struct{}{}
+
+	return nil
+}
+
+// sweep removes nonces older than the cutoff and identities that have
+// no nonces left.
+func (v *NonceVerifier) sweep(cutoff int64) {
+	for id, seen := range v.seen {
+		for n := range seen {
+			if n < cutoff {
+				delete(seen, n)
+			}
+		}
+
+		if len(seen) == 0 {
+			delete(v.seen, id)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package btcpay
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithSignatureNonce(t *testing.T) {
+	c := &Client{}
+	WithSignatureNonce()(c)
+	assert.NotNil(t, c.nonces)
+}
+
+func Test_nonceSource_next(t *testing.T) {
+	var ns nonceSource
+
+	now := time.Unix(1000, 0)
+	assert.Equal(t, int64(1000000), ns.next(now))
+	assert.Equal(t, int64(1000001), ns.next(now))
+	assert.Equal(t, int64(1000002), ns.next(now.Add(-time.Second)))
+	assert.Equal(t, int64(1002000), ns.next(now.Add(time.Second*2)))
+}
+
+func Test_NonceVerifier_VerifyRequest(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	var (
+		h    http.Header
+		body string
+	)
+
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder(http.MethodPost, "http://test.com/invoices", func(r *http.Request) (*http.Response, error) {
+		h = r.Header.Clone()
+
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		body = string(b)
+
+		return httpmock.NewStringResponse(http.StatusOK, `{"data":{"id":"i1"}}`), nil
+	})
+
+	client, err := NewClient("http://test.com", "token", WithHTTPClient(&http.Client{Transport: mt}), WithSignatureNonce(),
+		WithClock(fixedClock{now: now}))
+	require.NoError(t, err)
+
+	_, err = client.CreateInvoice(context.Background(), CreateInvoiceParams{Currency: "USD"})
+	require.NoError(t, err)
+	assert.Equal(t, "1000000", h.Get("X-Nonce"))
+
+	with := func(key, value string) http.Header {
+		hh := h.Clone()
+		hh.Set(key, value)
+
+		return hh
+	}
+
+	cc := map[string]struct {
+		Now    time.Time
+		URL    string
+		Header http.Header
+		Err    bool
+	}{
+		"Missing identity": {
+			Now:    now,
+			URL:    "http://test.com/invoices",
+			Header: with("X-Identity", ""),
+			Err:    true,
+		},
+		"Missing nonce": {
+			Now:    now,
+			URL:    "http://test.com/invoices",
+			Header: with("X-Nonce", ""),
+			Err:    true,
+		},
+		"Invalid nonce": {
+			Now:    now,
+			URL:    "http://test.com/invoices",
+			Header: with("X-Nonce", "abc"),
+			Err:    true,
+		},
+		"Modified nonce": {
+			Now:    now,
+			URL:    "http://test.com/invoices",
+			Header: with("X-Nonce", strconv.Itoa(1000001)),
+			Err:    true,
+		},
+		"Modified URL": {
+			Now:    now,
+			URL:    "http://test.com/invoices?a=1",
+			Header: h,
+			Err:    true,
+		},
+		"Stale nonce": {
+			Now:    now.Add(defaultNonceMaxAge + time.Second),
+			URL:    "http://test.com/invoices",
+			Header: h,
+			Err:    true,
+		},
+		"Successful verification": {
+			Now:    now.Add(time.Minute),
+			URL:    "http://test.com/invoices",
+			Header: h,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			v := NonceVerifier{Clock: fixedClock{now: c.Now}}
+
+			err := v.VerifyRequest(c.URL, body, c.Header)
+			if c.Err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+
+			// replayed request
+			assert.Error(t, v.VerifyRequest(c.URL, body, c.Header))
+		})
+	}
+}
+
+func Test_NonceVerifier_use(t *testing.T) {
+	now := time.Unix(1000, 0)
+	v := NonceVerifier{MaxAge: time.Second, Clock: fixedClock{now: now}}
+
+	assert.NoError(t, v.use("a", 1000000))
+	assert.NoError(t, v.use("b", 1000000))
+	assert.NoError(t, v.use("a", 999500))
+	assert.Error(t, v.use("a", 999500))
+	assert.Error(t, v.use("a", 998999))
+	assert.Error(t, v.use("a", 1001001))
+
+	v.Clock = fixedClock{now: now.Add(time.Second * 2)}
+	assert.NoError(t, v.use("a", 1002000))
+	assert.Len(t, v.seen["a"], 1)
+
+	// identities without fresh nonces are forgotten
+	assert.NotContains(t, v.seen, "b")
+	assert.Len(t, v.seen, 1)
+}
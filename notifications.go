@@ -0,0 +1,80 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Notification holds data of a single notification of the current user.
+type Notification struct {
+	ID          string `json:"id"`
+	Identifier  string `json:"identifier"`
+	Type        string `json:"type"`
+	Body        string `json:"body"`
+	StoreID     string `json:"storeId"`
+	Link        string `json:"link"`
+	CreatedTime int64  `json:"createdTime"`
+	Seen        bool   `json:"seen"`
+}
+
+// Notifications retrieves notifications of the current user. If
+// unseenOnly is true, notifications that were marked as seen are
+// omitted.
+func (c *Client) Notifications(ctx context.Context, unseenOnly bool, opts ...RequestOption) ([]Notification, error) {
+	var params url.Values
+
+	if unseenOnly {
+		params = url.Values{}
+		params.Set("seen", "false")
+	}
+
+	resp, err := c.sendAPI(ctx, http.MethodGet, newRoute("/api/v1/users/me/notifications"), params, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var nn []Notification
+
+	if err = c.decode(resp.Body, &nn); err != nil {
+		return nil, err
+	}
+
+	return nn, nil
+}
+
+// MarkNotification marks the specified notification as seen or unseen.
+func (c *Client) MarkNotification(ctx context.Context, id string, seen bool, opts ...RequestOption) (Notification, error) {
+	data := struct {
+		Seen bool `json:"seen"`
+	}{
+		Seen: seen,
+	}
+
+	resp, err := c.sendAPI(ctx, http.MethodPut, newRoute("/api/v1/users/me/notifications/:id", id), nil, data, opts...)
+	if err != nil {
+		return Notification{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var n Notification
+
+	if err = c.decode(resp.Body, &n); err != nil {
+		return Notification{}, err
+	}
+
+	return n, nil
+}
+
+// RemoveNotification removes the specified notification.
+func (c *Client) RemoveNotification(ctx context.Context, id string, opts ...RequestOption) error {
+	resp, err := c.sendAPI(ctx, http.MethodDelete, newRoute("/api/v1/users/me/notifications/:id", id), nil, nil, opts...)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
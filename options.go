@@ -0,0 +1,118 @@
+package btcpay
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// RequestOption modifies a single request sent to the server.
+type RequestOption func(o *requestOptions)
+
+// requestOptions holds data that overrides client defaults for a single
+// request.
+type requestOptions struct {
+	timeout time.Duration
+	header  map[string]string
+	token   string
+	noSign  bool
+
+	cacheable bool
+	noCache   bool
+}
+
+// newRequestOptions applies the provided options.
+func newRequestOptions(opts []RequestOption) requestOptions {
+	var o requestOptions
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithRequestTimeout sets the timeout of a single request, overriding
+// the client's default request timeout. The timeout covers reading of
+// the response body as well.
+func WithRequestTimeout(d time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.timeout = d
+	}
+}
+
+// WithHeader sets an HTTP header on a single request. Default client
+// headers are overridden.
+func WithHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.header == nil {
+			o.header = make(map[string]string)
+		}
+
+		o.header[key] = value
+	}
+}
+
+// WithToken sets the token used by a single legacy API request, e.g.
+// a token of a facade other than the one the client was paired with.
+func WithToken(token string) RequestOption {
+	return func(o *requestOptions) {
+		o.token = token
+	}
+}
+
+// WithoutSignature disables signing of a single legacy API request.
+// This is useful for facades, such as pos, that don't require
+// signed requests.
+func WithoutSignature() RequestOption {
+	return func(o *requestOptions) {
+		o.noSign = true
+	}
+}
+
+// doWith applies the request options and executes the request.
+func (c *Client) doWith(req *http.Request, o requestOptions) (*http.Response, error) {
+	c.setCorrelationHeader(req)
+
+	for k, v := range o.header {
+		req.Header.Set(k, v)
+	}
+
+	if o.timeout <= 0 {
+		o.timeout = c.timeout
+	}
+
+	if o.timeout <= 0 {
+		return c.doCached(req, o)
+	}
+
+	// the deadline is enforced in real time, so it must not be
+	// derived from the client's clock
+	ctx, cancel := context.WithTimeout(req.Context(), o.timeout)
+
+	resp, err := c.doCached(req.WithContext(ctx), o)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = cancelBody{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+// cancelBody releases the resources of the request's context when the
+// response body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the request's context.
+func (b cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+
+	return err
+}
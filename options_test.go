@@ -0,0 +1,124 @@
+package btcpay
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RequestOptions(t *testing.T) {
+	o := newRequestOptions([]RequestOption{
+		WithRequestTimeout(time.Second),
+		WithHeader("X-Foo", "bar"),
+		WithHeader("X-Bar", "foo"),
+		WithToken("123"),
+		WithoutSignature(),
+	})
+
+	assert.Equal(t, requestOptions{
+		timeout: time.Second,
+		header:  map[string]string{"X-Foo": "bar", "X-Bar": "foo"},
+		token:   "123",
+		noSign:  true,
+	}, o)
+}
+
+func Test_Client_send_RequestOptions(t *testing.T) {
+	cc := map[string]struct {
+		Opts  []RequestOption
+		Check func(r *http.Request) error
+	}{
+		"Default options": {
+			Check: func(r *http.Request) error {
+				if r.URL.Query().Get("token") != "tok" || r.Header.Get("X-Signature") == "" {
+					return errors.New("invalid request")
+				}
+
+				if _, ok := r.Context().Deadline(); ok {
+					return errors.New("unexpected deadline")
+				}
+
+				return nil
+			},
+		},
+		"Custom options": {
+			Opts: []RequestOption{
+				WithHeader("X-Foo", "bar"),
+				WithHeader("User-Agent", "test"),
+				WithToken("pos"),
+				WithoutSignature(),
+				WithRequestTimeout(time.Minute),
+			},
+			Check: func(r *http.Request) error {
+				if r.URL.Query().Get("token") != "pos" || r.Header.Get("X-Signature") != "" || r.Header.Get("X-Identity") != "" {
+					return errors.New("invalid request")
+				}
+
+				if r.Header.Get("X-Foo") != "bar" || r.Header.Get("User-Agent") != "test" {
+					return errors.New("invalid header")
+				}
+
+				if _, ok := r.Context().Deadline(); !ok {
+					return errors.New("deadline not set")
+				}
+
+				return nil
+			},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "tok", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/test", func(r *http.Request) (*http.Response, error) {
+				if err := c.Check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, "test"), nil
+			})
+
+			resp, err := client.send(context.Background(), http.MethodGet, newRoute("/test"), nil, nil, true, c.Opts...)
+			require.NoError(t, err)
+
+			b, err := ioutil.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, "test", string(b))
+			assert.NoError(t, resp.Body.Close())
+		})
+	}
+}
+
+func Test_Client_sendAPI_RequestTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second * 5):
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "")
+	require.NoError(t, err)
+
+	start := time.Now()
+
+	_, err = client.Health(context.Background(), WithRequestTimeout(time.Millisecond*50))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, int64(time.Since(start)), int64(time.Second*5))
+}
@@ -0,0 +1,305 @@
+package btcpay
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// Invoice outbox defaults.
+const (
+	defaultOutboxInterval    = time.Second
+	defaultOutboxMaxAttempts = 5
+	defaultOutboxBackoff     = time.Second
+	maxOutboxBackoff         = time.Minute * 10
+
+	// maxOutboxClockSkew is the allowed difference between the clocks
+	// of the client and the server when the invoices created by
+	// previous attempts are looked up.
+	maxOutboxClockSkew = time.Minute * 5
+)
+
+// OutboxEntry holds an invoice creation request waiting in the outbox.
+type OutboxEntry struct {
+	ID     string              `json:"id"`
+	Params CreateInvoiceParams `json:"params"`
+
+	// IdempotencyKey is stored separately, since it is not a part of
+	// the encoded params.
+	IdempotencyKey string `json:"idempotencyKey"`
+
+	// Sent is set before the first attempt is made, so that attempts
+	// that may have reached the server, including ones interrupted by
+	// a crash, are known when the entry is retried.
+	Sent bool `json:"sent"`
+
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"nextAttempt"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// OutboxStore persists outbox entries. Entries must survive process
+// restarts for invoice creation to be crash-safe.
+type OutboxStore interface {
+	// Add stores a new entry.
+	Add(ctx context.Context, e OutboxEntry) error
+
+	// Due returns entries whose next attempt is not after the
+	// provided time, oldest first.
+	Due(ctx context.Context, t time.Time) ([]OutboxEntry, error)
+
+	// Update replaces the stored entry with the same ID.
+	Update(ctx context.Context, e OutboxEntry) error
+
+	// Remove removes the entry.
+	Remove(ctx context.Context, id string) error
+}
+
+// OutboxResult holds the outcome of an outbox entry. Err is set if the
+// invoice could not be created within the allowed number of attempts.
+type OutboxResult struct {
+	EntryID string
+	Invoice Invoice
+	Err     error
+}
+
+// OutboxConfig holds settings of an invoice outbox.
+type OutboxConfig struct {
+	// Store persists the entries. Required.
+	Store OutboxStore
+
+	// Interval specifies how often the store is checked for due
+	// entries. Defaults to 1 second.
+	Interval time.Duration
+
+	// MaxAttempts is the number of attempts after which an entry is
+	// dropped. Defaults to 5.
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry; it doubles with
+	// every failed attempt. Defaults to 1 second.
+	Backoff time.Duration
+
+	// OnResult is called with the outcome of every entry.
+	OnResult func(r OutboxResult)
+
+	// Results receives the outcome of every entry, if set.
+	Results chan<- OutboxResult
+}
+
+// InvoiceOutbox creates invoices in the background. Invoice creation
+// requests are persisted before they are sent and retried until they
+// succeed, so that no invoice is lost if the process crashes or the
+// server is unavailable.
+//
+// Invoices are created at least once. Every entry is sent with its ID
+// as the idempotency key, but BTCPay Server does not enforce it, so
+// before an entry is sent again, an invoice that a previous attempt
+// may have created is looked up by the entry's order ID, price,
+// currency and POS data. Entries without an order ID cannot be looked
+// up and may create duplicate invoices when an attempt times out or
+// the process crashes before the entry is removed.
+type InvoiceOutbox struct {
+	c    *Client
+	cfg  OutboxConfig
+	opts []RequestOption
+}
+
+// NewInvoiceOutbox creates a new invoice outbox that uses the client.
+// The request options are applied to every invoice creation request.
+func (c *Client) NewInvoiceOutbox(cfg OutboxConfig, opts ...RequestOption) (*InvoiceOutbox, error) {
+	if cfg.Store == nil {
+		return nil, errors.New("outbox store is required")
+	}
+
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultOutboxInterval
+	}
+
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultOutboxMaxAttempts
+	}
+
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = defaultOutboxBackoff
+	}
+
+	return &InvoiceOutbox{c: c, cfg: cfg, opts: opts}, nil
+}
+
+// Enqueue validates the params and stores them for creation. The ID of
+// the entry is returned. Unless the params already have an idempotency
+// key, the entry ID is used.
+func (o *InvoiceOutbox) Enqueue(ctx context.Context, p CreateInvoiceParams) (string, error) {
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+
+	e := OutboxEntry{
+		ID:             hex.EncodeToString(b),
+		Params:         p,
+		IdempotencyKey: p.IdempotencyKey,
+	}
+
+	if e.IdempotencyKey == "" {
+		e.IdempotencyKey = e.ID
+	}
+
+	e.CreatedAt = o.c.getClock().Now()
+	e.NextAttempt = e.CreatedAt
+
+	err := o.cfg.Store.Add(ctx, e)
+	if err != nil {
+		return "", err
+	}
+
+	return e.ID, nil
+}
+
+// Run processes due entries until the context is cancelled or the store
+// fails.
+func (o *InvoiceOutbox) Run(ctx context.Context) error {
+	clk := o.c.getClock()
+
+	for {
+		if err := o.Flush(ctx); err != nil {
+			return err
+		}
+
+		if err := sleep(ctx, clk, o.cfg.Interval); err != nil {
+			return err
+		}
+	}
+}
+
+// Flush processes all entries that are due once.
+func (o *InvoiceOutbox) Flush(ctx context.Context) error {
+	ee, err := o.cfg.Store.Due(ctx, o.c.getClock().Now())
+	if err != nil {
+		return err
+	}
+
+	for _, e := range ee {
+		if err = o.process(ctx, e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// process sends a single entry and records its outcome.
+func (o *InvoiceOutbox) process(ctx context.Context, e OutboxEntry) error {
+	e.Params.IdempotencyKey = e.IdempotencyKey
+
+	var (
+		inv   Invoice
+		found bool
+		err   error
+	)
+
+	if e.Sent {
+		inv, found, err = o.lookup(ctx, e)
+	} else {
+		e.Sent = true
+
+		if err = o.cfg.Store.Update(ctx, e); err != nil {
+			return err
+		}
+	}
+
+	if err == nil && !found {
+		inv, err = o.c.CreateInvoice(ctx, e.Params, o.opts...)
+	}
+
+	if err == nil {
+		if err = o.cfg.Store.Remove(ctx, e.ID); err != nil {
+			return err
+		}
+
+		o.report(ctx, OutboxResult{EntryID: e.ID, Invoice: inv})
+
+		return nil
+	}
+
+	if ctx.Err() != nil {
+		// the attempt was interrupted, so it's not counted
+		return ctx.Err()
+	}
+
+	e.Attempts++
+
+	if e.Attempts >= o.cfg.MaxAttempts {
+		if rerr := o.cfg.Store.Remove(ctx, e.ID); rerr != nil {
+			return rerr
+		}
+
+		o.report(ctx, OutboxResult{EntryID: e.ID, Err: err})
+
+		return nil
+	}
+
+	backoff := o.cfg.Backoff << (e.Attempts - 1)
+	if backoff > maxOutboxBackoff || backoff <= 0 {
+		backoff = maxOutboxBackoff
+	}
+
+	e.NextAttempt = o.c.getClock().Now().Add(backoff)
+
+	return o.cfg.Store.Update(ctx, e)
+}
+
+// lookup retrieves the invoice that a previous attempt of the entry
+// may have created. The second value is false if there's none.
+func (o *InvoiceOutbox) lookup(ctx context.Context, e OutboxEntry) (Invoice, bool, error) {
+	if e.Params.OrderID == "" {
+		return Invoice{}, false, nil
+	}
+
+	since := e.CreatedAt.Add(-maxOutboxClockSkew)
+
+	ii, err := o.c.Invoices(ctx, InvoicesParams{
+		OrderID: e.Params.OrderID,
+		// the filter has the precision of a day
+		DateStart: since.Add(-time.Hour * 24),
+	}, o.opts...)
+	if err != nil {
+		return Invoice{}, false, err
+	}
+
+	for _, inv := range ii {
+		if inv.CreatedAt().Before(since) ||
+			!inv.Price.Equal(e.Params.Price) ||
+			!strings.EqualFold(inv.Currency, e.Params.Currency) ||
+			inv.POSData != e.Params.POSData {
+			continue
+		}
+
+		return inv, true, nil
+	}
+
+	return Invoice{}, false, nil
+}
+
+// report passes the result to the callback and the results channel.
+func (o *InvoiceOutbox) report(ctx context.Context, r OutboxResult) {
+	if o.cfg.OnResult != nil {
+		o.cfg.OnResult(r)
+	}
+
+	if o.cfg.Results != nil {
+		select {
+		case <-ctx.Done():
+		case o.cfg.Results <- r:
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_NewInvoiceOutbox(t *testing.T) {
+	c := &Client{}
+
+	o, err := c.NewInvoiceOutbox(OutboxConfig{})
+	assert.Error(t, err)
+	assert.Nil(t, o)
+
+	s, err := NewLocalOutboxStore("")
+	require.NoError(t, err)
+
+	o, err = c.NewInvoiceOutbox(OutboxConfig{Store: s})
+	require.NoError(t, err)
+	assert.Equal(t, OutboxConfig{
+		Store:       s,
+		Interval:    defaultOutboxInterval,
+		MaxAttempts: defaultOutboxMaxAttempts,
+		Backoff:     defaultOutboxBackoff,
+	}, o.cfg)
+}
+
+func Test_InvoiceOutbox_Enqueue(t *testing.T) {
+	now := time.Unix(1000, 0)
+	c := &Client{clock: fixedClock{now: now}}
+
+	s, err := NewLocalOutboxStore("")
+	require.NoError(t, err)
+
+	o, err := c.NewInvoiceOutbox(OutboxConfig{Store: s})
+	require.NoError(t, err)
+
+	_, err = o.Enqueue(context.Background(), CreateInvoiceParams{Currency: "US$"})
+	assert.Error(t, err)
+
+	id, err := o.Enqueue(context.Background(), CreateInvoiceParams{Currency: "USD", Price: decimal.NewFromInt(10)})
+	require.NoError(t, err)
+
+	id2, err := o.Enqueue(context.Background(), CreateInvoiceParams{Currency: "USD", IdempotencyKey: "key123"})
+	require.NoError(t, err)
+	assert.NotEqual(t, id, id2)
+
+	ee, err := s.Due(context.Background(), now)
+	require.NoError(t, err)
+	require.Len(t, ee, 2)
+
+	for _, e := range ee {
+		switch e.ID {
+		case id:
+			assert.Equal(t, id, e.IdempotencyKey)
+		case id2:
+			assert.Equal(t, "key123", e.IdempotencyKey)
+		}
+
+		assert.Equal(t, now, e.CreatedAt)
+		assert.Equal(t, now, e.NextAttempt)
+	}
+}
+
+func Test_InvoiceOutbox_Flush(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	params := CreateInvoiceParams{Currency: "USD", OrderID: "o1", POSData: "p1"}
+
+	created := func(r *http.Request) (*http.Response, error) {
+		assert.Equal(t, "e1", r.Header.Get("Idempotency-Key"))
+		return httpmock.NewStringResponse(http.StatusOK, `{"data":{"id":"i1"}}`), nil
+	}
+
+	cc := map[string]struct {
+		Params   CreateInvoiceParams
+		Sent     bool
+		Attempts int
+		Lookup   httpmock.Responder
+		Resp     httpmock.Responder
+		Created  int
+		Result   *OutboxResult
+		Pending  *OutboxEntry
+	}{
+		"Failed attempt": {
+			Params:   params,
+			Attempts: 1,
+			Resp:     httpmock.NewStringResponder(http.StatusBadGateway, `{"error":"unavailable"}`),
+			Created:  1,
+			Pending: &OutboxEntry{
+				ID:             "e1",
+				Params:         CreateInvoiceParams{Currency: "USD", OrderID: "o1", POSData: "p1", IdempotencyKey: "e1"},
+				IdempotencyKey: "e1",
+				Sent:           true,
+				Attempts:       2,
+				NextAttempt:    now.Add(time.Second * 2),
+				CreatedAt:      now,
+			},
+		},
+		"Last failed attempt": {
+			Params:   params,
+			Attempts: 2,
+			Resp:     httpmock.NewStringResponder(http.StatusBadGateway, `{"error":"unavailable"}`),
+			Created:  1,
+			Result:   &OutboxResult{EntryID: "e1"},
+		},
+		"Failed lookup of a sent entry": {
+			Params:   params,
+			Sent:     true,
+			Attempts: 2,
+			Lookup:   httpmock.NewStringResponder(http.StatusBadGateway, `{"error":"unavailable"}`),
+			Resp:     created,
+			Result:   &OutboxResult{EntryID: "e1"},
+		},
+		"Sent entry that created an invoice": {
+			Params: params,
+			Sent:   true,
+			Lookup: httpmock.NewStringResponder(http.StatusOK, `{"data":[`+
+				`{"id":"i0","orderId":"o1","currency":"USD","posData":"p1","invoiceTime":1000},`+
+				`{"id":"i2","orderId":"o1","currency":"USD","price":"5","posData":"p1","invoiceTime":1000000},`+
+				`{"id":"i3","orderId":"o1","currency":"EUR","posData":"p1","invoiceTime":1000000},`+
+				`{"id":"i4","orderId":"o1","currency":"USD","posData":"p2","invoiceTime":1000000},`+
+				`{"id":"i1","orderId":"o1","currency":"usd","posData":"p1","invoiceTime":1000000}]}`),
+			Result: &OutboxResult{EntryID: "e1", Invoice: Invoice{
+				ID:          "i1",
+				OrderID:     "o1",
+				Currency:    "usd",
+				POSData:     "p1",
+				InvoiceTime: 1000000,
+			}},
+		},
+		"Sent entry that did not create an invoice": {
+			Params: params,
+			Sent:   true,
+			Lookup: httpmock.NewStringResponder(http.StatusOK, `{"data":[`+
+				`{"id":"i2","orderId":"o1","currency":"USD","price":"5","posData":"p1","invoiceTime":1000000}]}`),
+			Resp:    created,
+			Created: 1,
+			Result:  &OutboxResult{EntryID: "e1", Invoice: Invoice{ID: "i1"}},
+		},
+		"Sent entry without order ID": {
+			Params:  CreateInvoiceParams{Currency: "USD"},
+			Sent:    true,
+			Resp:    created,
+			Created: 1,
+			Result:  &OutboxResult{EntryID: "e1", Invoice: Invoice{ID: "i1"}},
+		},
+		"Successful attempt": {
+			Params:  params,
+			Resp:    created,
+			Created: 1,
+			Result:  &OutboxResult{EntryID: "e1", Invoice: Invoice{ID: "i1"}},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}), WithClock(fixedClock{now: now}))
+			require.NoError(t, err)
+
+			if c.Resp != nil {
+				mt.RegisterResponder(http.MethodPost, "http://test.com/invoices", c.Resp)
+			}
+
+			if c.Lookup != nil {
+				mt.RegisterResponder(http.MethodGet, "http://test.com/invoices", func(r *http.Request) (*http.Response, error) {
+					assert.Equal(t, "o1", r.URL.Query().Get("orderId"))
+					assert.Equal(t, "1969-12-31", r.URL.Query().Get("dateStart"))
+
+					return c.Lookup(r)
+				})
+			}
+
+			s, err := NewLocalOutboxStore("")
+			require.NoError(t, err)
+
+			require.NoError(t, s.Add(context.Background(), OutboxEntry{
+				ID:             "e1",
+				Params:         c.Params,
+				IdempotencyKey: "e1",
+				Sent:           c.Sent,
+				Attempts:       c.Attempts,
+				NextAttempt:    now,
+				CreatedAt:      now,
+			}))
+
+			var results []OutboxResult
+
+			ch := make(chan OutboxResult, 1)
+
+			o, err := client.NewInvoiceOutbox(OutboxConfig{
+				Store:       s,
+				MaxAttempts: 3,
+				OnResult: func(r OutboxResult) {
+					results = append(results, r)
+				},
+				Results: ch,
+			})
+			require.NoError(t, err)
+
+			require.NoError(t, o.Flush(context.Background()))
+			assert.Equal(t, c.Created, mt.GetCallCountInfo()["POST http://test.com/invoices"])
+
+			ee, err := s.Due(context.Background(), now.Add(time.Hour))
+			require.NoError(t, err)
+
+			if c.Pending != nil {
+				assert.Equal(t, []OutboxEntry{*c.Pending}, ee)
+				assert.Empty(t, results)
+
+				return
+			}
+
+			assert.Empty(t, ee)
+			require.Len(t, results, 1)
+			assert.Equal(t, results[0], <-ch)
+
+			if c.Result.Invoice.ID == "" {
+				assert.Error(t, results[0].Err)
+				return
+			}
+
+			assert.Equal(t, c.Result.EntryID, results[0].EntryID)
+			assert.NoError(t, results[0].Err)
+			assert.Equal(t, c.Result.Invoice.ID, results[0].Invoice.ID)
+			assert.Equal(t, c.Result.Invoice.OrderID, results[0].Invoice.OrderID)
+			assert.Equal(t, c.Result.Invoice.InvoiceTime, results[0].Invoice.InvoiceTime)
+		})
+	}
+}
+
+func Test_InvoiceOutbox_Run(t *testing.T) {
+	s, err := NewLocalOutboxStore("")
+	require.NoError(t, err)
+
+	client, err := NewClient("http://test.com", "")
+	require.NoError(t, err)
+
+	o, err := client.NewInvoiceOutbox(OutboxConfig{Store: s, Interval: time.Millisecond})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, o.Run(ctx))
+}
@@ -0,0 +1,170 @@
+package btcpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LocalOutboxStore is an OutboxStore that keeps entries in memory and,
+// if a file path is set, persists them to a JSON file after every
+// change. The file is replaced atomically, so it is never left
+// half-written.
+// It is safe for concurrent use by multiple goroutines, but the file
+// must not be shared by multiple processes.
+type LocalOutboxStore struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]OutboxEntry
+}
+
+// NewLocalOutboxStore creates a new local outbox store. Entries stored
+// in the file are loaded. If the path is empty, entries are kept in
+// memory only.
+func NewLocalOutboxStore(path string) (*LocalOutboxStore, error) {
+	s := &LocalOutboxStore{
+		path:    path,
+		entries: make(map[string]OutboxEntry),
+	}
+
+	if path == "" {
+		return s, nil
+	}
+
+	b, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return s, nil
+	case err != nil:
+		return nil, err
+	}
+
+	var ee []OutboxEntry
+
+	if err = json.Unmarshal(b, &ee); err != nil {
+		return nil, err
+	}
+
+	for _, e := range ee {
+		s.entries[e.ID] = e
+	}
+
+	return s, nil
+}
+
+// Add stores a new entry.
+func (s *LocalOutboxStore) Add(_ context.Context, e OutboxEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[e.ID]; ok {
+		return errors.New("outbox entry already exists")
+	}
+
+	s.entries[e.ID] = e
+
+	return s.save()
+}
+
+// Due returns entries whose next attempt is not after the provided
+// time, oldest first.
+func (s *LocalOutboxStore) Due(_ context.Context, t time.Time) ([]OutboxEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ee []OutboxEntry
+
+	for _, e := range s.entries {
+		if !e.NextAttempt.After(t) {
+			ee = append(ee, e)
+		}
+	}
+
+	sortOutboxEntries(ee)
+
+	return ee, nil
+}
+
+// Update replaces the stored entry with the same ID.
+func (s *LocalOutboxStore) Update(_ context.Context, e OutboxEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[e.ID]; !ok {
+		return errors.New("outbox entry not found")
+	}
+
+	s.entries[e.ID] = e
+
+	return s.save()
+}
+
+// Remove removes the entry.
+func (s *LocalOutboxStore) Remove(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, id)
+
+	return s.save()
+}
+
+// save writes all entries to the file. The lock must be held.
+func (s *LocalOutboxStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	ee := make([]OutboxEntry, 0, len(s.entries))
+	for _, e := range s.entries {
+		ee = append(ee, e)
+	}
+
+	sortOutboxEntries(ee)
+
+	b, err := json.Marshal(ee)
+	if err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(f.Name()) //nolint:errcheck // the file is gone after a successful rename
+
+	if _, err = f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err = f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), s.path)
+}
+
+// sortOutboxEntries sorts the entries by their creation time.
+func sortOutboxEntries(ee []OutboxEntry) {
+	sort.Slice(ee, func(i, j int) bool {
+		if ee[i].CreatedAt.Equal(ee[j].CreatedAt) {
+			return ee[i].ID < ee[j].ID
+		}
+
+		return ee[i].CreatedAt.Before(ee[j].CreatedAt)
+	})
+}
@@ -0,0 +1,84 @@
+package btcpay
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewLocalOutboxStore(t *testing.T) {
+	params := CreateInvoiceParams{Currency: "USD", Price: decimal.NewFromInt(10)}
+
+	dir, err := ioutil.TempDir("", "outbox")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "outbox.json")
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("{"), 0o600))
+
+	_, err = NewLocalOutboxStore(path)
+	assert.Error(t, err)
+
+	require.NoError(t, os.Remove(path))
+
+	s, err := NewLocalOutboxStore(path)
+	require.NoError(t, err)
+	assert.Empty(t, s.entries)
+
+	e := OutboxEntry{ID: "e1", Params: params, IdempotencyKey: "e1", CreatedAt: time.Unix(1000, 0).UTC()}
+	require.NoError(t, s.Add(context.Background(), e))
+
+	s, err = NewLocalOutboxStore(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]OutboxEntry{"e1": e}, s.entries)
+}
+
+func Test_LocalOutboxStore(t *testing.T) {
+	params := CreateInvoiceParams{Currency: "USD", Price: decimal.NewFromInt(10)}
+
+	dir, err := ioutil.TempDir("", "outbox")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	s, err := NewLocalOutboxStore(filepath.Join(dir, "outbox.json"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	now := time.Unix(1000, 0).UTC()
+
+	e1 := OutboxEntry{ID: "e1", Params: params, NextAttempt: now, CreatedAt: now.Add(time.Second)}
+	e2 := OutboxEntry{ID: "e2", Params: params, NextAttempt: now, CreatedAt: now}
+	e3 := OutboxEntry{ID: "e3", Params: params, NextAttempt: now.Add(time.Minute), CreatedAt: now}
+
+	require.NoError(t, s.Add(ctx, e1))
+	require.NoError(t, s.Add(ctx, e2))
+	require.NoError(t, s.Add(ctx, e3))
+	assert.Error(t, s.Add(ctx, e1))
+
+	ee, err := s.Due(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, []OutboxEntry{e2, e1}, ee)
+
+	assert.Error(t, s.Update(ctx, OutboxEntry{ID: "e4"}))
+
+	e1.NextAttempt = now.Add(time.Hour)
+	require.NoError(t, s.Update(ctx, e1))
+	require.NoError(t, s.Remove(ctx, "e2"))
+
+	s, err = NewLocalOutboxStore(filepath.Join(dir, "outbox.json"))
+	require.NoError(t, err)
+
+	ee, err = s.Due(ctx, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []OutboxEntry{e3, e1}, ee)
+}
@@ -0,0 +1,309 @@
+package btcpay
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// PayjoinParams holds optional data used to negotiate a payjoin
+// transaction.
+// More at: https://github.com/bitcoin/bips/blob/master/bip-0078.mediawiki
+type PayjoinParams struct {
+	// AdditionalFeeOutputIndex is the index of the sender's output
+	// that the receiver may decrease to pay for its additional inputs.
+	AdditionalFeeOutputIndex *int
+
+	// MaxAdditionalFeeContribution is the maximum amount, in satoshis,
+	// that the receiver may deduct from the additional fee output.
+	MaxAdditionalFeeContribution int64
+
+	// MinFeeRate is the minimum fee rate, in sat/vB, that the
+	// proposal must have. The proposal's fee rate must not be lower
+	// than the original's either.
+	MinFeeRate float64
+
+	DisableOutputSubstitution bool
+
+	// Network is used to match the payee's address against the
+	// transaction outputs. Bitcoin mainnet is used by default.
+	Network *chaincfg.Params
+}
+
+// Payjoin sends the original, fully signed PSBT to the payjoin endpoint
+// specified in the BIP21 URI and returns the receiver's proposal PSBT.
+// The inputs of the original PSBT must carry the data of the outputs
+// they spend, so that the proposal's fee can be verified.
+// The proposal is checked against the original before being returned;
+// the sender's inputs in it are not signed and must be signed again
+// before the transaction is broadcast.
+func (c *Client) Payjoin(ctx context.Context, uri, psbt string, p PayjoinParams, opts ...RequestOption) (string, error) {
+	addr, q, err := parseBIP21(uri)
+	if err != nil {
+		return "", err
+	}
+
+	pj, err := url.Parse(q.Get("pj"))
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case pj.Scheme == "https":
+	case pj.Scheme == "http" && isOnion(pj.String()):
+	default:
+		return "", errors.New("payjoin endpoint must use https or be an onion service")
+	}
+
+	orig, err := DecodePSBT(psbt)
+	if err != nil {
+		return "", err
+	}
+
+	for i, in := range orig.Inputs {
+		if !in.Finalized {
+			return "", fmt.Errorf("original input %d is not finalized", i)
+		}
+	}
+
+	if !orig.FeeKnown {
+		return "", errors.New("original inputs must carry UTXO data")
+	}
+
+	params := pj.Query()
+	params.Set("v", "1")
+
+	if p.AdditionalFeeOutputIndex != nil {
+		if *p.AdditionalFeeOutputIndex < 0 || *p.AdditionalFeeOutputIndex >= len(orig.Outputs) {
+			return "", errors.New("invalid additional fee output index")
+		}
+
+		params.Set("additionalfeeoutputindex", strconv.Itoa(*p.AdditionalFeeOutputIndex))
+		params.Set("maxadditionalfeecontribution", strconv.FormatInt(p.MaxAdditionalFeeContribution, 10))
+	}
+
+	if p.MinFeeRate > 0 {
+		params.Set("minfeerate", strconv.FormatFloat(p.MinFeeRate, 'f', -1, 64))
+	}
+
+	if p.DisableOutputSubstitution || q.Get("pjos") == "0" {
+		p.DisableOutputSubstitution = true
+		params.Set("disableoutputsubstitution", "true")
+	}
+
+	pj.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, pj.String(), strings.NewReader(orig.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	for k, v := range c.header {
+		req.Header.Set(k, v)
+	}
+
+	req.Header.Set("Content-Type", "text/plain")
+
+	resp, err := c.doWith(req, newRequestOptions(opts))
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	prop, err := DecodePSBT(string(bytes.TrimSpace(b)))
+	if err != nil {
+		return "", err
+	}
+
+	if p.Network == nil {
+		p.Network = &chaincfg.MainNetParams
+	}
+
+	if err = checkPayjoinProposal(orig, prop, addr, p); err != nil {
+		return "", err
+	}
+
+	return prop.Encode(), nil
+}
+
+// checkPayjoinProposal checks whether the receiver's modifications of
+// the original transaction are acceptable to the sender.
+func checkPayjoinProposal(orig, prop *PSBT, payee string, p PayjoinParams) error {
+	if orig.Version != prop.Version || orig.LockTime != prop.LockTime {
+		return errors.New("proposal version or lock time was modified")
+	}
+
+	type outpoint struct {
+		txID  string
+		index uint32
+	}
+
+	origIns := make(map[outpoint]int, len(orig.Inputs))
+	for i, in := range orig.Inputs {
+		origIns[outpoint{in.PreviousTxID, in.PreviousIndex}] = i
+	}
+
+	// the sender's inputs in the proposal are not signed, so the
+	// final data of the original ones is used to measure its size
+	tx := prop.packet.UnsignedTx.Copy()
+	fee := int64(0)
+
+	for i, in := range prop.Inputs {
+		j, ok := origIns[outpoint{in.PreviousTxID, in.PreviousIndex}]
+		if !ok {
+			if !in.Finalized {
+				return fmt.Errorf("proposal input %d of the receiver is not finalized", i)
+			}
+
+			if !in.ValueKnown {
+				return fmt.Errorf("proposal input %d of the receiver has no UTXO data", i)
+			}
+
+			if in.Sequence != orig.Inputs[0].Sequence {
+				return fmt.Errorf("proposal input %d has a different sequence", i)
+			}
+
+			fee += in.Value
+
+			if err := finalizeInput(tx.TxIn[i], prop.packet.Inputs[i]); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		delete(origIns, outpoint{in.PreviousTxID, in.PreviousIndex})
+
+		if in.Sequence != orig.Inputs[j].Sequence {
+			return fmt.Errorf("proposal input %d has a modified sequence", i)
+		}
+
+		if in.Finalized {
+			return fmt.Errorf("proposal input %d of the sender is finalized", i)
+		}
+
+		fee += orig.Inputs[j].Value
+
+		if err := finalizeInput(tx.TxIn[i], orig.packet.Inputs[j]); err != nil {
+			return err
+		}
+	}
+
+	if len(origIns) > 0 {
+		return errors.New("proposal is missing inputs of the sender")
+	}
+
+	for _, out := range prop.Outputs {
+		fee -= out.Value
+	}
+
+	var contribution int64
+
+	used := make([]bool, len(prop.Outputs))
+
+	for i, out := range orig.Outputs {
+		if !p.DisableOutputSubstitution {
+			if addr, err := out.Address(p.Network); err == nil && strings.EqualFold(addr, payee) {
+				continue
+			}
+		}
+
+		j := -1
+
+		for k, pout := range prop.Outputs {
+			if !used[k] && bytes.Equal(pout.PkScript, out.PkScript) {
+				j = k
+				break
+			}
+		}
+
+		if j < 0 {
+			return fmt.Errorf("proposal is missing output %d", i)
+		}
+
+		used[j] = true
+
+		if p.AdditionalFeeOutputIndex != nil && *p.AdditionalFeeOutputIndex == i {
+			contribution = out.Value - prop.Outputs[j].Value
+			if contribution > p.MaxAdditionalFeeContribution {
+				return errors.New("proposal exceeds the maximum fee contribution")
+			}
+
+			continue
+		}
+
+		if prop.Outputs[j].Value < out.Value {
+			return fmt.Errorf("proposal decreases output %d", i)
+		}
+	}
+
+	return checkPayjoinFee(orig, fee, vsize(tx), contribution, p.MinFeeRate)
+}
+
+// checkPayjoinFee checks whether the proposal's fee is acceptable: the
+// sender's contribution must go to the fee only and the fee rate must
+// be at least the original's and the minimum one.
+func checkPayjoinFee(orig *PSBT, fee, size, contribution int64, minFeeRate float64) error {
+	if contribution > 0 && contribution > fee-orig.Fee {
+		return errors.New("proposal fee contribution exceeds the fee increase")
+	}
+
+	origTx := orig.packet.UnsignedTx.Copy()
+
+	for i, in := range origTx.TxIn {
+		if err := finalizeInput(in, orig.packet.Inputs[i]); err != nil {
+			return err
+		}
+	}
+
+	if fee*vsize(origTx) < orig.Fee*size {
+		return errors.New("proposal fee rate is lower than the original")
+	}
+
+	if float64(fee)/float64(size) < minFeeRate {
+		return errors.New("proposal fee rate is lower than the minimum")
+	}
+
+	return nil
+}
+
+// vsize returns the virtual size of the transaction in vbytes.
+func vsize(tx *wire.MsgTx) int64 {
+	weight := int64(tx.SerializeSizeStripped()*3 + tx.SerializeSize())
+	return (weight + 3) / 4
+}
+
+// parseBIP21 parses the BIP21 URI and returns its address and query
+// parameters.
+func parseBIP21(uri string) (string, url.Values, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if !strings.EqualFold(u.Scheme, "bitcoin") || u.Opaque == "" {
+		return "", nil, errors.New("invalid BIP21 URI")
+	}
+
+	q, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return u.Opaque, q, nil
+}
@@ -0,0 +1,293 @@
+package btcpay
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_Payjoin(t *testing.T) {
+	changeScript := append([]byte{0x00, 0x14}, make([]byte, 20)...)
+
+	newTx := func(lockTime uint32, ins []*chainhash.Hash, outs ...int64) *wire.MsgTx {
+		tx := wire.NewMsgTx(2)
+		tx.LockTime = lockTime
+
+		for _, h := range ins {
+			tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(h, 0), nil, nil))
+			tx.TxIn[len(tx.TxIn)-1].Sequence = 0xfffffffd
+		}
+
+		tx.AddTxOut(wire.NewTxOut(outs[0], p2wpkhScript))
+		tx.AddTxOut(wire.NewTxOut(outs[1], changeScript))
+
+		return tx
+	}
+
+	// finalized input with the value of the output it spends
+	final := func(val int64) psbt.PInput {
+		return psbt.PInput{
+			WitnessUtxo:    wire.NewTxOut(val, p2wpkhScript),
+			FinalScriptSig: []byte{0x51},
+		}
+	}
+
+	sender, receiver := &chainhash.Hash{3}, &chainhash.Hash{4}
+
+	// the original pays 1000 sat for 114 vB, the proposal 1400 sat for
+	// 156 vB
+	orig := encodeTestPSBT(t, newTx(0, []*chainhash.Hash{sender}, 50000, 9000), []psbt.PInput{final(60000)})
+	prop := encodeTestPSBT(t, newTx(0, []*chainhash.Hash{sender, receiver}, 70000, 8800), []psbt.PInput{{}, final(20200)})
+
+	feeIndex := 1
+	uri := "bitcoin:bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4?amount=0.0005&pj=https://test.com/pj"
+
+	check := func(r *http.Request) error {
+		q := r.URL.Query()
+		if q.Get("v") != "1" || q.Get("additionalfeeoutputindex") != "1" || q.Get("maxadditionalfeecontribution") != "300" {
+			return errors.New("invalid query params")
+		}
+
+		if r.Header.Get("Content-Type") != "text/plain" {
+			return errors.New("invalid content type")
+		}
+
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+
+		if string(b) != orig {
+			return errors.New("invalid body")
+		}
+
+		return nil
+	}
+
+	cc := map[string]struct {
+		URI    string
+		PSBT   string
+		Params PayjoinParams
+		Resp   httpmock.Responder
+		Sent   bool
+		Err    bool
+	}{
+		"Invalid BIP21 URI": {
+			URI:  "test:123",
+			PSBT: orig,
+			Err:  true,
+		},
+		"Insecure payjoin endpoint": {
+			URI:  "bitcoin:bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4?pj=http://test.com/pj",
+			PSBT: orig,
+			Err:  true,
+		},
+		"Invalid original PSBT": {
+			URI:  uri,
+			PSBT: "???",
+			Err:  true,
+		},
+		"Original PSBT not finalized": {
+			URI:  uri,
+			PSBT: encodeTestPSBT(t, newTx(0, []*chainhash.Hash{sender}, 50000, 9000), []psbt.PInput{{}}),
+			Err:  true,
+		},
+		"Original PSBT without UTXO data": {
+			URI: uri,
+			PSBT: encodeTestPSBT(t, newTx(0, []*chainhash.Hash{sender}, 50000, 9000),
+				[]psbt.PInput{{FinalScriptSig: []byte{0x51}}}),
+			Err: true,
+		},
+		"Invalid additional fee output index": {
+			URI:    uri,
+			PSBT:   orig,
+			Params: PayjoinParams{AdditionalFeeOutputIndex: func() *int { i := 2; return &i }()},
+			Err:    true,
+		},
+		"Error returned during request sending": {
+			URI:    uri,
+			PSBT:   orig,
+			Params: PayjoinParams{AdditionalFeeOutputIndex: &feeIndex, MaxAdditionalFeeContribution: 300},
+			Resp:   httpmock.NewErrorResponder(assert.AnError),
+			Sent:   true,
+			Err:    true,
+		},
+		"Error returned by the receiver": {
+			URI:    uri,
+			PSBT:   orig,
+			Params: PayjoinParams{AdditionalFeeOutputIndex: &feeIndex, MaxAdditionalFeeContribution: 300},
+			Resp:   httpmock.NewStringResponder(http.StatusServiceUnavailable, `{"errorCode":"unavailable","message":"test"}`),
+			Sent:   true,
+			Err:    true,
+		},
+		"Invalid proposal": {
+			URI:    uri,
+			PSBT:   orig,
+			Params: PayjoinParams{AdditionalFeeOutputIndex: &feeIndex, MaxAdditionalFeeContribution: 300},
+			Resp:   httpmock.NewStringResponder(http.StatusOK, "test"),
+			Sent:   true,
+			Err:    true,
+		},
+		"Proposal with modified lock time": {
+			URI:    uri,
+			PSBT:   orig,
+			Params: PayjoinParams{AdditionalFeeOutputIndex: &feeIndex, MaxAdditionalFeeContribution: 300},
+			Resp: httpmock.NewStringResponder(http.StatusOK, encodeTestPSBT(t,
+				newTx(1, []*chainhash.Hash{sender, receiver}, 70000, 8800), []psbt.PInput{{}, final(20200)})),
+			Sent: true,
+			Err:  true,
+		},
+		"Proposal with finalized sender input": {
+			URI:    uri,
+			PSBT:   orig,
+			Params: PayjoinParams{AdditionalFeeOutputIndex: &feeIndex, MaxAdditionalFeeContribution: 300},
+			Resp: httpmock.NewStringResponder(http.StatusOK, encodeTestPSBT(t,
+				newTx(0, []*chainhash.Hash{sender, receiver}, 70000, 8800), []psbt.PInput{final(60000), final(20200)})),
+			Sent: true,
+			Err:  true,
+		},
+		"Proposal with unsigned receiver input": {
+			URI:    uri,
+			PSBT:   orig,
+			Params: PayjoinParams{AdditionalFeeOutputIndex: &feeIndex, MaxAdditionalFeeContribution: 300},
+			Resp: httpmock.NewStringResponder(http.StatusOK, encodeTestPSBT(t,
+				newTx(0, []*chainhash.Hash{sender, receiver}, 70000, 8800), []psbt.PInput{{}, {}})),
+			Sent: true,
+			Err:  true,
+		},
+		"Proposal without sender input": {
+			URI:    uri,
+			PSBT:   orig,
+			Params: PayjoinParams{AdditionalFeeOutputIndex: &feeIndex, MaxAdditionalFeeContribution: 300},
+			Resp: httpmock.NewStringResponder(http.StatusOK, encodeTestPSBT(t,
+				newTx(0, []*chainhash.Hash{receiver}, 70000, 8800), []psbt.PInput{final(20200)})),
+			Sent: true,
+			Err:  true,
+		},
+		"Proposal exceeding fee contribution": {
+			URI:    uri,
+			PSBT:   orig,
+			Params: PayjoinParams{AdditionalFeeOutputIndex: &feeIndex, MaxAdditionalFeeContribution: 300},
+			Resp: httpmock.NewStringResponder(http.StatusOK, encodeTestPSBT(t,
+				newTx(0, []*chainhash.Hash{sender, receiver}, 70000, 8000), []psbt.PInput{{}, final(20200)})),
+			Sent: true,
+			Err:  true,
+		},
+		"Proposal decreasing payee output with substitution disabled": {
+			URI:  uri + "&pjos=0",
+			PSBT: orig,
+			Resp: httpmock.NewStringResponder(http.StatusOK, encodeTestPSBT(t,
+				newTx(0, []*chainhash.Hash{sender, receiver}, 40000, 9000), []psbt.PInput{{}, final(20200)})),
+			Sent: true,
+			Err:  true,
+		},
+		"Proposal without receiver UTXO data": {
+			URI:    uri,
+			PSBT:   orig,
+			Params: PayjoinParams{AdditionalFeeOutputIndex: &feeIndex, MaxAdditionalFeeContribution: 300},
+			Resp: httpmock.NewStringResponder(http.StatusOK, encodeTestPSBT(t,
+				newTx(0, []*chainhash.Hash{sender, receiver}, 70000, 8800),
+				[]psbt.PInput{{}, {FinalScriptSig: []byte{0x51}}})),
+			Sent: true,
+			Err:  true,
+		},
+		"Proposal taking the fee contribution": {
+			URI:    uri,
+			PSBT:   orig,
+			Params: PayjoinParams{AdditionalFeeOutputIndex: &feeIndex, MaxAdditionalFeeContribution: 500},
+			// 450 sat are taken from the sender, but the fee grows
+			// by 380 sat only
+			Resp: httpmock.NewStringResponder(http.StatusOK, encodeTestPSBT(t,
+				newTx(0, []*chainhash.Hash{sender, receiver}, 70000, 8550), []psbt.PInput{{}, final(19930)})),
+			Sent: true,
+			Err:  true,
+		},
+		"Proposal lowering the fee rate": {
+			URI:    uri,
+			PSBT:   orig,
+			Params: PayjoinParams{AdditionalFeeOutputIndex: &feeIndex, MaxAdditionalFeeContribution: 300},
+			// 1250 sat for 156 vB is less than 1000 sat for 114 vB
+			Resp: httpmock.NewStringResponder(http.StatusOK, encodeTestPSBT(t,
+				newTx(0, []*chainhash.Hash{sender, receiver}, 70000, 8800), []psbt.PInput{{}, final(20050)})),
+			Sent: true,
+			Err:  true,
+		},
+		"Proposal below the minimum fee rate": {
+			URI:    uri,
+			PSBT:   orig,
+			Params: PayjoinParams{AdditionalFeeOutputIndex: &feeIndex, MaxAdditionalFeeContribution: 300, MinFeeRate: 10},
+			Resp:   httpmock.NewStringResponder(http.StatusOK, prop),
+			Sent:   true,
+			Err:    true,
+		},
+		"Successful execution with onion endpoint": {
+			URI:    "bitcoin:bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4?amount=0.0005&pj=http://test.onion/pj",
+			PSBT:   orig,
+			Params: PayjoinParams{AdditionalFeeOutputIndex: &feeIndex, MaxAdditionalFeeContribution: 300},
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, prop), nil
+			},
+			Sent: true,
+		},
+		"Successful execution": {
+			URI:    uri,
+			PSBT:   orig,
+			Params: PayjoinParams{AdditionalFeeOutputIndex: &feeIndex, MaxAdditionalFeeContribution: 300},
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, prop+"\n"), nil
+			},
+			Sent: true,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			if c.Resp != nil {
+				mt.RegisterResponder(http.MethodPost, "https://test.com/pj", c.Resp)
+				mt.RegisterResponder(http.MethodPost, "http://test.onion/pj", c.Resp)
+			}
+
+			res, err := client.Payjoin(context.Background(), c.URI, c.PSBT, c.Params)
+
+			if c.Sent {
+				assert.Equal(t, 1, mt.GetTotalCallCount())
+			} else {
+				assert.Zero(t, mt.GetTotalCallCount())
+			}
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, prop, res)
+		})
+	}
+}
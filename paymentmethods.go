@@ -0,0 +1,211 @@
+package btcpay
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// PaymentMethod holds generic data of a store payment method.
+// More at: https://docs.btcpayserver.org/API/Greenfield/v1/#tag/Store-Payment-Methods
+type PaymentMethod struct {
+	Enabled    bool            `json:"enabled"`
+	CryptoCode string          `json:"cryptoCode"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// OnChainPaymentMethodParams holds data used to update an on-chain
+// payment method of a store.
+type OnChainPaymentMethodParams struct {
+	Enabled          bool   `json:"enabled"`
+	DerivationScheme string `json:"derivationScheme"`
+	Label            string `json:"label,omitempty"`
+	AccountKeyPath   string `json:"accountKeyPath,omitempty"`
+}
+
+// OnChainPaymentMethod holds data of an on-chain payment method of a
+// store.
+type OnChainPaymentMethod struct {
+	Enabled          bool   `json:"enabled"`
+	CryptoCode       string `json:"cryptoCode"`
+	PaymentMethod    string `json:"paymentMethod"`
+	DerivationScheme string `json:"derivationScheme"`
+	Label            string `json:"label"`
+	AccountKeyPath   string `json:"accountKeyPath"`
+}
+
+// OnChainAddressPreview holds an address derived from the derivation
+// scheme of an on-chain payment method.
+type OnChainAddressPreview struct {
+	KeyPath string `json:"keyPath"`
+	Address string `json:"address"`
+}
+
+// LightningPaymentMethodParams holds data used to update a Lightning
+// payment method of a store.
+type LightningPaymentMethodParams struct {
+	Enabled          bool   `json:"enabled"`
+	ConnectionString string `json:"connectionString"`
+}
+
+// LightningPaymentMethod holds data of a Lightning payment method of a
+// store.
+type LightningPaymentMethod struct {
+	Enabled          bool   `json:"enabled"`
+	CryptoCode       string `json:"cryptoCode"`
+	PaymentMethod    string `json:"paymentMethod"`
+	ConnectionString string `json:"connectionString"`
+}
+
+// PaymentMethods retrieves all payment methods of the specified store,
+// keyed by their payment method IDs.
+func (c *Client) PaymentMethods(ctx context.Context, storeID string, enabledOnly bool, opts ...RequestOption) (map[string]PaymentMethod, error) {
+	var params url.Values
+
+	if enabledOnly {
+		params = url.Values{}
+		params.Set("enabled", strconv.FormatBool(enabledOnly))
+	}
+
+	resp, err := c.sendAPI(ctx, http.MethodGet, newRoute("/api/v1/stores/:id/payment-methods", storeID), params, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var pms map[string]PaymentMethod
+
+	if err = c.decode(resp.Body, &pms); err != nil {
+		return nil, err
+	}
+
+	return pms, nil
+}
+
+// OnChainPaymentMethod retrieves the on-chain payment method of the
+// specified store and cryptocurrency.
+func (c *Client) OnChainPaymentMethod(ctx context.Context, storeID, cryptoCode string, opts ...RequestOption) (OnChainPaymentMethod, error) {
+	resp, err := c.sendAPI(ctx, http.MethodGet, newRoute("/api/v1/stores/:id/payment-methods/onchain/:id", storeID, cryptoCode), nil, nil, opts...)
+	if err != nil {
+		return OnChainPaymentMethod{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var pm OnChainPaymentMethod
+
+	if err = c.decode(resp.Body, &pm); err != nil {
+		return OnChainPaymentMethod{}, err
+	}
+
+	return pm, nil
+}
+
+// UpdateOnChainPaymentMethod creates or updates the on-chain payment
+// method of the specified store and cryptocurrency.
+func (c *Client) UpdateOnChainPaymentMethod(ctx context.Context, storeID, cryptoCode string, p OnChainPaymentMethodParams, opts ...RequestOption) (OnChainPaymentMethod, error) {
+	resp, err := c.sendAPI(ctx, http.MethodPut, newRoute("/api/v1/stores/:id/payment-methods/onchain/:id", storeID, cryptoCode), nil, p, opts...)
+	if err != nil {
+		return OnChainPaymentMethod{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var pm OnChainPaymentMethod
+
+	if err = c.decode(resp.Body, &pm); err != nil {
+		return OnChainPaymentMethod{}, err
+	}
+
+	return pm, nil
+}
+
+// RemoveOnChainPaymentMethod removes the on-chain payment method of the
+// specified store and cryptocurrency.
+func (c *Client) RemoveOnChainPaymentMethod(ctx context.Context, storeID, cryptoCode string, opts ...RequestOption) error {
+	resp, err := c.sendAPI(ctx, http.MethodDelete, newRoute("/api/v1/stores/:id/payment-methods/onchain/:id", storeID, cryptoCode), nil, nil, opts...)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// PreviewOnChainPaymentMethod derives the addresses of the provided
+// derivation scheme, starting at the offset, without saving it. This
+// allows the wallet configuration to be checked before the payment
+// method is updated.
+func (c *Client) PreviewOnChainPaymentMethod(ctx context.Context, storeID, cryptoCode string, p OnChainPaymentMethodParams, offset, count int, opts ...RequestOption) ([]OnChainAddressPreview, error) {
+	params := url.Values{}
+	params.Set("offset", strconv.Itoa(offset))
+	params.Set("amount", strconv.Itoa(count))
+
+	resp, err := c.sendAPI(ctx, http.MethodPost, newRoute("/api/v1/stores/:id/payment-methods/onchain/:id/preview", storeID, cryptoCode), params, p, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var res struct {
+		Addresses []OnChainAddressPreview `json:"addresses"`
+	}
+
+	if err = c.decode(resp.Body, &res); err != nil {
+		return nil, err
+	}
+
+	return res.Addresses, nil
+}
+
+// LightningPaymentMethod retrieves the Lightning payment method of the
+// specified store and cryptocurrency.
+func (c *Client) LightningPaymentMethod(ctx context.Context, storeID, cryptoCode string, opts ...RequestOption) (LightningPaymentMethod, error) {
+	resp, err := c.sendAPI(ctx, http.MethodGet, newRoute("/api/v1/stores/:id/payment-methods/LightningNetwork/:id", storeID, cryptoCode), nil, nil, opts...)
+	if err != nil {
+		return LightningPaymentMethod{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var pm LightningPaymentMethod
+
+	if err = c.decode(resp.Body, &pm); err != nil {
+		return LightningPaymentMethod{}, err
+	}
+
+	return pm, nil
+}
+
+// UpdateLightningPaymentMethod creates or updates the Lightning payment
+// method of the specified store and cryptocurrency.
+func (c *Client) UpdateLightningPaymentMethod(ctx context.Context, storeID, cryptoCode string, p LightningPaymentMethodParams, opts ...RequestOption) (LightningPaymentMethod, error) {
+	resp, err := c.sendAPI(ctx, http.MethodPut, newRoute("/api/v1/stores/:id/payment-methods/LightningNetwork/:id", storeID, cryptoCode), nil, p, opts...)
+	if err != nil {
+		return LightningPaymentMethod{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var pm LightningPaymentMethod
+
+	if err = c.decode(resp.Body, &pm); err != nil {
+		return LightningPaymentMethod{}, err
+	}
+
+	return pm, nil
+}
+
+// RemoveLightningPaymentMethod removes the Lightning payment method of
+// the specified store and cryptocurrency.
+func (c *Client) RemoveLightningPaymentMethod(ctx context.Context, storeID, cryptoCode string, opts ...RequestOption) error {
+	resp, err := c.sendAPI(ctx, http.MethodDelete, newRoute("/api/v1/stores/:id/payment-methods/LightningNetwork/:id", storeID, cryptoCode), nil, nil, opts...)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
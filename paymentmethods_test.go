@@ -0,0 +1,479 @@
+package btcpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_PaymentMethods(t *testing.T) {
+	check := func(r *http.Request) error {
+		if r.URL.Query().Get("enabled") != "true" {
+			return errors.New("invalid query params")
+		}
+
+		return nil
+	}
+
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result map[string]PaymentMethod
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, "["), nil
+			},
+			Err: true,
+		},
+		"Successful execution": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"BTC":{"enabled":true,"cryptoCode":"BTC","data":{"label":"l1"}}}`), nil
+			},
+			Result: map[string]PaymentMethod{"BTC": {Enabled: true, CryptoCode: "BTC", Data: json.RawMessage(`{"label":"l1"}`)}},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/stores/s1/payment-methods", c.Resp)
+
+			res, err := client.PaymentMethods(context.Background(), "s1", true)
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodGet+" http://test.com/api/v1/stores/s1/payment-methods"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Nil(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_OnChainPaymentMethod(t *testing.T) {
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result OnChainPaymentMethod
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, "{"),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `{"enabled":true,"cryptoCode":"BTC","derivationScheme":"xpub1"}`),
+			Result: OnChainPaymentMethod{Enabled: true, CryptoCode: "BTC", DerivationScheme: "xpub1"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/stores/s1/payment-methods/onchain/BTC", c.Resp)
+
+			res, err := client.OnChainPaymentMethod(context.Background(), "s1", "BTC")
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodGet+" http://test.com/api/v1/stores/s1/payment-methods/onchain/BTC"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_UpdateOnChainPaymentMethod(t *testing.T) {
+	check := func(r *http.Request) error {
+		var p OnChainPaymentMethodParams
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			return err
+		}
+
+		if !p.Enabled || p.DerivationScheme != "xpub1" {
+			return errors.New("invalid payload")
+		}
+
+		return nil
+	}
+
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result OnChainPaymentMethod
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, "{"), nil
+			},
+			Err: true,
+		},
+		"Successful execution": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"enabled":true,"cryptoCode":"BTC","derivationScheme":"xpub1"}`), nil
+			},
+			Result: OnChainPaymentMethod{Enabled: true, CryptoCode: "BTC", DerivationScheme: "xpub1"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodPut, "http://test.com/api/v1/stores/s1/payment-methods/onchain/BTC", c.Resp)
+
+			res, err := client.UpdateOnChainPaymentMethod(context.Background(), "s1", "BTC", OnChainPaymentMethodParams{Enabled: true, DerivationScheme: "xpub1"})
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodPut+" http://test.com/api/v1/stores/s1/payment-methods/onchain/BTC"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_RemoveOnChainPaymentMethod(t *testing.T) {
+	cc := map[string]struct {
+		Resp httpmock.Responder
+		Err  bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, ""),
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodDelete, "http://test.com/api/v1/stores/s1/payment-methods/onchain/BTC", c.Resp)
+
+			err = client.RemoveOnChainPaymentMethod(context.Background(), "s1", "BTC")
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodDelete+" http://test.com/api/v1/stores/s1/payment-methods/onchain/BTC"])
+
+			if c.Err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_Client_PreviewOnChainPaymentMethod(t *testing.T) {
+	check := func(r *http.Request) error {
+		var p OnChainPaymentMethodParams
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			return err
+		}
+
+		if p.DerivationScheme != "xpub1" {
+			return errors.New("invalid payload")
+		}
+
+		return nil
+	}
+
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result []OnChainAddressPreview
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, "{"), nil
+			},
+			Err: true,
+		},
+		"Successful execution": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"addresses":[{"keyPath":"0/5","address":"bc1q1"},{"keyPath":"0/6","address":"bc1q2"}]}`), nil
+			},
+			Result: []OnChainAddressPreview{
+				{KeyPath: "0/5", Address: "bc1q1"},
+				{KeyPath: "0/6", Address: "bc1q2"},
+			},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponderWithQuery(http.MethodPost, "http://test.com/api/v1/stores/s1/payment-methods/onchain/BTC/preview", "offset=5&amount=2", c.Resp)
+
+			res, err := client.PreviewOnChainPaymentMethod(context.Background(), "s1", "BTC", OnChainPaymentMethodParams{DerivationScheme: "xpub1"}, 5, 2)
+
+			assert.Equal(t, 1, mt.GetTotalCallCount())
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Nil(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_LightningPaymentMethod(t *testing.T) {
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result LightningPaymentMethod
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, "{"),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `{"enabled":true,"cryptoCode":"BTC","connectionString":"type=lnd-rest"}`),
+			Result: LightningPaymentMethod{Enabled: true, CryptoCode: "BTC", ConnectionString: "type=lnd-rest"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/stores/s1/payment-methods/LightningNetwork/BTC", c.Resp)
+
+			res, err := client.LightningPaymentMethod(context.Background(), "s1", "BTC")
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodGet+" http://test.com/api/v1/stores/s1/payment-methods/LightningNetwork/BTC"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_UpdateLightningPaymentMethod(t *testing.T) {
+	check := func(r *http.Request) error {
+		var p LightningPaymentMethodParams
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			return err
+		}
+
+		if !p.Enabled || p.ConnectionString != "type=lnd-rest" {
+			return errors.New("invalid payload")
+		}
+
+		return nil
+	}
+
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result LightningPaymentMethod
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, "{"), nil
+			},
+			Err: true,
+		},
+		"Successful execution": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"enabled":true,"cryptoCode":"BTC","connectionString":"type=lnd-rest"}`), nil
+			},
+			Result: LightningPaymentMethod{Enabled: true, CryptoCode: "BTC", ConnectionString: "type=lnd-rest"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodPut, "http://test.com/api/v1/stores/s1/payment-methods/LightningNetwork/BTC", c.Resp)
+
+			res, err := client.UpdateLightningPaymentMethod(context.Background(), "s1", "BTC", LightningPaymentMethodParams{Enabled: true, ConnectionString: "type=lnd-rest"})
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodPut+" http://test.com/api/v1/stores/s1/payment-methods/LightningNetwork/BTC"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_RemoveLightningPaymentMethod(t *testing.T) {
+	cc := map[string]struct {
+		Resp httpmock.Responder
+		Err  bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, ""),
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodDelete, "http://test.com/api/v1/stores/s1/payment-methods/LightningNetwork/BTC", c.Resp)
+
+			err = client.RemoveLightningPaymentMethod(context.Background(), "s1", "BTC")
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodDelete+" http://test.com/api/v1/stores/s1/payment-methods/LightningNetwork/BTC"])
+
+			if c.Err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
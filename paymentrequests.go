@@ -0,0 +1,125 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/shopspring/decimal"
+)
+
+// PaymentRequestParams holds data used to create or update a payment
+// request.
+// More at: https://docs.btcpayserver.org/API/Greenfield/v1/#tag/Payment-Requests
+type PaymentRequestParams struct {
+	Amount                    decimal.Decimal `json:"amount"`
+	Title                     string          `json:"title"`
+	Currency                  string          `json:"currency,omitempty"`
+	Email                     string          `json:"email,omitempty"`
+	Description               string          `json:"description,omitempty"`
+	ExpiryDate                int64           `json:"expiryDate,omitempty"`
+	EmbeddedCSS               string          `json:"embeddedCSS,omitempty"`
+	CustomCSSLink             string          `json:"customCSSLink,omitempty"`
+	AllowCustomPaymentAmounts bool            `json:"allowCustomPaymentAmounts,omitempty"`
+}
+
+// PaymentRequest holds payment request data retrieved from the payment
+// processor.
+type PaymentRequest struct {
+	ID                        string          `json:"id"`
+	StoreID                   string          `json:"storeId"`
+	Status                    string          `json:"status"`
+	CreatedTime               int64           `json:"createdTime"`
+	Archived                  bool            `json:"archived"`
+	Amount                    decimal.Decimal `json:"amount"`
+	Title                     string          `json:"title"`
+	Currency                  string          `json:"currency"`
+	Email                     string          `json:"email"`
+	Description               string          `json:"description"`
+	ExpiryDate                int64           `json:"expiryDate"`
+	EmbeddedCSS               string          `json:"embeddedCSS"`
+	CustomCSSLink             string          `json:"customCSSLink"`
+	AllowCustomPaymentAmounts bool            `json:"allowCustomPaymentAmounts"`
+}
+
+// CreatePaymentRequest creates a new payment request in the specified
+// store.
+func (c *Client) CreatePaymentRequest(ctx context.Context, storeID string, p PaymentRequestParams, opts ...RequestOption) (PaymentRequest, error) {
+	resp, err := c.sendAPI(ctx, http.MethodPost, newRoute("/api/v1/stores/:id/payment-requests", storeID), nil, p, opts...)
+	if err != nil {
+		return PaymentRequest{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var pr PaymentRequest
+
+	if err = c.decode(resp.Body, &pr); err != nil {
+		return PaymentRequest{}, err
+	}
+
+	return pr, nil
+}
+
+// PaymentRequests retrieves all payment requests of the specified store.
+func (c *Client) PaymentRequests(ctx context.Context, storeID string, opts ...RequestOption) ([]PaymentRequest, error) {
+	resp, err := c.sendAPI(ctx, http.MethodGet, newRoute("/api/v1/stores/:id/payment-requests", storeID), nil, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var prs []PaymentRequest
+
+	if err = c.decode(resp.Body, &prs); err != nil {
+		return nil, err
+	}
+
+	return prs, nil
+}
+
+// PaymentRequest retrieves a payment request by the provided ID.
+func (c *Client) PaymentRequest(ctx context.Context, storeID, id string, opts ...RequestOption) (PaymentRequest, error) {
+	resp, err := c.sendAPI(ctx, http.MethodGet, newRoute("/api/v1/stores/:id/payment-requests/:id", storeID, id), nil, nil, opts...)
+	if err != nil {
+		return PaymentRequest{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var pr PaymentRequest
+
+	if err = c.decode(resp.Body, &pr); err != nil {
+		return PaymentRequest{}, err
+	}
+
+	return pr, nil
+}
+
+// UpdatePaymentRequest updates the specified payment request.
+func (c *Client) UpdatePaymentRequest(ctx context.Context, storeID, id string, p PaymentRequestParams, opts ...RequestOption) (PaymentRequest, error) {
+	resp, err := c.sendAPI(ctx, http.MethodPut, newRoute("/api/v1/stores/:id/payment-requests/:id", storeID, id), nil, p, opts...)
+	if err != nil {
+		return PaymentRequest{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var pr PaymentRequest
+
+	if err = c.decode(resp.Body, &pr); err != nil {
+		return PaymentRequest{}, err
+	}
+
+	return pr, nil
+}
+
+// ArchivePaymentRequest archives the specified payment request.
+func (c *Client) ArchivePaymentRequest(ctx context.Context, storeID, id string, opts ...RequestOption) error {
+	resp, err := c.sendAPI(ctx, http.MethodDelete, newRoute("/api/v1/stores/:id/payment-requests/:id", storeID, id), nil, nil, opts...)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
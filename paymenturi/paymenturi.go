@@ -0,0 +1,138 @@
+// Package paymenturi builds payment URIs and QR codes that can be
+// rendered on checkout pages.
+package paymenturi
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"github.com/skip2/go-qrcode"
+	"github.com/swithek/btcpay-go"
+)
+
+// BIP21Params holds optional data of a BIP21 URI.
+// More at: https://github.com/bitcoin/bips/blob/master/bip-0021.mediawiki
+type BIP21Params struct {
+	Amount  decimal.Decimal
+	Label   string
+	Message string
+
+	// Payjoin is the payjoin endpoint of the receiver.
+	Payjoin string
+}
+
+// BIP21 builds a BIP21 URI that pays to the specified address.
+func BIP21(address string, p BIP21Params) string {
+	var params []string
+
+	if p.Amount.IsPositive() {
+		params = append(params, "amount="+p.Amount.String())
+	}
+
+	if p.Label != "" {
+		params = append(params, "label="+escape(p.Label))
+	}
+
+	if p.Message != "" {
+		params = append(params, "message="+escape(p.Message))
+	}
+
+	if p.Payjoin != "" {
+		params = append(params, "pj="+escape(p.Payjoin))
+	}
+
+	uri := "bitcoin:" + address
+	if len(params) > 0 {
+		uri += "?" + strings.Join(params, "&")
+	}
+
+	return uri
+}
+
+// BOLT11 builds a URI of the specified lightning invoice.
+func BOLT11(invoice string) string {
+	if strings.HasPrefix(strings.ToLower(invoice), "lightning:") {
+		return invoice
+	}
+
+	return "lightning:" + invoice
+}
+
+// escape escapes the URI parameter value. Spaces are encoded as %20,
+// since not all wallets decode + signs.
+func escape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// Payment holds a ready-to-render payment string of a single payment
+// method of an invoice.
+type Payment struct {
+	CryptoCode  string
+	PaymentType string
+	URI         string
+}
+
+// FromInvoice returns payment strings of all payment methods of the
+// invoice. URIs provided by the server are preferred; otherwise they
+// are built from the payment address and due amount.
+func FromInvoice(inv btcpay.Invoice) []Payment {
+	pp := make([]Payment, 0, len(inv.CryptoInfo))
+
+	for _, ci := range inv.CryptoInfo {
+		p := Payment{CryptoCode: ci.CryptoCode, PaymentType: ci.PaymentType}
+
+		switch {
+		case ci.PaymentURLs.BOLT11 != "":
+			p.URI = BOLT11(ci.PaymentURLs.BOLT11)
+		case ci.PaymentURLs.BIP21 != "":
+			p.URI = ci.PaymentURLs.BIP21
+		case ci.Address == "":
+			continue
+		case strings.Contains(strings.ToLower(ci.PaymentType), "lightning"):
+			p.URI = BOLT11(ci.Address)
+		default:
+			p.URI = BIP21(ci.Address, BIP21Params{Amount: ci.Due})
+		}
+
+		pp = append(pp, p)
+	}
+
+	return pp
+}
+
+// PNG encodes the content as a QR code PNG image of the specified
+// size in pixels.
+func PNG(content string, size int) ([]byte, error) {
+	return qrcode.Encode(content, qrcode.Medium, size)
+}
+
+// SVG encodes the content as a QR code SVG image of the specified
+// size in pixels.
+func SVG(content string, size int) ([]byte, error) {
+	q, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return nil, err
+	}
+
+	bm := q.Bitmap()
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size, len(bm), len(bm))
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, len(bm), len(bm))
+
+	for y, row := range bm {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+
+	buf.WriteString(`"/></svg>`)
+
+	return buf.Bytes(), nil
+}
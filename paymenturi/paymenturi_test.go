@@ -0,0 +1,119 @@
+package paymenturi
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swithek/btcpay-go"
+)
+
+func Test_BIP21(t *testing.T) {
+	cc := map[string]struct {
+		Params BIP21Params
+		Result string
+	}{
+		"Address only": {
+			Result: "bitcoin:bc1qtest",
+		},
+		"Zero amount": {
+			Params: BIP21Params{Amount: decimal.Zero},
+			Result: "bitcoin:bc1qtest",
+		},
+		"All params": {
+			Params: BIP21Params{
+				Amount:  decimal.RequireFromString("0.00150000"),
+				Label:   "Coffee & cake",
+				Message: "Order #1",
+				Payjoin: "https://test.com/BTC/pj?a=1",
+			},
+			Result: "bitcoin:bc1qtest?amount=0.0015&label=Coffee%20%26%20cake&message=Order%20%231&pj=https%3A%2F%2Ftest.com%2FBTC%2Fpj%3Fa%3D1",
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, c.Result, BIP21("bc1qtest", c.Params))
+		})
+	}
+}
+
+func Test_BOLT11(t *testing.T) {
+	assert.Equal(t, "lightning:lnbc1test", BOLT11("lnbc1test"))
+	assert.Equal(t, "LIGHTNING:LNBC1TEST", BOLT11("LIGHTNING:LNBC1TEST"))
+}
+
+func Test_FromInvoice(t *testing.T) {
+	inv := btcpay.Invoice{
+		CryptoInfo: []btcpay.CryptoInfo{
+			{
+				CryptoCode:  "BTC",
+				PaymentType: "BTCLike",
+				Address:     "bc1qtest",
+				PaymentURLs: btcpay.PaymentURLs{BIP21: "bitcoin:bc1qtest?amount=1"},
+			},
+			{
+				CryptoCode:  "BTC",
+				PaymentType: "LightningLike",
+				Address:     "lnbc1test",
+				PaymentURLs: btcpay.PaymentURLs{BOLT11: "lightning:lnbc1test"},
+			},
+			{
+				CryptoCode:  "LTC",
+				PaymentType: "BTCLike",
+			},
+			{
+				CryptoCode:  "BTC",
+				PaymentType: "BTCLike",
+				Address:     "bc1qtest2",
+				Due:         decimal.RequireFromString("0.5"),
+			},
+			{
+				CryptoCode:  "BTC",
+				PaymentType: "LightningLike",
+				Address:     "lnbc2test",
+			},
+		},
+	}
+
+	assert.Equal(t, []Payment{
+		{CryptoCode: "BTC", PaymentType: "BTCLike", URI: "bitcoin:bc1qtest?amount=1"},
+		{CryptoCode: "BTC", PaymentType: "LightningLike", URI: "lightning:lnbc1test"},
+		{CryptoCode: "BTC", PaymentType: "BTCLike", URI: "bitcoin:bc1qtest2?amount=0.5"},
+		{CryptoCode: "BTC", PaymentType: "LightningLike", URI: "lightning:lnbc2test"},
+	}, FromInvoice(inv))
+}
+
+func Test_PNG(t *testing.T) {
+	b, err := PNG("bitcoin:bc1qtest", 256)
+	require.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(b))
+	require.NoError(t, err)
+	assert.Equal(t, 256, img.Bounds().Dx())
+
+	_, err = PNG(strings.Repeat("a", 5000), 256)
+	assert.Error(t, err)
+}
+
+func Test_SVG(t *testing.T) {
+	b, err := SVG("bitcoin:bc1qtest", 256)
+	require.NoError(t, err)
+
+	s := string(b)
+	assert.True(t, strings.HasPrefix(s, `<svg xmlns="http://www.w3.org/2000/svg" width="256" height="256"`))
+	assert.True(t, strings.HasSuffix(s, "</svg>"))
+	assert.Contains(t, s, `viewBox="0 0 33 33"`)
+	assert.Contains(t, s, "M4 4h1v1h-1z")
+
+	_, err = SVG(strings.Repeat("a", 5000), 256)
+	assert.Error(t, err)
+}
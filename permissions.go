@@ -0,0 +1,138 @@
+package btcpay
+
+import "strings"
+
+// Permission is a Greenfield API key permission. A store permission may
+// be limited to a single store with ForStore; otherwise it applies to
+// all stores of the user.
+// More at: https://docs.btcpayserver.org/API/Greenfield/v1/#section/Authentication/APIKey
+type Permission string
+
+// Available permissions.
+const (
+	PermissionUnrestricted Permission = "unrestricted"
+
+	PermissionCanViewProfile                  Permission = "btcpay.user.canviewprofile"
+	PermissionCanModifyProfile                Permission = "btcpay.user.canmodifyprofile"
+	PermissionCanViewNotificationsForUser     Permission = "btcpay.user.canviewnotificationsforuser"
+	PermissionCanManageNotificationsForUser   Permission = "btcpay.user.canmanagenotificationsforuser"
+	PermissionCanModifyServerSettings         Permission = "btcpay.server.canmodifyserversettings"
+	PermissionCanUseInternalLightningNode     Permission = "btcpay.server.canuseinternallightningnode"
+	PermissionCanCreateLightningInvoiceInNode Permission = "btcpay.server.cancreatelightninginvoiceinternalnode"
+	PermissionCanManageUsers                  Permission = "btcpay.server.canmanageusers"
+	PermissionCanCreateUser                   Permission = "btcpay.server.cancreateuser"
+	PermissionCanViewUsers                    Permission = "btcpay.server.canviewusers"
+	PermissionCanDeleteUser                   Permission = "btcpay.server.candeleteuser"
+	PermissionCanModifyStoreSettings          Permission = "btcpay.store.canmodifystoresettings"
+	PermissionCanViewStoreSettings            Permission = "btcpay.store.canviewstoresettings"
+	PermissionCanModifyWebhooks               Permission = "btcpay.store.webhooks.canmodifywebhooks"
+	PermissionCanModifyInvoices               Permission = "btcpay.store.canmodifyinvoices"
+	PermissionCanViewInvoices                 Permission = "btcpay.store.canviewinvoices"
+	PermissionCanCreateInvoice                Permission = "btcpay.store.cancreateinvoice"
+	PermissionCanModifyPaymentRequests        Permission = "btcpay.store.canmodifypaymentrequests"
+	PermissionCanViewPaymentRequests          Permission = "btcpay.store.canviewpaymentrequests"
+	PermissionCanUseLightningNode             Permission = "btcpay.store.canuselightningnode"
+	PermissionCanCreateLightningInvoice       Permission = "btcpay.store.cancreatelightninginvoice"
+	PermissionCanManagePullPayments           Permission = "btcpay.store.canmanagepullpayments"
+	PermissionCanCreatePullPayments           Permission = "btcpay.store.cancreatenonapprovedpullpayments"
+)
+
+// subPermissions holds permissions implied by broader permissions.
+var subPermissions = map[Permission][]Permission{
+	PermissionCanModifyProfile:              {PermissionCanViewProfile},
+	PermissionCanManageNotificationsForUser: {PermissionCanViewNotificationsForUser},
+	PermissionCanModifyServerSettings:       {PermissionCanUseInternalLightningNode, PermissionCanManageUsers},
+	PermissionCanUseInternalLightningNode:   {PermissionCanCreateLightningInvoiceInNode},
+	PermissionCanManageUsers:                {PermissionCanCreateUser, PermissionCanViewUsers, PermissionCanDeleteUser},
+	PermissionCanModifyStoreSettings: {
+		PermissionCanViewStoreSettings,
+		PermissionCanModifyWebhooks,
+		PermissionCanModifyInvoices,
+		PermissionCanModifyPaymentRequests,
+		PermissionCanUseLightningNode,
+		PermissionCanManagePullPayments,
+	},
+	PermissionCanViewStoreSettings:     {PermissionCanViewInvoices, PermissionCanViewPaymentRequests},
+	PermissionCanModifyInvoices:        {PermissionCanViewInvoices, PermissionCanCreateInvoice, PermissionCanCreateLightningInvoice},
+	PermissionCanModifyPaymentRequests: {PermissionCanViewPaymentRequests},
+	PermissionCanUseLightningNode:      {PermissionCanCreateLightningInvoice},
+	PermissionCanManagePullPayments:    {PermissionCanCreatePullPayments},
+}
+
+// ForStore limits the permission to the specified store.
+func (p Permission) ForStore(storeID string) Permission {
+	return p.Policy() + Permission(":"+storeID)
+}
+
+// Policy returns the permission without its store scope.
+func (p Permission) Policy() Permission {
+	if i := strings.IndexByte(string(p), ':'); i >= 0 {
+		return p[:i]
+	}
+
+	return p
+}
+
+// StoreID returns the store that the permission is limited to, or an
+// empty string if it is not limited to a single store.
+func (p Permission) StoreID() string {
+	if i := strings.IndexByte(string(p), ':'); i >= 0 {
+		return string(p[i+1:])
+	}
+
+	return ""
+}
+
+// Satisfies checks whether the permission grants the required
+// permission, either directly or through a broader policy.
+func (p Permission) Satisfies(required Permission) bool {
+	if p.Policy() == PermissionUnrestricted {
+		return true
+	}
+
+	if sid := p.StoreID(); sid != "" && sid != required.StoreID() {
+		return false
+	}
+
+	return impliesPolicy(p.Policy(), required.Policy())
+}
+
+// impliesPolicy checks whether the policy is equal to or includes the
+// required policy.
+func impliesPolicy(p, required Permission) bool {
+	if p == required {
+		return true
+	}
+
+	for _, sp := range subPermissions[p] {
+		if impliesPolicy(sp, required) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Permissions is a set of permissions, e.g. of an API key.
+type Permissions []Permission
+
+// Satisfies checks whether every required permission is granted by at
+// least one permission of the set.
+func (pp Permissions) Satisfies(required Permissions) bool {
+	for _, r := range required {
+		ok := false
+
+		for _, p := range pp {
+			if p.Satisfies(r) {
+				ok = true
+				break
+			}
+		}
+
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,121 @@
+package btcpay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Permission_ForStore(t *testing.T) {
+	assert.Equal(t, Permission("btcpay.store.canviewinvoices:s1"), PermissionCanViewInvoices.ForStore("s1"))
+	assert.Equal(t, Permission("btcpay.store.canviewinvoices:s2"), PermissionCanViewInvoices.ForStore("s1").ForStore("s2"))
+}
+
+func Test_Permission_Policy(t *testing.T) {
+	assert.Equal(t, PermissionCanViewInvoices, PermissionCanViewInvoices.Policy())
+	assert.Equal(t, PermissionCanViewInvoices, PermissionCanViewInvoices.ForStore("s1").Policy())
+}
+
+func Test_Permission_StoreID(t *testing.T) {
+	assert.Empty(t, PermissionCanViewInvoices.StoreID())
+	assert.Equal(t, "s1", PermissionCanViewInvoices.ForStore("s1").StoreID())
+}
+
+func Test_Permission_Satisfies(t *testing.T) {
+	cc := map[string]struct {
+		Permission Permission
+		Required   Permission
+		Result     bool
+	}{
+		"Unrestricted": {
+			Permission: PermissionUnrestricted,
+			Required:   PermissionCanModifyServerSettings,
+			Result:     true,
+		},
+		"Same permission": {
+			Permission: PermissionCanViewInvoices,
+			Required:   PermissionCanViewInvoices,
+			Result:     true,
+		},
+		"Different permission": {
+			Permission: PermissionCanViewInvoices,
+			Required:   PermissionCanCreateInvoice,
+		},
+		"Narrower permission": {
+			Permission: PermissionCanViewInvoices,
+			Required:   PermissionCanModifyInvoices,
+		},
+		"Broader permission": {
+			Permission: PermissionCanModifyInvoices,
+			Required:   PermissionCanViewInvoices,
+			Result:     true,
+		},
+		"Transitively broader permission": {
+			Permission: PermissionCanModifyStoreSettings,
+			Required:   PermissionCanCreateLightningInvoice,
+			Result:     true,
+		},
+		"All stores permission for a single store": {
+			Permission: PermissionCanViewInvoices,
+			Required:   PermissionCanViewInvoices.ForStore("s1"),
+			Result:     true,
+		},
+		"Single store permission for all stores": {
+			Permission: PermissionCanViewInvoices.ForStore("s1"),
+			Required:   PermissionCanViewInvoices,
+		},
+		"Single store permission for a different store": {
+			Permission: PermissionCanModifyInvoices.ForStore("s1"),
+			Required:   PermissionCanViewInvoices.ForStore("s2"),
+		},
+		"Single store permission for the same store": {
+			Permission: PermissionCanModifyInvoices.ForStore("s1"),
+			Required:   PermissionCanViewInvoices.ForStore("s1"),
+			Result:     true,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, c.Result, c.Permission.Satisfies(c.Required))
+		})
+	}
+}
+
+func Test_Permissions_Satisfies(t *testing.T) {
+	cc := map[string]struct {
+		Permissions Permissions
+		Required    Permissions
+		Result      bool
+	}{
+		"No required permissions": {
+			Result: true,
+		},
+		"No permissions": {
+			Required: Permissions{PermissionCanViewProfile},
+		},
+		"Missing permission": {
+			Permissions: Permissions{PermissionCanModifyProfile, PermissionCanViewInvoices},
+			Required:    Permissions{PermissionCanViewProfile, PermissionCanCreateInvoice},
+		},
+		"All permissions granted": {
+			Permissions: Permissions{PermissionCanModifyProfile, PermissionCanModifyInvoices.ForStore("s1")},
+			Required:    Permissions{PermissionCanViewProfile, PermissionCanCreateInvoice.ForStore("s1")},
+			Result:      true,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, c.Result, c.Permissions.Satisfies(c.Required))
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package btcpay
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+)
+
+// signedPOSData is the envelope of POS data authenticated with an HMAC.
+type signedPOSData struct {
+	Data json.RawMessage `json:"data"`
+	HMAC string          `json:"hmac"`
+}
+
+// SetPOSData encodes the value as JSON and sets it as the invoice's POS
+// data.
+func (p *CreateInvoiceParams) SetPOSData(v interface{}) error {
+	d, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	p.POSData = string(d)
+
+	return nil
+}
+
+// SetSignedPOSData encodes the value as JSON and sets it as the
+// invoice's POS data along with an HMAC-SHA256 of the data, so that the
+// POS data returned in invoice notifications can be authenticated.
+func (p *CreateInvoiceParams) SetSignedPOSData(v interface{}, key []byte) error {
+	d, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	d, err = json.Marshal(signedPOSData{
+		Data: d,
+		HMAC: hex.EncodeToString(posDataMAC(d, key)),
+	})
+	if err != nil {
+		return err
+	}
+
+	p.POSData = string(d)
+
+	return nil
+}
+
+// POSDataAs decodes the invoice's JSON encoded POS data into the
+// provided value.
+func (inv Invoice) POSDataAs(out interface{}) error {
+	if inv.POSData == "" {
+		return errors.New("POS data is empty")
+	}
+
+	return json.Unmarshal([]byte(inv.POSData), out)
+}
+
+// SignedPOSDataAs authenticates the invoice's POS data set with
+// SetSignedPOSData and decodes it into the provided value.
+func (inv Invoice) SignedPOSDataAs(out interface{}, key []byte) error {
+	var sd signedPOSData
+	if err := inv.POSDataAs(&sd); err != nil {
+		return err
+	}
+
+	mac, err := hex.DecodeString(sd.HMAC)
+	if err != nil {
+		return err
+	}
+
+	if !hmac.Equal(mac, posDataMAC(sd.Data, key)) {
+		return errors.New("invalid POS data signature")
+	}
+
+	return json.Unmarshal(sd.Data, out)
+}
+
+// posDataMAC calculates the HMAC-SHA256 of the POS data.
+func posDataMAC(d, key []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(d) //nolint:errcheck // hash writes never fail
+
+	return h.Sum(nil)
+}
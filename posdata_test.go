@@ -0,0 +1,93 @@
+package btcpay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type posDataStub struct {
+	OrderID string `json:"orderId"`
+	Items   []int  `json:"items"`
+}
+
+func Test_CreateInvoiceParams_SetPOSData(t *testing.T) {
+	var p CreateInvoiceParams
+	assert.Error(t, p.SetPOSData(func() {}))
+	assert.Empty(t, p.POSData)
+
+	require.NoError(t, p.SetPOSData(posDataStub{OrderID: "o1", Items: []int{1, 2}}))
+	assert.Equal(t, `{"orderId":"o1","items":[1,2]}`, p.POSData)
+}
+
+func Test_Invoice_POSDataAs(t *testing.T) {
+	var res posDataStub
+
+	assert.Error(t, Invoice{}.POSDataAs(&res))
+	assert.Error(t, Invoice{POSData: "{"}.POSDataAs(&res))
+
+	require.NoError(t, Invoice{POSData: `{"orderId":"o1","items":[1,2]}`}.POSDataAs(&res))
+	assert.Equal(t, posDataStub{OrderID: "o1", Items: []int{1, 2}}, res)
+}
+
+func Test_SignedPOSData(t *testing.T) {
+	key := []byte("key123")
+
+	var p CreateInvoiceParams
+	assert.Error(t, p.SetSignedPOSData(func() {}, key))
+	assert.Empty(t, p.POSData)
+
+	require.NoError(t, p.SetSignedPOSData(posDataStub{OrderID: "o1", Items: []int{1, 2}}, key))
+
+	cc := map[string]struct {
+		POSData string
+		Key     []byte
+		Err     bool
+	}{
+		"Invalid POS data": {
+			POSData: "{",
+			Key:     key,
+			Err:     true,
+		},
+		"Invalid HMAC encoding": {
+			POSData: `{"data":{"orderId":"o1"},"hmac":"z"}`,
+			Key:     key,
+			Err:     true,
+		},
+		"Tampered POS data": {
+			POSData: `{"data":{"orderId":"o2","items":[1,2]},"hmac":"` + p.POSData[len(p.POSData)-66:len(p.POSData)-2] + `"}`,
+			Key:     key,
+			Err:     true,
+		},
+		"Invalid key": {
+			POSData: p.POSData,
+			Key:     []byte("key456"),
+			Err:     true,
+		},
+		"Successful decoding": {
+			POSData: p.POSData,
+			Key:     key,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			var res posDataStub
+
+			err := Invoice{POSData: c.POSData}.SignedPOSDataAs(&res, c.Key)
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, posDataStub{OrderID: "o1", Items: []int{1, 2}}, res)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package btcpay
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PrometheusMetrics is a Metrics collector that records request count,
+// error count and request latency, labeled by method, endpoint and
+// status, with Prometheus client vectors. It implements
+// prometheus.Collector, so it can be registered in an application's
+// existing registry and served with promhttp next to other metrics.
+// It is safe for concurrent use by multiple goroutines.
+type PrometheusMetrics struct {
+	requests *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewPrometheusMetrics creates a new Prometheus collector. Metric names
+// are prefixed with the namespace, which defaults to "btcpay_client".
+// If no buckets are provided, the Prometheus client default latency
+// buckets are used. The collector must be registered before its
+// metrics are exposed.
+func NewPrometheusMetrics(namespace string, buckets ...float64) *PrometheusMetrics {
+	if namespace == "" {
+		namespace = "btcpay_client"
+	}
+
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
+
+	labels := []string{"method", "endpoint", "status"}
+
+	return &PrometheusMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "Number of requests sent to the BTCPay server.",
+		}, labels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "errors_total",
+			Help:      "Number of requests that resulted in an error.",
+		}, labels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Duration of requests sent to the BTCPay server.",
+			Buckets:   buckets,
+		}, labels),
+	}
+}
+
+// RegisterPrometheusMetrics creates a new Prometheus collector, see
+// NewPrometheusMetrics, and registers it with the registerer.
+func RegisterPrometheusMetrics(reg prometheus.Registerer, namespace string, buckets ...float64) (*PrometheusMetrics, error) {
+	m := NewPrometheusMetrics(namespace, buckets...)
+
+	if err := reg.Register(m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// ObserveRequest records the completed request.
+func (m *PrometheusMetrics) ObserveRequest(method, endpoint string, status int, err error, d time.Duration) {
+	st := "none"
+	if status > 0 {
+		st = strconv.Itoa(status)
+	}
+
+	m.requests.WithLabelValues(method, endpoint, st).Inc()
+
+	if err != nil {
+		m.errors.WithLabelValues(method, endpoint, st).Inc()
+	}
+
+	m.duration.WithLabelValues(method, endpoint, st).Observe(d.Seconds())
+}
+
+// Describe sends the descriptors of all metrics to the channel.
+func (m *PrometheusMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.requests.Describe(ch)
+	m.errors.Describe(ch)
+	m.duration.Describe(ch)
+}
+
+// Collect sends the current values of all metrics to the channel.
+func (m *PrometheusMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.requests.Collect(ch)
+	m.errors.Collect(ch)
+	m.duration.Collect(ch)
+}
@@ -0,0 +1,61 @@
+package btcpay
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RegisterPrometheusMetrics(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+
+	m, err := RegisterPrometheusMetrics(reg, "", 0.01, 0.1)
+	require.NoError(t, err)
+
+	_, err = RegisterPrometheusMetrics(reg, "")
+	assert.Error(t, err)
+
+	m.ObserveRequest(http.MethodGet, "/invoices/:id", http.StatusOK, nil, time.Millisecond*50)
+	m.ObserveRequest(http.MethodGet, "/invoices/:id", http.StatusOK, nil, time.Millisecond*5)
+	m.ObserveRequest(http.MethodPost, `/a"b`, 0, assert.AnError, time.Second)
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP btcpay_client_requests_total Number of requests sent to the BTCPay server.
+# TYPE btcpay_client_requests_total counter
+btcpay_client_requests_total{endpoint="/a\"b",method="POST",status="none"} 1
+btcpay_client_requests_total{endpoint="/invoices/:id",method="GET",status="200"} 2
+# HELP btcpay_client_errors_total Number of requests that resulted in an error.
+# TYPE btcpay_client_errors_total counter
+btcpay_client_errors_total{endpoint="/a\"b",method="POST",status="none"} 1
+# HELP btcpay_client_request_duration_seconds Duration of requests sent to the BTCPay server.
+# TYPE btcpay_client_request_duration_seconds histogram
+btcpay_client_request_duration_seconds_bucket{endpoint="/a\"b",method="POST",status="none",le="0.01"} 0
+btcpay_client_request_duration_seconds_bucket{endpoint="/a\"b",method="POST",status="none",le="0.1"} 0
+btcpay_client_request_duration_seconds_bucket{endpoint="/a\"b",method="POST",status="none",le="+Inf"} 1
+btcpay_client_request_duration_seconds_sum{endpoint="/a\"b",method="POST",status="none"} 1
+btcpay_client_request_duration_seconds_count{endpoint="/a\"b",method="POST",status="none"} 1
+btcpay_client_request_duration_seconds_bucket{endpoint="/invoices/:id",method="GET",status="200",le="0.01"} 1
+btcpay_client_request_duration_seconds_bucket{endpoint="/invoices/:id",method="GET",status="200",le="0.1"} 2
+btcpay_client_request_duration_seconds_bucket{endpoint="/invoices/:id",method="GET",status="200",le="+Inf"} 2
+btcpay_client_request_duration_seconds_sum{endpoint="/invoices/:id",method="GET",status="200"} 0.055
+btcpay_client_request_duration_seconds_count{endpoint="/invoices/:id",method="GET",status="200"} 2
+`)))
+}
+
+func Test_NewPrometheusMetrics(t *testing.T) {
+	m := NewPrometheusMetrics("shop")
+
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(m))
+
+	m.ObserveRequest(http.MethodGet, "/api/v1/health", http.StatusOK, nil, time.Millisecond)
+
+	assert.Equal(t, 1, testutil.CollectAndCount(m, "shop_requests_total"))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.requests))
+}
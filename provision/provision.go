@@ -0,0 +1,540 @@
+// Package provision converges a BTCPay store to a declared state, so
+// that servers can be managed the same way as the rest of the
+// infrastructure, e.g. from configuration kept in version control.
+package provision
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+
+	"github.com/swithek/btcpay-go"
+)
+
+// Action specifies what was, or would be in a dry run, done to a
+// resource to converge it to the declared state.
+type Action string
+
+// Available actions.
+const (
+	ActionNone   Action = "none"
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Kinds of provisioned resources.
+const (
+	KindStore     = "store"
+	KindOnChain   = "onchain"
+	KindLightning = "lightning"
+	KindWebhook   = "webhook"
+	KindAPIKey    = "apikey"
+)
+
+// State holds the desired state of a store. The store is identified by
+// its name, on-chain and Lightning payment methods by their crypto
+// codes, webhooks by their URLs and API keys by their labels.
+type State struct {
+	Store     btcpay.StoreParams
+	OnChain   map[string]btcpay.OnChainPaymentMethodParams
+	Lightning map[string]btcpay.LightningPaymentMethodParams
+	Webhooks  []btcpay.WebhookParams
+	APIKeys   []APIKey
+}
+
+// APIKey holds the desired state of an API key. The server does not
+// list existing API keys, so the key of a previously created API key
+// must be provided in order to reuse it; otherwise a new key is
+// created.
+type APIKey struct {
+	Label       string
+	Permissions btcpay.Permissions
+
+	// StoreScoped limits all permissions to the provisioned store.
+	StoreScoped bool
+
+	// Key is the API key created by a previous run, if any.
+	Key string
+}
+
+// Change describes the action applied to a single resource.
+type Change struct {
+	Kind   string
+	Name   string
+	Action Action
+}
+
+// Result holds the outcome of a provisioning run.
+type Result struct {
+	// StoreID is empty if the store would be created in a dry run.
+	StoreID string
+	Changes []Change
+
+	// APIKeys holds the keys of all declared API keys, keyed by their
+	// labels. Newly created keys must be stored by the caller.
+	APIKeys map[string]string
+}
+
+// Changed checks whether any resource was created, updated or
+// deleted.
+func (r Result) Changed() bool {
+	for _, ch := range r.Changes {
+		if ch.Action != ActionNone {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Option configures the provisioner.
+type Option func(p *Provisioner)
+
+// WithDryRun makes the provisioner only report the changes that would
+// be applied.
+func WithDryRun() Option {
+	return func(p *Provisioner) {
+		p.dryRun = true
+	}
+}
+
+// WithPrune makes the provisioner delete webhooks that are not
+// declared in the state.
+func WithPrune() Option {
+	return func(p *Provisioner) {
+		p.prune = true
+	}
+}
+
+// Provisioner converges stores to their declared states. The client
+// must use an API key with permissions to modify the stores and to
+// create API keys, if any are declared.
+type Provisioner struct {
+	c      *btcpay.Client
+	dryRun bool
+	prune  bool
+}
+
+// New creates a fresh instance of provisioner.
+func New(c *btcpay.Client, opts ...Option) *Provisioner {
+	p := &Provisioner{c: c}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Apply creates or updates the store and its resources so that they
+// match the state. Resources that already match are left untouched.
+func (p *Provisioner) Apply(ctx context.Context, s State) (Result, error) {
+	if s.Store.Name == "" {
+		return Result{}, errors.New("store name is required")
+	}
+
+	res := Result{APIKeys: make(map[string]string)}
+
+	steps := []func(context.Context, State, *Result) error{
+		p.applyStore,
+		p.applyOnChain,
+		p.applyLightning,
+		p.applyWebhooks,
+		p.applyAPIKeys,
+	}
+
+	for _, step := range steps {
+		if err := step(ctx, s, &res); err != nil {
+			return res, err
+		}
+	}
+
+	return res, nil
+}
+
+// applyStore creates or updates the store.
+func (p *Provisioner) applyStore(ctx context.Context, s State, res *Result) error {
+	ss, err := p.c.Stores(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, st := range ss {
+		if st.Name != s.Store.Name {
+			continue
+		}
+
+		res.StoreID = st.ID
+
+		ok, err := matches(s.Store, st)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			res.add(KindStore, st.Name, ActionNone)
+			return nil
+		}
+
+		// omitted fields must not be reset to their defaults
+		var sp btcpay.StoreParams
+
+		if err = merge(&sp, st, s.Store); err != nil {
+			return err
+		}
+
+		if !p.dryRun {
+			if _, err = p.c.UpdateStore(ctx, st.ID, sp); err != nil {
+				return err
+			}
+		}
+
+		res.add(KindStore, st.Name, ActionUpdate)
+
+		return nil
+	}
+
+	if !p.dryRun {
+		st, err := p.c.CreateStore(ctx, s.Store)
+		if err != nil {
+			return err
+		}
+
+		res.StoreID = st.ID
+	}
+
+	res.add(KindStore, s.Store.Name, ActionCreate)
+
+	return nil
+}
+
+// applyOnChain creates or updates the on-chain payment methods.
+func (p *Provisioner) applyOnChain(ctx context.Context, s State, res *Result) error {
+	for _, code := range sortedKeys(s.OnChain) {
+		want := s.OnChain[code]
+
+		act := ActionCreate
+
+		if res.StoreID != "" {
+			pm, err := p.c.OnChainPaymentMethod(ctx, res.StoreID, code)
+			switch {
+			case isNotFound(err):
+			case err != nil:
+				return err
+			default:
+				act, err = updateAction(want, pm)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if act != ActionNone && !p.dryRun {
+			if _, err := p.c.UpdateOnChainPaymentMethod(ctx, res.StoreID, code, want); err != nil {
+				return err
+			}
+		}
+
+		res.add(KindOnChain, code, act)
+	}
+
+	return nil
+}
+
+// applyLightning creates or updates the Lightning payment methods.
+func (p *Provisioner) applyLightning(ctx context.Context, s State, res *Result) error {
+	for _, code := range sortedKeys(s.Lightning) {
+		want := s.Lightning[code]
+
+		act := ActionCreate
+
+		if res.StoreID != "" {
+			pm, err := p.c.LightningPaymentMethod(ctx, res.StoreID, code)
+			switch {
+			case isNotFound(err):
+			case err != nil:
+				return err
+			default:
+				act, err = updateAction(want, pm)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if act != ActionNone && !p.dryRun {
+			if _, err := p.c.UpdateLightningPaymentMethod(ctx, res.StoreID, code, want); err != nil {
+				return err
+			}
+		}
+
+		res.add(KindLightning, code, act)
+	}
+
+	return nil
+}
+
+// applyWebhooks creates, updates and, if pruning is enabled, deletes
+// the webhooks. Secrets are not returned by the server, so changed
+// secrets alone are not detected.
+func (p *Provisioner) applyWebhooks(ctx context.Context, s State, res *Result) error {
+	existing := make(map[string]btcpay.Webhook)
+
+	if res.StoreID != "" {
+		whs, err := p.c.Webhooks(ctx, res.StoreID)
+		if err != nil {
+			return err
+		}
+
+		for _, wh := range whs {
+			existing[wh.URL] = wh
+		}
+	}
+
+	for _, want := range s.Webhooks {
+		wh, ok := existing[want.URL]
+		delete(existing, want.URL)
+
+		if !ok {
+			if !p.dryRun {
+				if _, err := p.c.CreateWebhook(ctx, res.StoreID, want); err != nil {
+					return err
+				}
+			}
+
+			res.add(KindWebhook, want.URL, ActionCreate)
+
+			continue
+		}
+
+		cmp := want
+		cmp.Secret = ""
+
+		act, err := updateAction(cmp, wh)
+		if err != nil {
+			return err
+		}
+
+		if act == ActionUpdate && !p.dryRun {
+			if _, err = p.c.UpdateWebhook(ctx, res.StoreID, wh.ID, want); err != nil {
+				return err
+			}
+		}
+
+		res.add(KindWebhook, want.URL, act)
+	}
+
+	if !p.prune {
+		return nil
+	}
+
+	urls := make([]string, 0, len(existing))
+	for u := range existing {
+		urls = append(urls, u)
+	}
+
+	sort.Strings(urls)
+
+	for _, u := range urls {
+		if !p.dryRun {
+			if err := p.c.DeleteWebhook(ctx, res.StoreID, existing[u].ID); err != nil {
+				return err
+			}
+		}
+
+		res.add(KindWebhook, u, ActionDelete)
+	}
+
+	return nil
+}
+
+// applyAPIKeys creates the API keys or, if their permissions differ,
+// replaces them.
+func (p *Provisioner) applyAPIKeys(ctx context.Context, s State, res *Result) error {
+	for _, want := range s.APIKeys {
+		perms := want.Permissions
+
+		if want.StoreScoped {
+			perms = make(btcpay.Permissions, len(want.Permissions))
+			for i, pm := range want.Permissions {
+				// the store ID is not known yet during a dry run
+				perms[i] = pm.ForStore(res.StoreID)
+			}
+		}
+
+		act := ActionCreate
+
+		if want.Key != "" {
+			k, err := p.c.CurrentAPIKey(ctx, btcpay.WithHeader("Authorization", "token "+want.Key))
+			switch {
+			case isUnauthorized(err):
+			case err != nil:
+				return err
+			case samePermissions(k.Permissions, perms):
+				act = ActionNone
+			default:
+				act = ActionUpdate
+			}
+		}
+
+		key := want.Key
+
+		if act != ActionNone && !p.dryRun {
+			if act == ActionUpdate {
+				if err := p.c.RevokeAPIKey(ctx, want.Key); err != nil {
+					return err
+				}
+			}
+
+			k, err := p.c.CreateAPIKey(ctx, btcpay.APIKeyParams{Label: want.Label, Permissions: perms})
+			if err != nil {
+				return err
+			}
+
+			key = k.APIKey
+		}
+
+		res.APIKeys[want.Label] = key
+		res.add(KindAPIKey, want.Label, act)
+	}
+
+	return nil
+}
+
+// add records a change of the resource.
+func (r *Result) add(kind, name string, act Action) {
+	r.Changes = append(r.Changes, Change{Kind: kind, Name: name, Action: act})
+}
+
+// updateAction returns the action needed to converge the current
+// resource to the desired one.
+func updateAction(want, cur interface{}) (Action, error) {
+	ok, err := matches(want, cur)
+	if err != nil {
+		return "", err
+	}
+
+	if ok {
+		return ActionNone, nil
+	}
+
+	return ActionUpdate, nil
+}
+
+// matches checks whether every field of the desired value's JSON
+// representation is equal to the same field of the current value.
+// Fields omitted from the desired value are ignored.
+func matches(want, cur interface{}) (bool, error) {
+	wm, err := toMap(want)
+	if err != nil {
+		return false, err
+	}
+
+	cm, err := toMap(cur)
+	if err != nil {
+		return false, err
+	}
+
+	for k, v := range wm {
+		if !reflect.DeepEqual(v, cm[k]) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// merge sets the fields of the desired value over the current value
+// and decodes the result into out.
+func merge(out, cur, want interface{}) error {
+	cm, err := toMap(cur)
+	if err != nil {
+		return err
+	}
+
+	wm, err := toMap(want)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range wm {
+		cm[k] = v
+	}
+
+	d, err := json.Marshal(cm)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(d, out)
+}
+
+// toMap converts the value into its generic JSON representation.
+func toMap(v interface{}) (map[string]interface{}, error) {
+	d, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var m map[string]interface{}
+
+	if err = json.Unmarshal(d, &m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// samePermissions checks whether both sets contain the same
+// permissions, regardless of their order.
+func samePermissions(a, b btcpay.Permissions) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	set := make(map[btcpay.Permission]int)
+
+	for _, p := range a {
+		set[p]++
+	}
+
+	for _, p := range b {
+		if set[p] == 0 {
+			return false
+		}
+
+		set[p]--
+	}
+
+	return true
+}
+
+// sortedKeys returns the keys of the map in a stable order.
+func sortedKeys(m interface{}) []string {
+	mv := reflect.ValueOf(m)
+	kk := make([]string, 0, mv.Len())
+
+	for _, k := range mv.MapKeys() {
+		kk = append(kk, k.String())
+	}
+
+	sort.Strings(kk)
+
+	return kk
+}
+
+// isNotFound checks whether the server responded with 404.
+func isNotFound(err error) bool {
+	var apiErr *btcpay.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// isUnauthorized checks whether the server rejected the API key.
+func isUnauthorized(err error) bool {
+	var apiErr *btcpay.APIError
+	return errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden)
+}
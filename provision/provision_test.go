@@ -0,0 +1,324 @@
+package provision
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swithek/btcpay-go"
+)
+
+func testState() State {
+	return State{
+		Store: btcpay.StoreParams{
+			Name:             "shop",
+			DefaultCurrency:  "EUR",
+			PaymentTolerance: decimal.NewFromInt(1),
+		},
+		OnChain: map[string]btcpay.OnChainPaymentMethodParams{
+			"BTC": {Enabled: true, DerivationScheme: "xpub1"},
+		},
+		Lightning: map[string]btcpay.LightningPaymentMethodParams{
+			"BTC": {Enabled: true, ConnectionString: "type=lnd-rest"},
+		},
+		Webhooks: []btcpay.WebhookParams{
+			{URL: "https://test.com/hook", Enabled: true, AuthorizedEvents: btcpay.WebhookEvents{Everything: true}, Secret: "s"},
+		},
+		APIKeys: []APIKey{
+			{Label: "shop", Permissions: btcpay.Permissions{btcpay.PermissionCanCreateInvoice}, StoreScoped: true, Key: "k1"},
+		},
+	}
+}
+
+func Test_Provisioner_Apply(t *testing.T) {
+	const (
+		storeURL     = "http://test.com/api/v1/stores/s1"
+		onChainURL   = storeURL + "/payment-methods/onchain/BTC"
+		lightningURL = storeURL + "/payment-methods/LightningNetwork/BTC"
+		webhooksURL  = storeURL + "/webhooks"
+	)
+
+	notFound := httpmock.NewStringResponder(http.StatusNotFound, `{"code":"not-found","message":"not found"}`)
+	store := `[{"id":"s1","name":"shop","defaultCurrency":"EUR","paymentTolerance":1,"invoiceExpiration":900}]`
+	onChain := `{"enabled":true,"cryptoCode":"BTC","derivationScheme":"xpub1","label":"main"}`
+	lightning := `{"enabled":true,"cryptoCode":"BTC","connectionString":"type=lnd-rest"}`
+	webhooks := `[{"id":"w1","url":"https://test.com/hook","enabled":true,"authorizedEvents":{"everything":true}}]`
+	key := `{"apiKey":"k1","permissions":["btcpay.store.cancreateinvoice:s1"]}`
+
+	cc := map[string]struct {
+		State     func(s *State)
+		Opts      []Option
+		Stores    string
+		OnChain   httpmock.Responder
+		Lightning httpmock.Responder
+		Webhooks  string
+		Key       httpmock.Responder
+		Changes   []Change
+		Calls     map[string]int
+		Keys      map[string]string
+		StoreID   string
+		Err       bool
+	}{
+		"Missing store name": {
+			State: func(s *State) {
+				s.Store.Name = ""
+			},
+			Err: true,
+		},
+		"Error returned during store retrieval": {
+			Err: true,
+		},
+		"Fresh server": {
+			State: func(s *State) {
+				s.APIKeys[0].Key = ""
+			},
+			Stores:    `[{"id":"s2","name":"other"}]`,
+			OnChain:   notFound,
+			Lightning: notFound,
+			Webhooks:  `[]`,
+			Changes: []Change{
+				{Kind: KindStore, Name: "shop", Action: ActionCreate},
+				{Kind: KindOnChain, Name: "BTC", Action: ActionCreate},
+				{Kind: KindLightning, Name: "BTC", Action: ActionCreate},
+				{Kind: KindWebhook, Name: "https://test.com/hook", Action: ActionCreate},
+				{Kind: KindAPIKey, Name: "shop", Action: ActionCreate},
+			},
+			Calls: map[string]int{
+				"POST http://test.com/api/v1/stores":   1,
+				"PUT " + onChainURL:                    1,
+				"PUT " + lightningURL:                  1,
+				"POST " + webhooksURL:                  1,
+				"POST http://test.com/api/v1/api-keys": 1,
+			},
+			Keys:    map[string]string{"shop": "k2"},
+			StoreID: "s1",
+		},
+		"Fresh server dry run": {
+			State: func(s *State) {
+				s.APIKeys[0].Key = ""
+			},
+			Opts:   []Option{WithDryRun()},
+			Stores: `[]`,
+			Changes: []Change{
+				{Kind: KindStore, Name: "shop", Action: ActionCreate},
+				{Kind: KindOnChain, Name: "BTC", Action: ActionCreate},
+				{Kind: KindLightning, Name: "BTC", Action: ActionCreate},
+				{Kind: KindWebhook, Name: "https://test.com/hook", Action: ActionCreate},
+				{Kind: KindAPIKey, Name: "shop", Action: ActionCreate},
+			},
+			Keys: map[string]string{"shop": ""},
+		},
+		"Converged server": {
+			Stores:    store,
+			OnChain:   httpmock.NewStringResponder(http.StatusOK, onChain),
+			Lightning: httpmock.NewStringResponder(http.StatusOK, lightning),
+			Webhooks:  webhooks,
+			Key:       httpmock.NewStringResponder(http.StatusOK, key),
+			Changes: []Change{
+				{Kind: KindStore, Name: "shop", Action: ActionNone},
+				{Kind: KindOnChain, Name: "BTC", Action: ActionNone},
+				{Kind: KindLightning, Name: "BTC", Action: ActionNone},
+				{Kind: KindWebhook, Name: "https://test.com/hook", Action: ActionNone},
+				{Kind: KindAPIKey, Name: "shop", Action: ActionNone},
+			},
+			Keys:    map[string]string{"shop": "k1"},
+			StoreID: "s1",
+		},
+		"Drifted server": {
+			Opts:      []Option{WithPrune()},
+			Stores:    `[{"id":"s1","name":"shop","defaultCurrency":"USD","paymentTolerance":1,"invoiceExpiration":900}]`,
+			OnChain:   httpmock.NewStringResponder(http.StatusOK, `{"enabled":false,"derivationScheme":"xpub1"}`),
+			Lightning: httpmock.NewStringResponder(http.StatusOK, lightning),
+			Webhooks:  `[{"id":"w1","url":"https://test.com/hook","enabled":false,"authorizedEvents":{"everything":true}},{"id":"w2","url":"https://test.com/old"}]`,
+			Key:       httpmock.NewStringResponder(http.StatusOK, `{"apiKey":"k1","permissions":["btcpay.store.canviewinvoices:s1"]}`),
+			Changes: []Change{
+				{Kind: KindStore, Name: "shop", Action: ActionUpdate},
+				{Kind: KindOnChain, Name: "BTC", Action: ActionUpdate},
+				{Kind: KindLightning, Name: "BTC", Action: ActionNone},
+				{Kind: KindWebhook, Name: "https://test.com/hook", Action: ActionUpdate},
+				{Kind: KindWebhook, Name: "https://test.com/old", Action: ActionDelete},
+				{Kind: KindAPIKey, Name: "shop", Action: ActionUpdate},
+			},
+			Calls: map[string]int{
+				"PUT " + storeURL:                           1,
+				"PUT " + onChainURL:                         1,
+				"PUT " + webhooksURL + "/w1":                1,
+				"DELETE " + webhooksURL + "/w2":             1,
+				"DELETE http://test.com/api/v1/api-keys/k1": 1,
+				"POST http://test.com/api/v1/api-keys":      1,
+			},
+			Keys:    map[string]string{"shop": "k2"},
+			StoreID: "s1",
+		},
+		"Revoked API key": {
+			Stores:    store,
+			OnChain:   httpmock.NewStringResponder(http.StatusOK, onChain),
+			Lightning: httpmock.NewStringResponder(http.StatusOK, lightning),
+			Webhooks:  webhooks,
+			Key:       httpmock.NewStringResponder(http.StatusUnauthorized, `{"code":"unauthenticated","message":"unauthenticated"}`),
+			Changes: []Change{
+				{Kind: KindStore, Name: "shop", Action: ActionNone},
+				{Kind: KindOnChain, Name: "BTC", Action: ActionNone},
+				{Kind: KindLightning, Name: "BTC", Action: ActionNone},
+				{Kind: KindWebhook, Name: "https://test.com/hook", Action: ActionNone},
+				{Kind: KindAPIKey, Name: "shop", Action: ActionCreate},
+			},
+			Calls: map[string]int{
+				"POST http://test.com/api/v1/api-keys": 1,
+			},
+			Keys:    map[string]string{"shop": "k2"},
+			StoreID: "s1",
+		},
+		"Error returned during payment method retrieval": {
+			Stores:  store,
+			OnChain: httpmock.NewErrorResponder(assert.AnError),
+			Changes: []Change{
+				{Kind: KindStore, Name: "shop", Action: ActionNone},
+			},
+			StoreID: "s1",
+			Err:     true,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+
+			if c.Stores != "" {
+				mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/stores", httpmock.NewStringResponder(http.StatusOK, c.Stores))
+			} else {
+				mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/stores", httpmock.NewErrorResponder(assert.AnError))
+			}
+
+			mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/stores", func(r *http.Request) (*http.Response, error) {
+				var p btcpay.StoreParams
+				if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+					return nil, err
+				}
+
+				if p.Name != "shop" {
+					return nil, errors.New("invalid body")
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"id":"s1","name":"shop"}`), nil
+			})
+			mt.RegisterResponder(http.MethodPut, storeURL, func(r *http.Request) (*http.Response, error) {
+				var p btcpay.StoreParams
+				if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+					return nil, err
+				}
+
+				if p.DefaultCurrency != "EUR" || p.InvoiceExpiration != 900 {
+					return nil, errors.New("invalid body")
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"id":"s1"}`), nil
+			})
+
+			if c.OnChain != nil {
+				mt.RegisterResponder(http.MethodGet, onChainURL, c.OnChain)
+			}
+
+			if c.Lightning != nil {
+				mt.RegisterResponder(http.MethodGet, lightningURL, c.Lightning)
+			}
+
+			mt.RegisterResponder(http.MethodPut, onChainURL, httpmock.NewStringResponder(http.StatusOK, onChain))
+			mt.RegisterResponder(http.MethodPut, lightningURL, httpmock.NewStringResponder(http.StatusOK, lightning))
+
+			if c.Webhooks != "" {
+				mt.RegisterResponder(http.MethodGet, webhooksURL, httpmock.NewStringResponder(http.StatusOK, c.Webhooks))
+			}
+
+			mt.RegisterResponder(http.MethodPost, webhooksURL, httpmock.NewStringResponder(http.StatusOK, `{"id":"w1"}`))
+			mt.RegisterResponder(http.MethodPut, webhooksURL+"/w1", func(r *http.Request) (*http.Response, error) {
+				var p btcpay.WebhookParams
+				if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+					return nil, err
+				}
+
+				if !p.Enabled || p.Secret != "s" {
+					return nil, errors.New("invalid body")
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"id":"w1"}`), nil
+			})
+			mt.RegisterResponder(http.MethodDelete, webhooksURL+"/w2", httpmock.NewStringResponder(http.StatusOK, ""))
+
+			if c.Key != nil {
+				mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/api-keys/current", func(r *http.Request) (*http.Response, error) {
+					if r.Header.Get("Authorization") != "token k1" {
+						return nil, errors.New("invalid authorization")
+					}
+
+					return c.Key(r)
+				})
+			}
+
+			mt.RegisterResponder(http.MethodDelete, "http://test.com/api/v1/api-keys/k1", httpmock.NewStringResponder(http.StatusOK, ""))
+			mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/api-keys", func(r *http.Request) (*http.Response, error) {
+				var p btcpay.APIKeyParams
+				if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+					return nil, err
+				}
+
+				if p.Label != "shop" || len(p.Permissions) != 1 || p.Permissions[0] != "btcpay.store.cancreateinvoice:s1" {
+					return nil, errors.New("invalid body")
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"apiKey":"k2"}`), nil
+			})
+
+			client, err := btcpay.NewClient("http://test.com", "", btcpay.WithAPIKey("admin"), btcpay.WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			s := testState()
+			if c.State != nil {
+				c.State(&s)
+			}
+
+			res, err := New(client, c.Opts...).Apply(context.Background(), s)
+
+			for k, n := range mt.GetCallCountInfo() {
+				if k[:4] == "GET " {
+					continue
+				}
+
+				assert.Equal(t, c.Calls[k], n, k)
+			}
+
+			assert.Equal(t, c.Changes, res.Changes)
+			assert.Equal(t, c.StoreID, res.StoreID)
+
+			if c.Err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Keys, res.APIKeys)
+		})
+	}
+}
+
+func Test_Result_Changed(t *testing.T) {
+	assert.False(t, Result{}.Changed())
+	assert.False(t, Result{Changes: []Change{{Action: ActionNone}}}.Changed())
+	assert.True(t, Result{Changes: []Change{{Action: ActionNone}, {Action: ActionDelete}}}.Changed())
+}
+
+func Test_samePermissions(t *testing.T) {
+	assert.True(t, samePermissions(btcpay.Permissions{"a", "b"}, btcpay.Permissions{"b", "a"}))
+	assert.False(t, samePermissions(btcpay.Permissions{"a", "a"}, btcpay.Permissions{"a", "b"}))
+	assert.False(t, samePermissions(btcpay.Permissions{"a"}, btcpay.Permissions{"a", "b"}))
+}
@@ -0,0 +1,382 @@
+package btcpay
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// PSBT holds a decoded partially signed bitcoin transaction.
+// All amounts are specified in satoshis.
+type PSBT struct {
+	Version  int32
+	LockTime uint32
+	Inputs   []PSBTInput
+	Outputs  []PSBTOutput
+
+	// Fee is known only if all inputs carry the data of the outputs
+	// they spend.
+	Fee      int64
+	FeeKnown bool
+
+	packet *psbt.Packet
+}
+
+// PSBTInput holds summary data of a single PSBT input.
+type PSBTInput struct {
+	PreviousTxID  string
+	PreviousIndex uint32
+	Sequence      uint32
+	Value         int64
+	ValueKnown    bool
+	Finalized     bool
+}
+
+// PSBTOutput holds summary data of a single PSBT output.
+type PSBTOutput struct {
+	Value    int64
+	PkScript []byte
+}
+
+// Address returns the address the output pays to on the specified
+// network.
+func (o PSBTOutput) Address(net *chaincfg.Params) (string, error) {
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(o.PkScript, net)
+	if err != nil {
+		return "", err
+	}
+
+	if len(addrs) != 1 {
+		return "", errors.New("output script does not pay to a single address")
+	}
+
+	return addrs[0].EncodeAddress(), nil
+}
+
+// DecodePSBT decodes the base64 encoded PSBT.
+func DecodePSBT(s string) (*PSBT, error) {
+	pkt, err := psbt.NewFromRawBytes(strings.NewReader(s), true)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &PSBT{packet: pkt}
+
+	if err = p.summarize(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// summarize fills the exported summary fields from the decoded data.
+func (p *PSBT) summarize() error {
+	tx := p.packet.UnsignedTx
+
+	p.Version = tx.Version
+	p.LockTime = tx.LockTime
+	p.Inputs = make([]PSBTInput, len(tx.TxIn))
+	p.Outputs = make([]PSBTOutput, len(tx.TxOut))
+	p.FeeKnown = true
+	p.Fee = 0
+
+	for i, in := range tx.TxIn {
+		pin := p.packet.Inputs[i]
+
+		pi := PSBTInput{
+			PreviousTxID:  in.PreviousOutPoint.Hash.String(),
+			PreviousIndex: in.PreviousOutPoint.Index,
+			Sequence:      in.Sequence,
+			Finalized:     len(pin.FinalScriptSig) > 0 || len(pin.FinalScriptWitness) > 0,
+		}
+
+		out, err := p.spentOutput(i)
+		if err != nil {
+			return err
+		}
+
+		if out != nil {
+			pi.Value = out.Value
+			pi.ValueKnown = true
+			p.Fee += out.Value
+		} else {
+			p.FeeKnown = false
+		}
+
+		p.Inputs[i] = pi
+	}
+
+	for i, out := range tx.TxOut {
+		p.Outputs[i] = PSBTOutput{
+			Value:    out.Value,
+			PkScript: out.PkScript,
+		}
+
+		p.Fee -= out.Value
+	}
+
+	if !p.FeeKnown {
+		p.Fee = 0
+	}
+
+	return nil
+}
+
+// spentOutput returns the output spent by the specified input. Nil is
+// returned if the input does not carry UTXO data.
+func (p *PSBT) spentOutput(i int) (*wire.TxOut, error) {
+	pin := p.packet.Inputs[i]
+
+	if pin.WitnessUtxo != nil {
+		return pin.WitnessUtxo, nil
+	}
+
+	if pin.NonWitnessUtxo != nil {
+		op := p.packet.UnsignedTx.TxIn[i].PreviousOutPoint
+		if pin.NonWitnessUtxo.TxHash() != op.Hash || int(op.Index) >= len(pin.NonWitnessUtxo.TxOut) {
+			return nil, fmt.Errorf("input %d: UTXO data does not match the spent output", i)
+		}
+
+		return pin.NonWitnessUtxo.TxOut[op.Index], nil
+	}
+
+	return nil, nil
+}
+
+// Encode returns the base64 encoded PSBT.
+func (p *PSBT) Encode() string {
+	s, _ := p.packet.B64Encode() //nolint:errcheck // decoded packets are always encodable
+	return s
+}
+
+// Combine merges data of the other PSBT (e.g. signatures added by
+// another signer) into this one. Both PSBTs must describe the same
+// unsigned transaction. Data already present in this PSBT is kept.
+func (p *PSBT) Combine(other *PSBT) error {
+	if p.packet.UnsignedTx.TxHash() != other.packet.UnsignedTx.TxHash() {
+		return errors.New("PSBTs describe different transactions")
+	}
+
+	p.packet.Unknowns = mergeUnknowns(p.packet.Unknowns, other.packet.Unknowns)
+
+	for i := range p.packet.Inputs {
+		mergePSBTInput(&p.packet.Inputs[i], other.packet.Inputs[i])
+	}
+
+	for i := range p.packet.Outputs {
+		mergePSBTOutput(&p.packet.Outputs[i], other.packet.Outputs[i])
+	}
+
+	return p.summarize()
+}
+
+// Extract returns the hex encoded final network transaction. All inputs
+// must be finalized.
+func (p *PSBT) Extract() (string, error) {
+	tx := p.packet.UnsignedTx.Copy()
+
+	for i, in := range tx.TxIn {
+		if !p.Inputs[i].Finalized {
+			return "", fmt.Errorf("input %d is not finalized", i)
+		}
+
+		if err := finalizeInput(in, p.packet.Inputs[i]); err != nil {
+			return "", err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf.Bytes()), nil
+}
+
+// finalizeInput sets the final script and witness of the PSBT input on
+// the transaction input.
+func finalizeInput(in *wire.TxIn, pin psbt.PInput) error {
+	if len(pin.FinalScriptSig) > 0 {
+		in.SignatureScript = pin.FinalScriptSig
+	}
+
+	if len(pin.FinalScriptWitness) > 0 {
+		wit, err := readWitness(pin.FinalScriptWitness)
+		if err != nil {
+			return err
+		}
+
+		in.Witness = wit
+	}
+
+	return nil
+}
+
+// readWitness decodes a serialized witness stack. The stack size is
+// checked before it is allocated, since the witness of a proposal is
+// provided by the payjoin receiver.
+func readWitness(b []byte) (wire.TxWitness, error) {
+	r := bytes.NewReader(b)
+
+	n, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if n > uint64(len(b)) {
+		return nil, errors.New("invalid witness stack size")
+	}
+
+	wit := make(wire.TxWitness, n)
+	for i := range wit {
+		wit[i], err = wire.ReadVarBytes(r, 0, psbt.MaxPsbtValueLength, "witness")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return wit, nil
+}
+
+// CombinePSBTs decodes and combines all provided base64 encoded PSBTs.
+func CombinePSBTs(pp ...string) (string, error) {
+	if len(pp) == 0 {
+		return "", errors.New("no PSBTs provided")
+	}
+
+	res, err := DecodePSBT(pp[0])
+	if err != nil {
+		return "", err
+	}
+
+	for _, s := range pp[1:] {
+		p, err := DecodePSBT(s)
+		if err != nil {
+			return "", err
+		}
+
+		if err = res.Combine(p); err != nil {
+			return "", err
+		}
+	}
+
+	return res.Encode(), nil
+}
+
+// mergePSBTInput adds the fields of the other input that are missing
+// from the input.
+func mergePSBTInput(in *psbt.PInput, other psbt.PInput) {
+	if in.NonWitnessUtxo == nil {
+		in.NonWitnessUtxo = other.NonWitnessUtxo
+	}
+
+	if in.WitnessUtxo == nil {
+		in.WitnessUtxo = other.WitnessUtxo
+	}
+
+	if in.SighashType == 0 {
+		in.SighashType = other.SighashType
+	}
+
+	mergeBytes(&in.RedeemScript, other.RedeemScript)
+	mergeBytes(&in.WitnessScript, other.WitnessScript)
+	mergeBytes(&in.FinalScriptSig, other.FinalScriptSig)
+	mergeBytes(&in.FinalScriptWitness, other.FinalScriptWitness)
+	mergeBytes(&in.TaprootKeySpendSig, other.TaprootKeySpendSig)
+	mergeBytes(&in.TaprootInternalKey, other.TaprootInternalKey)
+	mergeBytes(&in.TaprootMerkleRoot, other.TaprootMerkleRoot)
+
+	for _, s := range other.PartialSigs {
+		if !hasMatch(len(in.PartialSigs), func(i int) bool { return bytes.Equal(in.PartialSigs[i].PubKey, s.PubKey) }) {
+			in.PartialSigs = append(in.PartialSigs, s)
+		}
+	}
+
+	for _, d := range other.Bip32Derivation {
+		if !hasMatch(len(in.Bip32Derivation), func(i int) bool { return bytes.Equal(in.Bip32Derivation[i].PubKey, d.PubKey) }) {
+			in.Bip32Derivation = append(in.Bip32Derivation, d)
+		}
+	}
+
+	for _, s := range other.TaprootScriptSpendSig {
+		if !hasMatch(len(in.TaprootScriptSpendSig), func(i int) bool { return in.TaprootScriptSpendSig[i].EqualKey(s) }) {
+			in.TaprootScriptSpendSig = append(in.TaprootScriptSpendSig, s)
+		}
+	}
+
+	for _, l := range other.TaprootLeafScript {
+		if !hasMatch(len(in.TaprootLeafScript), func(i int) bool { return bytes.Equal(in.TaprootLeafScript[i].ControlBlock, l.ControlBlock) }) {
+			in.TaprootLeafScript = append(in.TaprootLeafScript, l)
+		}
+	}
+
+	in.TaprootBip32Derivation = mergeTaprootDerivations(in.TaprootBip32Derivation, other.TaprootBip32Derivation)
+	in.Unknowns = mergeUnknowns(in.Unknowns, other.Unknowns)
+}
+
+// mergePSBTOutput adds the fields of the other output that are missing
+// from the output.
+func mergePSBTOutput(out *psbt.POutput, other psbt.POutput) {
+	mergeBytes(&out.RedeemScript, other.RedeemScript)
+	mergeBytes(&out.WitnessScript, other.WitnessScript)
+	mergeBytes(&out.TaprootInternalKey, other.TaprootInternalKey)
+	mergeBytes(&out.TaprootTapTree, other.TaprootTapTree)
+
+	for _, d := range other.Bip32Derivation {
+		if !hasMatch(len(out.Bip32Derivation), func(i int) bool { return bytes.Equal(out.Bip32Derivation[i].PubKey, d.PubKey) }) {
+			out.Bip32Derivation = append(out.Bip32Derivation, d)
+		}
+	}
+
+	out.TaprootBip32Derivation = mergeTaprootDerivations(out.TaprootBip32Derivation, other.TaprootBip32Derivation)
+	out.Unknowns = mergeUnknowns(out.Unknowns, other.Unknowns)
+}
+
+// mergeTaprootDerivations adds the derivations of the second slice
+// whose keys are not present in the first one.
+func mergeTaprootDerivations(dd1, dd2 []*psbt.TaprootBip32Derivation) []*psbt.TaprootBip32Derivation {
+	for _, d := range dd2 {
+		if !hasMatch(len(dd1), func(i int) bool { return bytes.Equal(dd1[i].XOnlyPubKey, d.XOnlyPubKey) }) {
+			dd1 = append(dd1, d)
+		}
+	}
+
+	return dd1
+}
+
+// mergeUnknowns adds the pairs of the second slice whose keys are not
+// present in the first one.
+func mergeUnknowns(uu1, uu2 []*psbt.Unknown) []*psbt.Unknown {
+	for _, u := range uu2 {
+		if !hasMatch(len(uu1), func(i int) bool { return bytes.Equal(uu1[i].Key, u.Key) }) {
+			uu1 = append(uu1, u)
+		}
+	}
+
+	return uu1
+}
+
+// mergeBytes sets the field to the value unless it is already set.
+func mergeBytes(field *[]byte, v []byte) {
+	if len(*field) == 0 {
+		*field = v
+	}
+}
+
+// hasMatch checks whether any of the first n elements satisfies eq.
+func hasMatch(n int, eq func(i int) bool) bool {
+	for i := 0; i < n; i++ {
+		if eq(i) {
+			return true
+		}
+	}
+
+	return false
+}
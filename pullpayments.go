@@ -0,0 +1,164 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+)
+
+// CreatePullPaymentParams holds data used to initialize a new pull
+// payment.
+// More at: https://docs.btcpayserver.org/API/Greenfield/v1/#tag/Pull-payments-(Management)
+type CreatePullPaymentParams struct {
+	Name              string          `json:"name,omitempty"`
+	Description       string          `json:"description,omitempty"`
+	Amount            decimal.Decimal `json:"amount"`
+	Currency          string          `json:"currency"`
+	Period            int64           `json:"period,omitempty"`
+	BOLT11Expiration  int64           `json:"BOLT11Expiration,omitempty"`
+	AutoApproveClaims bool            `json:"autoApproveClaims,omitempty"`
+	StartsAt          int64           `json:"startsAt,omitempty"`
+	ExpiresAt         int64           `json:"expiresAt,omitempty"`
+	PaymentMethods    []string        `json:"paymentMethods,omitempty"`
+}
+
+// PullPayment holds pull payment data retrieved from the payment
+// processor.
+type PullPayment struct {
+	ID                string          `json:"id"`
+	Name              string          `json:"name"`
+	Description       string          `json:"description"`
+	Currency          string          `json:"currency"`
+	Amount            decimal.Decimal `json:"amount"`
+	Period            int64           `json:"period"`
+	BOLT11Expiration  int64           `json:"BOLT11Expiration"`
+	AutoApproveClaims bool            `json:"autoApproveClaims"`
+	Archived          bool            `json:"archived"`
+	ViewLink          string          `json:"viewLink"`
+}
+
+// CreatePayoutParams holds data used to claim a new payout from a pull
+// payment.
+type CreatePayoutParams struct {
+	Destination   string          `json:"destination"`
+	Amount        decimal.Decimal `json:"amount"`
+	PaymentMethod string          `json:"paymentMethod"`
+}
+
+// ApprovePayoutParams holds data used to approve a payout.
+type ApprovePayoutParams struct {
+	Revision int64  `json:"revision"`
+	RateRule string `json:"rateRule,omitempty"`
+}
+
+// Payout holds payout data retrieved from the payment processor.
+type Payout struct {
+	ID                  string          `json:"id"`
+	Revision            int64           `json:"revision"`
+	PullPaymentID       string          `json:"pullPaymentId"`
+	Date                int64           `json:"date"`
+	Destination         string          `json:"destination"`
+	Amount              decimal.Decimal `json:"amount"`
+	PaymentMethod       string          `json:"paymentMethod"`
+	CryptoCode          string          `json:"cryptoCode"`
+	PaymentMethodAmount decimal.Decimal `json:"paymentMethodAmount"`
+	State               string          `json:"state"`
+}
+
+// CreatePullPayment creates a new pull payment in the specified store.
+func (c *Client) CreatePullPayment(ctx context.Context, storeID string, p CreatePullPaymentParams, opts ...RequestOption) (PullPayment, error) {
+	resp, err := c.sendAPI(ctx, http.MethodPost, newRoute("/api/v1/stores/:id/pull-payments", storeID), nil, p, opts...)
+	if err != nil {
+		return PullPayment{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var pp PullPayment
+
+	if err = c.decode(resp.Body, &pp); err != nil {
+		return PullPayment{}, err
+	}
+
+	return pp, nil
+}
+
+// PullPayments retrieves all pull payments of the specified store.
+func (c *Client) PullPayments(ctx context.Context, storeID string, includeArchived bool, opts ...RequestOption) ([]PullPayment, error) {
+	params := url.Values{}
+	params.Set("includeArchived", strconv.FormatBool(includeArchived))
+
+	resp, err := c.sendAPI(ctx, http.MethodGet, newRoute("/api/v1/stores/:id/pull-payments", storeID), params, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var pps []PullPayment
+
+	if err = c.decode(resp.Body, &pps); err != nil {
+		return nil, err
+	}
+
+	return pps, nil
+}
+
+// ArchivePullPayment archives the specified pull payment.
+func (c *Client) ArchivePullPayment(ctx context.Context, storeID, id string, opts ...RequestOption) error {
+	resp, err := c.sendAPI(ctx, http.MethodDelete, newRoute("/api/v1/stores/:id/pull-payments/:id", storeID, id), nil, nil, opts...)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// CreatePayout claims a new payout from the specified pull payment.
+func (c *Client) CreatePayout(ctx context.Context, pullPaymentID string, p CreatePayoutParams, opts ...RequestOption) (Payout, error) {
+	resp, err := c.sendAPI(ctx, http.MethodPost, newRoute("/api/v1/pull-payments/:id/payouts", pullPaymentID), nil, p, opts...)
+	if err != nil {
+		return Payout{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var po Payout
+
+	if err = c.decode(resp.Body, &po); err != nil {
+		return Payout{}, err
+	}
+
+	return po, nil
+}
+
+// ApprovePayout approves the specified payout.
+func (c *Client) ApprovePayout(ctx context.Context, storeID, id string, p ApprovePayoutParams, opts ...RequestOption) (Payout, error) {
+	resp, err := c.sendAPI(ctx, http.MethodPost, newRoute("/api/v1/stores/:id/payouts/:id", storeID, id), nil, p, opts...)
+	if err != nil {
+		return Payout{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var po Payout
+
+	if err = c.decode(resp.Body, &po); err != nil {
+		return Payout{}, err
+	}
+
+	return po, nil
+}
+
+// CancelPayout cancels the specified payout.
+func (c *Client) CancelPayout(ctx context.Context, storeID, id string, opts ...RequestOption) error {
+	resp, err := c.sendAPI(ctx, http.MethodDelete, newRoute("/api/v1/stores/:id/payouts/:id", storeID, id), nil, nil, opts...)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
@@ -0,0 +1,373 @@
+package btcpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_CreatePullPayment(t *testing.T) {
+	checkBody := func(r *http.Request) error {
+		var p CreatePullPaymentParams
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			return err
+		}
+
+		if p.Currency != "BTC" || !p.Amount.Equal(decimal.NewFromInt(1)) {
+			return errors.New("invalid body")
+		}
+
+		return nil
+	}
+
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result PullPayment
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := checkBody(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, "{"), nil
+			},
+			Err: true,
+		},
+		"Successful execution": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := checkBody(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"id":"pp1","currency":"BTC"}`), nil
+			},
+			Result: PullPayment{ID: "pp1", Currency: "BTC"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/stores/s1/pull-payments", c.Resp)
+
+			pp, err := client.CreatePullPayment(context.Background(), "s1", CreatePullPaymentParams{
+				Currency: "BTC",
+				Amount:   decimal.NewFromInt(1),
+			})
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodPost+" http://test.com/api/v1/stores/s1/pull-payments"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, pp)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, pp)
+		})
+	}
+}
+
+func Test_Client_PullPayments(t *testing.T) {
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result []PullPayment
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, "["),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if r.URL.Query().Get("includeArchived") != "true" {
+					return nil, errors.New("invalid query params")
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `[{"id":"pp1","archived":true}]`), nil
+			},
+			Result: []PullPayment{{ID: "pp1", Archived: true}},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/stores/s1/pull-payments", c.Resp)
+
+			pps, err := client.PullPayments(context.Background(), "s1", true)
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodGet+" http://test.com/api/v1/stores/s1/pull-payments"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Nil(t, pps)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, pps)
+		})
+	}
+}
+
+func Test_Client_ArchivePullPayment(t *testing.T) {
+	cc := map[string]struct {
+		Resp httpmock.Responder
+		Err  bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, ""),
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodDelete, "http://test.com/api/v1/stores/s1/pull-payments/pp1", c.Resp)
+
+			err = client.ArchivePullPayment(context.Background(), "s1", "pp1")
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodDelete+" http://test.com/api/v1/stores/s1/pull-payments/pp1"])
+
+			if c.Err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_Client_CreatePayout(t *testing.T) {
+	checkBody := func(r *http.Request) error {
+		var p CreatePayoutParams
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			return err
+		}
+
+		if p.Destination != "addr1" || p.PaymentMethod != "BTC" {
+			return errors.New("invalid body")
+		}
+
+		return nil
+	}
+
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result Payout
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := checkBody(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, "{"), nil
+			},
+			Err: true,
+		},
+		"Successful execution": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := checkBody(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"id":"po1","state":"AwaitingApproval"}`), nil
+			},
+			Result: Payout{ID: "po1", State: "AwaitingApproval"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/pull-payments/pp1/payouts", c.Resp)
+
+			po, err := client.CreatePayout(context.Background(), "pp1", CreatePayoutParams{
+				Destination:   "addr1",
+				PaymentMethod: "BTC",
+			})
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodPost+" http://test.com/api/v1/pull-payments/pp1/payouts"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, po)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, po)
+		})
+	}
+}
+
+func Test_Client_ApprovePayout(t *testing.T) {
+	checkBody := func(r *http.Request) error {
+		var p ApprovePayoutParams
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			return err
+		}
+
+		if p.Revision != 2 {
+			return errors.New("invalid body")
+		}
+
+		return nil
+	}
+
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result Payout
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := checkBody(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, "{"), nil
+			},
+			Err: true,
+		},
+		"Successful execution": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := checkBody(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"id":"po1","state":"AwaitingPayment"}`), nil
+			},
+			Result: Payout{ID: "po1", State: "AwaitingPayment"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/stores/s1/payouts/po1", c.Resp)
+
+			po, err := client.ApprovePayout(context.Background(), "s1", "po1", ApprovePayoutParams{Revision: 2})
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodPost+" http://test.com/api/v1/stores/s1/payouts/po1"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, po)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, po)
+		})
+	}
+}
+
+func Test_Client_CancelPayout(t *testing.T) {
+	cc := map[string]struct {
+		Resp httpmock.Responder
+		Err  bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, ""),
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodDelete, "http://test.com/api/v1/stores/s1/payouts/po1", c.Resp)
+
+			err = client.CancelPayout(context.Background(), "s1", "po1")
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodDelete+" http://test.com/api/v1/stores/s1/payouts/po1"])
+
+			if c.Err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
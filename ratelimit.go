@@ -0,0 +1,112 @@
+package btcpay
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// maxRateLimitRetries specifies how many times a request is retried
+// after the server responds with 429 Too Many Requests.
+const maxRateLimitRetries = 3
+
+// maxRetryAfter specifies the longest Retry-After delay the client waits
+// for. Responses asking for a longer delay are returned to the caller.
+const maxRetryAfter = time.Minute
+
+// Limiter limits the rate at which requests are sent to the server.
+type Limiter interface {
+	// Wait blocks until the next request is allowed to be sent or
+	// the context is cancelled.
+	Wait(ctx context.Context) error
+}
+
+// WithRateLimit limits the number of requests per second sent by the
+// BTCPay client. Up to burst requests may be sent at once.
+func WithRateLimit(rps float64, burst int) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.limiter = rate.NewLimiter(rate.Limit(rps), burst)
+	}
+}
+
+// WithLimiter sets a custom rate limiter on the BTCPay client.
+func WithLimiter(l Limiter) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.limiter = l
+	}
+}
+
+// roundTrip sends the request while respecting the rate limits of both
+// the client and the server.
+func (c *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	for i := 0; ; i++ {
+		if c.limiter != nil {
+			if err := c.limiter.Wait(req.Context()); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.sendOnce(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusTooManyRequests || i >= maxRateLimitRetries {
+			return resp, nil
+		}
+
+		clk := c.getClock()
+
+		d, ok := retryAfter(resp.Header, clk.Now())
+
+		// there's no point in waiting if the request would time out
+		// before it could be retried
+		if !ok || d > maxRetryAfter || req.GetBody == nil || !fitsDeadline(req.Context(), clk, d) {
+			return resp, nil
+		}
+
+		resp.Body.Close()
+
+		if err = sleep(req.Context(), clk, d); err != nil {
+			return nil, err
+		}
+
+		req.Body, err = req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryAfter parses the Retry-After header. Both delay seconds and
+// HTTP date formats are supported.
+func retryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if sec, err := strconv.ParseInt(v, 10, 64); err == nil {
+		if sec < 0 || sec > math.MaxInt64/int64(time.Second) {
+			return 0, false
+		}
+
+		return time.Duration(sec) * time.Second, true
+	}
+
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+
+	d := t.Sub(now)
+	if d < 0 {
+		d = 0
+	}
+
+	return d, true
+}
@@ -0,0 +1,202 @@
+package btcpay
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+type limiterFunc func(ctx context.Context) error
+
+func (f limiterFunc) Wait(ctx context.Context) error {
+	return f(ctx)
+}
+
+func Test_WithRateLimit(t *testing.T) {
+	c := &Client{}
+	WithRateLimit(1, 1)(c)
+	assert.NotNil(t, c.limiter)
+}
+
+func Test_WithLimiter(t *testing.T) {
+	c := &Client{}
+	WithLimiter(limiterFunc(func(context.Context) error { return nil }))(c)
+	assert.NotNil(t, c.limiter)
+}
+
+func Test_Client_roundTrip(t *testing.T) {
+	limited := func(n int, next httpmock.Responder) httpmock.Responder {
+		var calls int
+
+		return func(r *http.Request) (*http.Response, error) {
+			calls++
+			if calls <= n {
+				resp := httpmock.NewStringResponse(http.StatusTooManyRequests, `{"error":"slow down"}`)
+				resp.Header.Set("Retry-After", "0")
+
+				return resp, nil
+			}
+
+			return next(r)
+		}
+	}
+
+	cc := map[string]struct {
+		Limiter Limiter
+		Resp    httpmock.Responder
+		Calls   int
+		Status  int
+		Err     bool
+	}{
+		"Error returned by limiter": {
+			Limiter: limiterFunc(func(context.Context) error { return assert.AnError }),
+			Resp:    httpmock.NewStringResponder(http.StatusOK, ""),
+			Err:     true,
+		},
+		"Error returned during request sending": {
+			Resp:  httpmock.NewErrorResponder(assert.AnError),
+			Calls: 1,
+			Err:   true,
+		},
+		"Too many requests without Retry-After": {
+			Resp:   httpmock.NewStringResponder(http.StatusTooManyRequests, ""),
+			Calls:  1,
+			Status: http.StatusTooManyRequests,
+		},
+		"Too many requests with too long Retry-After": {
+			Resp: func(*http.Request) (*http.Response, error) {
+				resp := httpmock.NewStringResponse(http.StatusTooManyRequests, "")
+				resp.Header.Set("Retry-After", "3600")
+
+				return resp, nil
+			},
+			Calls:  1,
+			Status: http.StatusTooManyRequests,
+		},
+		"Too many requests after all retries": {
+			Resp:   limited(10, httpmock.NewStringResponder(http.StatusOK, "")),
+			Calls:  maxRateLimitRetries + 1,
+			Status: http.StatusTooManyRequests,
+		},
+		"Successful execution after retry": {
+			Resp: limited(2, func(r *http.Request) (*http.Response, error) {
+				b, err := ioutil.ReadAll(r.Body)
+				if err != nil {
+					return nil, err
+				}
+
+				if string(b) != "body123" {
+					return nil, assert.AnError
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, ""), nil
+			}),
+			Calls:  3,
+			Status: http.StatusOK,
+		},
+		"Successful execution with limiter": {
+			Limiter: rate.NewLimiter(rate.Inf, 1),
+			Resp:    httpmock.NewStringResponder(http.StatusOK, ""),
+			Calls:   1,
+			Status:  http.StatusOK,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			mt.RegisterResponder(http.MethodPost, "http://test.com/testing", c.Resp)
+
+			client := &Client{hc: &http.Client{Transport: mt}, limiter: c.Limiter}
+
+			req, err := http.NewRequest(http.MethodPost, "http://test.com/testing", strings.NewReader("body123"))
+			require.NoError(t, err)
+
+			resp, err := client.roundTrip(req)
+
+			assert.Equal(t, c.Calls, mt.GetCallCountInfo()[http.MethodPost+" http://test.com/testing"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Nil(t, resp)
+				return
+			}
+
+			assert.NoError(t, err)
+			require.NotNil(t, resp)
+			assert.Equal(t, c.Status, resp.StatusCode)
+		})
+	}
+}
+
+func Test_retryAfter(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cc := map[string]struct {
+		Value  string
+		Result time.Duration
+		OK     bool
+	}{
+		"Missing header": {},
+		"Invalid value": {
+			Value: "soon",
+		},
+		"Negative seconds": {
+			Value: "-1",
+		},
+		"Overflowing seconds": {
+			Value: "9223372037",
+		},
+		"Seconds": {
+			Value:  "5",
+			Result: time.Second * 5,
+			OK:     true,
+		},
+		"Past date": {
+			Value: now.Add(-time.Minute).Format(http.TimeFormat),
+			OK:    true,
+		},
+		"Date": {
+			Value:  now.Add(time.Minute).Format(http.TimeFormat),
+			Result: time.Minute,
+			OK:     true,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			h := http.Header{}
+			if c.Value != "" {
+				h.Set("Retry-After", c.Value)
+			}
+
+			d, ok := retryAfter(h, now)
+			assert.Equal(t, c.OK, ok)
+			assert.Equal(t, c.Result, d)
+		})
+	}
+}
+
+func Test_sleep(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.Equal(t, context.Canceled, sleep(ctx, realClock{}, time.Hour))
+	assert.NoError(t, sleep(context.Background(), realClock{}, time.Millisecond))
+}
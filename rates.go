@@ -0,0 +1,237 @@
+package btcpay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"golang.org/x/sync/singleflight"
+)
+
+// Rate holds the exchange rate of a currency pair.
+type Rate struct {
+	CurrencyPair string          `json:"currencyPair"`
+	Code         string          `json:"code"`
+	Name         string          `json:"name"`
+	Rate         decimal.Decimal `json:"rate"`
+}
+
+// WithRateCache makes the BTCPay client cache exchange rates in memory
+// for the specified duration, per currency pair. Concurrent requests of
+// the same rates are collapsed into a single request to the server.
+func WithRateCache(ttl time.Duration) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.rates = newRateCache(ttl)
+	}
+}
+
+// Rate retrieves the price of one unit of the base currency in the
+// quote currency, e.g. the price of BTC in USD.
+func (c *Client) Rate(ctx context.Context, base, quote string, opts ...RequestOption) (Rate, error) {
+	base, quote = strings.ToUpper(base), strings.ToUpper(quote)
+	pair := base + "_" + quote
+
+	if r, ok := c.rates.get(pair, c.getClock().Now()); ok {
+		return r, nil
+	}
+
+	v, err := c.rates.do(pair, func() (interface{}, error) {
+		resp, err := c.send(ctx, http.MethodGet, newRoute("/rates/:id/:id", base, quote), nil, nil, true, append([]RequestOption{cacheable()}, opts...)...)
+		if err != nil {
+			return nil, err
+		}
+
+		defer resp.Body.Close()
+
+		var r Rate
+
+		if err = c.decodeData(resp.Body, &r); err != nil {
+			return nil, err
+		}
+
+		r.CurrencyPair = pair
+		c.rates.set(pair, r, c.getClock().Now())
+
+		return r, nil
+	})
+	if err != nil {
+		return Rate{}, err
+	}
+
+	return v.(Rate), nil
+}
+
+// Rates retrieves exchange rates of the currency pairs, e.g. BTC_USD, in
+// a single request. Only rates that are not cached are requested.
+func (c *Client) Rates(ctx context.Context, pairs []string, opts ...RequestOption) ([]Rate, error) {
+	var (
+		res     = make([]Rate, len(pairs))
+		keys    = make([]string, len(pairs))
+		missing []string
+		now     = c.getClock().Now()
+	)
+
+	for i, p := range pairs {
+		keys[i] = strings.ToUpper(p)
+
+		if r, ok := c.rates.get(keys[i], now); ok {
+			res[i] = r
+		} else {
+			missing = append(missing, keys[i])
+		}
+	}
+
+	if len(missing) == 0 {
+		return res, nil
+	}
+
+	sort.Strings(missing)
+
+	v, err := c.rates.do(strings.Join(missing, ","), func() (interface{}, error) {
+		params := url.Values{}
+		params.Set("currencyPairs", strings.Join(missing, ","))
+
+		resp, err := c.send(ctx, http.MethodGet, newRoute("/rates"), params, nil, true, append([]RequestOption{cacheable()}, opts...)...)
+		if err != nil {
+			return nil, err
+		}
+
+		defer resp.Body.Close()
+
+		var rr []Rate
+
+		if err = c.decodeData(resp.Body, &rr); err != nil {
+			return nil, err
+		}
+
+		now := c.getClock().Now()
+
+		for _, r := range rr {
+			c.rates.set(r.CurrencyPair, r, now)
+		}
+
+		return rr, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fetched := make(map[string]Rate)
+	for _, r := range v.([]Rate) {
+		fetched[r.CurrencyPair] = r
+	}
+
+	for i, p := range keys {
+		if res[i].CurrencyPair != "" {
+			continue
+		}
+
+		r, ok := fetched[p]
+		if !ok {
+			return nil, fmt.Errorf("rate of %s is not available", p)
+		}
+
+		res[i] = r
+	}
+
+	return res, nil
+}
+
+// Convert converts the amount from one currency to another using the
+// current exchange rate. The result is not rounded.
+func (c *Client) Convert(ctx context.Context, amount decimal.Decimal, from, to string, opts ...RequestOption) (decimal.Decimal, error) {
+	if strings.EqualFold(from, to) {
+		return amount, nil
+	}
+
+	r, err := c.Rate(ctx, from, to, opts...)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	if !r.Rate.IsPositive() {
+		return decimal.Decimal{}, errors.New("exchange rate is not available")
+	}
+
+	return amount.Mul(r.Rate), nil
+}
+
+// rateCache holds exchange rates retrieved from the server.
+type rateCache struct {
+	ttl   time.Duration
+	group singleflight.Group
+
+	mu    sync.Mutex
+	rates map[string]cachedRate
+}
+
+// cachedRate is an exchange rate along with its expiration time.
+type cachedRate struct {
+	rate Rate
+	exp  time.Time
+}
+
+// newRateCache creates a new rate cache.
+func newRateCache(ttl time.Duration) *rateCache {
+	return &rateCache{
+		ttl:   ttl,
+		rates: make(map[string]cachedRate),
+	}
+}
+
+// get returns the cached rate of the currency pair unless it has
+// expired. It is safe to call on a nil cache.
+func (rc *rateCache) get(pair string, now time.Time) (Rate, bool) {
+	if rc == nil {
+		return Rate{}, false
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	cr, ok := rc.rates[pair]
+	if !ok || !now.Before(cr.exp) {
+		return Rate{}, false
+	}
+
+	return cr.rate, true
+}
+
+// do calls fn, making sure that only one call with the same key is in
+// flight at a time; duplicate callers wait for and receive its result.
+// Without a cache, fn is called directly.
+func (rc *rateCache) do(key string, fn func() (interface{}, error)) (interface{}, error) {
+	if rc == nil {
+		return fn()
+	}
+
+	v, err, _ := rc.group.Do(key, fn)
+
+	return v, err
+}
+
+// set caches the rate of the currency pair. It is safe to call on a nil
+// cache.
+func (rc *rateCache) set(pair string, r Rate, now time.Time) {
+	if rc == nil || rc.ttl <= 0 {
+		return
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	for p, cr := range rc.rates {
+		if !now.Before(cr.exp) {
+			delete(rc.rates, p)
+		}
+	}
+
+	rc.rates[pair] = cachedRate{rate: r, exp: now.Add(rc.ttl)}
+}
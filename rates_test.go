@@ -0,0 +1,284 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_Rate(t *testing.T) {
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result Rate
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, "{"),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `{"data":{"code":"USD","name":"US Dollar","rate":20000.5}}`),
+			Result: Rate{CurrencyPair: "BTC_USD", Code: "USD", Name: "US Dollar", Rate: decimal.RequireFromString("20000.5")},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/rates/BTC/USD", c.Resp)
+
+			res, err := client.Rate(context.Background(), "btc", "usd")
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodGet+" http://test.com/rates/BTC/USD"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_WithRateCache(t *testing.T) {
+	c := &Client{}
+	WithRateCache(time.Minute)(c)
+	assert.Equal(t, time.Minute, c.rates.ttl)
+}
+
+func Test_Client_Rate_Cache(t *testing.T) {
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder(http.MethodGet, "http://test.com/rates/BTC/USD", httpmock.NewStringResponder(http.StatusOK, `{"data":{"code":"USD","rate":20000}}`))
+
+	now := time.Unix(1000, 0)
+
+	client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}), WithRateCache(time.Minute), WithClock(fixedClock{now: now}))
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		r, err := client.Rate(context.Background(), "BTC", "USD")
+		require.NoError(t, err)
+		assert.Equal(t, "20000", r.Rate.String())
+	}
+
+	assert.Equal(t, 1, mt.GetTotalCallCount())
+
+	client.clock = fixedClock{now: now.Add(time.Minute)}
+
+	_, err = client.Rate(context.Background(), "BTC", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, 2, mt.GetTotalCallCount())
+}
+
+func Test_Client_Rate_Concurrent(t *testing.T) {
+	release := make(chan struct{})
+
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder(http.MethodGet, "http://test.com/rates/BTC/USD", func(req *http.Request) (*http.Response, error) {
+		<-release
+		return httpmock.NewStringResponse(http.StatusOK, `{"data":{"code":"USD","rate":20000}}`), nil
+	})
+
+	client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}), WithRateCache(time.Minute))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			r, err := client.Rate(context.Background(), "BTC", "USD")
+			assert.NoError(t, err)
+			assert.Equal(t, "20000", r.Rate.String())
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, 1, mt.GetTotalCallCount())
+}
+
+func Test_Client_Rates(t *testing.T) {
+	cc := map[string]struct {
+		Cached []Rate
+		Query  string
+		Resp   httpmock.Responder
+		Result []Rate
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Query: "BTC_EUR,BTC_USD",
+			Resp:  httpmock.NewErrorResponder(assert.AnError),
+			Err:   true,
+		},
+		"Invalid response body": {
+			Query: "BTC_EUR,BTC_USD",
+			Resp:  httpmock.NewStringResponder(http.StatusOK, "{"),
+			Err:   true,
+		},
+		"Missing rate": {
+			Query: "BTC_EUR,BTC_USD",
+			Resp:  httpmock.NewStringResponder(http.StatusOK, `{"data":[{"currencyPair":"BTC_USD","code":"USD","rate":20000}]}`),
+			Err:   true,
+		},
+		"All rates cached": {
+			Cached: []Rate{
+				{CurrencyPair: "BTC_USD", Code: "USD", Rate: decimal.NewFromInt(20000)},
+				{CurrencyPair: "BTC_EUR", Code: "EUR", Rate: decimal.NewFromInt(18000)},
+			},
+			Result: []Rate{
+				{CurrencyPair: "BTC_USD", Code: "USD", Rate: decimal.NewFromInt(20000)},
+				{CurrencyPair: "BTC_EUR", Code: "EUR", Rate: decimal.NewFromInt(18000)},
+			},
+		},
+		"Successful execution": {
+			Cached: []Rate{
+				{CurrencyPair: "BTC_USD", Code: "USD", Rate: decimal.NewFromInt(20000)},
+			},
+			Query: "BTC_EUR",
+			Resp:  httpmock.NewStringResponder(http.StatusOK, `{"data":[{"currencyPair":"BTC_EUR","code":"EUR","rate":18000}]}`),
+			Result: []Rate{
+				{CurrencyPair: "BTC_USD", Code: "USD", Rate: decimal.NewFromInt(20000)},
+				{CurrencyPair: "BTC_EUR", Code: "EUR", Rate: decimal.RequireFromString("18000")},
+			},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			now := time.Unix(1000, 0)
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}), WithRateCache(time.Minute), WithClock(fixedClock{now: now}))
+			require.NoError(t, err)
+
+			for _, r := range c.Cached {
+				client.rates.set(r.CurrencyPair, r, now)
+			}
+
+			if c.Resp != nil {
+				mt.RegisterResponderWithQuery(http.MethodGet, "http://test.com/rates", "currencyPairs="+c.Query, c.Resp)
+			}
+
+			res, err := client.Rates(context.Background(), []string{"btc_usd", "btc_eur"})
+
+			if c.Resp != nil {
+				assert.Equal(t, 1, mt.GetTotalCallCount())
+			} else {
+				assert.Zero(t, mt.GetTotalCallCount())
+			}
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Nil(t, res)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_Convert(t *testing.T) {
+	cc := map[string]struct {
+		From   string
+		To     string
+		Resp   httpmock.Responder
+		Sent   bool
+		Err    bool
+		Result string
+	}{
+		"Error returned during rate retrieval": {
+			From: "BTC",
+			To:   "USD",
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Sent: true,
+			Err:  true,
+		},
+		"Unavailable rate": {
+			From: "BTC",
+			To:   "USD",
+			Resp: httpmock.NewStringResponder(http.StatusOK, `{"data":{"code":"USD","rate":0}}`),
+			Sent: true,
+			Err:  true,
+		},
+		"Same currency": {
+			From:   "usd",
+			To:     "USD",
+			Result: "0.5",
+		},
+		"Successful conversion": {
+			From:   "BTC",
+			To:     "USD",
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `{"data":{"code":"USD","rate":20000.5}}`),
+			Sent:   true,
+			Result: "10000.25",
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			if c.Resp != nil {
+				mt.RegisterResponder(http.MethodGet, "http://test.com/rates/"+c.From+"/"+c.To, c.Resp)
+			}
+
+			res, err := client.Convert(context.Background(), decimal.RequireFromString("0.5"), c.From, c.To)
+
+			if c.Sent {
+				assert.Equal(t, 1, mt.GetTotalCallCount())
+			} else {
+				assert.Zero(t, mt.GetTotalCallCount())
+			}
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res.String())
+		})
+	}
+}
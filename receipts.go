@@ -0,0 +1,86 @@
+package btcpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ReceiptParams holds data used to send an invoice receipt.
+type ReceiptParams struct {
+	// Email is the recipient of the receipt. Defaults to the buyer's
+	// email in the invoice's metadata.
+	Email Sensitive
+
+	// Subject is the subject of the email. Defaults to "Payment
+	// receipt" followed by the order ID, if any.
+	Subject string
+}
+
+// SendInvoiceReceipt emails the buyer a link to the receipt page of
+// the specified settled invoice of the store. The server has no
+// endpoint that resends its own receipt emails, so the email is sent
+// with the store's SMTP settings, see SendStoreEmail, which requires
+// the store's email permissions.
+func (c *Client) SendInvoiceReceipt(ctx context.Context, storeID, id string, p ReceiptParams, opts ...RequestOption) error {
+	inv, err := c.StoreInvoice(ctx, storeID, id, append([]RequestOption{WithoutCache()}, opts...)...)
+	if err != nil {
+		return err
+	}
+
+	if inv.Status != string(InvoiceStatusSettled) {
+		return errors.New("receipts are only available for settled invoices")
+	}
+
+	var md struct {
+		OrderID    string    `json:"orderId"`
+		ItemDesc   string    `json:"itemDesc"`
+		BuyerEmail Sensitive `json:"buyerEmail"`
+	}
+
+	if len(inv.Metadata) > 0 {
+		if err = json.Unmarshal(inv.Metadata, &md); err != nil {
+			return err
+		}
+	}
+
+	if p.Email == "" {
+		p.Email = md.BuyerEmail
+	}
+
+	if p.Email == "" {
+		return errors.New("buyer email is not known")
+	}
+
+	if p.Subject == "" {
+		p.Subject = "Payment receipt"
+
+		if md.OrderID != "" {
+			p.Subject += " for order " + md.OrderID
+		}
+	}
+
+	var b strings.Builder
+
+	b.WriteString("Thank you for your payment.\n\n")
+	fmt.Fprintf(&b, "Invoice: %s\n", inv.ID)
+
+	if md.OrderID != "" {
+		fmt.Fprintf(&b, "Order: %s\n", md.OrderID)
+	}
+
+	if md.ItemDesc != "" {
+		fmt.Fprintf(&b, "Item: %s\n", md.ItemDesc)
+	}
+
+	fmt.Fprintf(&b, "Amount: %s %s\n\n", inv.Amount.String(), inv.Currency)
+	fmt.Fprintf(&b, "Receipt: %s/i/%s/receipt\n", c.baseURL(), inv.ID)
+
+	return c.SendStoreEmail(ctx, storeID, EmailParams{
+		Email:   p.Email,
+		Subject: p.Subject,
+		Body:    b.String(),
+	}, opts...)
+}
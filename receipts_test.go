@@ -0,0 +1,108 @@
+package btcpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_SendInvoiceReceipt(t *testing.T) {
+	settled := `{"id":"i1","status":"Settled","amount":"10.5","currency":"EUR",
+		"metadata":{"orderId":"o1","itemDesc":"Book","buyerEmail":"buyer@test.com"}}`
+
+	body := "Thank you for your payment.\n\nInvoice: i1\nOrder: o1\nItem: Book\nAmount: 10.5 EUR\n\n" +
+		"Receipt: http://test.com/i/i1/receipt\n"
+
+	email := func(exp EmailParams) httpmock.Responder {
+		return func(r *http.Request) (*http.Response, error) {
+			var p EmailParams
+			if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+				return nil, err
+			}
+
+			if p != exp {
+				return nil, errors.New("invalid request")
+			}
+
+			return httpmock.NewStringResponse(http.StatusOK, ""), nil
+		}
+	}
+
+	cc := map[string]struct {
+		Params      ReceiptParams
+		InvoiceResp httpmock.Responder
+		EmailResp   httpmock.Responder
+		Err         bool
+	}{
+		"Error returned during invoice retrieval": {
+			InvoiceResp: httpmock.NewErrorResponder(assert.AnError),
+			Err:         true,
+		},
+		"Invoice is not settled": {
+			InvoiceResp: httpmock.NewStringResponder(http.StatusOK, `{"id":"i1","status":"New"}`),
+			Err:         true,
+		},
+		"Invalid metadata": {
+			InvoiceResp: httpmock.NewStringResponder(http.StatusOK, `{"id":"i1","status":"Settled","metadata":[]}`),
+			Err:         true,
+		},
+		"Unknown buyer email": {
+			InvoiceResp: httpmock.NewStringResponder(http.StatusOK, `{"id":"i1","status":"Settled"}`),
+			Err:         true,
+		},
+		"Error returned during email sending": {
+			InvoiceResp: httpmock.NewStringResponder(http.StatusOK, settled),
+			EmailResp:   httpmock.NewStringResponder(http.StatusNotFound, `{"code":"not-found","message":"not found"}`),
+			Err:         true,
+		},
+		"Successful receipt sending": {
+			InvoiceResp: httpmock.NewStringResponder(http.StatusOK, settled),
+			EmailResp: email(EmailParams{
+				Email:   "buyer@test.com",
+				Subject: "Payment receipt for order o1",
+				Body:    body,
+			}),
+		},
+		"Successful receipt sending with custom params": {
+			Params:      ReceiptParams{Email: "other@test.com", Subject: "Receipt"},
+			InvoiceResp: httpmock.NewStringResponder(http.StatusOK, settled),
+			EmailResp: email(EmailParams{
+				Email:   "other@test.com",
+				Subject: "Receipt",
+				Body:    body,
+			}),
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/stores/s1/invoices/i1", c.InvoiceResp)
+
+			if c.EmailResp != nil {
+				mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/stores/s1/email/send", c.EmailResp)
+			}
+
+			err = client.ForStore("s1").SendInvoiceReceipt(context.Background(), "i1", c.Params)
+			if c.Err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
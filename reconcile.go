@@ -0,0 +1,288 @@
+package btcpay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/swithek/btcpay-go/currency"
+	"golang.org/x/sync/errgroup"
+)
+
+// PaymentState specifies how much of an invoice was paid.
+type PaymentState string
+
+// Available payment states.
+const (
+	PaymentStateUnpaid    PaymentState = "unpaid"
+	PaymentStateUnderpaid PaymentState = "underpaid"
+	PaymentStateExact     PaymentState = "exact"
+	PaymentStateOverpaid  PaymentState = "overpaid"
+)
+
+// ReconcileParams holds the settings of an invoice reconciliation.
+type ReconcileParams struct {
+	StoreID string
+
+	// Start and End limit the report to invoices created within the
+	// period. Zero values are ignored.
+	Start time.Time
+	End   time.Time
+
+	// Statuses limits the report to invoices with the statuses. All
+	// invoices are included if none are provided.
+	Statuses []InvoiceStatus
+
+	// Concurrency is the maximum number of concurrent requests of
+	// invoice payment details. Defaults to 4.
+	Concurrency int
+}
+
+// ReconciledPayment holds data of a single payment received towards an
+// invoice.
+type ReconciledPayment struct {
+	PaymentMethod string
+	CryptoCode    string
+	ID            string
+	TxID          string
+	Destination   string
+	Status        string
+	ReceivedDate  int64
+
+	// Value and Fee are specified in the payment method's
+	// cryptocurrency.
+	Value decimal.Decimal
+	Fee   decimal.Decimal
+
+	// FiatValue is the value converted to the invoice currency at the
+	// invoice rate.
+	FiatValue decimal.Decimal
+}
+
+// InvoiceReconciliation holds the settlement details of a single
+// invoice. Amounts are specified in the invoice currency and rounded to
+// its precision, unless stated otherwise.
+type InvoiceReconciliation struct {
+	Invoice  StoreInvoice
+	State    PaymentState
+	Payments []ReconciledPayment
+
+	// Paid is the total amount paid using all payment methods.
+	Paid decimal.Decimal
+
+	// Difference is the paid amount minus the invoice amount. It is
+	// positive for overpayments and negative for underpayments.
+	Difference decimal.Decimal
+
+	// NetworkFees is the total of network fees covered by the buyer.
+	NetworkFees decimal.Decimal
+}
+
+// ReconciliationTotals holds the totals of invoices in a single
+// currency.
+type ReconciliationTotals struct {
+	Invoices    int
+	Invoiced    decimal.Decimal
+	Paid        decimal.Decimal
+	Overpaid    decimal.Decimal
+	Underpaid   decimal.Decimal
+	NetworkFees decimal.Decimal
+}
+
+// ReconciliationReport holds the settlement details of invoices.
+type ReconciliationReport struct {
+	Invoices []InvoiceReconciliation
+
+	// Totals are keyed by invoice currencies. Underpaid holds the
+	// missing amounts of underpaid invoices as positive values.
+	Totals map[string]ReconciliationTotals
+}
+
+// ReconcileInvoices retrieves invoices of the store along with payment
+// details of their payment methods and produces a reconciliation report.
+// The payment state of an invoice is taken from its additional status
+// when the server provides one, so that payment tolerance is respected.
+func (c *Client) ReconcileInvoices(ctx context.Context, p ReconcileParams, opts ...RequestOption) (ReconciliationReport, error) {
+	if p.StoreID == "" {
+		return ReconciliationReport{}, errors.New("store ID is required")
+	}
+
+	if p.Concurrency <= 0 {
+		p.Concurrency = defaultBatchConcurrency
+	}
+
+	invs, err := c.reconciledInvoices(ctx, p, opts...)
+	if err != nil {
+		return ReconciliationReport{}, err
+	}
+
+	res := make([]InvoiceReconciliation, len(invs))
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(p.Concurrency)
+
+	for i := range invs {
+		i := i
+
+		g.Go(func() error {
+			pms, err := c.StoreInvoicePaymentMethods(gctx, p.StoreID, invs[i].ID, opts...)
+			if err != nil {
+				return err
+			}
+
+			res[i] = reconcileInvoice(invs[i], pms)
+
+			return nil
+		})
+	}
+
+	if err = g.Wait(); err != nil {
+		return ReconciliationReport{}, err
+	}
+
+	rep := ReconciliationReport{
+		Invoices: res,
+		Totals:   make(map[string]ReconciliationTotals),
+	}
+
+	for _, r := range res {
+		t := rep.Totals[r.Invoice.Currency]
+		t.Invoices++
+		t.Invoiced = t.Invoiced.Add(r.Invoice.Amount)
+		t.Paid = t.Paid.Add(r.Paid)
+		t.NetworkFees = t.NetworkFees.Add(r.NetworkFees)
+
+		switch r.State {
+		case PaymentStateOverpaid:
+			t.Overpaid = t.Overpaid.Add(r.Difference)
+		case PaymentStateUnderpaid:
+			t.Underpaid = t.Underpaid.Sub(r.Difference)
+		}
+
+		rep.Totals[r.Invoice.Currency] = t
+	}
+
+	return rep, nil
+}
+
+// reconciledInvoices retrieves invoices of the store that match the
+// params.
+func (c *Client) reconciledInvoices(ctx context.Context, p ReconcileParams, opts ...RequestOption) ([]StoreInvoice, error) {
+	params := url.Values{}
+
+	if !p.Start.IsZero() {
+		params.Set("startDate", strconv.FormatInt(p.Start.Unix(), 10))
+	}
+
+	if !p.End.IsZero() {
+		params.Set("endDate", strconv.FormatInt(p.End.Unix(), 10))
+	}
+
+	match := make(map[InvoiceStatus]bool, len(p.Statuses))
+
+	for _, s := range p.Statuses {
+		params.Add("status", string(s))
+		match[s] = true
+	}
+
+	resp, err := c.sendAPI(ctx, http.MethodGet, newRoute("/api/v1/stores/:id/invoices", p.StoreID), params, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var invs []StoreInvoice
+
+	if err = c.decode(resp.Body, &invs); err != nil {
+		return nil, err
+	}
+
+	res := invs[:0]
+
+	for _, inv := range invs {
+		// older servers ignore the filters
+		if (!p.Start.IsZero() && inv.CreatedTime < p.Start.Unix()) ||
+			(!p.End.IsZero() && inv.CreatedTime > p.End.Unix()) ||
+			(len(match) > 0 && !match[InvoiceStatus(inv.Status)]) {
+			continue
+		}
+
+		res = append(res, inv)
+	}
+
+	return res, nil
+}
+
+// reconcileInvoice calculates the settlement details of the invoice.
+func reconcileInvoice(inv StoreInvoice, pms []InvoicePaymentMethod) InvoiceReconciliation {
+	r := InvoiceReconciliation{Invoice: inv}
+
+	var diff, fees decimal.Decimal
+
+	for _, pm := range pms {
+		// every payment method reports the total of all payments in its
+		// own currency, so any activated one can be used
+		if pm.Amount.IsPositive() && diff.IsZero() {
+			diff = pm.TotalPaid.Sub(pm.Amount).Mul(pm.Rate)
+		}
+
+		for _, pay := range pm.Payments {
+			r.Payments = append(r.Payments, ReconciledPayment{
+				PaymentMethod: pm.PaymentMethod,
+				CryptoCode:    pm.CryptoCode,
+				ID:            pay.ID,
+				TxID:          pay.TxID(),
+				Destination:   pay.Destination,
+				Status:        pay.Status,
+				ReceivedDate:  pay.ReceivedDate,
+				Value:         pay.Value,
+				Fee:           pay.Fee,
+				FiatValue:     currency.Round(pay.Value.Mul(pm.Rate), inv.Currency),
+			})
+
+			fees = fees.Add(pay.Fee.Mul(pm.Rate))
+		}
+	}
+
+	if len(r.Payments) == 0 {
+		r.State = PaymentStateUnpaid
+		r.Difference = inv.Amount.Neg()
+
+		return r
+	}
+
+	r.Difference = currency.Round(diff, inv.Currency)
+	r.Paid = inv.Amount.Add(r.Difference)
+	r.NetworkFees = currency.Round(fees, inv.Currency)
+
+	switch inv.AdditionalStatus {
+	case "PaidOver":
+		r.State = PaymentStateOverpaid
+	case "PaidPartial":
+		r.State = PaymentStateUnderpaid
+	case "":
+		r.State = stateOf(r.Difference)
+	default:
+		r.State = PaymentStateExact
+	}
+
+	return r
+}
+
+// stateOf returns the payment state of an invoice that has payments,
+// based on the difference between the paid and invoiced amounts.
+func stateOf(diff decimal.Decimal) PaymentState {
+	switch diff.Sign() {
+	case 1:
+		return PaymentStateOverpaid
+	case -1:
+		return PaymentStateUnderpaid
+	default:
+		return PaymentStateExact
+	}
+}
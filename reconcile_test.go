@@ -0,0 +1,181 @@
+package btcpay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_ReconcileInvoices(t *testing.T) {
+	list := `[
+		{"id":"i1","status":"Settled","additionalStatus":"None","amount":"10","currency":"USD","createdTime":200},
+		{"id":"i2","status":"Expired","additionalStatus":"PaidPartial","amount":"10","currency":"USD","createdTime":300},
+		{"id":"i3","status":"Settled","additionalStatus":"PaidOver","amount":"20","currency":"EUR","createdTime":400},
+		{"id":"i4","status":"New","amount":"5","currency":"USD","createdTime":500},
+		{"id":"i5","status":"Settled","amount":"5","currency":"USD","createdTime":50}
+	]`
+
+	pms := map[string]string{
+		"i1": `[{"paymentMethod":"BTC","cryptoCode":"BTC","rate":"20000","amount":"0.00051","totalPaid":"0.00051","paymentMethodPaid":"0.00051",
+			"payments":[{"id":"tx1-0","receivedDate":210,"value":"0.00051","fee":"0.00001","status":"Settled","destination":"bc1q1"}]}]`,
+		"i2": `[{"paymentMethod":"BTC","cryptoCode":"BTC","rate":"20000","amount":"0.0005","totalPaid":"0.00025","paymentMethodPaid":"0.00025",
+			"payments":[{"id":"tx2-1","receivedDate":310,"value":"0.00025","status":"Settled","destination":"bc1q2"}]}]`,
+		"i3": `[{"paymentMethod":"BTC","cryptoCode":"BTC","rate":"10000","amount":"0.002","totalPaid":"0.0025","paymentMethodPaid":"0"},
+			{"paymentMethod":"BTC-LightningNetwork","cryptoCode":"BTC","rate":"10000","amount":"0.002","totalPaid":"0.0025","paymentMethodPaid":"0.0025",
+			"payments":[{"id":"h3","receivedDate":410,"value":"0.0025","status":"Settled"}]}]`,
+		"i4": `[{"paymentMethod":"BTC","cryptoCode":"BTC","rate":"20000","amount":"0.00025","totalPaid":"0"}]`,
+	}
+
+	cc := map[string]struct {
+		Params   ReconcileParams
+		ListResp httpmock.Responder
+		PMResp   httpmock.Responder
+		Err      bool
+	}{
+		"Missing store ID": {
+			Err: true,
+		},
+		"Error returned during invoice listing": {
+			Params:   ReconcileParams{StoreID: "s1"},
+			ListResp: httpmock.NewErrorResponder(assert.AnError),
+			Err:      true,
+		},
+		"Invalid invoice list": {
+			Params:   ReconcileParams{StoreID: "s1"},
+			ListResp: httpmock.NewStringResponder(http.StatusOK, "{"),
+			Err:      true,
+		},
+		"Error returned during payment method retrieval": {
+			Params:   ReconcileParams{StoreID: "s1"},
+			ListResp: httpmock.NewStringResponder(http.StatusOK, list),
+			PMResp:   httpmock.NewErrorResponder(assert.AnError),
+			Err:      true,
+		},
+		"Successful reconciliation": {
+			Params: ReconcileParams{
+				StoreID:     "s1",
+				Start:       time.Unix(100, 0),
+				End:         time.Unix(1000, 0),
+				Concurrency: 2,
+			},
+			ListResp: func(r *http.Request) (*http.Response, error) {
+				q := r.URL.Query()
+				if q.Get("startDate") != "100" || q.Get("endDate") != "1000" || q.Get("status") != "" {
+					return nil, errors.New("invalid query params")
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, list), nil
+			},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/stores/s1/invoices", c.ListResp)
+
+			for id, body := range pms {
+				resp := c.PMResp
+				if resp == nil {
+					resp = httpmock.NewStringResponder(http.StatusOK, body)
+				}
+
+				mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/stores/s1/invoices/"+id+"/payment-methods", resp)
+			}
+
+			rep, err := client.ReconcileInvoices(context.Background(), c.Params)
+			if c.Err {
+				assert.Error(t, err)
+				assert.Empty(t, rep.Invoices)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, rep.Invoices, 4)
+
+			exp := []struct {
+				ID, State, Paid, Difference, Fees string
+				Payments                          int
+			}{
+				{"i1", "exact", "10", "0", "0.2", 1},
+				{"i2", "underpaid", "5", "-5", "0", 1},
+				{"i3", "overpaid", "25", "5", "0", 1},
+				{"i4", "unpaid", "0", "-5", "0", 0},
+			}
+
+			for i, e := range exp {
+				r := rep.Invoices[i]
+				assert.Equal(t, e.ID, r.Invoice.ID)
+				assert.Equal(t, PaymentState(e.State), r.State, e.ID)
+				assert.Equal(t, e.Paid, r.Paid.String(), e.ID)
+				assert.Equal(t, e.Difference, r.Difference.String(), e.ID)
+				assert.Equal(t, e.Fees, r.NetworkFees.String(), e.ID)
+				assert.Len(t, r.Payments, e.Payments, e.ID)
+			}
+
+			pay := rep.Invoices[0].Payments[0]
+			assert.Equal(t, "BTC", pay.PaymentMethod)
+			assert.Equal(t, "tx1", pay.TxID)
+			assert.Equal(t, "bc1q1", pay.Destination)
+			assert.Equal(t, int64(210), pay.ReceivedDate)
+			assert.Equal(t, "0.00051", pay.Value.String())
+			assert.Equal(t, "0.00001", pay.Fee.String())
+			assert.Equal(t, "10.2", pay.FiatValue.String())
+			assert.Equal(t, "BTC-LightningNetwork", rep.Invoices[2].Payments[0].PaymentMethod)
+
+			require.Len(t, rep.Totals, 2)
+
+			usd := rep.Totals["USD"]
+			assert.Equal(t, 3, usd.Invoices)
+			assert.Equal(t, "25", usd.Invoiced.String())
+			assert.Equal(t, "15", usd.Paid.String())
+			assert.Equal(t, "0", usd.Overpaid.String())
+			assert.Equal(t, "5", usd.Underpaid.String())
+			assert.Equal(t, "0.2", usd.NetworkFees.String())
+
+			eur := rep.Totals["EUR"]
+			assert.Equal(t, 1, eur.Invoices)
+			assert.Equal(t, "20", eur.Invoiced.String())
+			assert.Equal(t, "25", eur.Paid.String())
+			assert.Equal(t, "5", eur.Overpaid.String())
+			assert.Equal(t, "0", eur.Underpaid.String())
+		})
+	}
+}
+
+func Test_reconcileInvoice_State(t *testing.T) {
+	pms := func(paid string) []InvoicePaymentMethod {
+		return []InvoicePaymentMethod{{
+			Rate:      decimal.RequireFromString("10"),
+			Amount:    decimal.RequireFromString("1"),
+			TotalPaid: decimal.RequireFromString(paid),
+			Payments:  []InvoicePayment{{ID: "p1", Value: decimal.RequireFromString(paid)}},
+		}}
+	}
+
+	inv := StoreInvoice{Amount: decimal.RequireFromString("10"), Currency: "USD"}
+
+	// servers without additional statuses
+	assert.Equal(t, PaymentStateExact, reconcileInvoice(inv, pms("1")).State)
+	assert.Equal(t, PaymentStateOverpaid, reconcileInvoice(inv, pms("1.5")).State)
+	assert.Equal(t, PaymentStateUnderpaid, reconcileInvoice(inv, pms("0.5")).State)
+
+	// payments within the tolerance
+	inv.AdditionalStatus = "None"
+	assert.Equal(t, PaymentStateExact, reconcileInvoice(inv, pms("0.999")).State)
+}
@@ -0,0 +1,180 @@
+package btcpay
+
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// redacted replaces sensitive values in formatted output.
+const redacted = "[REDACTED]"
+
+// Sensitive is a string that holds personal data of a buyer or a
+// secret. It is sent to the server as it is, but formatted as
+// [REDACTED] by the fmt package, so that it doesn't leak into logs.
+type Sensitive string
+
+// String returns [REDACTED], unless the value is empty.
+func (s Sensitive) String() string {
+	if s == "" {
+		return ""
+	}
+
+	return redacted
+}
+
+// GoString returns the quoted String value.
+func (s Sensitive) GoString() string {
+	return strconv.Quote(s.String())
+}
+
+// Reveal returns the actual value.
+func (s Sensitive) Reveal() string {
+	return string(s)
+}
+
+// WithoutRedaction disables redaction of tokens and personal data in
+// errors, signature debug output and telemetry attributes of the BTCPay
+// client. It is meant for development only. Sensitive values are
+// redacted by the fmt package regardless.
+func WithoutRedaction() setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.noRedaction = true
+	}
+}
+
+// sensitiveKeys holds the JSON keys and query parameters whose values
+// are redacted.
+var sensitiveKeys = map[string]bool{
+	"token":         true,
+	"password":      true,
+	"email":         true,
+	"buyerEmail":    true,
+	"name":          true,
+	"buyerName":     true,
+	"address1":      true,
+	"address2":      true,
+	"buyerAddress1": true,
+	"buyerAddress2": true,
+	"postalCode":    true,
+	"buyerZip":      true,
+	"phone":         true,
+	"buyerPhone":    true,
+}
+
+// emailPattern matches email addresses in free text.
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// redactText replaces email addresses in the text.
+func (c *Client) redactText(s string) string {
+	if c.noRedaction {
+		return s
+	}
+
+	return emailPattern.ReplaceAllString(s, redacted)
+}
+
+// redactURL replaces values of sensitive query parameters, such as the
+// token, and email addresses in the URL. The order of the parameters
+// is preserved.
+func (c *Client) redactURL(rawURL string) string {
+	if c.noRedaction {
+		return rawURL
+	}
+
+	i := strings.IndexByte(rawURL, '?')
+	if i < 0 {
+		return c.redactText(rawURL)
+	}
+
+	pp := strings.Split(rawURL[i+1:], "&")
+
+	for j, p := range pp {
+		k := strings.SplitN(p, "=", 2)[0]
+
+		if uk, err := url.QueryUnescape(k); err == nil && sensitiveKeys[uk] {
+			pp[j] = k + "=" + redacted
+		}
+	}
+
+	return c.redactText(rawURL[:i+1] + strings.Join(pp, "&"))
+}
+
+// redactBody replaces values of sensitive fields in the JSON body.
+// Bodies that are not JSON are redacted as text.
+func (c *Client) redactBody(body string) string {
+	if c.noRedaction || body == "" {
+		return body
+	}
+
+	var v interface{}
+
+	if json.Unmarshal([]byte(body), &v) != nil {
+		return c.redactText(body)
+	}
+
+	d, err := json.Marshal(redactValue(v))
+	if err != nil {
+		// unlikely to happen
+		return c.redactText(body)
+	}
+
+	return c.redactText(string(d))
+}
+
+// redactValue replaces values of sensitive fields in the decoded JSON
+// value.
+func redactValue(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		for k, fv := range vv {
+			if _, ok := fv.(string); ok && sensitiveKeys[k] {
+				vv[k] = redacted
+				continue
+			}
+
+			vv[k] = redactValue(fv)
+		}
+	case []interface{}:
+		for i := range vv {
+			vv[i] = redactValue(vv[i])
+		}
+	}
+
+	return v
+}
+
+// redactError removes the token and personal data from the error
+// returned by the client. URLs of transport errors and messages of
+// server errors are redacted.
+func (c *Client) redactError(err error) error {
+	if c.noRedaction {
+		return err
+	}
+
+	switch e := err.(type) { //nolint:errorlint // only errors created by the client are redacted
+	case *url.Error:
+		return &url.Error{
+			Op:  e.Op,
+			URL: c.redactURL(e.URL),
+			Err: e.Err,
+		}
+	case *APIError:
+		res := *e
+		res.Message = c.redactText(e.Message)
+
+		if len(e.Fields) > 0 {
+			res.Fields = make([]FieldError, len(e.Fields))
+
+			for i, f := range e.Fields {
+				res.Fields[i] = FieldError{Path: f.Path, Message: c.redactText(f.Message)}
+			}
+		}
+
+		return &res
+	default:
+		return err
+	}
+}
@@ -0,0 +1,124 @@
+package btcpay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Sensitive(t *testing.T) {
+	s := Sensitive("buyer@test.com")
+
+	assert.Equal(t, "[REDACTED] [REDACTED] \"[REDACTED]\"", fmt.Sprintf("%v %+v %#v", s, s, s))
+	assert.Contains(t, fmt.Sprintf("%+v", EmailParams{Email: s}), "Email:[REDACTED]")
+	assert.Equal(t, "", Sensitive("").String())
+	assert.Equal(t, "buyer@test.com", s.Reveal())
+
+	b, err := json.Marshal(InvoiceBuyer{Email: s})
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"buyer@test.com"`)
+}
+
+func Test_WithoutRedaction(t *testing.T) {
+	c := &Client{}
+	WithoutRedaction()(c)
+	assert.True(t, c.noRedaction)
+}
+
+func Test_Client_redactURL(t *testing.T) {
+	c := &Client{}
+
+	assert.Equal(t, "http://test.com/invoices?token=[REDACTED]&status=new&buyerEmail=[REDACTED]",
+		c.redactURL("http://test.com/invoices?token=tok&status=new&buyerEmail=a%40test.com"))
+	assert.Equal(t, "http://test.com/users/[REDACTED]", c.redactURL("http://test.com/users/a@test.com"))
+
+	c.noRedaction = true
+	assert.Equal(t, "http://test.com/invoices?token=tok", c.redactURL("http://test.com/invoices?token=tok"))
+}
+
+func Test_Client_redactBody(t *testing.T) {
+	c := &Client{}
+
+	assert.Equal(t, `{"buyer":{"country":"LT","email":"[REDACTED]"},"items":[{"password":"[REDACTED]"}],"price":1}`,
+		c.redactBody(`{"price":1,"buyer":{"email":"a@test.com","country":"LT"},"items":[{"password":"p"}]}`))
+	assert.Equal(t, "contact [REDACTED]", c.redactBody("contact a@test.com"))
+
+	c.noRedaction = true
+	assert.Equal(t, `{"email":"a@test.com"}`, c.redactBody(`{"email":"a@test.com"}`))
+}
+
+func Test_Client_redactError(t *testing.T) {
+	cc := map[string]struct {
+		Setters []setter
+		Call    func(c *Client) error
+		Exp     []string
+		NotExp  []string
+	}{
+		"Transport error with the token": {
+			Call: func(c *Client) error {
+				_, err := c.Invoice(context.Background(), "1")
+				return err
+			},
+			Exp:    []string{"token=[REDACTED]"},
+			NotExp: []string{"token=secret"},
+		},
+		"Server error with an email": {
+			Call: func(c *Client) error {
+				_, err := c.StoreInvoice(context.Background(), "s1", "1")
+				return err
+			},
+			Exp:    []string{"[REDACTED] is invalid"},
+			NotExp: []string{"a@test.com"},
+		},
+		"Redaction disabled": {
+			Setters: []setter{WithoutRedaction()},
+			Call: func(c *Client) error {
+				_, err := c.Invoice(context.Background(), "1")
+				return err
+			},
+			Exp: []string{"token=secret"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			mt.RegisterResponder(http.MethodGet, "http://test.com/invoices/1", httpmock.NewErrorResponder(assert.AnError))
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/stores/s1/invoices/1",
+				httpmock.NewStringResponder(http.StatusUnprocessableEntity, `[{"path":"email","message":"a@test.com is invalid"}]`))
+
+			client, err := NewClient("http://test.com", "secret", append([]setter{WithHTTPClient(&http.Client{Transport: mt})}, c.Setters...)...)
+			require.NoError(t, err)
+
+			err = c.Call(client)
+			require.Error(t, err)
+
+			for _, s := range c.Exp {
+				assert.Contains(t, err.Error(), s)
+			}
+
+			for _, s := range c.NotExp {
+				assert.NotContains(t, err.Error(), s)
+			}
+		})
+	}
+}
+
+func Test_Client_redactError_wrapped(t *testing.T) {
+	c := &Client{}
+
+	err := c.redactError(&url.Error{Op: "Get", URL: "http://test.com/?token=tok", Err: assert.AnError})
+	assert.EqualError(t, err, `Get "http://test.com/?token=[REDACTED]": `+assert.AnError.Error())
+	assert.ErrorIs(t, err, assert.AnError)
+}
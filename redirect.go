@@ -0,0 +1,133 @@
+package btcpay
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+)
+
+// invoiceIDPlaceholder is replaced with the ID of the invoice in its
+// redirect URL by the server.
+const invoiceIDPlaceholder = "{InvoiceId}"
+
+// RedirectParams holds data extracted from a verified redirect URL.
+type RedirectParams struct {
+	InvoiceID string
+	OrderID   string
+
+	// InvoiceVerified is false if the URL was signed without the
+	// invoice ID, in which case the invoice ID was filled in by the
+	// server (or anyone else) and is not authenticated.
+	InvoiceVerified bool
+}
+
+// SignRedirectURL adds the order and invoice IDs along with their
+// HMAC-SHA256 to the query of the redirect URL, so that they can be
+// authenticated with VerifyRedirectURL when the buyer returns.
+// If the invoice ID is not known yet, e.g. when the URL is used as the
+// redirect URL of a new invoice, it may be left empty; the server then
+// fills it in and only the order ID is authenticated.
+func SignRedirectURL(rawURL, invoiceID, orderID string, secret []byte) (string, error) {
+	if orderID == "" {
+		return "", errors.New("order ID is required")
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	if !u.IsAbs() {
+		return "", errors.New("redirect URL must be absolute")
+	}
+
+	q := u.Query()
+	q.Set("orderId", orderID)
+	q.Set("sig", hex.EncodeToString(redirectMAC(invoiceID, orderID, secret)))
+
+	if invoiceID != "" {
+		q.Set("invoiceId", invoiceID)
+		u.RawQuery = q.Encode()
+
+		return u.String(), nil
+	}
+
+	q.Del("invoiceId")
+
+	// the placeholder must not be escaped, otherwise the server
+	// would not replace it
+	u.RawQuery = q.Encode() + "&invoiceId=" + invoiceIDPlaceholder
+
+	return u.String(), nil
+}
+
+// VerifyRedirectURL authenticates the order and invoice IDs of a
+// redirect URL signed with SignRedirectURL. A valid signature only
+// proves that the URL was issued by the merchant; the invoice must
+// still be retrieved to check whether it was paid.
+func VerifyRedirectURL(u *url.URL, secret []byte) (RedirectParams, error) {
+	q := u.Query()
+
+	p := RedirectParams{
+		InvoiceID: q.Get("invoiceId"),
+		OrderID:   q.Get("orderId"),
+	}
+
+	if p.OrderID == "" {
+		return RedirectParams{}, errors.New("order ID is missing")
+	}
+
+	sig, err := hex.DecodeString(q.Get("sig"))
+	if err != nil {
+		return RedirectParams{}, err
+	}
+
+	if p.InvoiceID != "" && hmac.Equal(sig, redirectMAC(p.InvoiceID, p.OrderID, secret)) {
+		p.InvoiceVerified = true
+		return p, nil
+	}
+
+	if !hmac.Equal(sig, redirectMAC("", p.OrderID, secret)) {
+		return RedirectParams{}, errors.New("invalid redirect signature")
+	}
+
+	return p, nil
+}
+
+// VerifyRedirect authenticates the redirect URL with VerifyRedirectURL
+// and retrieves its invoice. If the invoice ID is not authenticated by
+// the signature, the invoice's order ID is checked instead. The status
+// of the returned invoice decides whether the order is paid.
+func (c *Client) VerifyRedirect(ctx context.Context, u *url.URL, secret []byte, opts ...RequestOption) (Invoice, error) {
+	p, err := VerifyRedirectURL(u, secret)
+	if err != nil {
+		return Invoice{}, err
+	}
+
+	if p.InvoiceID == "" || p.InvoiceID == invoiceIDPlaceholder {
+		return Invoice{}, errors.New("invoice ID is missing")
+	}
+
+	// the status must be current for the redirect to be trusted
+	inv, err := c.Invoice(ctx, p.InvoiceID, append([]RequestOption{WithoutCache()}, opts...)...)
+	if err != nil {
+		return Invoice{}, err
+	}
+
+	if inv.OrderID != p.OrderID {
+		return Invoice{}, errors.New("invoice does not belong to the order")
+	}
+
+	return inv, nil
+}
+
+// redirectMAC calculates the HMAC-SHA256 of the invoice and order IDs.
+func redirectMAC(invoiceID, orderID string, secret []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(invoiceID + "\n" + orderID)) //nolint:errcheck // hash writes never fail
+
+	return h.Sum(nil)
+}
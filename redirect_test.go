@@ -0,0 +1,180 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SignRedirectURL(t *testing.T) {
+	_, err := SignRedirectURL("http://test.com/return", "i1", "", []byte("key"))
+	assert.Error(t, err)
+
+	_, err = SignRedirectURL("/return", "i1", "o1", []byte("key"))
+	assert.Error(t, err)
+
+	_, err = SignRedirectURL("http://test.com/%zz", "i1", "o1", []byte("key"))
+	assert.Error(t, err)
+
+	res, err := SignRedirectURL("http://test.com/return?lang=en", "i1", "o1", []byte("key"))
+	require.NoError(t, err)
+
+	u, err := url.Parse(res)
+	require.NoError(t, err)
+	assert.Equal(t, "en", u.Query().Get("lang"))
+
+	p, err := VerifyRedirectURL(u, []byte("key"))
+	require.NoError(t, err)
+	assert.Equal(t, RedirectParams{InvoiceID: "i1", OrderID: "o1", InvoiceVerified: true}, p)
+
+	res, err = SignRedirectURL("http://test.com/return", "", "o1", []byte("key"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(res, "&invoiceId={InvoiceId}"))
+}
+
+func Test_VerifyRedirectURL(t *testing.T) {
+	signed := func(invoiceID, orderID string) string {
+		res, err := SignRedirectURL("http://test.com/return", invoiceID, orderID, []byte("key"))
+		require.NoError(t, err)
+
+		return res
+	}
+
+	cc := map[string]struct {
+		URL    string
+		Err    bool
+		Result RedirectParams
+	}{
+		"Missing order ID": {
+			URL: "http://test.com/return?invoiceId=i1&sig=00",
+			Err: true,
+		},
+		"Invalid signature encoding": {
+			URL: "http://test.com/return?invoiceId=i1&orderId=o1&sig=zz",
+			Err: true,
+		},
+		"Invalid signature": {
+			URL: "http://test.com/return?invoiceId=i1&orderId=o1&sig=00",
+			Err: true,
+		},
+		"Tampered order ID": {
+			URL: strings.Replace(signed("i1", "o1"), "orderId=o1", "orderId=o2", 1),
+			Err: true,
+		},
+		"Tampered invoice ID": {
+			URL: strings.Replace(signed("i1", "o1"), "invoiceId=i1", "invoiceId=i2", 1),
+			Err: true,
+		},
+		"Successful verification with invoice ID": {
+			URL:    signed("i1", "o1"),
+			Result: RedirectParams{InvoiceID: "i1", OrderID: "o1", InvoiceVerified: true},
+		},
+		"Successful verification without invoice ID": {
+			URL:    strings.Replace(signed("", "o1"), "{InvoiceId}", "i1", 1),
+			Result: RedirectParams{InvoiceID: "i1", OrderID: "o1"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			u, err := url.Parse(c.URL)
+			require.NoError(t, err)
+
+			res, err := VerifyRedirectURL(u, []byte("key"))
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_VerifyRedirect(t *testing.T) {
+	signed := func(invoiceID string) string {
+		res, err := SignRedirectURL("http://test.com/return", invoiceID, "o1", []byte("key"))
+		require.NoError(t, err)
+
+		return strings.Replace(res, "{InvoiceId}", "i1", 1)
+	}
+
+	cc := map[string]struct {
+		URL    string
+		Resp   httpmock.Responder
+		Err    bool
+		Result Invoice
+	}{
+		"Invalid signature": {
+			URL: "http://test.com/return?invoiceId=i1&orderId=o1&sig=00",
+			Err: true,
+		},
+		"Missing invoice ID": {
+			URL: func() string {
+				res, err := SignRedirectURL("http://test.com/return", "", "o1", []byte("key"))
+				require.NoError(t, err)
+
+				return res
+			}(),
+			Err: true,
+		},
+		"Error returned during invoice retrieval": {
+			URL:  signed("i1"),
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Order ID mismatch": {
+			URL:  signed(""),
+			Resp: httpmock.NewStringResponder(http.StatusOK, `{"data":{"id":"i1","orderId":"o2"}}`),
+			Err:  true,
+		},
+		"Successful verification": {
+			URL:    signed(""),
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `{"data":{"id":"i1","orderId":"o1","status":"complete"}}`),
+			Result: Invoice{ID: "i1", OrderID: "o1", Status: "complete"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			if c.Resp != nil {
+				mt.RegisterResponder(http.MethodGet, "http://test.com/invoices/i1", c.Resp)
+			}
+
+			u, err := url.Parse(c.URL)
+			require.NoError(t, err)
+
+			res, err := client.VerifyRedirect(context.Background(), u, []byte("key"))
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package btcpay
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxRedirects is the maximum number of redirects followed by a single
+// request.
+const maxRedirects = 10
+
+// followRedirects sends the request and follows the redirects of
+// reverse proxies, e.g. from HTTP to HTTPS or to paths with a trailing
+// slash. The HTTP client's own redirect handling cannot be used, since
+// legacy API signatures cover the URL and have to be computed again.
+// Only redirects within the same host are followed, optionally
+// upgraded to HTTPS; other redirects result in an error.
+func (c *Client) followRedirects(req *http.Request) (*http.Response, error) {
+	for i := 0; ; i++ {
+		resp, err := c.roundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isRedirect(resp.StatusCode) {
+			return resp, nil
+		}
+
+		loc := resp.Header.Get("Location")
+		if loc == "" {
+			return resp, nil
+		}
+
+		resp.Body.Close()
+
+		u, err := req.URL.Parse(loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redirect location: %w", err)
+		}
+
+		if i >= maxRedirects {
+			return nil, fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+
+		if !sameHost(req.URL, u) {
+			return nil, fmt.Errorf("server redirected %s to %s, which is not followed, since the request is "+
+				"authenticated for the original host; the client's host should be set to the redirect's host",
+				c.redactURL(req.URL.String()), c.redactURL(u.String()))
+		}
+
+		// bodies that cannot be read again are never resent
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return nil, fmt.Errorf("server redirected %s to %s, but the request body cannot be resent",
+				c.redactURL(req.URL.String()), c.redactURL(u.String()))
+		}
+
+		if resp.StatusCode == http.StatusSeeOther && req.Method != http.MethodHead {
+			req = req.Clone(req.Context())
+			req.Method = http.MethodGet
+			req.Body = http.NoBody
+			req.GetBody = nil
+			req.ContentLength = 0
+		}
+
+		if req, err = c.retarget(req, u); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sendOnce sends the request with the HTTP client without following
+// redirects.
+func (c *Client) sendOnce(req *http.Request) (*http.Response, error) {
+	hc := *c.hc
+	hc.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	return hc.Do(req)
+}
+
+// isRedirect checks whether the status code is a redirect that has a
+// location.
+func isRedirect(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	default:
+		return false
+	}
+}
+
+// sameHost checks whether the redirect from a to b stays within the
+// same host. The port may only change along with an upgrade from HTTP
+// to HTTPS; downgrades are never allowed.
+func sameHost(a, b *url.URL) bool {
+	if !strings.EqualFold(a.Hostname(), b.Hostname()) {
+		return false
+	}
+
+	switch {
+	case a.Scheme == b.Scheme:
+		return effectivePort(a) == effectivePort(b)
+	case a.Scheme == "http" && b.Scheme == "https":
+		return true
+	default:
+		return false
+	}
+}
+
+// effectivePort returns the port of the URL or the default port of its
+// scheme.
+func effectivePort(u *url.URL) string {
+	if p := u.Port(); p != "" {
+		return p
+	}
+
+	if u.Scheme == "https" {
+		return "443"
+	}
+
+	return "80"
+}
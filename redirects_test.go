@@ -0,0 +1,163 @@
+package btcpay
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_followRedirects(t *testing.T) {
+	redirect := func(status int, loc string) httpmock.Responder {
+		return func(*http.Request) (*http.Response, error) {
+			resp := httpmock.NewStringResponse(status, "")
+			resp.Header.Set("Location", loc)
+
+			return resp, nil
+		}
+	}
+
+	// signed checks whether the request is signed for its own URL
+	signed := func(r *http.Request) (*http.Response, error) {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = VerifySignature(r.Header.Get("X-Identity"), r.URL.String()+string(b), r.Header.Get("X-Signature")); err != nil {
+			return httpmock.NewStringResponse(http.StatusUnauthorized, `{"error":"invalid signature"}`), nil
+		}
+
+		return httpmock.NewStringResponse(http.StatusOK, `{"data":{"id":"i1"}}`), nil
+	}
+
+	getInvoice := func(c *Client) error {
+		_, err := c.Invoice(context.Background(), "i1")
+		return err
+	}
+
+	createInvoice := func(c *Client) error {
+		_, err := c.CreateInvoice(context.Background(), CreateInvoiceParams{Currency: "USD"})
+		return err
+	}
+
+	cc := map[string]struct {
+		Host  string
+		Resps map[string]httpmock.Responder
+		Call  func(c *Client) error
+		Err   string
+	}{
+		"Redirect to another host": {
+			Host: "http://test.com",
+			Resps: map[string]httpmock.Responder{
+				"GET http://test.com/invoices/i1": redirect(http.StatusMovedPermanently, "http://other.com/invoices/i1?token=tok"),
+			},
+			Call: getInvoice,
+			Err:  "server redirected http://test.com/invoices/i1?token=[REDACTED] to http://other.com/invoices/i1?token=[REDACTED]",
+		},
+		"Redirect to HTTP": {
+			Host: "https://test.com",
+			Resps: map[string]httpmock.Responder{
+				"GET https://test.com/invoices/i1": redirect(http.StatusFound, "http://test.com/invoices/i1"),
+			},
+			Call: getInvoice,
+			Err:  "is not followed",
+		},
+		"Redirect to another port": {
+			Host: "http://test.com",
+			Resps: map[string]httpmock.Responder{
+				"GET http://test.com/invoices/i1": redirect(http.StatusFound, "http://test.com:8080/invoices/i1"),
+			},
+			Call: getInvoice,
+			Err:  "is not followed",
+		},
+		"Too many redirects": {
+			Host: "http://test.com",
+			Resps: map[string]httpmock.Responder{
+				"GET http://test.com/invoices/i1": redirect(http.StatusFound, "/invoices/i1"),
+			},
+			Call: getInvoice,
+			Err:  "stopped after 10 redirects",
+		},
+		"Redirect without location": {
+			Host: "http://test.com",
+			Resps: map[string]httpmock.Responder{
+				"GET http://test.com/invoices/i1": httpmock.NewStringResponder(http.StatusFound, `{"data":{"id":"i1"}}`),
+			},
+			Call: getInvoice,
+		},
+		"Successful redirect to HTTPS": {
+			Host: "http://test.com",
+			Resps: map[string]httpmock.Responder{
+				"GET http://test.com/invoices/i1":  redirect(http.StatusMovedPermanently, "https://test.com/invoices/i1"),
+				"GET https://test.com/invoices/i1": signed,
+			},
+			Call: getInvoice,
+		},
+		"Successful redirect with a body": {
+			Host: "http://test.com",
+			Resps: map[string]httpmock.Responder{
+				"POST http://test.com/invoices":  redirect(http.StatusPermanentRedirect, "/invoices/"),
+				"POST http://test.com/invoices/": signed,
+			},
+			Call: createInvoice,
+		},
+		"Successful see other redirect": {
+			Host: "http://test.com",
+			Resps: map[string]httpmock.Responder{
+				"POST http://test.com/invoices":   redirect(http.StatusSeeOther, "/invoices/i1"),
+				"GET http://test.com/invoices/i1": signed,
+			},
+			Call: createInvoice,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+
+			for k, r := range c.Resps {
+				mr := strings.SplitN(k, " ", 2)
+				mt.RegisterResponder(mr[0], mr[1], r)
+			}
+
+			client, err := NewClient(c.Host, "tok", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			err = c.Call(client)
+			if c.Err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), c.Err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_sameHost(t *testing.T) {
+	parse := func(s string) *url.URL {
+		u, err := url.Parse(s)
+		require.NoError(t, err)
+
+		return u
+	}
+
+	assert.True(t, sameHost(parse("http://test.com/a"), parse("http://TEST.com:80/b")))
+	assert.True(t, sameHost(parse("http://test.com:8080/a"), parse("https://test.com/a")))
+	assert.False(t, sameHost(parse("https://test.com/a"), parse("http://test.com/a")))
+	assert.False(t, sameHost(parse("https://test.com/a"), parse("https://test.com:8443/a")))
+	assert.False(t, sameHost(parse("http://test.com/a"), parse("http://other.com/a")))
+}
@@ -0,0 +1,75 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+)
+
+// ServerInfo holds information about the BTCPay server.
+type ServerInfo struct {
+	Version                 string       `json:"version"`
+	Onion                   string       `json:"onion"`
+	SupportedPaymentMethods []string     `json:"supportedPaymentMethods"`
+	FullySynched            bool         `json:"fullySynched"`
+	SyncStatus              []SyncStatus `json:"syncStatus"`
+}
+
+// SyncStatus holds synchronization status of a single cryptocurrency
+// node.
+type SyncStatus struct {
+	CryptoCode      string          `json:"cryptoCode"`
+	NodeInformation NodeInformation `json:"nodeInformation"`
+	ChainHeight     int64           `json:"chainHeight"`
+	SyncHeight      int64           `json:"syncHeight"`
+	Available       bool            `json:"available"`
+}
+
+// NodeInformation holds information about the state of a cryptocurrency
+// node.
+type NodeInformation struct {
+	Headers              int64   `json:"headers"`
+	Blocks               int64   `json:"blocks"`
+	VerificationProgress float64 `json:"verificationProgress"`
+}
+
+// Health holds health status of the BTCPay server.
+type Health struct {
+	Synchronized bool `json:"synchronized"`
+}
+
+// ServerInfo retrieves information about the BTCPay server.
+func (c *Client) ServerInfo(ctx context.Context, opts ...RequestOption) (ServerInfo, error) {
+	resp, err := c.sendAPI(ctx, http.MethodGet, newRoute("/api/v1/server/info"), nil, nil, opts...)
+	if err != nil {
+		return ServerInfo{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var si ServerInfo
+
+	if err = c.decode(resp.Body, &si); err != nil {
+		return ServerInfo{}, err
+	}
+
+	return si, nil
+}
+
+// Health retrieves health status of the BTCPay server. No API key is
+// needed.
+func (c *Client) Health(ctx context.Context, opts ...RequestOption) (Health, error) {
+	resp, err := c.sendAPI(ctx, http.MethodGet, newRoute("/api/v1/health"), nil, nil, opts...)
+	if err != nil {
+		return Health{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var h Health
+
+	if err = c.decode(resp.Body, &h); err != nil {
+		return Health{}, err
+	}
+
+	return h, nil
+}
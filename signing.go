@@ -0,0 +1,273 @@
+package btcpay
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+)
+
+// WithSignatureDebug makes the BTCPay client write the exact message
+// that was signed along with the resulting X-Identity and X-Signature
+// header values of every signed legacy API request, preceded by its
+// correlation ID if it has one, to w. It helps to find out why a
+// server, or a proxy in front of it, rejects signatures.
+// The facade token and personal data in the message are redacted unless
+// WithoutRedaction is used, in which case the output should not be
+// enabled in production.
+func WithSignatureDebug(w io.Writer) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.sigDebug = &syncWriter{w: w}
+	}
+}
+
+// WithCanonicalSignatures makes the BTCPay client normalize signatures
+// of legacy API requests to low-S, strictly DER encoded form and verify
+// them against the signer's public key before they are sent. The
+// default PEM signer always produces such signatures deterministically
+// (RFC6979), so the option is meant for custom signers, e.g. cloud KMS
+// services, that may return high-S signatures. Custom signers must be
+// deterministic themselves for their signatures to be stable.
+func WithCanonicalSignatures() setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.canonicalSigs = true
+	}
+}
+
+// QueryEncoding controls how the query string of legacy API requests is
+// built. The server verifies signatures against the query string it
+// receives, so it must be reproduced exactly by anyone who signs
+// requests independently of the client, e.g. with SignRequest.
+type QueryEncoding struct {
+	// SortToken sorts the token among the other parameters instead of
+	// placing it first.
+	SortToken bool
+
+	// PercentSpaces encodes spaces as %20 instead of +, for proxies that
+	// decode + differently before the signature is verified.
+	PercentSpaces bool
+}
+
+// WithQueryEncoding sets the query encoding of legacy API requests on
+// the BTCPay client. By default, the token is placed first.
+func WithQueryEncoding(e QueryEncoding) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.queryEncoding = e
+	}
+}
+
+// CanonicalQuery builds the query string of a legacy API request. Unless
+// the encoding sorts it, the token comes first, followed by the
+// parameters sorted by key; repeated keys keep the order of their
+// values. A token parameter is dropped in favour of a non-empty token.
+// Values must not be escaped beforehand, since they are escaped here.
+func CanonicalQuery(token string, params url.Values, e QueryEncoding) string {
+	var q string
+
+	switch {
+	case token == "":
+		q = params.Encode()
+	case e.SortToken:
+		pp := withoutToken(params)
+		pp.Set("token", token)
+		q = pp.Encode()
+	default:
+		q = "token=" + url.QueryEscape(token)
+		if rest := withoutToken(params).Encode(); rest != "" {
+			q += "&" + rest
+		}
+	}
+
+	if e.PercentSpaces {
+		q = strings.ReplaceAll(q, "+", "%20")
+	}
+
+	return q
+}
+
+// withoutToken returns a copy of the parameters without the token.
+func withoutToken(params url.Values) url.Values {
+	pp := make(url.Values, len(params)+1)
+
+	for k, v := range params {
+		if k != "token" {
+			pp[k] = v
+		}
+	}
+
+	return pp
+}
+
+// SignRequest signs the legacy API request with the private key in the
+// PEM string and returns the values of the X-Identity and X-Signature
+// headers. The URL must include the query string exactly as it is sent
+// and the body must be empty for requests without a payload.
+func SignRequest(pm, url, body string) (identity, signature string, err error) {
+	s, err := NewPEMSigner(pm)
+	if err != nil {
+		return "", "", err
+	}
+
+	return signMessage(s, url+body)
+}
+
+// VerifySignature checks whether the hex encoded DER signature of the
+// message was made by the hex encoded public key. Only low-S signatures
+// are accepted, as produced by the client's default signer or with
+// WithCanonicalSignatures.
+func VerifySignature(pub, msg, sig string) error {
+	pb, err := hex.DecodeString(pub)
+	if err != nil {
+		return err
+	}
+
+	pk, err := btcec.ParsePubKey(pb)
+	if err != nil {
+		return err
+	}
+
+	sb, err := hex.DecodeString(sig)
+	if err != nil {
+		return err
+	}
+
+	s, err := ecdsa.ParseDERSignature(sb)
+	if err != nil {
+		return err
+	}
+
+	// serialization normalizes the signature
+	if !bytes.Equal(s.Serialize(), sb) {
+		return errors.New("signature is not low-S normalized")
+	}
+
+	hash := sha256.Sum256([]byte(msg))
+
+	if !s.Verify(hash[:], pk) {
+		return errors.New("invalid signature")
+	}
+
+	return nil
+}
+
+// canonicalSigner normalizes and verifies signatures of another
+// signer.
+type canonicalSigner struct {
+	Signer
+	pub *btcec.PublicKey
+}
+
+// newCanonicalSigner wraps the signer so that its signatures are
+// normalized.
+func newCanonicalSigner(s Signer) (Signer, error) {
+	b, err := hex.DecodeString(s.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+
+	pub, err := btcec.ParsePubKey(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return canonicalSigner{Signer: s, pub: pub}, nil
+}
+
+// Sign signs the message with the wrapped signer and returns the low-S
+// form of its signature.
+func (s canonicalSigner) Sign(msg []byte) ([]byte, error) {
+	b, err := s.Signer.Sign(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := ecdsa.ParseDERSignature(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+
+	hash := sha256.Sum256(msg)
+
+	if !sig.Verify(hash[:], s.pub) {
+		return nil, errors.New("signature does not match the public key")
+	}
+
+	return sig.Serialize(), nil
+}
+
+// signMessage signs the message with the signer and returns the values
+// of the X-Identity and X-Signature headers.
+func signMessage(s Signer, msg string) (identity, signature string, err error) {
+	sig, err := s.Sign([]byte(msg))
+	if err != nil {
+		return "", "", err
+	}
+
+	return s.PublicKey(), hex.EncodeToString(sig), nil
+}
+
+// sign adds the identity and signature headers to the request. The
+// signed message is the full request URL, including the query string,
+// followed by the body.
+func (c *Client) sign(req *http.Request, body string) error {
+	msg := req.URL.String() + body
+
+	id, sig, err := signMessage(c.signer, msg)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("X-Identity", id)
+	req.Header.Set("X-Signature", sig)
+
+	if c.sigDebug != nil {
+		var corr string
+		if cid := c.correlationID(req.Context()); cid != "" {
+			corr = "correlation ID: " + cid + "\n"
+		}
+
+		c.sigDebug.printf("%s %s\n%ssigned: %s\nX-Identity: %s\nX-Signature: %s\n\n", req.Method, c.redactText(req.URL.Path),
+			corr, c.redactURL(req.URL.String())+c.redactBody(body), id, sig)
+	}
+
+	if c.nonces == nil {
+		return nil
+	}
+
+	nonce := strconv.FormatInt(c.nonces.next(c.getClock().Now()), 10)
+
+	_, sig, err = signMessage(c.signer, nonceMessage(nonce, req.URL.String(), body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("X-Nonce", nonce)
+	req.Header.Set("X-Nonce-Signature", sig)
+
+	return nil
+}
+
+// syncWriter serializes writes of concurrent requests.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// printf writes the formatted string to the underlying writer. Write
+// errors are ignored, since debug output must not fail requests.
+func (sw *syncWriter) printf(format string, args ...interface{}) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	fmt.Fprintf(sw.w, format, args...)
+}
@@ -0,0 +1,385 @@
+package btcpay
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/hex"
+	"math/big"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithSignatureDebug(t *testing.T) {
+	c := &Client{}
+	WithSignatureDebug(&bytes.Buffer{})(c)
+	assert.NotNil(t, c.sigDebug)
+}
+
+func Test_WithCanonicalSignatures(t *testing.T) {
+	c := &Client{}
+	WithCanonicalSignatures()(c)
+	assert.True(t, c.canonicalSigs)
+}
+
+// highS returns the high-S form of the DER signature.
+func highS(t *testing.T, sig []byte) []byte {
+	t.Helper()
+
+	var rs struct {
+		R, S *big.Int
+	}
+
+	_, err := asn1.Unmarshal(sig, &rs)
+	require.NoError(t, err)
+
+	rs.S.Sub(btcec.S256().N, rs.S)
+
+	b, err := asn1.Marshal(rs)
+	require.NoError(t, err)
+
+	return b
+}
+
+func Test_VerifySignature(t *testing.T) {
+	pm, err := GeneratePEMFromMnemonic(testMnemonic, "")
+	require.NoError(t, err)
+
+	ps, err := NewPEMSigner(pm)
+	require.NoError(t, err)
+
+	sig, err := ps.Sign([]byte("test"))
+	require.NoError(t, err)
+
+	other, err := GeneratePEM()
+	require.NoError(t, err)
+
+	oss, err := NewPEMSigner(other)
+	require.NoError(t, err)
+
+	cc := map[string]struct {
+		PublicKey string
+		Message   string
+		Signature string
+		Err       bool
+	}{
+		"Invalid public key hex": {
+			PublicKey: "test",
+			Message:   "test",
+			Signature: hex.EncodeToString(sig),
+			Err:       true,
+		},
+		"Invalid public key": {
+			PublicKey: "0102",
+			Message:   "test",
+			Signature: hex.EncodeToString(sig),
+			Err:       true,
+		},
+		"Invalid signature hex": {
+			PublicKey: ps.PublicKey(),
+			Message:   "test",
+			Signature: "test",
+			Err:       true,
+		},
+		"Invalid signature": {
+			PublicKey: ps.PublicKey(),
+			Message:   "test",
+			Signature: "0102",
+			Err:       true,
+		},
+		"High-S signature": {
+			PublicKey: ps.PublicKey(),
+			Message:   "test",
+			Signature: hex.EncodeToString(highS(t, sig)),
+			Err:       true,
+		},
+		"Different message": {
+			PublicKey: ps.PublicKey(),
+			Message:   "test2",
+			Signature: hex.EncodeToString(sig),
+			Err:       true,
+		},
+		"Different public key": {
+			PublicKey: oss.PublicKey(),
+			Message:   "test",
+			Signature: hex.EncodeToString(sig),
+			Err:       true,
+		},
+		"Successful verification": {
+			PublicKey: ps.PublicKey(),
+			Message:   "test",
+			Signature: hex.EncodeToString(sig),
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			err := VerifySignature(c.PublicKey, c.Message, c.Signature)
+			if c.Err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_canonicalSigner(t *testing.T) {
+	_, err := newCanonicalSigner(signerStub{pub: "test"})
+	assert.Error(t, err)
+
+	_, err = newCanonicalSigner(signerStub{pub: "0102"})
+	assert.Error(t, err)
+
+	pm, err := GeneratePEM()
+	require.NoError(t, err)
+
+	ps, err := NewPEMSigner(pm)
+	require.NoError(t, err)
+
+	sig, err := ps.Sign([]byte("test"))
+	require.NoError(t, err)
+
+	other, err := GeneratePEM()
+	require.NoError(t, err)
+
+	oss, err := NewPEMSigner(other)
+	require.NoError(t, err)
+
+	osig, err := oss.Sign([]byte("test"))
+	require.NoError(t, err)
+
+	cc := map[string]struct {
+		Signer    signerStub
+		Signature []byte
+		Err       bool
+	}{
+		"Error returned by signer": {
+			Signer: signerStub{err: assert.AnError},
+			Err:    true,
+		},
+		"Invalid signature": {
+			Signer: signerStub{sig: []byte{1, 2}},
+			Err:    true,
+		},
+		"Signature of a different key": {
+			Signer: signerStub{sig: osig},
+			Err:    true,
+		},
+		"High-S signature": {
+			Signer:    signerStub{sig: highS(t, sig)},
+			Signature: sig,
+		},
+		"Low-S signature": {
+			Signer:    signerStub{sig: sig},
+			Signature: sig,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			c.Signer.pub = ps.PublicKey()
+
+			s, err := newCanonicalSigner(c.Signer)
+			require.NoError(t, err)
+			assert.Equal(t, ps.PublicKey(), s.PublicKey())
+
+			res, err := s.Sign([]byte("test"))
+			if c.Err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, c.Signature, res)
+			assert.NoError(t, VerifySignature(ps.PublicKey(), "test", hex.EncodeToString(res)))
+		})
+	}
+
+	client, err := NewClient("http://test.com", "", WithSigner(signerStub{pub: ps.PublicKey(), sig: highS(t, sig)}), WithCanonicalSignatures())
+	require.NoError(t, err)
+
+	res, err := client.signer.Sign([]byte("test"))
+	require.NoError(t, err)
+	assert.Equal(t, sig, res)
+
+	_, err = NewClient("http://test.com", "", WithSigner(signerStub{pub: "test"}), WithCanonicalSignatures())
+	assert.Error(t, err)
+}
+
+func Test_WithQueryEncoding(t *testing.T) {
+	c := &Client{}
+	WithQueryEncoding(QueryEncoding{SortToken: true})(c)
+	assert.Equal(t, QueryEncoding{SortToken: true}, c.queryEncoding)
+}
+
+func Test_CanonicalQuery(t *testing.T) {
+	cc := map[string]struct {
+		Token    string
+		Params   url.Values
+		Encoding QueryEncoding
+		Result   string
+	}{
+		"Empty": {},
+		"Token only": {
+			Token:  "tok",
+			Result: "token=tok",
+		},
+		"Params only": {
+			Params: url.Values{"status": {"new"}, "limit": {"5"}},
+			Result: "limit=5&status=new",
+		},
+		"Token first": {
+			Token:  "tok",
+			Params: url.Values{"a": {"1"}, "z": {"2"}},
+			Result: "token=tok&a=1&z=2",
+		},
+		"Token param replaced": {
+			Token:  "tok",
+			Params: url.Values{"token": {"old"}, "a": {"1"}},
+			Result: "token=tok&a=1",
+		},
+		"Token param kept without token": {
+			Params: url.Values{"token": {"old"}, "a": {"1"}},
+			Result: "a=1&token=old",
+		},
+		"Repeated keys": {
+			Token:  "tok",
+			Params: url.Values{"status": {"new", "paid"}},
+			Result: "token=tok&status=new&status=paid",
+		},
+		"Escaped values": {
+			Token:  "t/k",
+			Params: url.Values{"orderId": {"a%20b"}, "itemDesc": {"a b+c"}},
+			Result: "token=t%2Fk&itemDesc=a+b%2Bc&orderId=a%2520b",
+		},
+		"Sorted token": {
+			Token:    "tok",
+			Params:   url.Values{"a": {"1"}, "z": {"2"}},
+			Encoding: QueryEncoding{SortToken: true},
+			Result:   "a=1&token=tok&z=2",
+		},
+		"Percent spaces": {
+			Token:    "tok",
+			Params:   url.Values{"itemDesc": {"a b+c"}},
+			Encoding: QueryEncoding{PercentSpaces: true},
+			Result:   "token=tok&itemDesc=a%20b%2Bc",
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, c.Result, CanonicalQuery(c.Token, c.Params, c.Encoding))
+		})
+	}
+
+	params := url.Values{"token": {"old"}}
+	CanonicalQuery("tok", params, QueryEncoding{SortToken: true})
+	assert.Equal(t, url.Values{"token": {"old"}}, params)
+}
+
+func Test_SignRequest(t *testing.T) {
+	_, _, err := SignRequest("test", "http://test.com/invoices", "")
+	assert.Error(t, err)
+
+	pm, err := GeneratePEM()
+	require.NoError(t, err)
+
+	id, sig, err := SignRequest(pm, "http://test.com/invoices?token=tok", "")
+	require.NoError(t, err)
+
+	b, err := hex.DecodeString(id)
+	require.NoError(t, err)
+
+	pub, err := btcec.ParsePubKey(b)
+	require.NoError(t, err)
+
+	b, err = hex.DecodeString(sig)
+	require.NoError(t, err)
+
+	s, err := ecdsa.ParseDERSignature(b)
+	require.NoError(t, err)
+
+	hash := sha256.Sum256([]byte("http://test.com/invoices?token=tok"))
+	assert.True(t, s.Verify(hash[:], pub))
+}
+
+func Test_Client_sign(t *testing.T) {
+	pm, err := GeneratePEM()
+	require.NoError(t, err)
+
+	mt := httpmock.NewMockTransport()
+
+	var buf bytes.Buffer
+
+	client, err := NewClient("http://test.com", "tok", WithHTTPClient(&http.Client{Transport: mt}), WithPEM(pm), WithSignatureDebug(&buf))
+	require.NoError(t, err)
+
+	var id, sig string
+
+	mt.RegisterResponder(http.MethodGet, "http://test.com/invoices", func(r *http.Request) (*http.Response, error) {
+		id, sig = r.Header.Get("X-Identity"), r.Header.Get("X-Signature")
+		return httpmock.NewStringResponse(http.StatusOK, ""), nil
+	})
+
+	_, err = client.send(context.Background(), http.MethodGet, newRoute("/invoices"), url.Values{"status": {"new"}}, nil, true)
+	require.NoError(t, err)
+
+	// query-only requests are signed over the full URL
+	expID, expSig, err := SignRequest(pm, "http://test.com/invoices?token=tok&status=new", "")
+	require.NoError(t, err)
+	assert.Equal(t, expID, id)
+	assert.Equal(t, expSig, sig)
+
+	assert.Equal(t, "GET /invoices\n"+
+		"signed: http://test.com/invoices?token=[REDACTED]&status=new\n"+
+		"X-Identity: "+id+"\n"+
+		"X-Signature: "+sig+"\n\n", buf.String())
+}
+
+func Test_Client_sign_QueryEncoding(t *testing.T) {
+	pm, err := GeneratePEM()
+	require.NoError(t, err)
+
+	mt := httpmock.NewMockTransport()
+
+	client, err := NewClient("http://test.com", "tok", WithHTTPClient(&http.Client{Transport: mt}), WithPEM(pm),
+		WithQueryEncoding(QueryEncoding{SortToken: true, PercentSpaces: true}))
+	require.NoError(t, err)
+
+	var query, sig string
+
+	mt.RegisterResponder(http.MethodGet, "http://test.com/invoices", func(r *http.Request) (*http.Response, error) {
+		query, sig = r.URL.RawQuery, r.Header.Get("X-Signature")
+		return httpmock.NewStringResponse(http.StatusOK, ""), nil
+	})
+
+	_, err = client.send(context.Background(), http.MethodGet, newRoute("/invoices"), url.Values{"orderId": {"a b"}}, nil, true)
+	require.NoError(t, err)
+	assert.Equal(t, "orderId=a%20b&token=tok", query)
+
+	_, expSig, err := SignRequest(pm, "http://test.com/invoices?orderId=a%20b&token=tok", "")
+	require.NoError(t, err)
+	assert.Equal(t, expSig, sig)
+}
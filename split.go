@@ -0,0 +1,239 @@
+package btcpay
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Metadata fields used to link the invoices of a group.
+const (
+	metaGroupID   = "invoiceGroupId"
+	metaGroupPart = "invoiceGroupPart"
+	metaGroupSize = "invoiceGroupSize"
+)
+
+// splitRollbackTimeout is the time given to mark the invoices of a group
+// as invalid after one of its invoices could not be created.
+const splitRollbackTimeout = time.Second * 30
+
+// InvoicePart holds the parameters of a single invoice of a group.
+type InvoicePart struct {
+	StoreID  string
+	Amount   decimal.Decimal
+	Currency string
+	Checkout *InvoiceCheckout
+}
+
+// SplitInvoiceParams holds the parameters used to create a group of
+// linked invoices for a single order, e.g. when a part of the order
+// must be paid in a different currency or to a different store.
+type SplitInvoiceParams struct {
+	// GroupID identifies the group. A random ID is generated if it is
+	// empty.
+	GroupID string
+
+	// OrderID is set as the orderId of every invoice of the group.
+	OrderID string
+
+	// Metadata is shared by all invoices of the group.
+	Metadata map[string]interface{}
+
+	Parts []InvoicePart
+}
+
+// GroupStatus specifies the aggregated status of an invoice group.
+type GroupStatus string
+
+// Available invoice group statuses.
+const (
+	// GroupStatusPending means that no invoice of the group is settled
+	// and at least one of them can still be paid.
+	GroupStatusPending GroupStatus = "Pending"
+
+	// GroupStatusPartiallySettled means that some, but not all,
+	// invoices of the group are settled.
+	GroupStatusPartiallySettled GroupStatus = "PartiallySettled"
+
+	// GroupStatusSettled means that all invoices of the group are
+	// settled.
+	GroupStatusSettled GroupStatus = "Settled"
+
+	// GroupStatusFailed means that no invoice of the group is settled
+	// and none of them can be paid anymore.
+	GroupStatusFailed GroupStatus = "Failed"
+)
+
+// InvoiceGroup holds the invoices created for a single order.
+type InvoiceGroup struct {
+	ID       string
+	Invoices []StoreInvoice
+}
+
+// Status returns the aggregated status of the group's invoices.
+func (g InvoiceGroup) Status() GroupStatus {
+	var settled, failed int
+
+	for _, inv := range g.Invoices {
+		switch InvoiceStatus(inv.Status) {
+		case InvoiceStatusSettled:
+			settled++
+		case InvoiceStatusExpired, InvoiceStatusInvalid:
+			failed++
+		}
+	}
+
+	switch {
+	case len(g.Invoices) > 0 && settled == len(g.Invoices):
+		return GroupStatusSettled
+	case settled > 0:
+		return GroupStatusPartiallySettled
+	case len(g.Invoices) > 0 && failed == len(g.Invoices):
+		return GroupStatusFailed
+	default:
+		return GroupStatusPending
+	}
+}
+
+// SplitInvoiceError is returned when an invoice of a group cannot be
+// created.
+type SplitInvoiceError struct {
+	// Part is the index of the part whose invoice could not be created.
+	Part int
+
+	// Err is the error returned by the invoice creation.
+	Err error
+
+	// Rollback holds the errors returned while marking the previously
+	// created invoices as invalid.
+	Rollback []error
+}
+
+// Error returns the creation error followed by the rollback errors.
+func (e *SplitInvoiceError) Error() string {
+	s := fmt.Sprintf("part %d: %v", e.Part, e.Err)
+
+	if len(e.Rollback) > 0 {
+		msgs := make([]string, len(e.Rollback))
+		for i, err := range e.Rollback {
+			msgs[i] = err.Error()
+		}
+
+		s += "; rollback: " + strings.Join(msgs, "; ")
+	}
+
+	return s
+}
+
+// Unwrap returns the creation error.
+func (e *SplitInvoiceError) Unwrap() error {
+	return e.Err
+}
+
+// CreateSplitInvoices creates an invoice for every part of the params.
+// The invoices are linked by the group ID, which is added to their
+// metadata along with the part's index and the size of the group. If
+// an invoice cannot be created, the invoices created before it are
+// marked as invalid, so that the order cannot be paid partially. The
+// invoices are marked even if the context is already cancelled, and
+// the errors of the invoice creation and marking are returned as a
+// *SplitInvoiceError.
+func (c *Client) CreateSplitInvoices(ctx context.Context, p SplitInvoiceParams, opts ...RequestOption) (InvoiceGroup, error) {
+	if err := p.Validate(); err != nil {
+		return InvoiceGroup{}, err
+	}
+
+	g := InvoiceGroup{ID: p.GroupID}
+
+	if g.ID == "" {
+		b := make([]byte, 16)
+		if _, err := io.ReadFull(rand.Reader, b); err != nil {
+			return InvoiceGroup{}, err
+		}
+
+		g.ID = hex.EncodeToString(b)
+	}
+
+	for i, part := range p.Parts {
+		meta := make(map[string]interface{}, len(p.Metadata)+4)
+		for k, v := range p.Metadata {
+			meta[k] = v
+		}
+
+		if p.OrderID != "" {
+			meta["orderId"] = p.OrderID
+		}
+
+		meta[metaGroupID] = g.ID
+		meta[metaGroupPart] = i
+		meta[metaGroupSize] = len(p.Parts)
+
+		inv, err := c.CreateStoreInvoice(ctx, part.StoreID, StoreInvoiceParams{
+			Amount:   part.Amount,
+			Currency: part.Currency,
+			Metadata: meta,
+			Checkout: part.Checkout,
+		}, opts...)
+		if err != nil {
+			return InvoiceGroup{}, &SplitInvoiceError{
+				Part:     i,
+				Err:      err,
+				Rollback: c.rollbackInvoiceGroup(g, opts...),
+			}
+		}
+
+		g.Invoices = append(g.Invoices, inv)
+	}
+
+	return g, nil
+}
+
+// rollbackInvoiceGroup marks all invoices of the group as invalid. The
+// context of the group's creation is not used, since its cancellation
+// is a common reason of the rollback.
+func (c *Client) rollbackInvoiceGroup(g InvoiceGroup, opts ...RequestOption) []error {
+	ctx, cancel := context.WithTimeout(context.Background(), splitRollbackTimeout)
+	defer cancel()
+
+	var ee []error
+
+	for _, inv := range g.Invoices {
+		if _, err := c.MarkStoreInvoiceStatus(ctx, inv.StoreID, inv.ID, "invalid", opts...); err != nil {
+			ee = append(ee, fmt.Errorf("invoice %s: %w", inv.ID, err))
+		}
+	}
+
+	return ee
+}
+
+// RefreshInvoiceGroup retrieves the current state of every invoice of
+// the group, bypassing the response cache.
+func (c *Client) RefreshInvoiceGroup(ctx context.Context, g InvoiceGroup, opts ...RequestOption) (InvoiceGroup, error) {
+	res := InvoiceGroup{ID: g.ID, Invoices: make([]StoreInvoice, 0, len(g.Invoices))}
+
+	// cached invoices would make the aggregated status stale
+	opts = append([]RequestOption{WithoutCache()}, opts...)
+
+	for _, old := range g.Invoices {
+		inv, err := c.StoreInvoice(ctx, old.StoreID, old.ID, opts...)
+		if err != nil {
+			return InvoiceGroup{}, err
+		}
+
+		res.Invoices = append(res.Invoices, inv)
+	}
+
+	return res, nil
+}
+
+// InvoiceGroupID returns the ID of the invoice group the invoice belongs
+// to, or an empty string if it was not created as a part of a group.
+func (inv StoreInvoice) InvoiceGroupID() string {
+	return inv.metadataString(metaGroupID)
+}
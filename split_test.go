@@ -0,0 +1,250 @@
+package btcpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_InvoiceGroup_Status(t *testing.T) {
+	group := func(ss ...string) InvoiceGroup {
+		var g InvoiceGroup
+
+		for _, s := range ss {
+			g.Invoices = append(g.Invoices, StoreInvoice{Status: s})
+		}
+
+		return g
+	}
+
+	assert.Equal(t, GroupStatusPending, group().Status())
+	assert.Equal(t, GroupStatusPending, group("New", "Expired").Status())
+	assert.Equal(t, GroupStatusPending, group("Processing", "Processing").Status())
+	assert.Equal(t, GroupStatusPartiallySettled, group("Settled", "New").Status())
+	assert.Equal(t, GroupStatusPartiallySettled, group("Settled", "Expired").Status())
+	assert.Equal(t, GroupStatusSettled, group("Settled", "Settled").Status())
+	assert.Equal(t, GroupStatusFailed, group("Expired", "Invalid").Status())
+}
+
+func Test_StoreInvoice_InvoiceGroupID(t *testing.T) {
+	assert.Equal(t, "g1", StoreInvoice{Metadata: json.RawMessage(`{"invoiceGroupId":"g1"}`)}.InvoiceGroupID())
+	assert.Equal(t, "", StoreInvoice{}.InvoiceGroupID())
+}
+
+func Test_Client_CreateSplitInvoices(t *testing.T) {
+	params := SplitInvoiceParams{
+		GroupID:  "g1",
+		OrderID:  "o1",
+		Metadata: map[string]interface{}{"itemDesc": "bundle"},
+		Parts: []InvoicePart{
+			{StoreID: "s1", Amount: decimal.NewFromInt(10), Currency: "USD"},
+			{StoreID: "s2", Amount: decimal.NewFromInt(5), Currency: "EUR"},
+		},
+	}
+
+	create := func(storeID, id string) httpmock.Responder {
+		return func(r *http.Request) (*http.Response, error) {
+			b, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				return nil, err
+			}
+
+			var p struct {
+				Metadata map[string]interface{} `json:"metadata"`
+			}
+
+			if err = json.Unmarshal(b, &p); err != nil {
+				return nil, err
+			}
+
+			if p.Metadata["orderId"] != "o1" || p.Metadata["itemDesc"] != "bundle" ||
+				p.Metadata["invoiceGroupId"] != "g1" || p.Metadata["invoiceGroupSize"] != float64(2) {
+				return nil, errors.New("invalid metadata")
+			}
+
+			return httpmock.NewStringResponse(http.StatusOK, `{"id":"`+id+`","storeId":"`+storeID+`","status":"New"}`), nil
+		}
+	}
+
+	cc := map[string]struct {
+		Params      SplitInvoiceParams
+		SecondResp  httpmock.Responder
+		MarkResp    httpmock.Responder
+		Cancel      bool
+		Marked      int
+		Err         bool
+		RollbackErr bool
+		Result      InvoiceGroup
+	}{
+		"Invalid params": {
+			Err: true,
+		},
+		"Error returned by the second invoice creation": {
+			Params:     params,
+			SecondResp: httpmock.NewErrorResponder(assert.AnError),
+			Marked:     1,
+			Err:        true,
+		},
+		"Context cancelled during the second invoice creation": {
+			Params:     params,
+			SecondResp: httpmock.NewErrorResponder(context.Canceled),
+			Cancel:     true,
+			Marked:     1,
+			Err:        true,
+		},
+		"Error returned by the rollback": {
+			Params:      params,
+			SecondResp:  httpmock.NewErrorResponder(assert.AnError),
+			MarkResp:    httpmock.NewStringResponder(http.StatusInternalServerError, ""),
+			Marked:      1,
+			Err:         true,
+			RollbackErr: true,
+		},
+		"Successful creation": {
+			Params:     params,
+			SecondResp: create("s2", "i2"),
+			Result: InvoiceGroup{
+				ID: "g1",
+				Invoices: []StoreInvoice{
+					{ID: "i1", StoreID: "s1", Status: "New"},
+					{ID: "i2", StoreID: "s2", Status: "New"},
+				},
+			},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			second := c.SecondResp
+			if c.Cancel {
+				second = func(r *http.Request) (*http.Response, error) {
+					cancel()
+					return c.SecondResp(r)
+				}
+			}
+
+			mark := c.MarkResp
+			if mark == nil {
+				mark = httpmock.NewStringResponder(http.StatusOK, `{"id":"i1","storeId":"s1","status":"Invalid"}`)
+			}
+
+			mt := httpmock.NewMockTransport()
+			mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/stores/s1/invoices", create("s1", "i1"))
+			mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/stores/s2/invoices", second)
+			mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/stores/s1/invoices/i1/status", mark)
+
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			g, err := client.CreateSplitInvoices(ctx, c.Params)
+			assert.Equal(t, c.Marked, mt.GetCallCountInfo()["POST http://test.com/api/v1/stores/s1/invoices/i1/status"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, g)
+
+				var serr *SplitInvoiceError
+				if c.Params.Parts != nil {
+					require.True(t, errors.As(err, &serr))
+					assert.Equal(t, 1, serr.Part)
+					assert.Equal(t, c.RollbackErr, len(serr.Rollback) > 0)
+				}
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result.ID, g.ID)
+			require.Len(t, g.Invoices, len(c.Result.Invoices))
+
+			for i, inv := range g.Invoices {
+				assert.Equal(t, c.Result.Invoices[i].ID, inv.ID)
+				assert.Equal(t, c.Result.Invoices[i].StoreID, inv.StoreID)
+				assert.Equal(t, c.Result.Invoices[i].Status, inv.Status)
+			}
+		})
+	}
+}
+
+func Test_Client_CreateSplitInvoices_generatedID(t *testing.T) {
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/stores/s1/invoices",
+		httpmock.NewStringResponder(http.StatusOK, `{"id":"i1","storeId":"s1"}`))
+
+	client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+	require.NoError(t, err)
+
+	g, err := client.CreateSplitInvoices(context.Background(), SplitInvoiceParams{
+		Parts: []InvoicePart{{StoreID: "s1", Amount: decimal.NewFromInt(1)}},
+	})
+	require.NoError(t, err)
+	assert.Len(t, g.ID, 32)
+}
+
+func Test_Client_RefreshInvoiceGroup(t *testing.T) {
+	g := InvoiceGroup{
+		ID: "g1",
+		Invoices: []StoreInvoice{
+			{ID: "i1", StoreID: "s1", Status: "New"},
+			{ID: "i2", StoreID: "s2", Status: "New"},
+		},
+	}
+
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Err    bool
+		Status GroupStatus
+	}{
+		"Error returned by the server": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Successful refresh": {
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `{"id":"i2","storeId":"s2","status":"Settled"}`),
+			Status: GroupStatusPartiallySettled,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/stores/s1/invoices/i1",
+				httpmock.NewStringResponder(http.StatusOK, `{"id":"i1","storeId":"s1","status":"Processing"}`))
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/stores/s2/invoices/i2", c.Resp)
+
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			res, err := client.RefreshInvoiceGroup(context.Background(), g)
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "g1", res.ID)
+			assert.Equal(t, c.Status, res.Status())
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package btcpay
+
+import (
+	"context"
+	"time"
+)
+
+// StoreClient provides access to the resources of a single store, so
+// that the store's ID does not have to be passed to every call.
+type StoreClient struct {
+	c  *Client
+	id string
+}
+
+// ForStore returns a client of the specified store. The returned client
+// shares the connection, credentials and settings of the parent client.
+func (c *Client) ForStore(storeID string) *StoreClient {
+	return &StoreClient{
+		c:  c,
+		id: storeID,
+	}
+}
+
+// ID returns the ID of the store.
+func (sc *StoreClient) ID() string {
+	return sc.id
+}
+
+// Get retrieves the store.
+func (sc *StoreClient) Get(ctx context.Context, opts ...RequestOption) (Store, error) {
+	return sc.c.Store(ctx, sc.id, opts...)
+}
+
+// Update updates the store.
+func (sc *StoreClient) Update(ctx context.Context, p StoreParams, opts ...RequestOption) (Store, error) {
+	return sc.c.UpdateStore(ctx, sc.id, p, opts...)
+}
+
+// Remove removes the store.
+func (sc *StoreClient) Remove(ctx context.Context, opts ...RequestOption) error {
+	return sc.c.RemoveStore(ctx, sc.id, opts...)
+}
+
+// CreateInvoice creates a new invoice in the store.
+func (sc *StoreClient) CreateInvoice(ctx context.Context, p StoreInvoiceParams, opts ...RequestOption) (StoreInvoice, error) {
+	return sc.c.CreateStoreInvoice(ctx, sc.id, p, opts...)
+}
+
+// Invoices retrieves all invoices of the store.
+func (sc *StoreClient) Invoices(ctx context.Context, opts ...RequestOption) ([]StoreInvoice, error) {
+	return sc.c.StoreInvoices(ctx, sc.id, opts...)
+}
+
+// Invoice retrieves an invoice of the store by the provided ID.
+func (sc *StoreClient) Invoice(ctx context.Context, id string, opts ...RequestOption) (StoreInvoice, error) {
+	return sc.c.StoreInvoice(ctx, sc.id, id, opts...)
+}
+
+// ExpireStaleInvoices archives stale invoices of the store. New invoices
+// are marked as invalid first. See Client.ExpireStaleStoreInvoices.
+func (sc *StoreClient) ExpireStaleInvoices(ctx context.Context, olderThan time.Duration, statuses []InvoiceStatus, opts ...RequestOption) ([]StoreInvoice, error) {
+	return sc.c.ExpireStaleStoreInvoices(ctx, sc.id, olderThan, statuses, opts...)
+}
+
+// UpdateInvoice updates the metadata of the specified invoice of the
+// store.
+func (sc *StoreClient) UpdateInvoice(ctx context.Context, id string, p UpdateInvoiceParams, opts ...RequestOption) (StoreInvoice, error) {
+	return sc.c.UpdateStoreInvoice(ctx, sc.id, id, p, opts...)
+}
+
+// ArchiveInvoice archives the specified invoice of the store.
+func (sc *StoreClient) ArchiveInvoice(ctx context.Context, id string, opts ...RequestOption) error {
+	return sc.c.ArchiveStoreInvoice(ctx, sc.id, id, opts...)
+}
+
+// UnarchiveInvoice restores the specified archived invoice of the store.
+func (sc *StoreClient) UnarchiveInvoice(ctx context.Context, id string, opts ...RequestOption) (StoreInvoice, error) {
+	return sc.c.UnarchiveStoreInvoice(ctx, sc.id, id, opts...)
+}
+
+// MarkInvoiceStatus manually marks the specified invoice of the store
+// as invalid or complete.
+func (sc *StoreClient) MarkInvoiceStatus(ctx context.Context, id, status string, opts ...RequestOption) (StoreInvoice, error) {
+	return sc.c.MarkStoreInvoiceStatus(ctx, sc.id, id, status, opts...)
+}
+
+// RefundInvoice creates a pull payment that refunds the specified
+// invoice of the store.
+func (sc *StoreClient) RefundInvoice(ctx context.Context, id string, p RefundInvoiceParams, opts ...RequestOption) (PullPayment, error) {
+	return sc.c.RefundStoreInvoice(ctx, sc.id, id, p, opts...)
+}
+
+// InvoicePaymentMethods retrieves payment details of all payment methods
+// of the specified invoice of the store.
+func (sc *StoreClient) InvoicePaymentMethods(ctx context.Context, id string, opts ...RequestOption) ([]InvoicePaymentMethod, error) {
+	return sc.c.StoreInvoicePaymentMethods(ctx, sc.id, id, opts...)
+}
+
+// ActivateInvoicePaymentMethod activates the payment method on the
+// specified invoice of the store.
+func (sc *StoreClient) ActivateInvoicePaymentMethod(ctx context.Context, id, paymentMethod string, opts ...RequestOption) error {
+	return sc.c.ActivateStoreInvoicePaymentMethod(ctx, sc.id, id, paymentMethod, opts...)
+}
+
+// SendInvoiceReceipt emails the buyer a link to the receipt page of
+// the specified settled invoice of the store.
+func (sc *StoreClient) SendInvoiceReceipt(ctx context.Context, id string, p ReceiptParams, opts ...RequestOption) error {
+	return sc.c.SendInvoiceReceipt(ctx, sc.id, id, p, opts...)
+}
+
+// ReconcileInvoices produces a reconciliation report of the store's
+// invoices. See Client.ReconcileInvoices.
+func (sc *StoreClient) ReconcileInvoices(ctx context.Context, p ReconcileParams, opts ...RequestOption) (ReconciliationReport, error) {
+	p.StoreID = sc.id
+	return sc.c.ReconcileInvoices(ctx, p, opts...)
+}
+
+// CreatePaymentRequest creates a new payment request in the store.
+func (sc *StoreClient) CreatePaymentRequest(ctx context.Context, p PaymentRequestParams, opts ...RequestOption) (PaymentRequest, error) {
+	return sc.c.CreatePaymentRequest(ctx, sc.id, p, opts...)
+}
+
+// PaymentRequests retrieves all payment requests of the store.
+func (sc *StoreClient) PaymentRequests(ctx context.Context, opts ...RequestOption) ([]PaymentRequest, error) {
+	return sc.c.PaymentRequests(ctx, sc.id, opts...)
+}
+
+// PaymentRequest retrieves a payment request of the store by the
+// provided ID.
+func (sc *StoreClient) PaymentRequest(ctx context.Context, id string, opts ...RequestOption) (PaymentRequest, error) {
+	return sc.c.PaymentRequest(ctx, sc.id, id, opts...)
+}
+
+// UpdatePaymentRequest updates the specified payment request of the
+// store.
+func (sc *StoreClient) UpdatePaymentRequest(ctx context.Context, id string, p PaymentRequestParams, opts ...RequestOption) (PaymentRequest, error) {
+	return sc.c.UpdatePaymentRequest(ctx, sc.id, id, p, opts...)
+}
+
+// ArchivePaymentRequest archives the specified payment request of the
+// store.
+func (sc *StoreClient) ArchivePaymentRequest(ctx context.Context, id string, opts ...RequestOption) error {
+	return sc.c.ArchivePaymentRequest(ctx, sc.id, id, opts...)
+}
+
+// CreatePullPayment creates a new pull payment in the store.
+func (sc *StoreClient) CreatePullPayment(ctx context.Context, p CreatePullPaymentParams, opts ...RequestOption) (PullPayment, error) {
+	return sc.c.CreatePullPayment(ctx, sc.id, p, opts...)
+}
+
+// PullPayments retrieves pull payments of the store.
+func (sc *StoreClient) PullPayments(ctx context.Context, includeArchived bool, opts ...RequestOption) ([]PullPayment, error) {
+	return sc.c.PullPayments(ctx, sc.id, includeArchived, opts...)
+}
+
+// ArchivePullPayment archives the specified pull payment of the store.
+func (sc *StoreClient) ArchivePullPayment(ctx context.Context, id string, opts ...RequestOption) error {
+	return sc.c.ArchivePullPayment(ctx, sc.id, id, opts...)
+}
+
+// ApprovePayout approves the specified payout of the store.
+func (sc *StoreClient) ApprovePayout(ctx context.Context, id string, p ApprovePayoutParams, opts ...RequestOption) (Payout, error) {
+	return sc.c.ApprovePayout(ctx, sc.id, id, p, opts...)
+}
+
+// CancelPayout cancels the specified payout of the store.
+func (sc *StoreClient) CancelPayout(ctx context.Context, id string, opts ...RequestOption) error {
+	return sc.c.CancelPayout(ctx, sc.id, id, opts...)
+}
+
+// PaymentMethods retrieves payment methods configured for the store.
+func (sc *StoreClient) PaymentMethods(ctx context.Context, enabledOnly bool, opts ...RequestOption) (map[string]PaymentMethod, error) {
+	return sc.c.PaymentMethods(ctx, sc.id, enabledOnly, opts...)
+}
+
+// Apps retrieves all apps of the store.
+func (sc *StoreClient) Apps(ctx context.Context, opts ...RequestOption) ([]App, error) {
+	return sc.c.Apps(ctx, sc.id, opts...)
+}
+
+// EmailSettings retrieves SMTP settings of the store.
+func (sc *StoreClient) EmailSettings(ctx context.Context, opts ...RequestOption) (EmailSettings, error) {
+	return sc.c.StoreEmailSettings(ctx, sc.id, opts...)
+}
+
+// UpdateEmailSettings updates SMTP settings of the store.
+func (sc *StoreClient) UpdateEmailSettings(ctx context.Context, s EmailSettings, opts ...RequestOption) (EmailSettings, error) {
+	return sc.c.UpdateStoreEmailSettings(ctx, sc.id, s, opts...)
+}
+
+// SendEmail sends an email using SMTP settings of the store.
+func (sc *StoreClient) SendEmail(ctx context.Context, p EmailParams, opts ...RequestOption) error {
+	return sc.c.SendStoreEmail(ctx, sc.id, p, opts...)
+}
+
+// Wallet returns a client of the store's on-chain wallet of the
+// specified cryptocurrency.
+func (sc *StoreClient) Wallet(cryptoCode string) *WalletClient {
+	return sc.c.Wallet(sc.id, cryptoCode)
+}
+
+// Lightning returns a client of the Lightning node configured for the
+// store and the specified cryptocurrency.
+func (sc *StoreClient) Lightning(cryptoCode string) *LightningClient {
+	return sc.c.StoreLightning(sc.id, cryptoCode)
+}
+
+// CreateWebhook creates a new webhook in the store.
+func (sc *StoreClient) CreateWebhook(ctx context.Context, p WebhookParams, opts ...RequestOption) (Webhook, error) {
+	return sc.c.CreateWebhook(ctx, sc.id, p, opts...)
+}
+
+// Webhooks retrieves all webhooks of the store.
+func (sc *StoreClient) Webhooks(ctx context.Context, opts ...RequestOption) ([]Webhook, error) {
+	return sc.c.Webhooks(ctx, sc.id, opts...)
+}
+
+// Webhook retrieves a webhook of the store by the provided ID.
+func (sc *StoreClient) Webhook(ctx context.Context, id string, opts ...RequestOption) (Webhook, error) {
+	return sc.c.Webhook(ctx, sc.id, id, opts...)
+}
+
+// UpdateWebhook updates the specified webhook of the store.
+func (sc *StoreClient) UpdateWebhook(ctx context.Context, id string, p WebhookParams, opts ...RequestOption) (Webhook, error) {
+	return sc.c.UpdateWebhook(ctx, sc.id, id, p, opts...)
+}
+
+// DeleteWebhook removes the specified webhook of the store.
+func (sc *StoreClient) DeleteWebhook(ctx context.Context, id string, opts ...RequestOption) error {
+	return sc.c.DeleteWebhook(ctx, sc.id, id, opts...)
+}
+
+// WebhookDeliveries retrieves the latest deliveries of the specified
+// webhook of the store.
+func (sc *StoreClient) WebhookDeliveries(ctx context.Context, id string, count int, opts ...RequestOption) ([]WebhookDelivery, error) {
+	return sc.c.WebhookDeliveries(ctx, sc.id, id, count, opts...)
+}
+
+// RedeliverWebhook sends the event of the specified delivery to the
+// webhook of the store again.
+func (sc *StoreClient) RedeliverWebhook(ctx context.Context, id, deliveryID string, opts ...RequestOption) (string, error) {
+	return sc.c.RedeliverWebhook(ctx, sc.id, id, deliveryID, opts...)
+}
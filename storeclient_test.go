@@ -0,0 +1,257 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_ForStore(t *testing.T) {
+	client, err := NewClient("http://test.com", "")
+	require.NoError(t, err)
+
+	sc := client.ForStore("s1")
+	assert.Equal(t, client, sc.c)
+	assert.Equal(t, "s1", sc.ID())
+	assert.Equal(t, "/api/v1/stores/s1/payment-methods/onchain/BTC/wallet", sc.Wallet("BTC").endpoint.path)
+	assert.Equal(t, "/api/v1/stores/s1/lightning/BTC", sc.Lightning("BTC").endpoint.path)
+}
+
+func Test_StoreClient(t *testing.T) {
+	cc := map[string]struct {
+		Method   string
+		Endpoint string
+		Body     string
+		Call     func(sc *StoreClient) error
+	}{
+		"Get": {
+			Method:   http.MethodGet,
+			Endpoint: "/api/v1/stores/s1",
+			Call: func(sc *StoreClient) error {
+				_, err := sc.Get(context.Background())
+				return err
+			},
+		},
+		"Update": {
+			Method:   http.MethodPut,
+			Endpoint: "/api/v1/stores/s1",
+			Call: func(sc *StoreClient) error {
+				_, err := sc.Update(context.Background(), StoreParams{Name: "n1"})
+				return err
+			},
+		},
+		"Remove": {
+			Method:   http.MethodDelete,
+			Endpoint: "/api/v1/stores/s1",
+			Call: func(sc *StoreClient) error {
+				return sc.Remove(context.Background())
+			},
+		},
+		"CreateInvoice": {
+			Method:   http.MethodPost,
+			Endpoint: "/api/v1/stores/s1/invoices",
+			Call: func(sc *StoreClient) error {
+				_, err := sc.CreateInvoice(context.Background(), StoreInvoiceParams{})
+				return err
+			},
+		},
+		"Invoice": {
+			Method:   http.MethodGet,
+			Endpoint: "/api/v1/stores/s1/invoices/i1",
+			Call: func(sc *StoreClient) error {
+				_, err := sc.Invoice(context.Background(), "i1")
+				return err
+			},
+		},
+		"ExpireStaleInvoices": {
+			Method:   http.MethodGet,
+			Endpoint: "/api/v1/stores/s1/invoices",
+			Body:     "[]",
+			Call: func(sc *StoreClient) error {
+				_, err := sc.ExpireStaleInvoices(context.Background(), time.Hour, nil)
+				return err
+			},
+		},
+		"UpdateInvoice": {
+			Method:   http.MethodPut,
+			Endpoint: "/api/v1/stores/s1/invoices/i1",
+			Call: func(sc *StoreClient) error {
+				_, err := sc.UpdateInvoice(context.Background(), "i1", UpdateInvoiceParams{OrderID: "o1"})
+				return err
+			},
+		},
+		"ArchiveInvoice": {
+			Method:   http.MethodDelete,
+			Endpoint: "/api/v1/stores/s1/invoices/i1",
+			Call: func(sc *StoreClient) error {
+				return sc.ArchiveInvoice(context.Background(), "i1")
+			},
+		},
+		"UnarchiveInvoice": {
+			Method:   http.MethodPost,
+			Endpoint: "/api/v1/stores/s1/invoices/i1/unarchive",
+			Call: func(sc *StoreClient) error {
+				_, err := sc.UnarchiveInvoice(context.Background(), "i1")
+				return err
+			},
+		},
+		"MarkInvoiceStatus": {
+			Method:   http.MethodPost,
+			Endpoint: "/api/v1/stores/s1/invoices/i1/status",
+			Call: func(sc *StoreClient) error {
+				_, err := sc.MarkInvoiceStatus(context.Background(), "i1", "invalid")
+				return err
+			},
+		},
+		"RefundInvoice": {
+			Method:   http.MethodPost,
+			Endpoint: "/api/v1/stores/s1/invoices/i1/refund",
+			Call: func(sc *StoreClient) error {
+				_, err := sc.RefundInvoice(context.Background(), "i1", RefundInvoiceParams{PaymentMethod: "BTC"})
+				return err
+			},
+		},
+		"InvoicePaymentMethods": {
+			Method:   http.MethodGet,
+			Endpoint: "/api/v1/stores/s1/invoices/i1/payment-methods",
+			Body:     "[]",
+			Call: func(sc *StoreClient) error {
+				_, err := sc.InvoicePaymentMethods(context.Background(), "i1")
+				return err
+			},
+		},
+		"ActivateInvoicePaymentMethod": {
+			Method:   http.MethodPost,
+			Endpoint: "/api/v1/stores/s1/invoices/i1/payment-methods/BTC/activate",
+			Call: func(sc *StoreClient) error {
+				return sc.ActivateInvoicePaymentMethod(context.Background(), "i1", "BTC")
+			},
+		},
+		"ReconcileInvoices": {
+			Method:   http.MethodGet,
+			Endpoint: "/api/v1/stores/s1/invoices",
+			Body:     "[]",
+			Call: func(sc *StoreClient) error {
+				_, err := sc.ReconcileInvoices(context.Background(), ReconcileParams{})
+				return err
+			},
+		},
+		"PaymentRequest": {
+			Method:   http.MethodGet,
+			Endpoint: "/api/v1/stores/s1/payment-requests/pr1",
+			Call: func(sc *StoreClient) error {
+				_, err := sc.PaymentRequest(context.Background(), "pr1")
+				return err
+			},
+		},
+		"CancelPayout": {
+			Method:   http.MethodDelete,
+			Endpoint: "/api/v1/stores/s1/payouts/p1",
+			Call: func(sc *StoreClient) error {
+				return sc.CancelPayout(context.Background(), "p1")
+			},
+		},
+		"SendEmail": {
+			Method:   http.MethodPost,
+			Endpoint: "/api/v1/stores/s1/email/send",
+			Call: func(sc *StoreClient) error {
+				return sc.SendEmail(context.Background(), EmailParams{Email: "u1@test.com"})
+			},
+		},
+		"Apps": {
+			Method:   http.MethodGet,
+			Endpoint: "/api/v1/stores/s1/apps",
+			Body:     "[]",
+			Call: func(sc *StoreClient) error {
+				_, err := sc.Apps(context.Background())
+				return err
+			},
+		},
+		"CreateWebhook": {
+			Method:   http.MethodPost,
+			Endpoint: "/api/v1/stores/s1/webhooks",
+			Call: func(sc *StoreClient) error {
+				_, err := sc.CreateWebhook(context.Background(), WebhookParams{})
+				return err
+			},
+		},
+		"Webhooks": {
+			Method:   http.MethodGet,
+			Endpoint: "/api/v1/stores/s1/webhooks",
+			Body:     "[]",
+			Call: func(sc *StoreClient) error {
+				_, err := sc.Webhooks(context.Background())
+				return err
+			},
+		},
+		"Webhook": {
+			Method:   http.MethodGet,
+			Endpoint: "/api/v1/stores/s1/webhooks/w1",
+			Call: func(sc *StoreClient) error {
+				_, err := sc.Webhook(context.Background(), "w1")
+				return err
+			},
+		},
+		"UpdateWebhook": {
+			Method:   http.MethodPut,
+			Endpoint: "/api/v1/stores/s1/webhooks/w1",
+			Call: func(sc *StoreClient) error {
+				_, err := sc.UpdateWebhook(context.Background(), "w1", WebhookParams{})
+				return err
+			},
+		},
+		"DeleteWebhook": {
+			Method:   http.MethodDelete,
+			Endpoint: "/api/v1/stores/s1/webhooks/w1",
+			Call: func(sc *StoreClient) error {
+				return sc.DeleteWebhook(context.Background(), "w1")
+			},
+		},
+		"WebhookDeliveries": {
+			Method:   http.MethodGet,
+			Endpoint: "/api/v1/stores/s1/webhooks/w1/deliveries",
+			Body:     "[]",
+			Call: func(sc *StoreClient) error {
+				_, err := sc.WebhookDeliveries(context.Background(), "w1", 0)
+				return err
+			},
+		},
+		"RedeliverWebhook": {
+			Method:   http.MethodPost,
+			Endpoint: "/api/v1/stores/s1/webhooks/w1/deliveries/d1/redeliver",
+			Body:     `"d2"`,
+			Call: func(sc *StoreClient) error {
+				_, err := sc.RedeliverWebhook(context.Background(), "w1", "d1")
+				return err
+			},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			if c.Body == "" {
+				c.Body = "{}"
+			}
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(c.Method, "http://test.com"+c.Endpoint, httpmock.NewStringResponder(http.StatusOK, c.Body))
+
+			err = c.Call(client.ForStore("s1"))
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[c.Method+" http://test.com"+c.Endpoint])
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,389 @@
+package btcpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// StoreInvoiceParams holds data used to create a new invoice through the
+// Greenfield API.
+// More at: https://docs.btcpayserver.org/API/Greenfield/v1/#tag/Invoices
+type StoreInvoiceParams struct {
+	Amount   decimal.Decimal        `json:"amount"`
+	Currency string                 `json:"currency,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	Checkout *InvoiceCheckout       `json:"checkout,omitempty"`
+}
+
+// InvoiceCheckout holds checkout settings of a Greenfield invoice.
+// Unset fields fall back to the store's settings.
+type InvoiceCheckout struct {
+	SpeedPolicy           SpeedPolicy      `json:"speedPolicy,omitempty"`
+	PaymentMethods        []string         `json:"paymentMethods,omitempty"`
+	DefaultPaymentMethod  string           `json:"defaultPaymentMethod,omitempty"`
+	LazyPaymentMethods    *bool            `json:"lazyPaymentMethods,omitempty"`
+	ExpirationMinutes     int64            `json:"expirationMinutes,omitempty"`
+	MonitoringMinutes     int64            `json:"monitoringMinutes,omitempty"`
+	PaymentTolerance      *decimal.Decimal `json:"paymentTolerance,omitempty"`
+	RedirectURL           string           `json:"redirectURL,omitempty"`
+	RedirectAutomatically *bool            `json:"redirectAutomatically,omitempty"`
+	RequiresRefundEmail   *bool            `json:"requiresRefundEmail,omitempty"`
+	DefaultLanguage       string           `json:"defaultLanguage,omitempty"`
+}
+
+// SpeedPolicy specifies how many confirmations a Greenfield invoice's
+// payment needs before the invoice is considered settled. It is the
+// Greenfield counterpart of TransactionSpeed.
+type SpeedPolicy string
+
+// Available speed policies.
+const (
+	// SpeedPolicyHigh requires 0 confirmations.
+	SpeedPolicyHigh SpeedPolicy = "HighSpeed"
+
+	// SpeedPolicyMedium requires 1 confirmation.
+	SpeedPolicyMedium SpeedPolicy = "MediumSpeed"
+
+	// SpeedPolicyLowMedium requires 2 confirmations.
+	SpeedPolicyLowMedium SpeedPolicy = "LowMediumSpeed"
+
+	// SpeedPolicyLow requires 6 confirmations.
+	SpeedPolicyLow SpeedPolicy = "LowSpeed"
+)
+
+// Valid checks whether the speed policy is one of the known values.
+// An empty speed policy is valid.
+func (s SpeedPolicy) Valid() bool {
+	switch s {
+	case "", SpeedPolicyHigh, SpeedPolicyMedium, SpeedPolicyLowMedium, SpeedPolicyLow:
+		return true
+	default:
+		return false
+	}
+}
+
+// StoreInvoice holds data of an invoice retrieved through the Greenfield
+// API.
+type StoreInvoice struct {
+	ID                   string          `json:"id"`
+	StoreID              string          `json:"storeId"`
+	Amount               decimal.Decimal `json:"amount"`
+	Currency             string          `json:"currency"`
+	Type                 string          `json:"type"`
+	CheckoutLink         string          `json:"checkoutLink"`
+	Status               string          `json:"status"`
+	AdditionalStatus     string          `json:"additionalStatus"`
+	CreatedTime          int64           `json:"createdTime"`
+	ExpirationTime       int64           `json:"expirationTime"`
+	MonitoringExpiration int64           `json:"monitoringExpiration"`
+	Archived             bool            `json:"archived"`
+	Metadata             json.RawMessage `json:"metadata"`
+	Checkout             InvoiceCheckout `json:"checkout"`
+}
+
+// CreateStoreInvoice creates a new invoice in the specified store.
+// ErrUnsupportedByServer is returned if the server has no Greenfield
+// API or, when they are requested, no lazy payment methods.
+func (c *Client) CreateStoreInvoice(ctx context.Context, storeID string, p StoreInvoiceParams, opts ...RequestOption) (StoreInvoice, error) {
+	p = c.withStoreCallbacks(p)
+
+	if p.Checkout != nil && p.Checkout.LazyPaymentMethods != nil && *p.Checkout.LazyPaymentMethods {
+		// older servers ignore the setting silently
+		if caps, err := c.Capabilities(ctx); err == nil && !caps.LazyPaymentMethods {
+			return StoreInvoice{}, fmt.Errorf("lazy payment methods: %w", ErrUnsupportedByServer)
+		}
+	}
+
+	resp, err := c.sendAPI(ctx, http.MethodPost, newRoute("/api/v1/stores/:id/invoices", storeID), nil, p, opts...)
+	if err != nil {
+		return StoreInvoice{}, c.unsupported(ctx, err, "greenfield invoices", func(caps Capabilities) bool {
+			return caps.Greenfield
+		})
+	}
+
+	defer resp.Body.Close()
+
+	var inv StoreInvoice
+
+	if err = c.decode(resp.Body, &inv); err != nil {
+		return StoreInvoice{}, err
+	}
+
+	return inv, nil
+}
+
+// StoreInvoices retrieves all invoices of the specified store.
+func (c *Client) StoreInvoices(ctx context.Context, storeID string, opts ...RequestOption) ([]StoreInvoice, error) {
+	resp, err := c.sendAPI(ctx, http.MethodGet, newRoute("/api/v1/stores/:id/invoices", storeID), nil, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var invs []StoreInvoice
+
+	if err = c.decode(resp.Body, &invs); err != nil {
+		return nil, err
+	}
+
+	return invs, nil
+}
+
+// StoreInvoice retrieves an invoice of the specified store by the
+// provided ID.
+func (c *Client) StoreInvoice(ctx context.Context, storeID, id string, opts ...RequestOption) (StoreInvoice, error) {
+	resp, err := c.sendAPI(ctx, http.MethodGet, newRoute("/api/v1/stores/:id/invoices/:id", storeID, id), nil, nil, append([]RequestOption{cacheable()}, opts...)...)
+	if err != nil {
+		return StoreInvoice{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var inv StoreInvoice
+
+	if err = c.decode(resp.Body, &inv); err != nil {
+		return StoreInvoice{}, err
+	}
+
+	return inv, nil
+}
+
+// ArchiveStoreInvoice archives the specified invoice of the store.
+func (c *Client) ArchiveStoreInvoice(ctx context.Context, storeID, id string, opts ...RequestOption) error {
+	resp, err := c.sendAPI(ctx, http.MethodDelete, newRoute("/api/v1/stores/:id/invoices/:id", storeID, id), nil, nil, opts...)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// UnarchiveStoreInvoice restores the specified archived invoice of the
+// store.
+func (c *Client) UnarchiveStoreInvoice(ctx context.Context, storeID, id string, opts ...RequestOption) (StoreInvoice, error) {
+	resp, err := c.sendAPI(ctx, http.MethodPost, newRoute("/api/v1/stores/:id/invoices/:id/unarchive", storeID, id), nil, nil, opts...)
+	if err != nil {
+		return StoreInvoice{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var inv StoreInvoice
+
+	if err = c.decode(resp.Body, &inv); err != nil {
+		return StoreInvoice{}, err
+	}
+
+	return inv, nil
+}
+
+// MarkStoreInvoiceStatus manually marks the specified invoice of the
+// store as invalid or complete, e.g. to accept an underpaid invoice.
+// Both Greenfield (Invalid, Settled) and legacy (invalid, complete)
+// status names are accepted.
+func (c *Client) MarkStoreInvoiceStatus(ctx context.Context, storeID, id, status string, opts ...RequestOption) (StoreInvoice, error) {
+	switch strings.ToLower(status) {
+	case "invalid":
+		status = "Invalid"
+	case "complete", "settled":
+		status = "Settled"
+	default:
+		return StoreInvoice{}, errors.New("status must be invalid or complete")
+	}
+
+	data := struct {
+		Status string `json:"status"`
+	}{
+		Status: status,
+	}
+
+	resp, err := c.sendAPI(ctx, http.MethodPost, newRoute("/api/v1/stores/:id/invoices/:id/status", storeID, id), nil, data, opts...)
+	if err != nil {
+		return StoreInvoice{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var inv StoreInvoice
+
+	if err = c.decode(resp.Body, &inv); err != nil {
+		return StoreInvoice{}, err
+	}
+
+	return inv, nil
+}
+
+// InvoicePaymentMethod holds payment details of a single payment method
+// of an invoice. Amounts are specified in the payment method's
+// cryptocurrency.
+type InvoicePaymentMethod struct {
+	PaymentMethod     string           `json:"paymentMethod"`
+	CryptoCode        string           `json:"cryptoCode"`
+	Destination       string           `json:"destination"`
+	PaymentLink       string           `json:"paymentLink"`
+	Rate              decimal.Decimal  `json:"rate"`
+	PaymentMethodPaid decimal.Decimal  `json:"paymentMethodPaid"`
+	TotalPaid         decimal.Decimal  `json:"totalPaid"`
+	Due               decimal.Decimal  `json:"due"`
+	Amount            decimal.Decimal  `json:"amount"`
+	NetworkFee        decimal.Decimal  `json:"networkFee"`
+	Activated         bool             `json:"activated"`
+	Payments          []InvoicePayment `json:"payments"`
+}
+
+// TxID returns the transaction ID of an on-chain payment. Payment IDs
+// consist of the transaction ID and output index (txid-vout).
+func (p InvoicePayment) TxID() string {
+	if i := strings.LastIndexByte(p.ID, '-'); i >= 0 {
+		return p.ID[:i]
+	}
+
+	return p.ID
+}
+
+// StoreInvoicePaymentMethods retrieves payment details of all payment
+// methods of the specified invoice of the store.
+func (c *Client) StoreInvoicePaymentMethods(ctx context.Context, storeID, id string, opts ...RequestOption) ([]InvoicePaymentMethod, error) {
+	resp, err := c.sendAPI(ctx, http.MethodGet, newRoute("/api/v1/stores/:id/invoices/:id/payment-methods", storeID, id), nil, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var pms []InvoicePaymentMethod
+
+	if err = c.decode(resp.Body, &pms); err != nil {
+		return nil, err
+	}
+
+	return pms, nil
+}
+
+// ActivateStoreInvoicePaymentMethod activates the payment method, e.g.
+// BTC or BTC-LightningNetwork, on the specified invoice of the store.
+// This is needed only if the store activates payment methods lazily.
+func (c *Client) ActivateStoreInvoicePaymentMethod(ctx context.Context, storeID, id, paymentMethod string, opts ...RequestOption) error {
+	resp, err := c.sendAPI(ctx, http.MethodPost, newRoute("/api/v1/stores/:id/invoices/:id/payment-methods/:id/activate", storeID, id, paymentMethod), nil, nil, opts...)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// UpdateInvoiceParams holds data used to update an invoice. The metadata
+// of the invoice is replaced, so fields that should be kept must be
+// included as well.
+type UpdateInvoiceParams struct {
+	Metadata map[string]interface{}
+
+	// OrderID and ItemDesc are set as the orderId and itemDesc metadata
+	// fields, overriding the values in Metadata.
+	OrderID  string
+	ItemDesc string
+}
+
+// MarshalJSON encodes the params with the order ID and item description
+// moved into the metadata.
+func (p UpdateInvoiceParams) MarshalJSON() ([]byte, error) {
+	md := make(map[string]interface{}, len(p.Metadata)+2)
+	for k, v := range p.Metadata {
+		md[k] = v
+	}
+
+	if p.OrderID != "" {
+		md["orderId"] = p.OrderID
+	}
+
+	if p.ItemDesc != "" {
+		md["itemDesc"] = p.ItemDesc
+	}
+
+	return json.Marshal(struct {
+		Metadata map[string]interface{} `json:"metadata"`
+	}{md})
+}
+
+// UpdateStoreInvoice updates the metadata of the specified invoice of
+// the store.
+func (c *Client) UpdateStoreInvoice(ctx context.Context, storeID, id string, p UpdateInvoiceParams, opts ...RequestOption) (StoreInvoice, error) {
+	resp, err := c.sendAPI(ctx, http.MethodPut, newRoute("/api/v1/stores/:id/invoices/:id", storeID, id), nil, p, opts...)
+	if err != nil {
+		return StoreInvoice{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var inv StoreInvoice
+
+	if err = c.decode(resp.Body, &inv); err != nil {
+		return StoreInvoice{}, err
+	}
+
+	return inv, nil
+}
+
+// RefundVariant specifies how the amount of an invoice refund is
+// calculated.
+type RefundVariant string
+
+// Available refund variants.
+const (
+	// RefundRateThen refunds the paid cryptocurrency amount.
+	RefundRateThen RefundVariant = "RateThen"
+
+	// RefundCurrentRate refunds the invoice amount converted at the
+	// current rate.
+	RefundCurrentRate RefundVariant = "CurrentRate"
+
+	// RefundFiat refunds the invoice amount in its currency.
+	RefundFiat RefundVariant = "Fiat"
+
+	// RefundOverpaidAmount refunds only the overpaid amount.
+	RefundOverpaidAmount RefundVariant = "OverpaidAmount"
+
+	// RefundCustom refunds the custom amount and currency.
+	RefundCustom RefundVariant = "Custom"
+)
+
+// RefundInvoiceParams holds data used to refund an invoice.
+type RefundInvoiceParams struct {
+	Name               string           `json:"name,omitempty"`
+	Description        string           `json:"description,omitempty"`
+	PaymentMethod      string           `json:"paymentMethod"`
+	RefundVariant      RefundVariant    `json:"refundVariant"`
+	SubtractPercentage *decimal.Decimal `json:"subtractPercentage,omitempty"`
+	CustomAmount       *decimal.Decimal `json:"customAmount,omitempty"`
+	CustomCurrency     string           `json:"customCurrency,omitempty"`
+}
+
+// RefundStoreInvoice creates a pull payment that refunds the specified
+// invoice of the store. The buyer claims the refund through the pull
+// payment's view link. ErrUnsupportedByServer is returned if the
+// server is too old to refund invoices.
+func (c *Client) RefundStoreInvoice(ctx context.Context, storeID, id string, p RefundInvoiceParams, opts ...RequestOption) (PullPayment, error) {
+	resp, err := c.sendAPI(ctx, http.MethodPost, newRoute("/api/v1/stores/:id/invoices/:id/refund", storeID, id), nil, p, opts...)
+	if err != nil {
+		return PullPayment{}, c.unsupported(ctx, err, "invoice refunds", func(caps Capabilities) bool {
+			return caps.Refunds
+		})
+	}
+
+	defer resp.Body.Close()
+
+	var pp PullPayment
+
+	if err = c.decode(resp.Body, &pp); err != nil {
+		return PullPayment{}, err
+	}
+
+	return pp, nil
+}
@@ -0,0 +1,621 @@
+package btcpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_CreateStoreInvoice(t *testing.T) {
+	check := func(r *http.Request) error {
+		var p map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			return err
+		}
+
+		if p["amount"] != "10" || p["currency"] != "USD" {
+			return errors.New("invalid payload")
+		}
+
+		ch, ok := p["checkout"].(map[string]interface{})
+		if !ok || ch["speedPolicy"] != "HighSpeed" || ch["paymentTolerance"] != "1.5" ||
+			ch["redirectAutomatically"] != false || ch["expirationMinutes"] != float64(30) {
+			return errors.New("invalid checkout payload")
+		}
+
+		if _, ok = ch["monitoringMinutes"]; ok {
+			return errors.New("unset checkout fields sent")
+		}
+
+		return nil
+	}
+
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result StoreInvoice
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, "{"), nil
+			},
+			Err: true,
+		},
+		"Successful execution": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"id":"i1","storeId":"s1","amount":"10","currency":"USD","status":"New"}`), nil
+			},
+			Result: StoreInvoice{ID: "i1", StoreID: "s1", Amount: decimal.NewFromInt(10), Currency: "USD", Status: "New"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/stores/s1/invoices", c.Resp)
+
+			tolerance := decimal.RequireFromString("1.5")
+			redirect := false
+
+			res, err := client.CreateStoreInvoice(context.Background(), "s1", StoreInvoiceParams{
+				Amount:   decimal.NewFromInt(10),
+				Currency: "USD",
+				Checkout: &InvoiceCheckout{
+					SpeedPolicy:           SpeedPolicyHigh,
+					ExpirationMinutes:     30,
+					PaymentTolerance:      &tolerance,
+					RedirectAutomatically: &redirect,
+				},
+			})
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodPost+" http://test.com/api/v1/stores/s1/invoices"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_StoreInvoices(t *testing.T) {
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result []StoreInvoice
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, "["),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `[{"id":"i1","status":"Settled"}]`),
+			Result: []StoreInvoice{{ID: "i1", Status: "Settled"}},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/stores/s1/invoices", c.Resp)
+
+			res, err := client.StoreInvoices(context.Background(), "s1")
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodGet+" http://test.com/api/v1/stores/s1/invoices"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Nil(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_StoreInvoice(t *testing.T) {
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result StoreInvoice
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, "{"),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `{"id":"i1","metadata":{"orderId":"o1"}}`),
+			Result: StoreInvoice{ID: "i1", Metadata: json.RawMessage(`{"orderId":"o1"}`)},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/stores/s1/invoices/i1", c.Resp)
+
+			res, err := client.StoreInvoice(context.Background(), "s1", "i1")
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodGet+" http://test.com/api/v1/stores/s1/invoices/i1"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_ArchiveStoreInvoice(t *testing.T) {
+	cc := map[string]struct {
+		Resp httpmock.Responder
+		Err  bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, ""),
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodDelete, "http://test.com/api/v1/stores/s1/invoices/i1", c.Resp)
+
+			err = client.ArchiveStoreInvoice(context.Background(), "s1", "i1")
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodDelete+" http://test.com/api/v1/stores/s1/invoices/i1"])
+
+			if c.Err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_Client_UnarchiveStoreInvoice(t *testing.T) {
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result StoreInvoice
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, "{"),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `{"id":"i1","archived":false}`),
+			Result: StoreInvoice{ID: "i1"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/stores/s1/invoices/i1/unarchive", c.Resp)
+
+			res, err := client.UnarchiveStoreInvoice(context.Background(), "s1", "i1")
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodPost+" http://test.com/api/v1/stores/s1/invoices/i1/unarchive"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_MarkStoreInvoiceStatus(t *testing.T) {
+	check := func(status string) httpmock.Responder {
+		return func(r *http.Request) (*http.Response, error) {
+			var p map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+				return nil, err
+			}
+
+			if p["status"] != status {
+				return nil, errors.New("invalid payload")
+			}
+
+			return httpmock.NewStringResponse(http.StatusOK, `{"id":"i1","status":"`+status+`"}`), nil
+		}
+	}
+
+	cc := map[string]struct {
+		Status string
+		Resp   httpmock.Responder
+		Calls  int
+		Result StoreInvoice
+		Err    bool
+	}{
+		"Invalid status": {
+			Status: "expired",
+			Resp:   check("Expired"),
+			Err:    true,
+		},
+		"Error returned during request sending": {
+			Status: "invalid",
+			Resp:   httpmock.NewErrorResponder(assert.AnError),
+			Calls:  1,
+			Err:    true,
+		},
+		"Invalid response body": {
+			Status: "invalid",
+			Resp:   httpmock.NewStringResponder(http.StatusOK, "{"),
+			Calls:  1,
+			Err:    true,
+		},
+		"Successful execution with legacy invalid status": {
+			Status: "invalid",
+			Resp:   check("Invalid"),
+			Calls:  1,
+			Result: StoreInvoice{ID: "i1", Status: "Invalid"},
+		},
+		"Successful execution with legacy complete status": {
+			Status: "complete",
+			Resp:   check("Settled"),
+			Calls:  1,
+			Result: StoreInvoice{ID: "i1", Status: "Settled"},
+		},
+		"Successful execution with Greenfield status": {
+			Status: "Settled",
+			Resp:   check("Settled"),
+			Calls:  1,
+			Result: StoreInvoice{ID: "i1", Status: "Settled"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/stores/s1/invoices/i1/status", c.Resp)
+
+			res, err := client.MarkStoreInvoiceStatus(context.Background(), "s1", "i1", c.Status)
+
+			assert.Equal(t, c.Calls, mt.GetCallCountInfo()[http.MethodPost+" http://test.com/api/v1/stores/s1/invoices/i1/status"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_StoreInvoicePaymentMethods(t *testing.T) {
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result []InvoicePaymentMethod
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, "["),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp:   httpmock.NewStringResponder(http.StatusOK, `[{"paymentMethod":"BTC","cryptoCode":"BTC","destination":"bc1q","due":"0.001","activated":true,"payments":[{"id":"tx1-0","value":"0.002","status":"Settled"}]}]`),
+			Result: []InvoicePaymentMethod{{PaymentMethod: "BTC", CryptoCode: "BTC", Destination: "bc1q", Due: decimal.RequireFromString("0.001"), Activated: true, Payments: []InvoicePayment{{ID: "tx1-0", Value: decimal.RequireFromString("0.002"), Status: "Settled"}}}},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/stores/s1/invoices/i1/payment-methods", c.Resp)
+
+			res, err := client.StoreInvoicePaymentMethods(context.Background(), "s1", "i1")
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodGet+" http://test.com/api/v1/stores/s1/invoices/i1/payment-methods"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Nil(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_InvoicePayment_TxID(t *testing.T) {
+	assert.Equal(t, "tx1", InvoicePayment{ID: "tx1-0"}.TxID())
+	assert.Equal(t, "hash1", InvoicePayment{ID: "hash1"}.TxID())
+}
+
+func Test_Client_ActivateStoreInvoicePaymentMethod(t *testing.T) {
+	cc := map[string]struct {
+		Resp httpmock.Responder
+		Err  bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Successful execution": {
+			Resp: httpmock.NewStringResponder(http.StatusOK, ""),
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/stores/s1/invoices/i1/payment-methods/BTC-LightningNetwork/activate", c.Resp)
+
+			err = client.ActivateStoreInvoicePaymentMethod(context.Background(), "s1", "i1", "BTC-LightningNetwork")
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodPost+" http://test.com/api/v1/stores/s1/invoices/i1/payment-methods/BTC-LightningNetwork/activate"])
+
+			if c.Err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_Client_UpdateStoreInvoice(t *testing.T) {
+	check := func(r *http.Request) error {
+		var p map[string]map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			return err
+		}
+
+		md := p["metadata"]
+		if len(md) != 3 || md["orderId"] != "o2" || md["itemDesc"] != "d1" || md["ref"] != "r1" {
+			return errors.New("invalid payload")
+		}
+
+		return nil
+	}
+
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result StoreInvoice
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, "{"), nil
+			},
+			Err: true,
+		},
+		"Successful execution": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"id":"i1","metadata":{"orderId":"o2"}}`), nil
+			},
+			Result: StoreInvoice{ID: "i1", Metadata: json.RawMessage(`{"orderId":"o2"}`)},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodPut, "http://test.com/api/v1/stores/s1/invoices/i1", c.Resp)
+
+			res, err := client.UpdateStoreInvoice(context.Background(), "s1", "i1", UpdateInvoiceParams{Metadata: map[string]interface{}{"orderId": "o1", "ref": "r1"}, OrderID: "o2", ItemDesc: "d1"})
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodPut+" http://test.com/api/v1/stores/s1/invoices/i1"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
+
+func Test_Client_RefundStoreInvoice(t *testing.T) {
+	check := func(r *http.Request) error {
+		var p map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			return err
+		}
+
+		if len(p) != 2 || p["paymentMethod"] != "BTC" || p["refundVariant"] != "RateThen" {
+			return errors.New("invalid payload")
+		}
+
+		return nil
+	}
+
+	cc := map[string]struct {
+		Resp   httpmock.Responder
+		Result PullPayment
+		Err    bool
+	}{
+		"Error returned during request sending": {
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Invalid response body": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, "{"), nil
+			},
+			Err: true,
+		},
+		"Successful execution": {
+			Resp: func(r *http.Request) (*http.Response, error) {
+				if err := check(r); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"id":"pp1","viewLink":"http://test.com/pp1"}`), nil
+			},
+			Result: PullPayment{ID: "pp1", ViewLink: "http://test.com/pp1"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			mt.RegisterResponder(http.MethodPost, "http://test.com/api/v1/stores/s1/invoices/i1/refund", c.Resp)
+
+			res, err := client.RefundStoreInvoice(context.Background(), "s1", "i1", RefundInvoiceParams{PaymentMethod: "BTC", RefundVariant: RefundRateThen})
+
+			assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodPost+" http://test.com/api/v1/stores/s1/invoices/i1/refund"])
+
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, res)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, res)
+		})
+	}
+}
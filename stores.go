@@ -0,0 +1,134 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/shopspring/decimal"
+)
+
+// StoreParams holds data used to create or update a store.
+// More at: https://docs.btcpayserver.org/API/Greenfield/v1/#tag/Stores
+type StoreParams struct {
+	Name                      string          `json:"name"`
+	Website                   string          `json:"website,omitempty"`
+	DefaultCurrency           string          `json:"defaultCurrency,omitempty"`
+	DefaultLang               string          `json:"defaultLang,omitempty"`
+	DefaultPaymentMethod      string          `json:"defaultPaymentMethod,omitempty"`
+	InvoiceExpiration         int64           `json:"invoiceExpiration,omitempty"`
+	MonitoringExpiration      int64           `json:"monitoringExpiration,omitempty"`
+	SpeedPolicy               string          `json:"speedPolicy,omitempty"`
+	PaymentTolerance          decimal.Decimal `json:"paymentTolerance"`
+	NetworkFeeMode            string          `json:"networkFeeMode,omitempty"`
+	AnyoneCanCreateInvoice    bool            `json:"anyoneCanCreateInvoice"`
+	RequiresRefundEmail       bool            `json:"requiresRefundEmail"`
+	LightningAmountInSatoshi  bool            `json:"lightningAmountInSatoshi"`
+	RedirectAutomatically     bool            `json:"redirectAutomatically"`
+	ShowRecommendedFee        bool            `json:"showRecommendedFee"`
+	RecommendedFeeBlockTarget int64           `json:"recommendedFeeBlockTarget,omitempty"`
+	PayJoinEnabled            bool            `json:"payJoinEnabled"`
+}
+
+// Store holds store data retrieved from the payment processor.
+type Store struct {
+	ID                        string          `json:"id"`
+	Name                      string          `json:"name"`
+	Website                   string          `json:"website"`
+	DefaultCurrency           string          `json:"defaultCurrency"`
+	DefaultLang               string          `json:"defaultLang"`
+	DefaultPaymentMethod      string          `json:"defaultPaymentMethod"`
+	InvoiceExpiration         int64           `json:"invoiceExpiration"`
+	MonitoringExpiration      int64           `json:"monitoringExpiration"`
+	SpeedPolicy               string          `json:"speedPolicy"`
+	PaymentTolerance          decimal.Decimal `json:"paymentTolerance"`
+	NetworkFeeMode            string          `json:"networkFeeMode"`
+	AnyoneCanCreateInvoice    bool            `json:"anyoneCanCreateInvoice"`
+	RequiresRefundEmail       bool            `json:"requiresRefundEmail"`
+	LightningAmountInSatoshi  bool            `json:"lightningAmountInSatoshi"`
+	RedirectAutomatically     bool            `json:"redirectAutomatically"`
+	ShowRecommendedFee        bool            `json:"showRecommendedFee"`
+	RecommendedFeeBlockTarget int64           `json:"recommendedFeeBlockTarget"`
+	PayJoinEnabled            bool            `json:"payJoinEnabled"`
+}
+
+// Stores retrieves all stores available to the API key.
+func (c *Client) Stores(ctx context.Context, opts ...RequestOption) ([]Store, error) {
+	resp, err := c.sendAPI(ctx, http.MethodGet, newRoute("/api/v1/stores"), nil, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var ss []Store
+
+	if err = c.decode(resp.Body, &ss); err != nil {
+		return nil, err
+	}
+
+	return ss, nil
+}
+
+// Store retrieves a store by the provided ID.
+func (c *Client) Store(ctx context.Context, id string, opts ...RequestOption) (Store, error) {
+	resp, err := c.sendAPI(ctx, http.MethodGet, newRoute("/api/v1/stores/:id", id), nil, nil, opts...)
+	if err != nil {
+		return Store{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var s Store
+
+	if err = c.decode(resp.Body, &s); err != nil {
+		return Store{}, err
+	}
+
+	return s, nil
+}
+
+// CreateStore creates a new store.
+func (c *Client) CreateStore(ctx context.Context, p StoreParams, opts ...RequestOption) (Store, error) {
+	resp, err := c.sendAPI(ctx, http.MethodPost, newRoute("/api/v1/stores"), nil, p, opts...)
+	if err != nil {
+		return Store{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var s Store
+
+	if err = c.decode(resp.Body, &s); err != nil {
+		return Store{}, err
+	}
+
+	return s, nil
+}
+
+// UpdateStore updates the specified store.
+func (c *Client) UpdateStore(ctx context.Context, id string, p StoreParams, opts ...RequestOption) (Store, error) {
+	resp, err := c.sendAPI(ctx, http.MethodPut, newRoute("/api/v1/stores/:id", id), nil, p, opts...)
+	if err != nil {
+		return Store{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var s Store
+
+	if err = c.decode(resp.Body, &s); err != nil {
+		return Store{}, err
+	}
+
+	return s, nil
+}
+
+// RemoveStore removes the specified store.
+func (c *Client) RemoveStore(ctx context.Context, id string, opts ...RequestOption) error {
+	resp, err := c.sendAPI(ctx, http.MethodDelete, newRoute("/api/v1/stores/:id", id), nil, nil, opts...)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
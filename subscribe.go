@@ -0,0 +1,417 @@
+package btcpay
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Invoice event subscription timing settings.
+const (
+	heartbeatInterval   = time.Second * 30
+	wsWriteTimeout      = time.Second * 10
+	reconnectMinBackoff = time.Second
+	reconnectMaxBackoff = time.Second * 30
+)
+
+// InvoiceEvent holds data of an invoice update pushed by the server.
+type InvoiceEvent struct {
+	Invoice Invoice
+
+	// Err is set if the updated invoice could not be retrieved.
+	Err error
+}
+
+// SubscriptionMode specifies how invoice events are received.
+type SubscriptionMode int
+
+// Available subscription modes.
+const (
+	// SubscriptionAuto uses websockets and falls back to Server-Sent
+	// Events if the websocket connection cannot be established and an
+	// SSE endpoint is set.
+	SubscriptionAuto SubscriptionMode = iota
+
+	// SubscriptionWebSocket uses only websockets.
+	SubscriptionWebSocket
+
+	// SubscriptionSSE uses only Server-Sent Events.
+	SubscriptionSSE
+)
+
+// SubscribeOption modifies an invoice event subscription.
+type SubscribeOption func(o *subscribeOptions)
+
+// subscribeOptions holds invoice event subscription settings.
+type subscribeOptions struct {
+	mode        SubscriptionMode
+	sseEndpoint string
+}
+
+// WithSubscriptionMode sets the subscription mode.
+func WithSubscriptionMode(m SubscriptionMode) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.mode = m
+	}
+}
+
+// WithSSEEndpoint sets the path of the Server-Sent Events endpoint that
+// publishes invoice notifications, e.g. a proxy in front of the
+// server's websocket. The {invoiceId} placeholder is replaced with the
+// ID of the invoice. Every event received from the endpoint is treated
+// as an invoice update notification.
+func WithSSEEndpoint(path string) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.sseEndpoint = path
+	}
+}
+
+// invoiceStream is a connection that receives invoice notifications.
+type invoiceStream interface {
+	// listen calls fn on every notification until the connection
+	// fails. True is returned if fn requests the subscription to end.
+	listen(ctx context.Context, fn func() bool) bool
+
+	// Close closes the connection.
+	Close() error
+}
+
+// SubscribeInvoiceEvents subscribes to updates of the specified invoice.
+// The current state of the invoice is sent first and later events are
+// sent only when its status or paid amount changes. The connection is
+// kept alive with heartbeats and re-established automatically if it
+// drops. The channel is closed once the context is cancelled or the
+// invoice reaches a final status (complete, expired or invalid).
+// Websockets are used by default.
+func (c *Client) SubscribeInvoiceEvents(ctx context.Context, invoiceID string, opts ...SubscribeOption) (<-chan InvoiceEvent, error) {
+	var o subscribeOptions
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.mode == SubscriptionSSE && o.sseEndpoint == "" {
+		return nil, errors.New("SSE endpoint is not set")
+	}
+
+	st, err := c.dialInvoiceStream(ctx, invoiceID, o)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan InvoiceEvent)
+
+	go c.watchInvoice(ctx, invoiceID, o, st, ch)
+
+	return ch, nil
+}
+
+// watchInvoice sends invoice events until the subscription ends,
+// reconnecting with exponential backoff whenever the connection drops.
+func (c *Client) watchInvoice(ctx context.Context, id string, o subscribeOptions, st invoiceStream, ch chan<- InvoiceEvent) {
+	defer close(ch)
+
+	var last Invoice
+
+	for {
+		// the current state is retrieved after every (re)connection,
+		// so that updates missed in between are not lost
+		done := !c.emitInvoice(ctx, id, &last, ch) ||
+			st.listen(ctx, func() bool { return c.emitInvoice(ctx, id, &last, ch) })
+
+		st.Close()
+
+		if done || ctx.Err() != nil {
+			return
+		}
+
+		backoff := reconnectMinBackoff
+
+		for {
+			if sleep(ctx, c.getClock(), backoff) != nil {
+				return
+			}
+
+			var err error
+
+			st, err = c.dialInvoiceStream(ctx, id, o)
+			if err == nil {
+				break
+			}
+
+			backoff *= 2
+			if backoff > reconnectMaxBackoff {
+				backoff = reconnectMaxBackoff
+			}
+		}
+	}
+}
+
+// emitInvoice retrieves the invoice and sends it if it has changed
+// since the last event. False is returned if the subscription should
+// end.
+func (c *Client) emitInvoice(ctx context.Context, id string, last *Invoice, ch chan<- InvoiceEvent) bool {
+	// a cached copy would hide the change the notification is about
+	inv, err := c.Invoice(ctx, id, WithoutCache())
+	if err != nil {
+		if ctx.Err() != nil {
+			return false
+		}
+
+		return sendInvoiceEvent(ctx, ch, InvoiceEvent{Err: err})
+	}
+
+	if inv.Status == last.Status && inv.AmountPaid.Equal(last.AmountPaid) {
+		return true
+	}
+
+	*last = inv
+
+	if !sendInvoiceEvent(ctx, ch, InvoiceEvent{Invoice: inv}) {
+		return false
+	}
+
+	switch inv.Status {
+	case "complete", "expired", "invalid":
+		return false
+	default:
+		return true
+	}
+}
+
+// sendInvoiceEvent sends the event unless the context is cancelled
+// first.
+func sendInvoiceEvent(ctx context.Context, ch chan<- InvoiceEvent, e InvoiceEvent) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case ch <- e:
+		return true
+	}
+}
+
+// dialInvoiceStream opens a connection that receives notifications
+// about updates of the invoice.
+func (c *Client) dialInvoiceStream(ctx context.Context, id string, o subscribeOptions) (invoiceStream, error) {
+	switch o.mode {
+	case SubscriptionWebSocket:
+		return c.dialWebSocket(ctx, id)
+	case SubscriptionSSE:
+		return c.dialSSE(ctx, id, o.sseEndpoint)
+	default:
+		st, err := c.dialWebSocket(ctx, id)
+		if err == nil || o.sseEndpoint == "" {
+			return st, err
+		}
+
+		return c.dialSSE(ctx, id, o.sseEndpoint)
+	}
+}
+
+// dialWebSocket opens a websocket connection to the status endpoint
+// of the invoice.
+func (c *Client) dialWebSocket(ctx context.Context, id string) (invoiceStream, error) {
+	u, err := url.Parse(c.baseURL() + "/i/" + id + "/status/ws")
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme == "https" {
+		u.Scheme = "wss"
+	} else {
+		u.Scheme = "ws"
+	}
+
+	d := &websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: c.hc.Timeout,
+	}
+
+	if t, ok := c.hc.Transport.(*http.Transport); ok {
+		d.Proxy = t.Proxy
+		d.TLSClientConfig = t.TLSClientConfig
+		d.NetDialContext = t.DialContext
+	}
+
+	h := make(http.Header)
+	h.Set("User-Agent", c.header["User-Agent"])
+
+	conn, resp, err := d.DialContext(ctx, u.String(), h)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body.Close()
+
+	return wsStream{conn}, nil
+}
+
+// wsStream receives invoice notifications through a websocket.
+type wsStream struct {
+	*websocket.Conn
+}
+
+// listen reads status notifications from the connection until it fails.
+// The connection's liveness is checked with pings.
+func (s wsStream) listen(ctx context.Context, fn func() bool) bool {
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go func() {
+		t := time.NewTicker(heartbeatInterval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				// unblocks the read below
+				s.Close()
+				return
+			case <-stop:
+				return
+			case <-t.C:
+				// failed pings are detected by the read deadline
+				_ = s.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout))
+			}
+		}
+	}()
+
+	extend := func() error {
+		return s.SetReadDeadline(time.Now().Add(heartbeatInterval * 2))
+	}
+
+	s.SetPongHandler(func(string) error {
+		return extend()
+	})
+
+	for {
+		if err := extend(); err != nil {
+			return false
+		}
+
+		if _, _, err := s.ReadMessage(); err != nil {
+			return false
+		}
+
+		if !fn() {
+			return true
+		}
+	}
+}
+
+// dialSSE opens a Server-Sent Events stream of the invoice.
+func (c *Client) dialSSE(ctx context.Context, id, endpoint string) (invoiceStream, error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL()+strings.ReplaceAll(endpoint, "{invoiceId}", id), nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", c.header["User-Agent"])
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+
+	// the stream is long-lived, so the client's timeout can't be used;
+	// liveness is checked by the listener instead
+	hc := *c.hc
+	hc.Timeout = 0
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		resp.Body.Close()
+		cancel()
+
+		return nil, fmt.Errorf("[%d] unexpected event stream response", resp.StatusCode)
+	}
+
+	return sseStream{resp: resp, cancel: cancel}, nil
+}
+
+// sseStream receives invoice notifications through Server-Sent Events.
+type sseStream struct {
+	resp   *http.Response
+	cancel context.CancelFunc
+}
+
+// listen reads events from the stream until it fails. The stream is
+// considered dead if nothing, including comments used as heartbeats, is
+// received for two heartbeat intervals.
+func (s sseStream) listen(ctx context.Context, fn func() bool) bool {
+	activity := make(chan struct{}, 1)
+	stop := make(chan struct{})
+
+	defer close(stop)
+
+	go func() {
+		t := time.NewTimer(heartbeatInterval * 2)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+			case <-t.C:
+			case <-stop:
+				return
+			case <-activity:
+				if !t.Stop() {
+					<-t.C
+				}
+
+				t.Reset(heartbeatInterval * 2)
+
+				continue
+			}
+
+			// unblocks the read below
+			s.cancel()
+
+			return
+		}
+	}()
+
+	sc := bufio.NewScanner(s.resp.Body)
+
+	var data bool
+
+	for sc.Scan() {
+		select {
+		case activity <- struct{}{}:
+		default:
+		}
+
+		line := sc.Text()
+
+		switch {
+		case line == "" && data:
+			data = false
+
+			if !fn() {
+				return true
+			}
+		case strings.HasPrefix(line, "data:"):
+			data = true
+		}
+	}
+
+	return false
+}
+
+// Close closes the stream.
+func (s sseStream) Close() error {
+	s.cancel()
+	return s.resp.Body.Close()
+}
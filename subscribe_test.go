@@ -0,0 +1,269 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// invoiceStatusServer imitates the invoice and invoice status endpoints
+// of the server.
+type invoiceStatusServer struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	status string
+	conns  chan *websocket.Conn
+
+	// events are sent to SSE clients; an empty event drops the
+	// connection
+	events chan string
+	noWS   bool
+}
+
+func newInvoiceStatusServer(t *testing.T, noWS bool) *invoiceStatusServer {
+	s := &invoiceStatusServer{
+		status: "new",
+		conns:  make(chan *websocket.Conn, 10),
+		events: make(chan string),
+		noWS:   noWS,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/invoices/inv1", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		_, _ = w.Write([]byte(`{"data":{"id":"inv1","status":"` + s.status + `"}}`))
+	})
+	mux.HandleFunc("/i/inv1/status/ws", func(w http.ResponseWriter, r *http.Request) {
+		if s.noWS {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		s.conns <- conn
+	})
+	mux.HandleFunc("/events/inv1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(": connected\n\n"))
+		w.(http.Flusher).Flush()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case e := <-s.events:
+				if e == "" {
+					return
+				}
+
+				_, _ = w.Write([]byte("event: update\ndata: " + e + "\n\n"))
+				w.(http.Flusher).Flush()
+			}
+		}
+	})
+
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+func (s *invoiceStatusServer) setStatus(status string) {
+	s.mu.Lock()
+	s.status = status
+	s.mu.Unlock()
+}
+
+func receiveInvoiceEvent(t *testing.T, ch <-chan InvoiceEvent) (InvoiceEvent, bool) {
+	t.Helper()
+
+	select {
+	case e, ok := <-ch:
+		return e, ok
+	case <-time.After(time.Second * 5):
+		t.Fatal("event not received")
+		return InvoiceEvent{}, false
+	}
+}
+
+func Test_Client_SubscribeInvoiceEvents(t *testing.T) {
+	srv := newInvoiceStatusServer(t, false)
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "")
+	require.NoError(t, err)
+
+	_, err = client.SubscribeInvoiceEvents(context.Background(), "inv2")
+	assert.Error(t, err)
+
+	ch, err := client.SubscribeInvoiceEvents(context.Background(), "inv1")
+	require.NoError(t, err)
+
+	conn := <-srv.conns
+
+	e, ok := receiveInvoiceEvent(t, ch)
+	require.True(t, ok)
+	assert.NoError(t, e.Err)
+	assert.Equal(t, "new", e.Invoice.Status)
+
+	srv.setStatus("paid")
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("inv1")))
+
+	e, ok = receiveInvoiceEvent(t, ch)
+	require.True(t, ok)
+	assert.Equal(t, "paid", e.Invoice.Status)
+
+	// updates missed while the connection is down are sent after
+	// reconnecting
+	srv.setStatus("complete")
+	require.NoError(t, conn.Close())
+
+	e, ok = receiveInvoiceEvent(t, ch)
+	require.True(t, ok)
+	assert.Equal(t, "complete", e.Invoice.Status)
+	assert.Len(t, srv.conns, 1)
+
+	_, ok = receiveInvoiceEvent(t, ch)
+	assert.False(t, ok)
+}
+
+func Test_Client_SubscribeInvoiceEvents_Cache(t *testing.T) {
+	srv := newInvoiceStatusServer(t, false)
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "", WithResponseCache(NewMemoryResponseCache(10), time.Hour))
+	require.NoError(t, err)
+
+	inv, err := client.Invoice(context.Background(), "inv1")
+	require.NoError(t, err)
+	assert.Equal(t, "new", inv.Status)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := client.SubscribeInvoiceEvents(ctx, "inv1")
+	require.NoError(t, err)
+
+	conn := <-srv.conns
+
+	e, ok := receiveInvoiceEvent(t, ch)
+	require.True(t, ok)
+	assert.Equal(t, "new", e.Invoice.Status)
+
+	srv.setStatus("paid")
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("inv1")))
+
+	e, ok = receiveInvoiceEvent(t, ch)
+	require.True(t, ok)
+	assert.Equal(t, "paid", e.Invoice.Status)
+}
+
+func Test_Client_SubscribeInvoiceEvents_Cancel(t *testing.T) {
+	srv := newInvoiceStatusServer(t, false)
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch, err := client.SubscribeInvoiceEvents(ctx, "inv1")
+	require.NoError(t, err)
+
+	_, ok := receiveInvoiceEvent(t, ch)
+	require.True(t, ok)
+
+	cancel()
+
+	_, ok = receiveInvoiceEvent(t, ch)
+	assert.False(t, ok)
+}
+
+func Test_Client_SubscribeInvoiceEvents_SSE(t *testing.T) {
+	cc := map[string]struct {
+		NoWS bool
+		Opts []SubscribeOption
+	}{
+		"SSE mode": {
+			Opts: []SubscribeOption{WithSubscriptionMode(SubscriptionSSE), WithSSEEndpoint("/events/{invoiceId}")},
+		},
+		"Fallback to SSE": {
+			NoWS: true,
+			Opts: []SubscribeOption{WithSSEEndpoint("/events/{invoiceId}")},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newInvoiceStatusServer(t, c.NoWS)
+			defer srv.Close()
+
+			client, err := NewClient(srv.URL, "")
+			require.NoError(t, err)
+
+			ch, err := client.SubscribeInvoiceEvents(context.Background(), "inv1", c.Opts...)
+			require.NoError(t, err)
+
+			e, ok := receiveInvoiceEvent(t, ch)
+			require.True(t, ok)
+			assert.Equal(t, "new", e.Invoice.Status)
+
+			srv.setStatus("paid")
+			srv.events <- "inv1"
+
+			e, ok = receiveInvoiceEvent(t, ch)
+			require.True(t, ok)
+			assert.Equal(t, "paid", e.Invoice.Status)
+
+			srv.setStatus("expired")
+			srv.events <- ""
+
+			e, ok = receiveInvoiceEvent(t, ch)
+			require.True(t, ok)
+			assert.Equal(t, "expired", e.Invoice.Status)
+			assert.Empty(t, srv.conns)
+
+			_, ok = receiveInvoiceEvent(t, ch)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func Test_Client_SubscribeInvoiceEvents_Errors(t *testing.T) {
+	srv := newInvoiceStatusServer(t, true)
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "")
+	require.NoError(t, err)
+
+	_, err = client.SubscribeInvoiceEvents(context.Background(), "inv1", WithSubscriptionMode(SubscriptionSSE))
+	assert.Error(t, err)
+
+	_, err = client.SubscribeInvoiceEvents(context.Background(), "inv1")
+	assert.Error(t, err)
+
+	_, err = client.SubscribeInvoiceEvents(context.Background(), "inv1", WithSSEEndpoint("/invoices/{invoiceId}"))
+	assert.Error(t, err)
+
+	_, err = client.SubscribeInvoiceEvents(context.Background(), "inv1", WithSubscriptionMode(SubscriptionSSE), WithSSEEndpoint("/test"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,205 @@
+package btcpay
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Default invoice sync settings.
+const (
+	defaultSyncWindow  = time.Hour * 24 * 7
+	defaultSyncOverlap = time.Hour * 24
+)
+
+// CheckpointStore persists the progress of invoice syncs, so that they
+// can be resumed. See Client.SyncInvoices.
+type CheckpointStore interface {
+	// Load returns the checkpoint saved under the key or the zero time
+	// if there is none.
+	Load(ctx context.Context, key string) (time.Time, error)
+
+	// Save saves the checkpoint under the key.
+	Save(ctx context.Context, key string, t time.Time) error
+}
+
+// MemoryCheckpointStore is a CheckpointStore that keeps checkpoints in
+// memory. It is suitable for a single process only.
+// It is safe for concurrent use by multiple goroutines.
+type MemoryCheckpointStore struct {
+	mu          sync.Mutex
+	checkpoints map[string]time.Time
+}
+
+// NewMemoryCheckpointStore creates a new in-memory checkpoint store.
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{checkpoints: make(map[string]time.Time)}
+}
+
+// Load returns the checkpoint saved under the key.
+func (s *MemoryCheckpointStore) Load(_ context.Context, key string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.checkpoints[key], nil
+}
+
+// Save saves the checkpoint under the key.
+func (s *MemoryCheckpointStore) Save(_ context.Context, key string, t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checkpoints[key] = t
+
+	return nil
+}
+
+// SyncOption modifies an invoice sync.
+type SyncOption func(s *invoiceSync)
+
+// WithCheckpointStore makes the sync save its progress under the key
+// after every window and resume from the saved checkpoint.
+func WithCheckpointStore(cs CheckpointStore, key string) SyncOption {
+	return func(s *invoiceSync) {
+		s.store = cs
+		s.key = key
+	}
+}
+
+// WithSyncWindow sets the date range retrieved by a single paginated
+// query. The server filters invoices by date only, so the window is
+// rounded up to whole days. Defaults to 7 days.
+func WithSyncWindow(d time.Duration) SyncOption {
+	return func(s *invoiceSync) {
+		s.window = d
+	}
+}
+
+// WithSyncOverlap sets the date range that consecutive windows, and
+// resumed syncs, share, so that invoices created around window
+// boundaries or while a window was being retrieved are not missed.
+// Defaults to 1 day.
+func WithSyncOverlap(d time.Duration) SyncOption {
+	return func(s *invoiceSync) {
+		s.overlap = d
+	}
+}
+
+// invoiceSync holds the settings of an invoice sync.
+type invoiceSync struct {
+	store   CheckpointStore
+	key     string
+	window  time.Duration
+	overlap time.Duration
+}
+
+// SyncInvoices walks forward over invoices created since the specified
+// time, up to now, and calls fn for each of them. Invoices are retrieved
+// in date windows, each of them paginated, so that no single query has
+// to page through the whole history. Invoices that appear in the
+// overlap of two windows are passed to fn once.
+//
+// With a checkpoint store, the sync resumes from the last saved
+// checkpoint, minus the overlap, if it is later than the specified
+// time. Invoices in the overlap are passed to fn again on resumption,
+// so fn must be idempotent.
+//
+// The sync stops at the first error returned by fn.
+func (c *Client) SyncInvoices(ctx context.Context, since time.Time, fn func(Invoice) error, opts ...SyncOption) error {
+	s := invoiceSync{
+		window:  defaultSyncWindow,
+		overlap: defaultSyncOverlap,
+	}
+
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	// the server filters by date only
+	s.window = s.window.Truncate(time.Hour * 24)
+	if s.window <= 0 {
+		s.window = time.Hour * 24
+	}
+
+	if s.overlap < 0 || s.overlap >= s.window {
+		return errors.New("sync overlap must be shorter than the window")
+	}
+
+	from := since.UTC()
+
+	if s.store != nil {
+		cp, err := s.store.Load(ctx, s.key)
+		if err != nil {
+			return err
+		}
+
+		if cp = cp.Add(-s.overlap).UTC(); cp.After(from) {
+			from = cp
+		}
+	}
+
+	if from.IsZero() {
+		return errors.New("sync start time is not set")
+	}
+
+	now := c.getClock().Now().UTC()
+	if !from.Before(now) {
+		return nil
+	}
+
+	var (
+		minTime  = since.UnixNano() / int64(time.Millisecond)
+		prevSeen map[string]struct{}
+	)
+
+	for {
+		to := from.Add(s.window)
+		if to.After(now) {
+			to = now
+		}
+
+		seen := make(map[string]struct{})
+
+		it := c.InvoiceIterator(ctx, InvoicesParams{DateStart: from, DateEnd: to})
+		for it.Next() {
+			inv := it.Invoice()
+
+			if _, ok := prevSeen[inv.ID]; ok {
+				continue
+			}
+
+			if _, ok := seen[inv.ID]; ok {
+				continue
+			}
+
+			seen[inv.ID] = struct{}{}
+
+			// whole days are retrieved
+			if !since.IsZero() && inv.InvoiceTime < minTime {
+				continue
+			}
+
+			if err := fn(inv); err != nil {
+				return err
+			}
+		}
+
+		if err := it.Err(); err != nil {
+			return err
+		}
+
+		if s.store != nil {
+			if err := s.store.Save(ctx, s.key, to); err != nil {
+				return err
+			}
+		}
+
+		if !to.Before(now) {
+			return nil
+		}
+
+		from = to.Add(-s.overlap)
+		prevSeen = seen
+	}
+}
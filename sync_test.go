@@ -0,0 +1,166 @@
+package btcpay
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_MemoryCheckpointStore(t *testing.T) {
+	s := NewMemoryCheckpointStore()
+
+	cp, err := s.Load(context.Background(), "k")
+	require.NoError(t, err)
+	assert.True(t, cp.IsZero())
+
+	now := time.Now()
+	require.NoError(t, s.Save(context.Background(), "k", now))
+
+	cp, err = s.Load(context.Background(), "k")
+	require.NoError(t, err)
+	assert.Equal(t, now, cp)
+}
+
+func Test_Client_SyncInvoices(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2021, 1, d, 12, 0, 0, 0, time.UTC)
+	}
+
+	now := day(20)
+
+	// invoices returns the invoices created on the days within the
+	// query's date range
+	invoices := func(days ...int) httpmock.Responder {
+		return func(r *http.Request) (*http.Response, error) {
+			start, err := time.Parse("2006-01-02", r.URL.Query().Get("dateStart"))
+			if err != nil {
+				return nil, err
+			}
+
+			end, err := time.Parse("2006-01-02", r.URL.Query().Get("dateEnd"))
+			if err != nil {
+				return nil, err
+			}
+
+			var ii []string
+
+			for _, d := range days {
+				if t := day(d).Truncate(time.Hour * 24); !t.Before(start) && !t.After(end) {
+					ii = append(ii, fmt.Sprintf(`{"id":"i%d","invoiceTime":%d}`, d, day(d).UnixNano()/int64(time.Millisecond)))
+				}
+			}
+
+			return httpmock.NewStringResponse(http.StatusOK, `{"data":[`+strings.Join(ii, ",")+`]}`), nil
+		}
+	}
+
+	cc := map[string]struct {
+		Since      time.Time
+		Checkpoint time.Time
+		Opts       []SyncOption
+		Resp       httpmock.Responder
+		FnErr      error
+		Calls      int
+		IDs        []string
+		Saved      time.Time
+		Err        bool
+	}{
+		"Invalid overlap": {
+			Since: day(1),
+			Opts:  []SyncOption{WithSyncOverlap(time.Hour * 24 * 7)},
+			Err:   true,
+		},
+		"Start time not set": {
+			Err: true,
+		},
+		"Error returned during request sending": {
+			Since: day(1),
+			Resp:  httpmock.NewErrorResponder(assert.AnError),
+			Calls: 1,
+			Err:   true,
+		},
+		"Error returned by the callback": {
+			Since: day(1),
+			Resp:  invoices(2),
+			FnErr: assert.AnError,
+			Calls: 1,
+			IDs:   []string{"i2"},
+			Err:   true,
+		},
+		"Start time in the future": {
+			Since: day(21),
+		},
+		"Successful sync": {
+			Since: day(1).Add(time.Hour),
+			Resp:  invoices(1, 2, 7, 8, 14, 20),
+			Calls: 3,
+			IDs:   []string{"i2", "i7", "i8", "i14", "i20"},
+			Saved: now,
+		},
+		"Successful sync with a custom window": {
+			Since: day(1),
+			Opts:  []SyncOption{WithSyncWindow(time.Hour * 24 * 30), WithSyncOverlap(0)},
+			Resp:  invoices(1, 20),
+			Calls: 1,
+			IDs:   []string{"i1", "i20"},
+			Saved: now,
+		},
+		"Successful resumed sync": {
+			Since:      day(1),
+			Checkpoint: day(15),
+			Resp:       invoices(2, 14, 15, 19),
+			Calls:      1,
+			IDs:        []string{"i14", "i15", "i19"},
+			Saved:      now,
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}), WithClock(fixedClock{now}))
+			require.NoError(t, err)
+
+			if c.Resp != nil {
+				mt.RegisterResponder(http.MethodGet, "http://test.com/invoices", c.Resp)
+			}
+
+			store := NewMemoryCheckpointStore()
+			if !c.Checkpoint.IsZero() {
+				require.NoError(t, store.Save(context.Background(), "k", c.Checkpoint))
+			}
+
+			var ids []string
+
+			err = client.SyncInvoices(context.Background(), c.Since, func(inv Invoice) error {
+				ids = append(ids, inv.ID)
+				return c.FnErr
+			}, append([]SyncOption{WithCheckpointStore(store, "k")}, c.Opts...)...)
+
+			assert.Equal(t, c.Calls, mt.GetTotalCallCount())
+			assert.Equal(t, c.IDs, ids)
+
+			if c.Err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+
+			saved, err := store.Load(context.Background(), "k")
+			require.NoError(t, err)
+			assert.Equal(t, c.Saved, saved)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the name used to identify the tracer and meter
+// created by this package.
+const instrumentationName = "github.com/swithek/btcpay-go"
+
+// WithTracing enables OpenTelemetry tracing on the BTCPay client. A new
+// span is emitted for every API call.
+func WithTracing(tp trace.TracerProvider) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.tracer = tp.Tracer(instrumentationName)
+	}
+}
+
+// WithMeter enables OpenTelemetry metrics on the BTCPay client. Request
+// count, error count and request latency are recorded for every API call.
+func WithMeter(mp metric.MeterProvider) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.meter = mp.Meter(instrumentationName)
+	}
+}
+
+// instruments holds metric instruments used by the client.
+type instruments struct {
+	requests metric.Int64Counter
+	errors   metric.Int64Counter
+	latency  metric.Float64Histogram
+}
+
+// newInstruments creates all metric instruments with the provided meter.
+func newInstruments(m metric.Meter) (*instruments, error) {
+	var (
+		ins instruments
+		err error
+	)
+
+	ins.requests, err = m.Int64Counter(
+		"btcpay.client.requests",
+		metric.WithDescription("Number of requests sent to the BTCPay server."),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ins.errors, err = m.Int64Counter(
+		"btcpay.client.errors",
+		metric.WithDescription("Number of requests that resulted in an error."),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ins.latency, err = m.Float64Histogram(
+		"btcpay.client.duration",
+		metric.WithDescription("Duration of requests sent to the BTCPay server."),
+		metric.WithUnit("ms"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ins, nil
+}
+
+// observe starts recording telemetry data about the request. The
+// returned function must be called once the request is complete.
+func (c *Client) observe(req *http.Request) (*http.Request, func(status int, err error)) {
+	if c.tracer == nil && c.instruments == nil && c.metrics == nil {
+		return req, func(int, error) {}
+	}
+
+	// the route template is used instead of the path, so that the
+	// number of distinct metric attribute sets stays bounded
+	route := routeLabel(req)
+
+	attrs := []attribute.KeyValue{
+		attribute.String("http.method", req.Method),
+		attribute.String("http.route", route),
+	}
+
+	ctx := req.Context()
+
+	var span trace.Span
+
+	if c.tracer != nil {
+		ctx, span = c.tracer.Start(ctx, req.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attrs...),
+			trace.WithAttributes(attribute.String("url.path", c.redactText(req.URL.Path))),
+		)
+
+		// the ID is unique per request, so it is kept out of
+		// metric attributes
+		if id := c.correlationID(ctx); id != "" {
+			span.SetAttributes(attribute.String("btcpay.correlation_id", id))
+		}
+	}
+
+	start := time.Now()
+
+	return req.WithContext(ctx), func(status int, err error) {
+		if status > 0 {
+			attrs = append(attrs, attribute.Int("http.status_code", status))
+		}
+
+		if span != nil {
+			span.SetAttributes(attrs...)
+
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+
+			span.End()
+		}
+
+		if c.instruments != nil {
+			c.recordMetrics(ctx, start, attrs, err)
+		}
+
+		if c.metrics != nil {
+			c.metrics.ObserveRequest(req.Method, route, status, err, time.Since(start))
+		}
+	}
+}
+
+// recordMetrics records request metrics with the provided attributes.
+func (c *Client) recordMetrics(ctx context.Context, start time.Time, attrs []attribute.KeyValue, err error) {
+	opt := metric.WithAttributes(attrs...)
+
+	c.instruments.requests.Add(ctx, 1, opt)
+
+	if err != nil {
+		c.instruments.errors.Add(ctx, 1, opt)
+	}
+
+	c.instruments.latency.Record(ctx, float64(time.Since(start))/float64(time.Millisecond), opt)
+}
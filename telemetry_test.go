@@ -0,0 +1,90 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func Test_WithTracing(t *testing.T) {
+	c := &Client{}
+	WithTracing(trace.NewNoopTracerProvider())(c)
+	assert.NotNil(t, c.tracer)
+}
+
+func Test_WithMeter(t *testing.T) {
+	c := &Client{}
+	WithMeter(noop.NewMeterProvider())(c)
+	assert.NotNil(t, c.meter)
+}
+
+func Test_Client_observe(t *testing.T) {
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder(http.MethodGet, "http://test.com/invoices/123", httpmock.NewStringResponder(http.StatusNotFound, `{"error":"not found"}`))
+
+	client, err := NewClient("http://test.com", "",
+		WithHTTPClient(&http.Client{Transport: mt}),
+		WithTracing(trace.NewNoopTracerProvider()),
+		WithMeter(noop.NewMeterProvider()),
+	)
+	require.NoError(t, err)
+	assert.NotNil(t, client.instruments)
+
+	_, err = client.Invoice(context.Background(), "123")
+	assert.EqualError(t, err, "[404] not found")
+	assert.Equal(t, 1, mt.GetCallCountInfo()[http.MethodGet+" http://test.com/invoices/123"])
+}
+
+// recordingTracer records the names and start attributes of spans.
+type recordingTracer struct {
+	trace.TracerProvider
+
+	mu    sync.Mutex
+	names []string
+	attrs map[attribute.Key]attribute.Value
+}
+
+func (rt *recordingTracer) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return rt
+}
+
+func (rt *recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	rt.names = append(rt.names, name)
+
+	cfg := trace.NewSpanStartConfig(opts...)
+
+	for _, kv := range cfg.Attributes() {
+		rt.attrs[kv.Key] = kv.Value
+	}
+
+	return trace.NewNoopTracerProvider().Tracer("").Start(ctx, name)
+}
+
+func Test_Client_observe_Route(t *testing.T) {
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder(http.MethodGet, "http://test.com/api/v1/stores/8kZAP5CrqvFd7cR7ehCkwX5hT3vFHfJvyyWzoP9bFQuw/invoices/XnHfJnJmtgNKe7MGuTAz9x",
+		httpmock.NewStringResponder(http.StatusOK, `{"id":"XnHfJnJmtgNKe7MGuTAz9x"}`))
+
+	rt := &recordingTracer{attrs: make(map[attribute.Key]attribute.Value)}
+
+	client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}), WithTracing(rt))
+	require.NoError(t, err)
+
+	_, err = client.StoreInvoice(context.Background(), "8kZAP5CrqvFd7cR7ehCkwX5hT3vFHfJvyyWzoP9bFQuw", "XnHfJnJmtgNKe7MGuTAz9x")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"GET /api/v1/stores/:id/invoices/:id"}, rt.names)
+	assert.Equal(t, "/api/v1/stores/:id/invoices/:id", rt.attrs["http.route"].AsString())
+	assert.Equal(t, "/api/v1/stores/8kZAP5CrqvFd7cR7ehCkwX5hT3vFHfJvyyWzoP9bFQuw/invoices/XnHfJnJmtgNKe7MGuTAz9x", rt.attrs["url.path"].AsString())
+}
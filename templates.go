@@ -0,0 +1,104 @@
+package btcpay
+
+import (
+	"context"
+	"fmt"
+)
+
+// InvoiceTemplate holds default parameters of invoices of a single
+// kind, e.g. donations. See Client.RegisterTemplate.
+type InvoiceTemplate CreateInvoiceParams
+
+// RegisterTemplate registers the invoice template under the name,
+// replacing any template registered under it before. The template is
+// validated only once an invoice is created from it, so it may omit
+// parameters, such as the price, that are always overridden. Its
+// idempotency key is ignored.
+func (c *Client) RegisterTemplate(name string, t InvoiceTemplate) {
+	t.IdempotencyKey = ""
+	t.PaymentCurrencies = append([]string(nil), t.PaymentCurrencies...)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.templates == nil {
+		c.templates = make(map[string]InvoiceTemplate)
+	}
+
+	c.templates[name] = t
+}
+
+// CreateInvoiceFromTemplate creates a new invoice with the parameters of
+// the template registered under the name. Non-zero fields of the
+// overrides, including fields of the buyer, take precedence over the
+// template's. Boolean fields can only be enabled by the overrides.
+func (c *Client) CreateInvoiceFromTemplate(ctx context.Context, name string, overrides CreateInvoiceParams, opts ...RequestOption) (Invoice, error) {
+	c.mu.RLock()
+	t, ok := c.templates[name]
+	c.mu.RUnlock()
+
+	if !ok {
+		return Invoice{}, fmt.Errorf("invoice template %q is not registered", name)
+	}
+
+	return c.CreateInvoice(ctx, t.apply(overrides), opts...)
+}
+
+// apply returns the template's parameters with the non-zero fields of
+// the overrides applied on top of them.
+func (t InvoiceTemplate) apply(o CreateInvoiceParams) CreateInvoiceParams {
+	p := CreateInvoiceParams(t)
+
+	override(&p.Currency, o.Currency)
+	override(&p.OrderID, o.OrderID)
+	override(&p.ItemDesc, o.ItemDesc)
+	override(&p.ItemCode, o.ItemCode)
+	override(&p.NotificationEmail, o.NotificationEmail)
+	override(&p.NotificationURL, o.NotificationURL)
+	override(&p.RedirectURL, o.RedirectURL)
+	override(&p.POSData, o.POSData)
+	overrideSensitive(&p.Buyer.Name, o.Buyer.Name)
+	overrideSensitive(&p.Buyer.Address1, o.Buyer.Address1)
+	overrideSensitive(&p.Buyer.Address2, o.Buyer.Address2)
+	override(&p.Buyer.Locality, o.Buyer.Locality)
+	override(&p.Buyer.Region, o.Buyer.Region)
+	overrideSensitive(&p.Buyer.PostalCode, o.Buyer.PostalCode)
+	override(&p.Buyer.Country, o.Buyer.Country)
+	overrideSensitive(&p.Buyer.Email, o.Buyer.Email)
+	overrideSensitive(&p.Buyer.Phone, o.Buyer.Phone)
+	override(&p.Buyer.Notify, o.Buyer.Notify)
+
+	if !o.Price.IsZero() {
+		p.Price = o.Price
+	}
+
+	if o.TransactionSpeed != "" {
+		p.TransactionSpeed = o.TransactionSpeed
+	}
+
+	if o.PaymentCurrencies != nil {
+		p.PaymentCurrencies = o.PaymentCurrencies
+	}
+
+	p.FullNotifications = p.FullNotifications || o.FullNotifications
+	p.ExtendedNotifications = p.ExtendedNotifications || o.ExtendedNotifications
+	p.Physical = p.Physical || o.Physical
+	p.IdempotencyKey = o.IdempotencyKey
+
+	return p
+}
+
+// override replaces the value with v, unless v is empty.
+func override(dst *string, v string) {
+	if v != "" {
+		*dst = v
+	}
+}
+
+// overrideSensitive replaces the sensitive value with v, unless v is
+// empty.
+func overrideSensitive(dst *Sensitive, v Sensitive) {
+	if v != "" {
+		*dst = v
+	}
+}
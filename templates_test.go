@@ -0,0 +1,138 @@
+package btcpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_RegisterTemplate(t *testing.T) {
+	c := &Client{}
+
+	pc := []string{"BTC"}
+	c.RegisterTemplate("donation", InvoiceTemplate{Currency: "USD", PaymentCurrencies: pc, IdempotencyKey: "k1"})
+	pc[0] = "LTC"
+
+	require.Contains(t, c.templates, "donation")
+	assert.Equal(t, InvoiceTemplate{Currency: "USD", PaymentCurrencies: []string{"BTC"}}, c.templates["donation"])
+
+	c.RegisterTemplate("donation", InvoiceTemplate{Currency: "EUR"})
+	assert.Equal(t, "EUR", c.templates["donation"].Currency)
+}
+
+func Test_InvoiceTemplate_apply(t *testing.T) {
+	tmpl := InvoiceTemplate{
+		Currency:          "USD",
+		Price:             decimal.NewFromInt(5),
+		ItemDesc:          "Donation",
+		NotificationURL:   "https://test.com/ipn",
+		TransactionSpeed:  SpeedHigh,
+		FullNotifications: true,
+		PaymentCurrencies: []string{"BTC"},
+		Buyer:             InvoiceBuyer{Country: "US", Notify: "true"},
+	}
+
+	assert.Equal(t, CreateInvoiceParams(tmpl), tmpl.apply(CreateInvoiceParams{}))
+
+	p := tmpl.apply(CreateInvoiceParams{
+		Price:             decimal.NewFromInt(10),
+		OrderID:           "o1",
+		TransactionSpeed:  SpeedLow,
+		Physical:          true,
+		PaymentCurrencies: []string{"BTC", "LTC"},
+		Buyer:             InvoiceBuyer{Email: "a@test.com", Country: "LT"},
+		IdempotencyKey:    "k1",
+	})
+
+	assert.Equal(t, CreateInvoiceParams{
+		Currency:          "USD",
+		Price:             decimal.NewFromInt(10),
+		OrderID:           "o1",
+		ItemDesc:          "Donation",
+		NotificationURL:   "https://test.com/ipn",
+		TransactionSpeed:  SpeedLow,
+		FullNotifications: true,
+		Physical:          true,
+		PaymentCurrencies: []string{"BTC", "LTC"},
+		Buyer:             InvoiceBuyer{Email: "a@test.com", Country: "LT", Notify: "true"},
+		IdempotencyKey:    "k1",
+	}, p)
+}
+
+func Test_Client_CreateInvoiceFromTemplate(t *testing.T) {
+	cc := map[string]struct {
+		Name      string
+		Overrides CreateInvoiceParams
+		Resp      httpmock.Responder
+		Result    Invoice
+		Err       bool
+	}{
+		"Unknown template": {
+			Name: "sale",
+			Err:  true,
+		},
+		"Invalid params": {
+			Name:      "donation",
+			Overrides: CreateInvoiceParams{Currency: "X"},
+			Err:       true,
+		},
+		"Error returned during invoice creation": {
+			Name: "donation",
+			Resp: httpmock.NewErrorResponder(assert.AnError),
+			Err:  true,
+		},
+		"Successful invoice creation": {
+			Name:      "donation",
+			Overrides: CreateInvoiceParams{Price: decimal.NewFromInt(10), OrderID: "o1"},
+			Resp: func(r *http.Request) (*http.Response, error) {
+				var p CreateInvoiceParams
+				if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+					return nil, err
+				}
+
+				if p.Currency != "USD" || p.Price.String() != "10" || p.OrderID != "o1" || p.TransactionSpeed != SpeedHigh {
+					return nil, errors.New("invalid request")
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"data":{"id":"i1"}}`), nil
+			},
+			Result: Invoice{ID: "i1"},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			mt := httpmock.NewMockTransport()
+			client, err := NewClient("http://test.com", "", WithHTTPClient(&http.Client{Transport: mt}))
+			require.NoError(t, err)
+
+			client.RegisterTemplate("donation", InvoiceTemplate{Currency: "USD", TransactionSpeed: SpeedHigh})
+
+			if c.Resp != nil {
+				mt.RegisterResponder(http.MethodPost, "http://test.com/invoices", c.Resp)
+			}
+
+			inv, err := client.CreateInvoiceFromTemplate(context.Background(), c.Name, c.Overrides)
+			if c.Err {
+				assert.Error(t, err)
+				assert.Zero(t, inv)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.Result, inv)
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package btcpay
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// torProxyAddr is the default address of a local Tor SOCKS5 proxy.
+const torProxyAddr = "127.0.0.1:9050"
+
+// WithSOCKS5Proxy routes all requests through the SOCKS5 proxy at the
+// provided address. Host names are resolved by the proxy, which makes
+// it suitable for Tor. Auth may be nil.
+func WithSOCKS5Proxy(addr string, auth *url.Userinfo) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.proxy = &url.URL{
+			Scheme: "socks5",
+			Host:   addr,
+			User:   auth,
+		}
+	}
+}
+
+// WithTLSConfig sets a custom TLS configuration on the BTCPay client's
+// HTTP transport.
+func WithTLSConfig(cfg *tls.Config) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.tlsConfig = cfg
+	}
+}
+
+// WithPinnedCertificate makes the BTCPay client trust only the server
+// certificates provided in the PEM data. This is useful when the server
+// uses a self-signed certificate.
+func WithPinnedCertificate(pemData []byte) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.pinnedCert = pemData
+	}
+}
+
+// Dialer establishes network connections. *net.Dialer implements it.
+type Dialer interface {
+	// DialContext connects to the address on the named network.
+	DialContext(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// WithDialer sets a custom dialer on the BTCPay client's HTTP
+// transport, so that host names can be resolved in a custom way, e.g.
+// with DNS over HTTPS through a net.Dialer with a custom net.Resolver,
+// without replacing the whole transport. When a proxy is used, the
+// dialer connects to the proxy and the proxy resolves the server's
+// host name.
+func WithDialer(d Dialer) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.dialer = d
+	}
+}
+
+// transportTuning holds connection pooling settings.
+type transportTuning struct {
+	maxIdleConns    int
+	maxConnsPerHost int
+	idleTimeout     time.Duration
+}
+
+// WithTransportTuning sets connection pooling settings on the BTCPay
+// client's HTTP transport: the number of idle connections kept open for
+// reuse, the maximum number of connections to the server (0 means no
+// limit) and how long an idle connection is kept open. HTTP/2 is
+// enabled if the server supports it, so that requests are multiplexed
+// over a single connection.
+func WithTransportTuning(maxIdleConns, maxConnsPerHost int, idleTimeout time.Duration) setter { //nolint:golint // setter funcs cannot be created outside of this package
+	return func(c *Client) {
+		c.tuning = &transportTuning{
+			maxIdleConns:    maxIdleConns,
+			maxConnsPerHost: maxConnsPerHost,
+			idleTimeout:     idleTimeout,
+		}
+	}
+}
+
+// configureTransport applies the transport related settings to the
+// HTTP client.
+func (c *Client) configureTransport() error {
+	// hidden services are only reachable through Tor, so the default
+	// local Tor proxy is used for them unless a proxy is set or the
+	// HTTP client uses a custom round tripper
+	onion := false
+	if c.proxy == nil && c.hasOnionHost() {
+		_, onion = transportOf(c.hc)
+	}
+
+	if c.proxy == nil && !onion && c.dialer == nil && c.tlsConfig == nil && c.pinnedCert == nil && c.tuning == nil {
+		return nil
+	}
+
+	t, ok := transportOf(c.hc)
+	if !ok {
+		return errors.New("transport settings cannot be applied to a custom round tripper")
+	}
+
+	switch {
+	case c.proxy != nil:
+		t.Proxy = http.ProxyURL(c.proxy)
+	case onion:
+		t.Proxy = onionProxy(&url.URL{Scheme: "socks5", Host: torProxyAddr}, t.Proxy)
+	}
+
+	if c.dialer != nil {
+		t.DialContext = c.dialer.DialContext
+	}
+
+	if c.tlsConfig != nil {
+		t.TLSClientConfig = c.tlsConfig.Clone()
+	}
+
+	if c.pinnedCert != nil {
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{} //nolint:gosec // verification is done by pinning
+		}
+
+		verify, err := pinCertificates(c.pinnedCert)
+		if err != nil {
+			return err
+		}
+
+		// the default verification is replaced by pinning as
+		// self-signed certificates usually fail it
+		t.TLSClientConfig.InsecureSkipVerify = true //nolint:gosec // verification is done by pinning
+		t.TLSClientConfig.VerifyPeerCertificate = verify
+	}
+
+	if c.tuning != nil {
+		// all requests go to a single host, so the whole idle pool
+		// can be used by it
+		t.MaxIdleConns = c.tuning.maxIdleConns
+		t.MaxIdleConnsPerHost = c.tuning.maxIdleConns
+		t.MaxConnsPerHost = c.tuning.maxConnsPerHost
+		t.IdleConnTimeout = c.tuning.idleTimeout
+
+		// custom TLS settings disable HTTP/2 unless it is forced
+		t.ForceAttemptHTTP2 = true
+	}
+
+	hc := *c.hc
+	hc.Transport = t
+	c.hc = &hc
+
+	return nil
+}
+
+// pinCertificates parses all certificates in the PEM data and returns
+// a function that accepts only a server certificate that matches one of
+// them.
+func pinCertificates(pemData []byte) (func([][]byte, [][]*x509.Certificate) error, error) {
+	var pinned [][]byte
+
+	for {
+		var b *pem.Block
+
+		b, pemData = pem.Decode(pemData)
+		if b == nil {
+			break
+		}
+
+		if b.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(b.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		pinned = append(pinned, cert.Raw)
+	}
+
+	if len(pinned) == 0 {
+		return nil, errors.New("pinned certificate not found")
+	}
+
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("server certificate not provided")
+		}
+
+		for _, p := range pinned {
+			if bytes.Equal(p, rawCerts[0]) {
+				return nil
+			}
+		}
+
+		return errors.New("server certificate does not match the pinned certificate")
+	}, nil
+}
+
+// transportOf returns a copy of the HTTP client's transport. False is
+// returned if the client uses a custom round tripper.
+func transportOf(hc *http.Client) (*http.Transport, bool) {
+	switch t := hc.Transport.(type) {
+	case nil:
+		return http.DefaultTransport.(*http.Transport).Clone(), true
+	case *http.Transport:
+		return t.Clone(), true
+	default:
+		return nil, false
+	}
+}
+
+// onionProxy returns a proxy func that sends requests to hidden services
+// through the Tor proxy and all other requests through the next proxy
+// func, if any, so that clearnet hosts do not depend on Tor.
+func onionProxy(tor *url.URL, next func(*http.Request) (*url.URL, error)) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		if strings.HasSuffix(req.URL.Hostname(), ".onion") {
+			return tor, nil
+		}
+
+		if next == nil {
+			return nil, nil
+		}
+
+		return next(req)
+	}
+}
+
+// hasOnionHost checks whether the primary host or any of the fallback
+// hosts is a Tor hidden service address.
+func (c *Client) hasOnionHost() bool {
+	if isOnion(c.host) {
+		return true
+	}
+
+	if c.failover != nil {
+		for _, h := range c.failover.hosts {
+			if isOnion(h) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isOnion checks whether the host is a Tor hidden service address.
+func isOnion(host string) bool {
+	u, err := url.Parse(host)
+	if err != nil {
+		return false
+	}
+
+	return strings.HasSuffix(u.Hostname(), ".onion")
+}
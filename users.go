@@ -0,0 +1,79 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+)
+
+// UserParams holds data used to create a new user.
+// More at: https://docs.btcpayserver.org/API/Greenfield/v1/#tag/Users
+type UserParams struct {
+	Email           string    `json:"email"`
+	Password        Sensitive `json:"password,omitempty"`
+	IsAdministrator bool      `json:"isAdministrator"`
+}
+
+// User holds data of a server user.
+type User struct {
+	ID                        string   `json:"id"`
+	Email                     string   `json:"email"`
+	EmailConfirmed            bool     `json:"emailConfirmed"`
+	RequiresEmailConfirmation bool     `json:"requiresEmailConfirmation"`
+	Created                   int64    `json:"created"`
+	Roles                     []string `json:"roles"`
+}
+
+// CreateUser creates a new user. Depending on server settings, no API
+// key may be needed to create the first user.
+func (c *Client) CreateUser(ctx context.Context, p UserParams, opts ...RequestOption) (User, error) {
+	resp, err := c.sendAPI(ctx, http.MethodPost, newRoute("/api/v1/users"), nil, p, opts...)
+	if err != nil {
+		return User{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var u User
+
+	if err = c.decode(resp.Body, &u); err != nil {
+		return User{}, err
+	}
+
+	return u, nil
+}
+
+// Users retrieves all users of the server.
+func (c *Client) Users(ctx context.Context, opts ...RequestOption) ([]User, error) {
+	resp, err := c.sendAPI(ctx, http.MethodGet, newRoute("/api/v1/users"), nil, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var uu []User
+
+	if err = c.decode(resp.Body, &uu); err != nil {
+		return nil, err
+	}
+
+	return uu, nil
+}
+
+// CurrentUser retrieves the user that owns the API key.
+func (c *Client) CurrentUser(ctx context.Context, opts ...RequestOption) (User, error) {
+	resp, err := c.sendAPI(ctx, http.MethodGet, newRoute("/api/v1/users/me"), nil, nil, opts...)
+	if err != nil {
+		return User{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var u User
+
+	if err = c.decode(resp.Body, &u); err != nil {
+		return User{}, err
+	}
+
+	return u, nil
+}
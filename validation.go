@@ -0,0 +1,164 @@
+package btcpay
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"github.com/swithek/btcpay-go/currency"
+)
+
+// ValidationErrors holds all problems found during parameters
+// validation.
+type ValidationErrors []error
+
+// Error returns all validation errors joined into a single string.
+func (ee ValidationErrors) Error() string {
+	msgs := make([]string, len(ee))
+	for i, e := range ee {
+		msgs[i] = e.Error()
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// Validate checks whether the invoice creation parameters are valid.
+// All found problems are returned as ValidationErrors.
+func (p CreateInvoiceParams) Validate() error {
+	var ee ValidationErrors
+
+	if err := currency.ValidateCurrency(p.Currency); err != nil {
+		ee = append(ee, fmt.Errorf("currency: %w", err))
+	}
+
+	if p.Price.IsNegative() {
+		ee = append(ee, errors.New("price: cannot be negative"))
+	}
+
+	if !p.TransactionSpeed.Valid() {
+		ee = append(ee, errors.New("transactionSpeed: invalid value"))
+	}
+
+	if p.NotificationURL != "" && !validURL(p.NotificationURL) {
+		ee = append(ee, errors.New("notificationURL: invalid URL"))
+	}
+
+	if p.RedirectURL != "" && !validURL(p.RedirectURL) {
+		ee = append(ee, errors.New("redirectURL: invalid URL"))
+	}
+
+	if p.NotificationEmail != "" && !validEmail(p.NotificationEmail) {
+		ee = append(ee, errors.New("notificationEmail: invalid email"))
+	}
+
+	if p.Buyer.Email != "" && !validEmail(p.Buyer.Email.Reveal()) {
+		ee = append(ee, errors.New("buyer.email: invalid email"))
+	}
+
+	if len(ee) > 0 {
+		return ee
+	}
+
+	return nil
+}
+
+// Validate checks whether the Greenfield invoice creation parameters
+// are valid. All found problems are returned as ValidationErrors.
+func (p StoreInvoiceParams) Validate() error {
+	var ee ValidationErrors
+
+	if p.Currency != "" {
+		if err := currency.ValidateCurrency(p.Currency); err != nil {
+			ee = append(ee, fmt.Errorf("currency: %w", err))
+		}
+	}
+
+	if p.Amount.IsNegative() {
+		ee = append(ee, errors.New("amount: cannot be negative"))
+	}
+
+	if p.Checkout != nil {
+		ee = append(ee, p.Checkout.validate()...)
+	}
+
+	if len(ee) > 0 {
+		return ee
+	}
+
+	return nil
+}
+
+// Validate checks whether the split invoice creation parameters are
+// valid. All found problems are returned as ValidationErrors.
+func (p SplitInvoiceParams) Validate() error {
+	var ee ValidationErrors
+
+	if len(p.Parts) == 0 {
+		ee = append(ee, errors.New("parts: cannot be empty"))
+	}
+
+	for i, part := range p.Parts {
+		if part.StoreID == "" {
+			ee = append(ee, fmt.Errorf("parts[%d].storeId: cannot be empty", i))
+		}
+
+		if !part.Amount.IsPositive() {
+			ee = append(ee, fmt.Errorf("parts[%d].amount: must be positive", i))
+		}
+
+		err := StoreInvoiceParams{Currency: part.Currency, Checkout: part.Checkout}.Validate()
+		if err != nil {
+			for _, e := range err.(ValidationErrors) { //nolint:errorlint // only ValidationErrors are returned
+				ee = append(ee, fmt.Errorf("parts[%d].%w", i, e))
+			}
+		}
+	}
+
+	if len(ee) > 0 {
+		return ee
+	}
+
+	return nil
+}
+
+// validate checks whether the checkout settings are valid.
+func (ch InvoiceCheckout) validate() []error {
+	var ee []error
+
+	if !ch.SpeedPolicy.Valid() {
+		ee = append(ee, errors.New("checkout.speedPolicy: invalid value"))
+	}
+
+	if ch.ExpirationMinutes < 0 {
+		ee = append(ee, errors.New("checkout.expirationMinutes: cannot be negative"))
+	}
+
+	if ch.MonitoringMinutes < 0 {
+		ee = append(ee, errors.New("checkout.monitoringMinutes: cannot be negative"))
+	}
+
+	if ch.PaymentTolerance != nil && (ch.PaymentTolerance.IsNegative() || ch.PaymentTolerance.GreaterThan(decimal.NewFromInt(100))) {
+		ee = append(ee, errors.New("checkout.paymentTolerance: must be between 0 and 100"))
+	}
+
+	if ch.RedirectURL != "" && !validURL(ch.RedirectURL) {
+		ee = append(ee, errors.New("checkout.redirectURL: invalid URL"))
+	}
+
+	return ee
+}
+
+// validURL checks whether the provided value is an absolute URL.
+func validURL(v string) bool {
+	u, err := url.Parse(v)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// validEmail checks whether the provided value is a bare email address.
+func validEmail(v string) bool {
+	a, err := mail.ParseAddress(v)
+	return err == nil && a.Address == v
+}
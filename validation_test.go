@@ -0,0 +1,208 @@
+package btcpay
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ValidationErrors_Error(t *testing.T) {
+	ee := ValidationErrors{errors.New("a"), errors.New("b")}
+	assert.Equal(t, "a; b", ee.Error())
+}
+
+func Test_CreateInvoiceParams_Validate(t *testing.T) {
+	cc := map[string]struct {
+		Params CreateInvoiceParams
+		ErrMsg string
+	}{
+		"Invalid params": {
+			Params: CreateInvoiceParams{
+				Currency:          "US$",
+				Price:             decimal.NewFromInt(-1),
+				TransactionSpeed:  "fast",
+				NotificationURL:   "/ipn",
+				RedirectURL:       "::",
+				NotificationEmail: "test",
+				Buyer: InvoiceBuyer{
+					Email: "Test <test@test.com>",
+				},
+			},
+			ErrMsg: "currency: invalid code; " +
+				"price: cannot be negative; " +
+				"transactionSpeed: invalid value; " +
+				"notificationURL: invalid URL; " +
+				"redirectURL: invalid URL; " +
+				"notificationEmail: invalid email; " +
+				"buyer.email: invalid email",
+		},
+		"Lowercase currency": {
+			Params: CreateInvoiceParams{
+				Currency: "usd",
+			},
+		},
+		"Currency missing from the currency list": {
+			Params: CreateInvoiceParams{
+				Currency: "ABCD",
+			},
+		},
+		"Valid minimal params": {
+			Params: CreateInvoiceParams{
+				Currency: "USD",
+			},
+		},
+		"Valid params": {
+			Params: CreateInvoiceParams{
+				Currency:          "BTC",
+				Price:             decimal.NewFromInt(1),
+				TransactionSpeed:  SpeedHigh,
+				NotificationURL:   "https://test.com/ipn",
+				RedirectURL:       "https://test.com/done",
+				NotificationEmail: "test@test.com",
+				Buyer: InvoiceBuyer{
+					Email: "buyer@test.com",
+				},
+			},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			err := c.Params.Validate()
+			if c.ErrMsg != "" {
+				assert.EqualError(t, err, c.ErrMsg)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_TransactionSpeed_Valid(t *testing.T) {
+	for _, s := range []TransactionSpeed{"", SpeedHigh, SpeedMedium, SpeedLowMedium, SpeedLow} {
+		assert.True(t, s.Valid(), s)
+	}
+
+	assert.False(t, TransactionSpeed("fast").Valid())
+	assert.False(t, TransactionSpeed("HIGH").Valid())
+}
+
+func Test_StoreInvoiceParams_Validate(t *testing.T) {
+	tolerance := decimal.NewFromInt(101)
+
+	cc := map[string]struct {
+		Params StoreInvoiceParams
+		ErrMsg string
+	}{
+		"Invalid params": {
+			Params: StoreInvoiceParams{
+				Currency: "US$",
+				Amount:   decimal.NewFromInt(-1),
+				Checkout: &InvoiceCheckout{
+					SpeedPolicy:       "high",
+					ExpirationMinutes: -1,
+					MonitoringMinutes: -1,
+					PaymentTolerance:  &tolerance,
+					RedirectURL:       "/done",
+				},
+			},
+			ErrMsg: "currency: invalid code; " +
+				"amount: cannot be negative; " +
+				"checkout.speedPolicy: invalid value; " +
+				"checkout.expirationMinutes: cannot be negative; " +
+				"checkout.monitoringMinutes: cannot be negative; " +
+				"checkout.paymentTolerance: must be between 0 and 100; " +
+				"checkout.redirectURL: invalid URL",
+		},
+		"Valid minimal params": {},
+		"Valid params": {
+			Params: StoreInvoiceParams{
+				Currency: "USD",
+				Amount:   decimal.NewFromInt(10),
+				Checkout: &InvoiceCheckout{
+					SpeedPolicy:       SpeedPolicyLow,
+					ExpirationMinutes: 15,
+					RedirectURL:       "https://test.com/done",
+				},
+			},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			err := c.Params.Validate()
+			if c.ErrMsg != "" {
+				assert.EqualError(t, err, c.ErrMsg)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_SpeedPolicy_Valid(t *testing.T) {
+	for _, s := range []SpeedPolicy{"", SpeedPolicyHigh, SpeedPolicyMedium, SpeedPolicyLowMedium, SpeedPolicyLow} {
+		assert.True(t, s.Valid(), s)
+	}
+
+	assert.False(t, SpeedPolicy("high").Valid())
+}
+
+func Test_SplitInvoiceParams_Validate(t *testing.T) {
+	cc := map[string]struct {
+		Params SplitInvoiceParams
+		ErrMsg string
+	}{
+		"No parts": {
+			ErrMsg: "parts: cannot be empty",
+		},
+		"Invalid parts": {
+			Params: SplitInvoiceParams{
+				Parts: []InvoicePart{
+					{StoreID: "s1", Amount: decimal.NewFromInt(1)},
+					{Currency: "US$", Checkout: &InvoiceCheckout{ExpirationMinutes: -1}},
+				},
+			},
+			ErrMsg: "parts[1].storeId: cannot be empty; " +
+				"parts[1].amount: must be positive; " +
+				"parts[1].currency: invalid code; " +
+				"parts[1].checkout.expirationMinutes: cannot be negative",
+		},
+		"Valid params": {
+			Params: SplitInvoiceParams{
+				Parts: []InvoicePart{
+					{StoreID: "s1", Amount: decimal.NewFromInt(10), Currency: "USD"},
+					{StoreID: "s2", Amount: decimal.NewFromInt(5), Currency: "EUR"},
+				},
+			},
+		},
+	}
+
+	for cn, c := range cc {
+		c := c
+
+		t.Run(cn, func(t *testing.T) {
+			t.Parallel()
+
+			err := c.Params.Validate()
+			if c.ErrMsg != "" {
+				assert.EqualError(t, err, c.ErrMsg)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package btcpay
+
+import "strings"
+
+// Version is the version of this module. It is included in the default
+// user agent of the BTCPay client, so that server-side logs identify
+// the client's version.
+const Version = "0.1.0"
+
+// userAgent returns the default user agent with the suffixes as its
+// comment.
+func userAgent(suffixes []string) string {
+	ua := "btcpay-go/" + Version
+
+	var ss []string
+
+	for _, s := range suffixes {
+		if s = strings.TrimSpace(s); s != "" {
+			ss = append(ss, s)
+		}
+	}
+
+	if len(ss) > 0 {
+		ua += " (" + strings.Join(ss, "; ") + ")"
+	}
+
+	return ua
+}
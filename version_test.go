@@ -0,0 +1,13 @@
+package btcpay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_userAgent(t *testing.T) {
+	assert.Equal(t, "btcpay-go/"+Version, userAgent(nil))
+	assert.Equal(t, "btcpay-go/"+Version, userAgent([]string{" "}))
+	assert.Equal(t, "btcpay-go/"+Version+" (shop/1.2; test)", userAgent([]string{"shop/1.2", " test "}))
+}
@@ -0,0 +1,252 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+)
+
+// WalletClient provides access to the on-chain wallet of a store.
+// More at: https://docs.btcpayserver.org/API/Greenfield/v1/#tag/Store-Wallet-(On-Chain)
+type WalletClient struct {
+	c        *Client
+	endpoint route
+}
+
+// Wallet returns a client of the on-chain wallet of the specified store
+// and cryptocurrency.
+func (c *Client) Wallet(storeID, cryptoCode string) *WalletClient {
+	return &WalletClient{
+		c:        c,
+		endpoint: newRoute("/api/v1/stores/:id/payment-methods/onchain/:id/wallet", storeID, cryptoCode),
+	}
+}
+
+// WalletBalance holds balance data of an on-chain wallet.
+type WalletBalance struct {
+	Balance            decimal.Decimal `json:"balance"`
+	UnconfirmedBalance decimal.Decimal `json:"unconfirmedBalance"`
+	ConfirmedBalance   decimal.Decimal `json:"confirmedBalance"`
+	Label              string          `json:"label"`
+}
+
+// WalletAddress holds data of a receive address of an on-chain wallet.
+type WalletAddress struct {
+	Address     string `json:"address"`
+	KeyPath     string `json:"keyPath"`
+	PaymentLink string `json:"paymentLink"`
+}
+
+// WalletTransaction holds data of a single on-chain wallet transaction.
+type WalletTransaction struct {
+	TransactionHash string          `json:"transactionHash"`
+	Comment         string          `json:"comment"`
+	Amount          decimal.Decimal `json:"amount"`
+	BlockHash       string          `json:"blockHash"`
+	BlockHeight     int64           `json:"blockHeight"`
+	Confirmations   int64           `json:"confirmations"`
+	Timestamp       int64           `json:"timestamp"`
+	Status          string          `json:"status"`
+}
+
+// WalletTransactionsParams holds data used to filter wallet
+// transactions.
+type WalletTransactionsParams struct {
+	Statuses []string
+	Skip     int
+	Limit    int
+}
+
+// TransactionDestination holds data of a single transaction output.
+type TransactionDestination struct {
+	Destination        string          `json:"destination"`
+	Amount             decimal.Decimal `json:"amount"`
+	SubtractFromAmount bool            `json:"subtractFromAmount,omitempty"`
+}
+
+// CreateTransactionParams holds data used to create a new on-chain
+// transaction.
+type CreateTransactionParams struct {
+	Destinations   []TransactionDestination `json:"destinations"`
+	FeeRate        *decimal.Decimal         `json:"feerate,omitempty"`
+	NoChange       bool                     `json:"noChange,omitempty"`
+	RBF            *bool                    `json:"rbf,omitempty"`
+	SelectedInputs []string                 `json:"selectedInputs,omitempty"`
+}
+
+// Balance retrieves the balance of the wallet.
+func (wc *WalletClient) Balance(ctx context.Context, opts ...RequestOption) (WalletBalance, error) {
+	resp, err := wc.c.sendAPI(ctx, http.MethodGet, wc.endpoint, nil, nil, opts...)
+	if err != nil {
+		return WalletBalance{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var wb WalletBalance
+
+	if err = wc.c.decode(resp.Body, &wb); err != nil {
+		return WalletBalance{}, err
+	}
+
+	return wb, nil
+}
+
+// FeeRate retrieves the estimated fee rate (in sat/vB) needed for a
+// transaction to be confirmed within the specified number of blocks.
+// If blockTarget is zero, the store's default is used.
+func (wc *WalletClient) FeeRate(ctx context.Context, blockTarget int, opts ...RequestOption) (decimal.Decimal, error) {
+	var params url.Values
+
+	if blockTarget > 0 {
+		params = url.Values{}
+		params.Set("blockTarget", strconv.Itoa(blockTarget))
+	}
+
+	resp, err := wc.c.sendAPI(ctx, http.MethodGet, wc.endpoint.join("/feerate"), params, nil, opts...)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var fr struct {
+		FeeRate decimal.Decimal `json:"feerate"`
+	}
+
+	if err = wc.c.decode(resp.Body, &fr); err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	return fr.FeeRate, nil
+}
+
+// ReceiveAddress retrieves an unused receive address of the wallet. If
+// forceGenerate is true, a new address is generated even if the
+// current one has not been used yet.
+func (wc *WalletClient) ReceiveAddress(ctx context.Context, forceGenerate bool, opts ...RequestOption) (WalletAddress, error) {
+	params := url.Values{}
+	params.Set("forceGenerate", strconv.FormatBool(forceGenerate))
+
+	resp, err := wc.c.sendAPI(ctx, http.MethodGet, wc.endpoint.join("/address"), params, nil, opts...)
+	if err != nil {
+		return WalletAddress{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var wa WalletAddress
+
+	if err = wc.c.decode(resp.Body, &wa); err != nil {
+		return WalletAddress{}, err
+	}
+
+	return wa, nil
+}
+
+// Transactions retrieves transactions of the wallet.
+func (wc *WalletClient) Transactions(ctx context.Context, p WalletTransactionsParams, opts ...RequestOption) ([]WalletTransaction, error) {
+	params := url.Values{}
+
+	for _, s := range p.Statuses {
+		params.Add("statusFilter", s)
+	}
+
+	if p.Skip > 0 {
+		params.Set("skip", strconv.Itoa(p.Skip))
+	}
+
+	if p.Limit > 0 {
+		params.Set("limit", strconv.Itoa(p.Limit))
+	}
+
+	resp, err := wc.c.sendAPI(ctx, http.MethodGet, wc.endpoint.join("/transactions"), params, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var tt []WalletTransaction
+
+	if err = wc.c.decode(resp.Body, &tt); err != nil {
+		return nil, err
+	}
+
+	return tt, nil
+}
+
+// Transaction retrieves a wallet transaction by the provided ID.
+func (wc *WalletClient) Transaction(ctx context.Context, txID string, opts ...RequestOption) (WalletTransaction, error) {
+	resp, err := wc.c.sendAPI(ctx, http.MethodGet, wc.endpoint.join("/transactions/:id", txID), nil, nil, opts...)
+	if err != nil {
+		return WalletTransaction{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var tx WalletTransaction
+
+	if err = wc.c.decode(resp.Body, &tx); err != nil {
+		return WalletTransaction{}, err
+	}
+
+	return tx, nil
+}
+
+// CreateTransaction creates and signs a new transaction with the hot
+// wallet without broadcasting it. The signed transaction is returned
+// in a hexadecimal format.
+func (wc *WalletClient) CreateTransaction(ctx context.Context, p CreateTransactionParams, opts ...RequestOption) (string, error) {
+	data := struct {
+		CreateTransactionParams
+		ProceedWithBroadcast bool `json:"proceedWithBroadcast"`
+	}{
+		CreateTransactionParams: p,
+	}
+
+	resp, err := wc.c.sendAPI(ctx, http.MethodPost, wc.endpoint.join("/transactions"), nil, data, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	var hx string
+
+	if err = wc.c.decode(resp.Body, &hx); err != nil {
+		return "", err
+	}
+
+	return hx, nil
+}
+
+// SendTransaction creates and signs a new transaction with the hot
+// wallet and broadcasts it to the network.
+func (wc *WalletClient) SendTransaction(ctx context.Context, p CreateTransactionParams, opts ...RequestOption) (WalletTransaction, error) {
+	data := struct {
+		CreateTransactionParams
+		ProceedWithBroadcast bool `json:"proceedWithBroadcast"`
+	}{
+		CreateTransactionParams: p,
+		ProceedWithBroadcast:    true,
+	}
+
+	resp, err := wc.c.sendAPI(ctx, http.MethodPost, wc.endpoint.join("/transactions"), nil, data, opts...)
+	if err != nil {
+		return WalletTransaction{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var tx WalletTransaction
+
+	if err = wc.c.decode(resp.Body, &tx); err != nil {
+		return WalletTransaction{}, err
+	}
+
+	return tx, nil
+}
@@ -0,0 +1,178 @@
+package btcpay
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// WebhookEvents specifies which events are sent to a webhook.
+type WebhookEvents struct {
+	Everything     bool        `json:"everything"`
+	SpecificEvents []EventType `json:"specificEvents,omitempty"`
+}
+
+// WebhookParams holds data used to create or update a webhook.
+// More at: https://docs.btcpayserver.org/API/Greenfield/v1/#tag/Webhooks
+type WebhookParams struct {
+	URL                 string        `json:"url"`
+	Enabled             bool          `json:"enabled"`
+	AutomaticRedelivery bool          `json:"automaticRedelivery"`
+	AuthorizedEvents    WebhookEvents `json:"authorizedEvents"`
+
+	// Secret is used to sign deliveries. If it is empty during
+	// creation, the server generates one; if it is empty during
+	// update, the current secret is kept.
+	Secret string `json:"secret,omitempty"`
+}
+
+// Webhook holds webhook data retrieved from the payment processor.
+type Webhook struct {
+	ID                  string        `json:"id"`
+	URL                 string        `json:"url"`
+	Enabled             bool          `json:"enabled"`
+	AutomaticRedelivery bool          `json:"automaticRedelivery"`
+	AuthorizedEvents    WebhookEvents `json:"authorizedEvents"`
+
+	// Secret is returned only when the webhook is created.
+	Secret string `json:"secret"`
+}
+
+// WebhookDelivery holds data of a single delivery attempt of a webhook
+// event.
+type WebhookDelivery struct {
+	ID           string `json:"id"`
+	Timestamp    int64  `json:"timestamp"`
+	HTTPCode     int    `json:"httpCode"`
+	ErrorMessage string `json:"errorMessage"`
+	Status       string `json:"status"`
+}
+
+// CreateWebhook creates a new webhook in the specified store.
+func (c *Client) CreateWebhook(ctx context.Context, storeID string, p WebhookParams, opts ...RequestOption) (Webhook, error) {
+	resp, err := c.sendAPI(ctx, http.MethodPost, newRoute("/api/v1/stores/:id/webhooks", storeID), nil, p, opts...)
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var wh Webhook
+
+	if err = c.decode(resp.Body, &wh); err != nil {
+		return Webhook{}, err
+	}
+
+	return wh, nil
+}
+
+// Webhooks retrieves all webhooks of the specified store.
+func (c *Client) Webhooks(ctx context.Context, storeID string, opts ...RequestOption) ([]Webhook, error) {
+	resp, err := c.sendAPI(ctx, http.MethodGet, newRoute("/api/v1/stores/:id/webhooks", storeID), nil, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var whs []Webhook
+
+	if err = c.decode(resp.Body, &whs); err != nil {
+		return nil, err
+	}
+
+	return whs, nil
+}
+
+// Webhook retrieves a webhook of the specified store by the provided
+// ID.
+func (c *Client) Webhook(ctx context.Context, storeID, id string, opts ...RequestOption) (Webhook, error) {
+	resp, err := c.sendAPI(ctx, http.MethodGet, newRoute("/api/v1/stores/:id/webhooks/:id", storeID, id), nil, nil, opts...)
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var wh Webhook
+
+	if err = c.decode(resp.Body, &wh); err != nil {
+		return Webhook{}, err
+	}
+
+	return wh, nil
+}
+
+// UpdateWebhook updates the specified webhook of the store.
+func (c *Client) UpdateWebhook(ctx context.Context, storeID, id string, p WebhookParams, opts ...RequestOption) (Webhook, error) {
+	resp, err := c.sendAPI(ctx, http.MethodPut, newRoute("/api/v1/stores/:id/webhooks/:id", storeID, id), nil, p, opts...)
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var wh Webhook
+
+	if err = c.decode(resp.Body, &wh); err != nil {
+		return Webhook{}, err
+	}
+
+	return wh, nil
+}
+
+// DeleteWebhook removes the specified webhook of the store.
+func (c *Client) DeleteWebhook(ctx context.Context, storeID, id string, opts ...RequestOption) error {
+	resp, err := c.sendAPI(ctx, http.MethodDelete, newRoute("/api/v1/stores/:id/webhooks/:id", storeID, id), nil, nil, opts...)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// WebhookDeliveries retrieves the latest deliveries of the specified
+// webhook of the store. If count is zero, the server's default is used.
+func (c *Client) WebhookDeliveries(ctx context.Context, storeID, id string, count int, opts ...RequestOption) ([]WebhookDelivery, error) {
+	var params url.Values
+
+	if count > 0 {
+		params = url.Values{}
+		params.Set("count", strconv.Itoa(count))
+	}
+
+	resp, err := c.sendAPI(ctx, http.MethodGet, newRoute("/api/v1/stores/:id/webhooks/:id/deliveries", storeID, id), params, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var dd []WebhookDelivery
+
+	if err = c.decode(resp.Body, &dd); err != nil {
+		return nil, err
+	}
+
+	return dd, nil
+}
+
+// RedeliverWebhook sends the event of the specified delivery to the
+// webhook again. The ID of the new delivery is returned.
+func (c *Client) RedeliverWebhook(ctx context.Context, storeID, id, deliveryID string, opts ...RequestOption) (string, error) {
+	resp, err := c.sendAPI(ctx, http.MethodPost, newRoute("/api/v1/stores/:id/webhooks/:id/deliveries/:id/redeliver", storeID, id, deliveryID), nil, nil, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	var newID string
+
+	if err = c.decode(resp.Body, &newID); err != nil {
+		return "", err
+	}
+
+	return newID, nil
+}